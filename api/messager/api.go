@@ -0,0 +1,111 @@
+package messager
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/api/messager"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	messagerTypes "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// IMessager extends the messager api defined in venus-shared with the features only supported by sophon-messager
+type IMessager interface {
+	messager.IMessager
+
+	// PushMessageWithSpec push message with priority, a new id will be generated if id is empty
+	PushMessageWithSpec(ctx context.Context, id string, msg *venusTypes.Message, spec *types.SendSpec) (string, error) //perm:write
+	SendWithSpec(ctx context.Context, params types.QuickSendParams) (string, error)                                    //perm:sign
+	// SetMessagePriority only the priority of unfill message can be changed
+	SetMessagePriority(ctx context.Context, id string, priority int) error //perm:write
+	// SimulateSelect runs the message selection of addr against the current head, but stops before signing and saving
+	SimulateSelect(ctx context.Context, addr address.Address) (*types.SelectSimulation, error) //perm:read
+	// NonceGapReport reports the assigned nonces which have no FillMsg message, they block the messages with bigger nonce
+	NonceGapReport(ctx context.Context, addr address.Address) (*types.NonceGapReport, error) //perm:read
+	// FillNonceGap re-signs the failed message or sends zero value message to self for each nonce gap
+	FillNonceGap(ctx context.Context, addr address.Address) ([]string, error) //perm:write
+	// SubscribeMessageEvents pushes an event when a message matched filter moves to a new state, requires websocket
+	SubscribeMessageEvents(ctx context.Context, filter *types.MessageEventFilter) (<-chan *types.MessageEvent, error) //perm:read
+	// GetMessageRevisions lists every signed version of the message from the oldest to the newest
+	GetMessageRevisions(ctx context.Context, id string) ([]*types.MessageRevision, error) //perm:read
+	// FeeReport sums the gas spent by on chain messages, grouped by actor, method or day
+	FeeReport(ctx context.Context, params *types.FeeReportParams) (*types.FeeReport, error) //perm:read
+
+	// SetFeeBumpPolicy creates or updates the fee bump policy of an address or an actor
+	SetFeeBumpPolicy(ctx context.Context, policy *types.FeeBumpPolicy) error //perm:admin
+	ListFeeBumpPolicy(ctx context.Context) ([]*types.FeeBumpPolicy, error)   //perm:read
+	DeleteFeeBumpPolicy(ctx context.Context, id string) error                //perm:admin
+	// ListFeeBumpRecord lists the automatic replacements of the message
+	ListFeeBumpRecord(ctx context.Context, id string) ([]*types.FeeBumpRecord, error) //perm:read
+
+	SetSelectStrategy(ctx context.Context, addr address.Address, strategy string) error       //perm:write
+	GetAddressConfig(ctx context.Context, addr address.Address) (*types.AddressConfig, error) //perm:read
+	SetAutoFillNonceGap(ctx context.Context, addr address.Address, enable bool) error         //perm:write
+	// SetBalanceReserve messages are held back when their worst-case cost eats into the reserve of balance
+	SetBalanceReserve(ctx context.Context, addr address.Address, reserve big.Int) error //perm:admin
+	// SetBatchSend collapses the small sends of address into one message of the aggregator in config before selecting
+	SetBatchSend(ctx context.Context, addr address.Address, enable bool) error //perm:write
+	// SetSpendBudget limits the value and the gas fee of messages signed in a rolling window, messages exceeding it are left unfill,
+	// admin only, so a leaked token with sign or write permission can not lift the limit
+	SetSpendBudget(ctx context.Context, addr address.Address, budget *types.SpendBudget) error //perm:admin
+
+	// SetSignerPolicy creates a policy if its id is empty, otherwise updates the policy with the id, returns the id of policy.
+	// Once a signer has policies, the messages pushed from it must match one of them
+	SetSignerPolicy(ctx context.Context, policy *types.SignerPolicy) (string, error) //perm:admin
+	// ListSignerPolicy lists the policies of signer, all policies if signer is address.Undef
+	ListSignerPolicy(ctx context.Context, signer address.Address) ([]*types.SignerPolicy, error) //perm:read
+	DeleteSignerPolicy(ctx context.Context, id string) error                                     //perm:admin
+
+	// ProposeMsig wraps the inner message into a propose message of multisig, returns the id of proposal.
+	// The TxnID of proposal is parsed from the receipt of propose message
+	ProposeMsig(ctx context.Context, params *types.MsigProposeParams) (string, error) //perm:write
+	// ApproveMsig pushes an approve message of the proposal from another signer, returns the id of message
+	ApproveMsig(ctx context.Context, params *types.MsigApproveParams) (string, error) //perm:write
+	// GetMsigProposal the proposal is only visible to the users of its proposer and approvers, or admin
+	GetMsigProposal(ctx context.Context, id string) (*types.MsigProposal, error) //perm:read
+	// ListMsigProposal lists the proposals of msig, all proposals if msig is address.Undef,
+	// only the proposals proposed or approved by the signers of user are listed unless it is admin
+	ListMsigProposal(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error) //perm:read
+
+	// PushEthTransaction builds the filecoin message of an EIP-1559 transaction from a delegated (f4) address and pushes it,
+	// returns the id of message. The message is signed with the delegated signature and can be found by the eth tx hash
+	PushEthTransaction(ctx context.Context, params *types.EthTxParams) (string, error) //perm:write
+	// GetMessageByEthHash finds the message by the hash of any eth transaction signed for it
+	GetMessageByEthHash(ctx context.Context, hash venusTypes.EthHash) (*messagerTypes.Message, error) //perm:read
+
+	// SetContractABI saves the json abi of an EVM actor, which is used to encode the calls of `SendWithSpec`
+	// with `ABIMethod` and to decode the params and return of messages to the actor
+	SetContractABI(ctx context.Context, contractABI *types.ContractABI) error             //perm:admin
+	GetContractABI(ctx context.Context, addr address.Address) (*types.ContractABI, error) //perm:read
+	ListContractABI(ctx context.Context) ([]*types.ContractABI, error)                    //perm:read
+	DeleteContractABI(ctx context.Context, addr address.Address) error                    //perm:admin
+
+	// GetDecodedMessage gets the message like `GetMessageByUid`, with its params and return decoded into json
+	// by the method of recipient actor
+	GetDecodedMessage(ctx context.Context, id string) (*types.DecodedMessage, error) //perm:read
+	// ListDecodedMessage lists the messages like `ListMessage`, with their params and return decoded into json
+	ListDecodedMessage(ctx context.Context, p *messagerTypes.MsgQueryParams) ([]*types.DecodedMessage, error) //perm:read
+	// ListDecodedMessageByFromState lists the messages like `ListMessageByFromState`, with their params and return decoded into json
+	ListDecodedMessageByFromState(ctx context.Context, from address.Address, state messagerTypes.MessageState, isAsc bool, pageIndex, pageSize int, d time.Duration) ([]*types.DecodedMessage, error) //perm:read
+
+	// SetExitCodeRule creates a rule if its id is empty, otherwise updates the rule with the id, returns the id of rule.
+	// The matched rule is applied to the message landed on chain with a non-zero exit code
+	SetExitCodeRule(ctx context.Context, rule *types.ExitCodeRule) (string, error) //perm:admin
+	ListExitCodeRule(ctx context.Context) ([]*types.ExitCodeRule, error)           //perm:read
+	DeleteExitCodeRule(ctx context.Context, id string) error                       //perm:admin
+	// ListMessageRetries lists the retries linked with the message, from the first message to the last retry
+	ListMessageRetries(ctx context.Context, id string) ([]*types.MessageRetry, error) //perm:read
+	// SetActorCfgPreflight enables or disables the pre-flight StateCall of the messages matching the actor config,
+	// the messages whose simulated exit code is non-zero are left unfill with the reason in ErrorMsg
+	SetActorCfgPreflight(ctx context.Context, id venusTypes.UUID, enable bool) error //perm:admin
+	// ListPreflightActorCfg lists the ids of actor configs with pre-flight enabled
+	ListPreflightActorCfg(ctx context.Context) ([]venusTypes.UUID, error) //perm:read
+	// GetLeader gets the lease of leader if leader election is enabled, only the leader selects and pushes messages
+	GetLeader(ctx context.Context) (*types.Lease, error) //perm:read
+	// ListAddressShard lists the instances holding the addresses if sharding is enabled
+	ListAddressShard(ctx context.Context) ([]*types.AddressShard, error) //perm:read
+}
@@ -0,0 +1,49 @@
+package messager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/filecoin-project/go-jsonrpc"
+	"github.com/filecoin-project/venus/venus-shared/api"
+	"github.com/filecoin-project/venus/venus-shared/api/messager"
+)
+
+// NewIMessagerRPC creates a new httpparse jsonrpc remotecli.
+func NewIMessagerRPC(ctx context.Context, addr string, requestHeader http.Header, opts ...jsonrpc.Option) (IMessager, jsonrpc.ClientCloser, error) {
+	endpoint, err := api.Endpoint(addr, messager.MajorVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid addr %s: %w", addr, err)
+	}
+
+	if requestHeader == nil {
+		requestHeader = http.Header{}
+	}
+	requestHeader.Set(api.VenusAPINamespaceHeader, messager.APINamespace)
+
+	var res IMessagerStruct
+	closer, err := jsonrpc.NewMergeClient(ctx, endpoint, messager.MethodNamespace, api.GetInternalStructs(&res), requestHeader, opts...)
+
+	return &res, closer, err
+}
+
+// DialIMessagerRPC is a more convenient way of building client, as it resolves any format (url, multiaddr) of addr string.
+func DialIMessagerRPC(ctx context.Context, addr string, token string, requestHeader http.Header, opts ...jsonrpc.Option) (IMessager, jsonrpc.ClientCloser, error) {
+	ainfo := api.NewAPIInfo(addr, token)
+	endpoint, err := ainfo.DialArgs(api.VerString(messager.MajorVersion))
+	if err != nil {
+		return nil, nil, fmt.Errorf("get dial args: %w", err)
+	}
+
+	if requestHeader == nil {
+		requestHeader = http.Header{}
+	}
+	requestHeader.Set(api.VenusAPINamespaceHeader, messager.APINamespace)
+	ainfo.SetAuthHeader(requestHeader)
+
+	var res IMessagerStruct
+	closer, err := jsonrpc.NewMergeClient(ctx, endpoint, messager.MethodNamespace, api.GetInternalStructs(&res), requestHeader, opts...)
+
+	return &res, closer, err
+}
@@ -0,0 +1,194 @@
+package messager
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/api/messager"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	messagerTypes "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type IMessagerStruct struct {
+	messager.IMessagerStruct
+
+	Internal struct {
+		ApproveMsig                   func(ctx context.Context, params *types.MsigApproveParams) (string, error)                                                                                               `perm:"write"`
+		DeleteContractABI             func(ctx context.Context, addr address.Address) error                                                                                                                    `perm:"admin"`
+		DeleteExitCodeRule            func(ctx context.Context, id string) error                                                                                                                               `perm:"admin"`
+		DeleteFeeBumpPolicy           func(ctx context.Context, id string) error                                                                                                                               `perm:"admin"`
+		DeleteSignerPolicy            func(ctx context.Context, id string) error                                                                                                                               `perm:"admin"`
+		FeeReport                     func(ctx context.Context, params *types.FeeReportParams) (*types.FeeReport, error)                                                                                       `perm:"read"`
+		FillNonceGap                  func(ctx context.Context, addr address.Address) ([]string, error)                                                                                                        `perm:"write"`
+		GetAddressConfig              func(ctx context.Context, addr address.Address) (*types.AddressConfig, error)                                                                                            `perm:"read"`
+		GetContractABI                func(ctx context.Context, addr address.Address) (*types.ContractABI, error)                                                                                              `perm:"read"`
+		GetDecodedMessage             func(ctx context.Context, id string) (*types.DecodedMessage, error)                                                                                                      `perm:"read"`
+		GetLeader                     func(ctx context.Context) (*types.Lease, error)                                                                                                                          `perm:"read"`
+		GetMessageByEthHash           func(ctx context.Context, hash venusTypes.EthHash) (*messagerTypes.Message, error)                                                                                       `perm:"read"`
+		GetMessageRevisions           func(ctx context.Context, id string) ([]*types.MessageRevision, error)                                                                                                   `perm:"read"`
+		GetMsigProposal               func(ctx context.Context, id string) (*types.MsigProposal, error)                                                                                                        `perm:"read"`
+		ListAddressShard              func(ctx context.Context) ([]*types.AddressShard, error)                                                                                                                 `perm:"read"`
+		ListContractABI               func(ctx context.Context) ([]*types.ContractABI, error)                                                                                                                  `perm:"read"`
+		ListDecodedMessage            func(ctx context.Context, p *messagerTypes.MsgQueryParams) ([]*types.DecodedMessage, error)                                                                              `perm:"read"`
+		ListDecodedMessageByFromState func(ctx context.Context, from address.Address, state messagerTypes.MessageState, isAsc bool, pageIndex, pageSize int, d time.Duration) ([]*types.DecodedMessage, error) `perm:"read"`
+		ListExitCodeRule              func(ctx context.Context) ([]*types.ExitCodeRule, error)                                                                                                                 `perm:"read"`
+		ListFeeBumpPolicy             func(ctx context.Context) ([]*types.FeeBumpPolicy, error)                                                                                                                `perm:"read"`
+		ListFeeBumpRecord             func(ctx context.Context, id string) ([]*types.FeeBumpRecord, error)                                                                                                     `perm:"read"`
+		ListMessageRetries            func(ctx context.Context, id string) ([]*types.MessageRetry, error)                                                                                                      `perm:"read"`
+		ListMsigProposal              func(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error)                                                                                           `perm:"read"`
+		ListPreflightActorCfg         func(ctx context.Context) ([]venusTypes.UUID, error)                                                                                                                     `perm:"read"`
+		ListSignerPolicy              func(ctx context.Context, signer address.Address) ([]*types.SignerPolicy, error)                                                                                         `perm:"read"`
+		NonceGapReport                func(ctx context.Context, addr address.Address) (*types.NonceGapReport, error)                                                                                           `perm:"read"`
+		ProposeMsig                   func(ctx context.Context, params *types.MsigProposeParams) (string, error)                                                                                               `perm:"write"`
+		PushEthTransaction            func(ctx context.Context, params *types.EthTxParams) (string, error)                                                                                                     `perm:"write"`
+		PushMessageWithSpec           func(ctx context.Context, id string, msg *venusTypes.Message, spec *types.SendSpec) (string, error)                                                                      `perm:"write"`
+		SendWithSpec                  func(ctx context.Context, params types.QuickSendParams) (string, error)                                                                                                  `perm:"sign"`
+		SetActorCfgPreflight          func(ctx context.Context, id venusTypes.UUID, enable bool) error                                                                                                         `perm:"admin"`
+		SetBalanceReserve             func(ctx context.Context, addr address.Address, reserve big.Int) error                                                                                                   `perm:"admin"`
+		SetBatchSend                  func(ctx context.Context, addr address.Address, enable bool) error                                                                                                       `perm:"write"`
+		SetContractABI                func(ctx context.Context, contractABI *types.ContractABI) error                                                                                                          `perm:"admin"`
+		SetExitCodeRule               func(ctx context.Context, rule *types.ExitCodeRule) (string, error)                                                                                                      `perm:"admin"`
+		SetFeeBumpPolicy              func(ctx context.Context, policy *types.FeeBumpPolicy) error                                                                                                             `perm:"admin"`
+		SetMessagePriority            func(ctx context.Context, id string, priority int) error                                                                                                                 `perm:"write"`
+		SetAutoFillNonceGap           func(ctx context.Context, addr address.Address, enable bool) error                                                                                                       `perm:"write"`
+		SetSelectStrategy             func(ctx context.Context, addr address.Address, strategy string) error                                                                                                   `perm:"write"`
+		SetSignerPolicy               func(ctx context.Context, policy *types.SignerPolicy) (string, error)                                                                                                    `perm:"admin"`
+		SetSpendBudget                func(ctx context.Context, addr address.Address, budget *types.SpendBudget) error                                                                                         `perm:"admin"`
+		SimulateSelect                func(ctx context.Context, addr address.Address) (*types.SelectSimulation, error)                                                                                         `perm:"read"`
+		SubscribeMessageEvents        func(ctx context.Context, filter *types.MessageEventFilter) (<-chan *types.MessageEvent, error)                                                                          `perm:"read"`
+	}
+}
+
+func (s *IMessagerStruct) ApproveMsig(p0 context.Context, p1 *types.MsigApproveParams) (string, error) {
+	return s.Internal.ApproveMsig(p0, p1)
+}
+func (s *IMessagerStruct) DeleteContractABI(p0 context.Context, p1 address.Address) error {
+	return s.Internal.DeleteContractABI(p0, p1)
+}
+func (s *IMessagerStruct) DeleteExitCodeRule(p0 context.Context, p1 string) error {
+	return s.Internal.DeleteExitCodeRule(p0, p1)
+}
+func (s *IMessagerStruct) DeleteFeeBumpPolicy(p0 context.Context, p1 string) error {
+	return s.Internal.DeleteFeeBumpPolicy(p0, p1)
+}
+func (s *IMessagerStruct) DeleteSignerPolicy(p0 context.Context, p1 string) error {
+	return s.Internal.DeleteSignerPolicy(p0, p1)
+}
+func (s *IMessagerStruct) FeeReport(p0 context.Context, p1 *types.FeeReportParams) (*types.FeeReport, error) {
+	return s.Internal.FeeReport(p0, p1)
+}
+func (s *IMessagerStruct) FillNonceGap(p0 context.Context, p1 address.Address) ([]string, error) {
+	return s.Internal.FillNonceGap(p0, p1)
+}
+func (s *IMessagerStruct) GetAddressConfig(p0 context.Context, p1 address.Address) (*types.AddressConfig, error) {
+	return s.Internal.GetAddressConfig(p0, p1)
+}
+func (s *IMessagerStruct) GetContractABI(p0 context.Context, p1 address.Address) (*types.ContractABI, error) {
+	return s.Internal.GetContractABI(p0, p1)
+}
+func (s *IMessagerStruct) GetDecodedMessage(p0 context.Context, p1 string) (*types.DecodedMessage, error) {
+	return s.Internal.GetDecodedMessage(p0, p1)
+}
+func (s *IMessagerStruct) GetLeader(p0 context.Context) (*types.Lease, error) {
+	return s.Internal.GetLeader(p0)
+}
+func (s *IMessagerStruct) GetMessageByEthHash(p0 context.Context, p1 venusTypes.EthHash) (*messagerTypes.Message, error) {
+	return s.Internal.GetMessageByEthHash(p0, p1)
+}
+func (s *IMessagerStruct) GetMessageRevisions(p0 context.Context, p1 string) ([]*types.MessageRevision, error) {
+	return s.Internal.GetMessageRevisions(p0, p1)
+}
+func (s *IMessagerStruct) GetMsigProposal(p0 context.Context, p1 string) (*types.MsigProposal, error) {
+	return s.Internal.GetMsigProposal(p0, p1)
+}
+func (s *IMessagerStruct) ListAddressShard(p0 context.Context) ([]*types.AddressShard, error) {
+	return s.Internal.ListAddressShard(p0)
+}
+func (s *IMessagerStruct) ListContractABI(p0 context.Context) ([]*types.ContractABI, error) {
+	return s.Internal.ListContractABI(p0)
+}
+func (s *IMessagerStruct) ListDecodedMessage(p0 context.Context, p1 *messagerTypes.MsgQueryParams) ([]*types.DecodedMessage, error) {
+	return s.Internal.ListDecodedMessage(p0, p1)
+}
+func (s *IMessagerStruct) ListDecodedMessageByFromState(p0 context.Context, p1 address.Address, p2 messagerTypes.MessageState, p3 bool, p4, p5 int, p6 time.Duration) ([]*types.DecodedMessage, error) {
+	return s.Internal.ListDecodedMessageByFromState(p0, p1, p2, p3, p4, p5, p6)
+}
+func (s *IMessagerStruct) ListExitCodeRule(p0 context.Context) ([]*types.ExitCodeRule, error) {
+	return s.Internal.ListExitCodeRule(p0)
+}
+func (s *IMessagerStruct) ListFeeBumpPolicy(p0 context.Context) ([]*types.FeeBumpPolicy, error) {
+	return s.Internal.ListFeeBumpPolicy(p0)
+}
+func (s *IMessagerStruct) ListFeeBumpRecord(p0 context.Context, p1 string) ([]*types.FeeBumpRecord, error) {
+	return s.Internal.ListFeeBumpRecord(p0, p1)
+}
+func (s *IMessagerStruct) ListMessageRetries(p0 context.Context, p1 string) ([]*types.MessageRetry, error) {
+	return s.Internal.ListMessageRetries(p0, p1)
+}
+func (s *IMessagerStruct) ListMsigProposal(p0 context.Context, p1 address.Address) ([]*types.MsigProposal, error) {
+	return s.Internal.ListMsigProposal(p0, p1)
+}
+func (s *IMessagerStruct) ListPreflightActorCfg(p0 context.Context) ([]venusTypes.UUID, error) {
+	return s.Internal.ListPreflightActorCfg(p0)
+}
+func (s *IMessagerStruct) ListSignerPolicy(p0 context.Context, p1 address.Address) ([]*types.SignerPolicy, error) {
+	return s.Internal.ListSignerPolicy(p0, p1)
+}
+func (s *IMessagerStruct) NonceGapReport(p0 context.Context, p1 address.Address) (*types.NonceGapReport, error) {
+	return s.Internal.NonceGapReport(p0, p1)
+}
+func (s *IMessagerStruct) ProposeMsig(p0 context.Context, p1 *types.MsigProposeParams) (string, error) {
+	return s.Internal.ProposeMsig(p0, p1)
+}
+func (s *IMessagerStruct) PushEthTransaction(p0 context.Context, p1 *types.EthTxParams) (string, error) {
+	return s.Internal.PushEthTransaction(p0, p1)
+}
+func (s *IMessagerStruct) PushMessageWithSpec(p0 context.Context, p1 string, p2 *venusTypes.Message, p3 *types.SendSpec) (string, error) {
+	return s.Internal.PushMessageWithSpec(p0, p1, p2, p3)
+}
+func (s *IMessagerStruct) SendWithSpec(p0 context.Context, p1 types.QuickSendParams) (string, error) {
+	return s.Internal.SendWithSpec(p0, p1)
+}
+func (s *IMessagerStruct) SetActorCfgPreflight(p0 context.Context, p1 venusTypes.UUID, p2 bool) error {
+	return s.Internal.SetActorCfgPreflight(p0, p1, p2)
+}
+func (s *IMessagerStruct) SetBalanceReserve(p0 context.Context, p1 address.Address, p2 big.Int) error {
+	return s.Internal.SetBalanceReserve(p0, p1, p2)
+}
+func (s *IMessagerStruct) SetBatchSend(p0 context.Context, p1 address.Address, p2 bool) error {
+	return s.Internal.SetBatchSend(p0, p1, p2)
+}
+func (s *IMessagerStruct) SetContractABI(p0 context.Context, p1 *types.ContractABI) error {
+	return s.Internal.SetContractABI(p0, p1)
+}
+func (s *IMessagerStruct) SetExitCodeRule(p0 context.Context, p1 *types.ExitCodeRule) (string, error) {
+	return s.Internal.SetExitCodeRule(p0, p1)
+}
+func (s *IMessagerStruct) SetFeeBumpPolicy(p0 context.Context, p1 *types.FeeBumpPolicy) error {
+	return s.Internal.SetFeeBumpPolicy(p0, p1)
+}
+func (s *IMessagerStruct) SetMessagePriority(p0 context.Context, p1 string, p2 int) error {
+	return s.Internal.SetMessagePriority(p0, p1, p2)
+}
+func (s *IMessagerStruct) SetAutoFillNonceGap(p0 context.Context, p1 address.Address, p2 bool) error {
+	return s.Internal.SetAutoFillNonceGap(p0, p1, p2)
+}
+func (s *IMessagerStruct) SetSelectStrategy(p0 context.Context, p1 address.Address, p2 string) error {
+	return s.Internal.SetSelectStrategy(p0, p1, p2)
+}
+func (s *IMessagerStruct) SetSignerPolicy(p0 context.Context, p1 *types.SignerPolicy) (string, error) {
+	return s.Internal.SetSignerPolicy(p0, p1)
+}
+func (s *IMessagerStruct) SetSpendBudget(p0 context.Context, p1 address.Address, p2 *types.SpendBudget) error {
+	return s.Internal.SetSpendBudget(p0, p1, p2)
+}
+func (s *IMessagerStruct) SimulateSelect(p0 context.Context, p1 address.Address) (*types.SelectSimulation, error) {
+	return s.Internal.SimulateSelect(p0, p1)
+}
+func (s *IMessagerStruct) SubscribeMessageEvents(p0 context.Context, p1 *types.MessageEventFilter) (<-chan *types.MessageEvent, error) {
+	return s.Internal.SubscribeMessageEvents(p0, p1)
+}
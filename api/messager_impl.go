@@ -11,13 +11,17 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin/multisig"
 	v1 "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
-	"github.com/filecoin-project/venus/venus-shared/api/messager"
 	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
 	"github.com/ipfs-force-community/sophon-messager/publisher/pubsub"
 
+	"github.com/ipfs-force-community/sophon-messager/api/messager"
 	"github.com/ipfs-force-community/sophon-messager/service"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 	"github.com/ipfs-force-community/sophon-messager/version"
 
 	"github.com/ipfs-force-community/sophon-auth/core"
@@ -97,6 +101,9 @@ func (m MessageImp) PushMessage(ctx context.Context, msg *venusTypes.Message, me
 	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); err != nil {
 		return "", err
 	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msg); err != nil {
+		return "", err
+	}
 
 	return m.MessageSrv.PushMessage(ctx, msg, meta)
 }
@@ -110,10 +117,311 @@ func (m MessageImp) PushMessageWithId(ctx context.Context, id string, msg *venus
 	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); err != nil {
 		return "", err
 	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msg); err != nil {
+		return "", err
+	}
 
 	return m.MessageSrv.PushMessageWithId(ctx, id, msg, meta)
 }
 
+func (m MessageImp) PushMessageWithSpec(ctx context.Context, id string, msg *venusTypes.Message, spec *sophonTypes.SendSpec) (string, error) {
+	var err error
+	msg.From, err = m.resolveAddress(ctx, msg.From)
+	if err != nil {
+		return "", err
+	}
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); err != nil {
+		return "", err
+	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msg); err != nil {
+		return "", err
+	}
+
+	return m.MessageSrv.PushMessageWithSpec(ctx, id, msg, spec)
+}
+
+func (m *MessageImp) SetMessagePriority(ctx context.Context, id string, priority int) error {
+	msg, err := m.MessageSrv.GetMessageByUid(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get message by id error: %w", err)
+	}
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); err != nil {
+		return err
+	}
+	return m.MessageSrv.SetMessagePriority(ctx, id, priority)
+}
+
+func (m *MessageImp) SimulateSelect(ctx context.Context, addr address.Address) (*sophonTypes.SelectSimulation, error) {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.SimulateSelect(ctx, addr)
+}
+
+func (m *MessageImp) NonceGapReport(ctx context.Context, addr address.Address) (*sophonTypes.NonceGapReport, error) {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.NonceGapReport(ctx, addr)
+}
+
+func (m *MessageImp) FillNonceGap(ctx context.Context, addr address.Address) ([]string, error) {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.FillNonceGap(ctx, addr)
+}
+
+func (m *MessageImp) SubscribeMessageEvents(ctx context.Context, filter *sophonTypes.MessageEventFilter) (<-chan *sophonTypes.MessageEvent, error) {
+	if filter == nil {
+		filter = &sophonTypes.MessageEventFilter{}
+	}
+	// only admin can subscribe all message
+	if len(filter.From) == 0 {
+		if !isAdmin(ctx) {
+			signers, err := getSigners(ctx, m.AuthClient)
+			if err != nil {
+				return nil, err
+			}
+			if len(signers) == 0 {
+				return nil, fmt.Errorf("no signer found")
+			}
+			filter.From = signers
+		}
+	} else {
+		if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, filter.From...); err != nil {
+			return nil, err
+		}
+	}
+	return m.MessageSrv.SubscribeMessageEvents(ctx, filter)
+}
+
+func (m *MessageImp) SetFeeBumpPolicy(ctx context.Context, policy *sophonTypes.FeeBumpPolicy) error {
+	return m.MessageSrv.SetFeeBumpPolicy(ctx, policy)
+}
+
+func (m *MessageImp) ListFeeBumpPolicy(ctx context.Context) ([]*sophonTypes.FeeBumpPolicy, error) {
+	return m.MessageSrv.ListFeeBumpPolicy(ctx)
+}
+
+func (m *MessageImp) DeleteFeeBumpPolicy(ctx context.Context, id string) error {
+	return m.MessageSrv.DeleteFeeBumpPolicy(ctx, id)
+}
+
+func (m *MessageImp) ListFeeBumpRecord(ctx context.Context, id string) ([]*sophonTypes.FeeBumpRecord, error) {
+	msg, err := m.MessageSrv.GetMessageByUid(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get message by id error: %w", err)
+	}
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.ListFeeBumpRecord(ctx, id)
+}
+
+func (m *MessageImp) GetMessageRevisions(ctx context.Context, id string) ([]*sophonTypes.MessageRevision, error) {
+	msg, err := m.MessageSrv.GetMessageByUid(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get message by id error: %w", err)
+	}
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.GetMessageRevisions(ctx, id)
+}
+
+func (m *MessageImp) FeeReport(ctx context.Context, params *sophonTypes.FeeReportParams) (*sophonTypes.FeeReport, error) {
+	if params == nil {
+		params = &sophonTypes.FeeReportParams{}
+	}
+	// only admin can report the fees of all addresses
+	if len(params.From) == 0 {
+		if !isAdmin(ctx) {
+			signers, err := getSigners(ctx, m.AuthClient)
+			if err != nil {
+				return nil, err
+			}
+			if len(signers) == 0 {
+				return nil, fmt.Errorf("no signer found")
+			}
+			params.From = signers
+		}
+	} else {
+		if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, params.From...); err != nil {
+			return nil, err
+		}
+	}
+	return m.MessageSrv.FeeReport(ctx, params)
+}
+
+func (m *MessageImp) SetExitCodeRule(ctx context.Context, rule *sophonTypes.ExitCodeRule) (string, error) {
+	return m.MessageSrv.SetExitCodeRule(ctx, rule)
+}
+
+func (m *MessageImp) ListExitCodeRule(ctx context.Context) ([]*sophonTypes.ExitCodeRule, error) {
+	return m.MessageSrv.ListExitCodeRule(ctx)
+}
+
+func (m *MessageImp) DeleteExitCodeRule(ctx context.Context, id string) error {
+	return m.MessageSrv.DeleteExitCodeRule(ctx, id)
+}
+
+func (m *MessageImp) ListMessageRetries(ctx context.Context, id string) ([]*sophonTypes.MessageRetry, error) {
+	if _, err := m.GetMessageByUid(ctx, id); err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.ListMessageRetries(ctx, id)
+}
+
+func (m *MessageImp) SetSignerPolicy(ctx context.Context, policy *sophonTypes.SignerPolicy) (string, error) {
+	return m.MessageSrv.SetSignerPolicy(ctx, policy)
+}
+
+func (m *MessageImp) ListSignerPolicy(ctx context.Context, signer address.Address) ([]*sophonTypes.SignerPolicy, error) {
+	if signer != address.Undef {
+		if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, signer); err != nil {
+			return nil, err
+		}
+		return m.MessageSrv.ListSignerPolicy(ctx, signer)
+	}
+
+	policies, err := m.MessageSrv.ListSignerPolicy(ctx, signer)
+	if err != nil || isAdmin(ctx) {
+		return policies, err
+	}
+	// only admin can list the policies of all signers
+	signers, err := getSigners(ctx, m.AuthClient)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[address.Address]struct{}, len(signers))
+	for _, s := range signers {
+		owned[s] = struct{}{}
+	}
+	res := make([]*sophonTypes.SignerPolicy, 0, len(policies))
+	for _, policy := range policies {
+		if _, ok := owned[policy.Signer]; ok {
+			res = append(res, policy)
+		}
+	}
+	return res, nil
+}
+
+func (m *MessageImp) DeleteSignerPolicy(ctx context.Context, id string) error {
+	return m.MessageSrv.DeleteSignerPolicy(ctx, id)
+}
+
+func (m *MessageImp) ProposeMsig(ctx context.Context, params *sophonTypes.MsigProposeParams) (string, error) {
+	if params == nil {
+		return "", fmt.Errorf("params is nil")
+	}
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, params.From); err != nil {
+		return "", err
+	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msigMessage(params.From, params.Msig, multisig.Methods.Propose)); err != nil {
+		return "", err
+	}
+	// the inner message moves the funds of multisig, it is checked as if it was sent by the signer
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msigInnerMessage(params.From, params.To, params.Value, params.Method, params.Params)); err != nil {
+		return "", err
+	}
+	return m.MessageSrv.ProposeMsig(ctx, params)
+}
+
+func (m *MessageImp) ApproveMsig(ctx context.Context, params *sophonTypes.MsigApproveParams) (string, error) {
+	if params == nil {
+		return "", fmt.Errorf("params is nil")
+	}
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, params.From); err != nil {
+		return "", err
+	}
+	proposal, err := m.MessageSrv.GetMsigProposal(ctx, params.ProposalID)
+	if err != nil {
+		return "", fmt.Errorf("get proposal %s failed: %w", params.ProposalID, err)
+	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msigMessage(params.From, proposal.Msig, multisig.Methods.Approve)); err != nil {
+		return "", err
+	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msigInnerMessage(params.From, proposal.To, proposal.Value, proposal.Method, proposal.Params)); err != nil {
+		return "", err
+	}
+	return m.MessageSrv.ApproveMsig(ctx, params)
+}
+
+// msigMessage the message checked by signer policy, the outer message of multisig transfers no value
+func msigMessage(from, msig address.Address, method abi.MethodNum) *venusTypes.Message {
+	return &venusTypes.Message{
+		From:   from,
+		To:     msig,
+		Value:  big.Zero(),
+		Method: method,
+	}
+}
+
+// msigInnerMessage the inner message executed by multisig once the proposal is approved
+func msigInnerMessage(from, to address.Address, value big.Int, method abi.MethodNum, params []byte) *venusTypes.Message {
+	if value.Nil() {
+		value = big.Zero()
+	}
+	return &venusTypes.Message{
+		From:   from,
+		To:     to,
+		Value:  value,
+		Method: method,
+		Params: params,
+	}
+}
+
+// msigProposalSigners the signers who can see the proposal
+func msigProposalSigners(proposal *sophonTypes.MsigProposal) []address.Address {
+	return append([]address.Address{proposal.Proposer}, proposal.Approvers...)
+}
+
+func (m *MessageImp) GetMsigProposal(ctx context.Context, id string) (*sophonTypes.MsigProposal, error) {
+	proposal, err := m.MessageSrv.GetMsigProposal(ctx, id)
+	if err != nil || isAdmin(ctx) {
+		return proposal, err
+	}
+	signers, err := getSigners(ctx, m.AuthClient)
+	if err != nil {
+		return nil, err
+	}
+	for _, signer := range msigProposalSigners(proposal) {
+		for _, s := range signers {
+			if s == signer {
+				return proposal, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("permission deny, proposal %s is not proposed or approved by the signers of user", id)
+}
+
+func (m *MessageImp) ListMsigProposal(ctx context.Context, msig address.Address) ([]*sophonTypes.MsigProposal, error) {
+	proposals, err := m.MessageSrv.ListMsigProposal(ctx, msig)
+	if err != nil || isAdmin(ctx) {
+		return proposals, err
+	}
+	// only admin can list the proposals of all signers
+	signers, err := getSigners(ctx, m.AuthClient)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[address.Address]struct{}, len(signers))
+	for _, s := range signers {
+		owned[s] = struct{}{}
+	}
+	res := make([]*sophonTypes.MsigProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		for _, signer := range msigProposalSigners(proposal) {
+			if _, ok := owned[signer]; ok {
+				res = append(res, proposal)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
 func (m *MessageImp) GetMessageByUid(ctx context.Context, id string) (*types.Message, error) {
 	msg, err := m.MessageSrv.GetMessageByUid(ctx, id)
 	if err != nil {
@@ -125,6 +433,50 @@ func (m *MessageImp) GetMessageByUid(ctx context.Context, id string) (*types.Mes
 	return msg, nil
 }
 
+func (m *MessageImp) PushEthTransaction(ctx context.Context, params *sophonTypes.EthTxParams) (string, error) {
+	if params == nil {
+		return "", fmt.Errorf("params is nil")
+	}
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, params.From); err != nil {
+		return "", err
+	}
+	msg, err := params.Message()
+	if err != nil {
+		return "", err
+	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, msg); err != nil {
+		return "", err
+	}
+	return m.MessageSrv.PushEthTransaction(ctx, params)
+}
+
+func (m *MessageImp) GetMessageByEthHash(ctx context.Context, hash venusTypes.EthHash) (*types.Message, error) {
+	msg, err := m.MessageSrv.GetMessageByEthHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get message by eth hash error: %w", err)
+	}
+	if checkErr := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); checkErr != nil {
+		return nil, checkErr
+	}
+	return msg, nil
+}
+
+func (m *MessageImp) SetContractABI(ctx context.Context, contractABI *sophonTypes.ContractABI) error {
+	return m.MessageSrv.SetContractABI(ctx, contractABI)
+}
+
+func (m *MessageImp) GetContractABI(ctx context.Context, addr address.Address) (*sophonTypes.ContractABI, error) {
+	return m.MessageSrv.GetContractABI(ctx, addr)
+}
+
+func (m *MessageImp) ListContractABI(ctx context.Context) ([]*sophonTypes.ContractABI, error) {
+	return m.MessageSrv.ListContractABI(ctx)
+}
+
+func (m *MessageImp) DeleteContractABI(ctx context.Context, addr address.Address) error {
+	return m.MessageSrv.DeleteContractABI(ctx, addr)
+}
+
 func (m *MessageImp) GetMessageBySignedCid(ctx context.Context, cid cid.Cid) (*types.Message, error) {
 	msg, err := m.MessageSrv.GetMessageBySignedCid(ctx, cid)
 	if err != nil {
@@ -179,6 +531,34 @@ func (m *MessageImp) ListMessage(ctx context.Context, p *types.MsgQueryParams) (
 	return m.MessageSrv.ListMessage(ctx, p)
 }
 
+func (m *MessageImp) GetDecodedMessage(ctx context.Context, id string) (*sophonTypes.DecodedMessage, error) {
+	msg, err := m.GetMessageByUid(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := m.MessageSrv.DecodeMessages(ctx, []*types.Message{msg})
+	if err != nil {
+		return nil, err
+	}
+	return decoded[0], nil
+}
+
+func (m *MessageImp) ListDecodedMessage(ctx context.Context, p *types.MsgQueryParams) ([]*sophonTypes.DecodedMessage, error) {
+	msgs, err := m.ListMessage(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.DecodeMessages(ctx, msgs)
+}
+
+func (m *MessageImp) ListDecodedMessageByFromState(ctx context.Context, from address.Address, state types.MessageState, isAsc bool, pageIndex, pageSize int, d time.Duration) ([]*sophonTypes.DecodedMessage, error) {
+	msgs, err := m.ListMessageByFromState(ctx, from, state, isAsc, pageIndex, pageSize, d)
+	if err != nil {
+		return nil, err
+	}
+	return m.MessageSrv.DecodeMessages(ctx, msgs)
+}
+
 func (m *MessageImp) ListMessageByFromState(ctx context.Context, from address.Address, state types.MessageState, isAsc bool, pageIndex, pageSize int, d time.Duration) ([]*types.Message, error) {
 	return m.MessageSrv.ListMessageByFromState(ctx, from, state, isAsc, pageIndex, pageSize, d)
 }
@@ -222,7 +602,7 @@ func (m *MessageImp) UpdateMessageStateByID(ctx context.Context, id string, stat
 	if checkErr := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); checkErr != nil {
 		return checkErr
 	}
-	log.Infof("update message(%s) state, from %s to %s, ", msg.ID, msg.State.String(), state.String())
+	log.Infof("update message(%s) state, from %s to %s, ", msg.ID, sophonTypes.MessageStateString(msg.State), sophonTypes.MessageStateString(state))
 	return m.MessageSrv.UpdateMessageStateByID(ctx, id, state)
 }
 
@@ -238,7 +618,7 @@ func (m *MessageImp) UpdateFilledMessageByID(ctx context.Context, id string) (st
 	if checkErr := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, msg.From); checkErr != nil {
 		return "", checkErr
 	}
-	if msg.State == types.OnChainMsg || msg.State == types.NonceConflictMsg {
+	if sophonTypes.IsOnChain(msg.State) || msg.State == types.NonceConflictMsg {
 		return "", fmt.Errorf("message state(%s) has been final, can not update", msg.State)
 	}
 	return m.MessageSrv.UpdateFilledMessageByID(ctx, id)
@@ -354,6 +734,48 @@ func (m *MessageImp) SetFeeParams(ctx context.Context, params *types.AddressSpec
 	return m.AddressSrv.SetFeeParams(ctx, params)
 }
 
+func (m *MessageImp) SetSelectStrategy(ctx context.Context, addr address.Address, strategy string) error {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return err
+	}
+	return m.AddressSrv.SetSelectStrategy(ctx, addr, strategy)
+}
+
+func (m *MessageImp) GetAddressConfig(ctx context.Context, addr address.Address) (*sophonTypes.AddressConfig, error) {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return nil, err
+	}
+	return m.AddressSrv.GetAddressConfig(ctx, addr)
+}
+
+func (m *MessageImp) SetAutoFillNonceGap(ctx context.Context, addr address.Address, enable bool) error {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return err
+	}
+	return m.AddressSrv.SetAutoFillNonceGap(ctx, addr, enable)
+}
+
+func (m *MessageImp) SetBalanceReserve(ctx context.Context, addr address.Address, reserve big.Int) error {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return err
+	}
+	return m.AddressSrv.SetBalanceReserve(ctx, addr, reserve)
+}
+
+func (m *MessageImp) SetBatchSend(ctx context.Context, addr address.Address, enable bool) error {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return err
+	}
+	return m.AddressSrv.SetBatchSend(ctx, addr, enable)
+}
+
+func (m *MessageImp) SetSpendBudget(ctx context.Context, addr address.Address, budget *sophonTypes.SpendBudget) error {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
+		return err
+	}
+	return m.AddressSrv.SetSpendBudget(ctx, addr, budget)
+}
+
 func (m *MessageImp) ClearUnFillMessage(ctx context.Context, addr address.Address) (int, error) {
 	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, addr); err != nil {
 		return 0, err
@@ -393,9 +815,32 @@ func (m *MessageImp) Send(ctx context.Context, params types.QuickSendParams) (st
 	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, params.From); err != nil {
 		return "", err
 	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, quickSendMessage(params)); err != nil {
+		return "", err
+	}
 	return m.MessageSrv.Send(ctx, params)
 }
 
+func (m *MessageImp) SendWithSpec(ctx context.Context, params sophonTypes.QuickSendParams) (string, error) {
+	if err := jwtclient.CheckPermissionBySigner(ctx, m.AuthClient, params.From); err != nil {
+		return "", err
+	}
+	if err := m.MessageSrv.CheckSignerPolicy(ctx, quickSendMessage(params.QuickSendParams)); err != nil {
+		return "", err
+	}
+	return m.MessageSrv.SendWithSpec(ctx, params)
+}
+
+// quickSendMessage the message checked by signer policy, the params are not needed
+func quickSendMessage(params types.QuickSendParams) *venusTypes.Message {
+	return &venusTypes.Message{
+		From:   params.From,
+		To:     params.To,
+		Value:  params.Val,
+		Method: params.Method,
+	}
+}
+
 func (m *MessageImp) NetFindPeer(ctx context.Context, peerID peer.ID) (peer.AddrInfo, error) {
 	return m.Net.FindPeer(ctx, peerID)
 }
@@ -464,3 +909,19 @@ func (m *MessageImp) ListActorCfg(ctx context.Context) ([]*types.ActorCfg, error
 func (m *MessageImp) GetActorCfgByID(ctx context.Context, id venusTypes.UUID) (*types.ActorCfg, error) {
 	return m.MessageSrv.GetActorCfgByID(ctx, id)
 }
+
+func (m *MessageImp) SetActorCfgPreflight(ctx context.Context, id venusTypes.UUID, enable bool) error {
+	return m.MessageSrv.SetActorCfgPreflight(ctx, id, enable)
+}
+
+func (m *MessageImp) ListPreflightActorCfg(ctx context.Context) ([]venusTypes.UUID, error) {
+	return m.MessageSrv.ListPreflightActorCfg(ctx)
+}
+
+func (m *MessageImp) GetLeader(ctx context.Context) (*sophonTypes.Lease, error) {
+	return m.MessageSrv.GetLeader(ctx)
+}
+
+func (m *MessageImp) ListAddressShard(ctx context.Context) ([]*sophonTypes.AddressShard, error) {
+	return m.MessageSrv.ListAddressShard(ctx)
+}
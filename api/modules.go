@@ -8,7 +8,6 @@ import (
 
 	"github.com/etherlabsio/healthcheck/v2"
 	"github.com/filecoin-project/go-jsonrpc"
-	"github.com/filecoin-project/venus/venus-shared/api/messager"
 	"github.com/filecoin-project/venus/venus-shared/api/permission"
 	"github.com/ipfs-force-community/metrics/ratelimit"
 	"github.com/ipfs-force-community/sophon-auth/core"
@@ -16,6 +15,7 @@ import (
 	logging "github.com/ipfs/go-log/v2"
 	"go.uber.org/fx"
 
+	"github.com/ipfs-force-community/sophon-messager/api/messager"
 	"github.com/ipfs-force-community/sophon-messager/config"
 )
 
@@ -37,6 +37,7 @@ func BindRateLimit(msgImp *MessageImp, remoteAuthCli jwtclient.IAuthClient, rate
 			return nil, err
 		}
 		var rateLimitAPI messager.IMessagerStruct
+		limiter.WraperLimiter(msgAPI.IMessagerStruct.Internal, &rateLimitAPI.IMessagerStruct.Internal)
 		limiter.WraperLimiter(msgAPI.Internal, &rateLimitAPI.Internal)
 		msgAPI = rateLimitAPI
 	}
@@ -32,6 +32,7 @@ var ActorCfgCmds = &cli.Command{
 		getActorCfgCmd,
 		updateActorCfgCmd,
 		addActorCfgCmd,
+		setPreflightCmd,
 		listBuiltinActorCmd,
 	},
 }
@@ -65,7 +66,11 @@ var listActorCfgCmd = &cli.Command{
 		}
 
 		if ctx.String(outputTypeFlag.Name) == "table" {
-			return outputActorCfgWithTable(actorCfgs)
+			preflightIDs, err := client.ListPreflightActorCfg(ctx.Context)
+			if err != nil {
+				return err
+			}
+			return outputActorCfgWithTable(actorCfgs, preflightIDs)
 		}
 
 		bytes, err := json.MarshalIndent(actorCfgs, " ", "\t")
@@ -270,6 +275,33 @@ var addActorCfgCmd = &cli.Command{
 	},
 }
 
+var setPreflightCmd = &cli.Command{
+	Name: "set-preflight",
+	Usage: "enable or disable the pre-flight StateCall of the messages matching the actor config, " +
+		"the messages failed in the simulation are left unfill with the reason in error msg",
+	ArgsUsage: "<uid> <true/false>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 2 {
+			return errors.New("must specify the uid and whether to enable the pre-flight")
+		}
+		id, err := types2.ParseUUID(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		enable, err := strconv.ParseBool(ctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+		return client.SetActorCfgPreflight(ctx.Context, id, enable)
+	},
+}
+
 var getActorCfgCmd = &cli.Command{
 	Name:      "get",
 	Usage:     "get actor config",
@@ -309,7 +341,11 @@ var getActorCfgCmd = &cli.Command{
 		}
 
 		if ctx.String(outputTypeFlag.Name) == "table" {
-			return outputActorCfgWithTable([]*types.ActorCfg{actorCfg})
+			preflightIDs, err := client.ListPreflightActorCfg(ctx.Context)
+			if err != nil {
+				return err
+			}
+			return outputActorCfgWithTable([]*types.ActorCfg{actorCfg}, preflightIDs)
 		}
 
 		bytes, err := json.MarshalIndent(actorCfg, " ", "\t")
@@ -419,10 +455,15 @@ var actorCfgTw = tablewriter.New(
 	tablewriter.Col("MaxFee"),
 	tablewriter.Col("GasFeeCap"),
 	tablewriter.Col("BaseFee"),
+	tablewriter.Col("Preflight"),
 	tablewriter.Col("CreateAt"),
 )
 
-func outputActorCfgWithTable(actorCfg []*types.ActorCfg) error {
+func outputActorCfgWithTable(actorCfg []*types.ActorCfg, preflightIDs []types2.UUID) error {
+	preflight := make(map[types2.UUID]bool, len(preflightIDs))
+	for _, id := range preflightIDs {
+		preflight[id] = true
+	}
 	for _, actorCfg := range actorCfg {
 		row := map[string]interface{}{
 			"ID":                actorCfg.ID,
@@ -435,6 +476,7 @@ func outputActorCfgWithTable(actorCfg []*types.ActorCfg) error {
 			"MaxFee":            actorCfg.FeeSpec.MaxFee,
 			"GasFeeCap":         actorCfg.FeeSpec.GasFeeCap,
 			"BaseFee":           actorCfg.FeeSpec.BaseFee,
+			"Preflight":         preflight[actorCfg.ID],
 			"CreateAt":          actorCfg.CreatedAt.Format("2006-01-02 15:04:05"),
 		}
 		actorCfgTw.Write(row)
@@ -3,10 +3,16 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/filecoin-project/venus/venus-shared/types/messager"
 	"github.com/urfave/cli/v2"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 var AddrCmds = &cli.Command{
@@ -20,6 +26,13 @@ var AddrCmds = &cli.Command{
 		activeAddrCmd,
 		setAddrSelMsgNumCmd,
 		setFeeParamsCmd,
+		setSelectStrategyCmd,
+		getAddrConfigCmd,
+		setAutoFillNonceGapCmd,
+		setBalanceReserveCmd,
+		setSpendBudgetCmd,
+		setBatchSendCmd,
+		listAddrShardCmd,
 	},
 }
 
@@ -253,3 +266,226 @@ var setFeeParamsCmd = &cli.Command{
 		return client.SetFeeParams(ctx.Context, params)
 	},
 }
+
+var setSelectStrategyCmd = &cli.Command{
+	Name:      "set-strategy",
+	Usage:     "set the strategy of address selection messages",
+	ArgsUsage: "<address>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "strategy",
+			Usage: "strategy used to select message, supports fifo, fee, deadline, empty means use the global config",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return fmt.Errorf("must pass address")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		return client.SetSelectStrategy(ctx.Context, addr, ctx.String("strategy"))
+	},
+}
+
+var getAddrConfigCmd = &cli.Command{
+	Name:      "config",
+	Usage:     "show the config of address",
+	ArgsUsage: "<address>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return fmt.Errorf("must pass address")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		addrCfg, err := client.GetAddressConfig(ctx.Context, addr)
+		if err != nil {
+			return err
+		}
+		bytes, err := json.MarshalIndent(addrCfg, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var setAutoFillNonceGapCmd = &cli.Command{
+	Name:      "auto-fill-nonce-gap",
+	Usage:     "enable or disable filling the nonce gaps of address automatically before selecting messages",
+	ArgsUsage: "<address> <true|false>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 2 {
+			return fmt.Errorf("must pass address and true or false")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		enable, err := strconv.ParseBool(ctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+
+		return client.SetAutoFillNonceGap(ctx.Context, addr, enable)
+	},
+}
+
+var setBalanceReserveCmd = &cli.Command{
+	Name:      "balance-reserve",
+	Usage:     "set the balance kept by address, messages are held back when their worst-case cost eats into it",
+	ArgsUsage: "<address> <amount, eg. 10FIL>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 2 {
+			return fmt.Errorf("must pass address and amount")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		reserve, err := venusTypes.ParseFIL(ctx.Args().Get(1))
+		if err != nil {
+			return fmt.Errorf("failed to parse amount: %w", err)
+		}
+
+		return client.SetBalanceReserve(ctx.Context, addr, big.Int(reserve))
+	},
+}
+
+var setSpendBudgetCmd = &cli.Command{
+	Name:      "spend-budget",
+	Usage:     "limit the value and the gas fee of messages signed by address in a rolling window, messages exceeding it are left unfill",
+	ArgsUsage: "<address>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "max-value",
+			Usage: "max value transferred in the window, eg. 100FIL, 0 means unlimited",
+			Value: "0",
+		},
+		&cli.StringFlag{
+			Name:  "max-gas-fee",
+			Usage: "max gas fee (GasLimit*GasFeeCap) in the window, eg. 1FIL, 0 means unlimited",
+			Value: "0",
+		},
+		&cli.DurationFlag{
+			Name:  "window",
+			Usage: "the rolling window of budget",
+			Value: sophonTypes.DefaultSpendBudgetWindow,
+		},
+		&cli.Int64Flag{
+			Name:  "window-epochs",
+			Usage: "the rolling window of budget in epochs, takes precedence over --window if not zero",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !ctx.Args().Present() {
+			return fmt.Errorf("must pass address")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		maxValue, err := venusTypes.ParseFIL(ctx.String("max-value"))
+		if err != nil {
+			return fmt.Errorf("failed to parse max value: %w", err)
+		}
+		maxGasFee, err := venusTypes.ParseFIL(ctx.String("max-gas-fee"))
+		if err != nil {
+			return fmt.Errorf("failed to parse max gas fee: %w", err)
+		}
+
+		return client.SetSpendBudget(ctx.Context, addr, &sophonTypes.SpendBudget{
+			MaxValue:     big.Int(maxValue),
+			MaxGasFee:    big.Int(maxGasFee),
+			Window:       ctx.Duration("window"),
+			WindowEpochs: abi.ChainEpoch(ctx.Int64("window-epochs")),
+		})
+	},
+}
+
+var setBatchSendCmd = &cli.Command{
+	Name:      "batch-send",
+	Usage:     "enable or disable collapsing the small sends of address into one message of the aggregator in config",
+	ArgsUsage: "<address> <true|false>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 2 {
+			return fmt.Errorf("must pass address and true or false")
+		}
+		addr, err := address.NewFromString(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		enable, err := strconv.ParseBool(ctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+
+		return client.SetBatchSend(ctx.Context, addr, enable)
+	},
+}
+
+var listAddrShardCmd = &cli.Command{
+	Name:  "shards",
+	Usage: "list the instances holding the addresses, only available when sharding is enabled",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		shards, err := client.ListAddressShard(ctx.Context)
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(shards, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
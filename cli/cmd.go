@@ -14,9 +14,8 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/urfave/cli/v2"
 
+	"github.com/ipfs-force-community/sophon-messager/api/messager"
 	"github.com/ipfs-force-community/sophon-messager/config"
-
-	"github.com/filecoin-project/venus/venus-shared/api/messager"
 )
 
 const (
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/api/messager"
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+	"github.com/ipfs-force-community/sophon-messager/utils/ethabi"
+)
+
+var ABICmds = &cli.Command{
+	Name:  "abi",
+	Usage: "solidity abis of EVM actors, used to encode the calls of 'send --abi-method' and decode the calls in 'msg search'",
+	Subcommands: []*cli.Command{
+		listABICmd,
+		getABICmd,
+		setABICmd,
+		deleteABICmd,
+	},
+}
+
+var listABICmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the contracts with abi",
+	Flags: []cli.Flag{
+		outputTypeFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		contractABIs, err := client.ListContractABI(ctx.Context)
+		if err != nil {
+			return err
+		}
+
+		if ctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col("Address"),
+				tablewriter.Col("Methods"),
+				tablewriter.Col("Description"),
+				tablewriter.Col("UpdatedAt"),
+			)
+			for _, contractABI := range contractABIs {
+				methods := 0
+				if parsed, err := ethabi.Parse([]byte(contractABI.ABI)); err == nil {
+					methods = len(parsed.Methods)
+				}
+				tw.Write(map[string]interface{}{
+					"Address":     contractABI.Address,
+					"Methods":     methods,
+					"Description": contractABI.Description,
+					"UpdatedAt":   contractABI.UpdatedAt.Format("2006-01-02 15:04:05"),
+				})
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(contractABIs, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var getABICmd = &cli.Command{
+	Name:      "get",
+	Usage:     "print the methods in the abi of contract",
+	ArgsUsage: "<address>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 1 {
+			return errors.New("must specify one address argument")
+		}
+		addr, err := address.NewFromString(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		contractABI, err := client.GetContractABI(ctx.Context, addr)
+		if err != nil {
+			return err
+		}
+		parsed, err := ethabi.Parse([]byte(contractABI.ABI))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("address: %s\ndescription: %s\nmethods:\n", contractABI.Address, contractABI.Description)
+		for _, method := range parsed.Methods {
+			fmt.Printf("\t%x %s\n", method.Selector, method.Sig)
+		}
+		if len(parsed.Unsupported) > 0 {
+			fmt.Printf("unsupported methods: %s\n", strings.Join(parsed.Unsupported, ", "))
+		}
+		return nil
+	},
+}
+
+var setABICmd = &cli.Command{
+	Name:      "set",
+	Usage:     "set the abi of an EVM actor, replace the abi if it exists",
+	ArgsUsage: "<address> <abi json file>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "description",
+			Usage: "description of the contract",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 2 {
+			return errors.New("must specify the address and the abi json file")
+		}
+		addr, err := address.NewFromString(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(ctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+
+		return client.SetContractABI(ctx.Context, &sophonTypes.ContractABI{
+			Address:     addr,
+			ABI:         string(data),
+			Description: ctx.String("description"),
+		})
+	},
+}
+
+var deleteABICmd = &cli.Command{
+	Name:      "delete",
+	Usage:     "delete the abi of contract",
+	ArgsUsage: "<address>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 1 {
+			return errors.New("must specify one address argument")
+		}
+		addr, err := address.NewFromString(ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+		return client.DeleteContractABI(ctx.Context, addr)
+	},
+}
+
+// contractCall the call of contract decoded by the abi
+type contractCall struct {
+	Method string
+	Args   []interface{}
+	Return []interface{} `json:",omitempty"`
+}
+
+// decodeContractCall decodes the `InvokeContract` message by the abi of recipient, returns nil if the recipient has no abi
+func decodeContractCall(ctx context.Context, client messager.IMessager, msg *types.Message) (*contractCall, error) {
+	if msg.Method != builtin.MethodsEVM.InvokeContract {
+		return nil, nil
+	}
+	contractABI, err := client.GetContractABI(ctx, msg.To)
+	if err != nil {
+		// the error loses its type through rpc
+		if strings.Contains(err.Error(), repo.ErrRecordNotFound.Error()) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get abi of %s failed: %w", msg.To, err)
+	}
+	parsed, err := ethabi.Parse([]byte(contractABI.ABI))
+	if err != nil {
+		return nil, err
+	}
+
+	var calldata abi.CborBytes
+	if err := calldata.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil {
+		return nil, fmt.Errorf("decode params failed: %w", err)
+	}
+	method, err := parsed.MethodBySelector(calldata)
+	if err != nil {
+		return nil, err
+	}
+	call := &contractCall{Method: method.Sig}
+	if call.Args, err = method.DecodeCall(calldata); err != nil {
+		return nil, err
+	}
+
+	if sophonTypes.IsOnChain(msg.State) && msg.Receipt != nil && msg.Receipt.ExitCode == exitcode.Ok {
+		var ret abi.CborBytes
+		if err := ret.UnmarshalCBOR(bytes.NewReader(msg.Receipt.Return)); err != nil {
+			return nil, fmt.Errorf("decode return failed: %w", err)
+		}
+		if call.Return, err = method.DecodeReturn(ret); err != nil {
+			return nil, err
+		}
+	}
+	return call, nil
+}
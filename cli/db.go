@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	"github.com/ipfs-force-community/sophon-messager/models"
+	"github.com/ipfs-force-community/sophon-messager/models/migration"
+)
+
+var DBCmds = &cli.Command{
+	Name:  "db",
+	Usage: "manage the schema of database, connects to the database in config directly",
+	Subcommands: []*cli.Command{
+		migrateDBCmd,
+		dbStatusCmd,
+		rollbackDBCmd,
+	},
+}
+
+func getSchemaMigrator(ctx *cli.Context) (*migration.Migrator, error) {
+	fsRepo, err := getRepo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := models.SetDataBase(fsRepo)
+	if err != nil {
+		return nil, err
+	}
+	return repo.SchemaMigrator(), nil
+}
+
+var migrateDBCmd = &cli.Command{
+	Name:  "migrate",
+	Usage: "apply the pending migrations, they are applied when running messager as well",
+	Action: func(ctx *cli.Context) error {
+		migrator, err := getSchemaMigrator(ctx)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Migrate(ctx.Context); err != nil {
+			return err
+		}
+		version, err := migrator.Version(ctx.Context)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("database is at version %d\n", version)
+		return nil
+	},
+}
+
+var dbStatusCmd = &cli.Command{
+	Name:  "status",
+	Usage: "show the version of database and the migrations",
+	Action: func(ctx *cli.Context) error {
+		migrator, err := getSchemaMigrator(ctx)
+		if err != nil {
+			return err
+		}
+		version, err := migrator.Version(ctx.Context)
+		if err != nil {
+			return err
+		}
+		list, err := migrator.Status(ctx.Context)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("database version: %d, latest version: %d\n", version, migrator.LatestVersion())
+		tw := tablewriter.New(
+			tablewriter.Col("Version"),
+			tablewriter.Col("Name"),
+			tablewriter.Col("Applied"),
+			tablewriter.Col("AppliedAt"),
+		)
+		for _, status := range list {
+			applied := "no"
+			appliedAt := ""
+			if status.Applied {
+				applied = "yes"
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			if status.Unknown {
+				applied = "yes (unknown to this version)"
+			}
+			tw.Write(map[string]interface{}{
+				"Version":   status.Version,
+				"Name":      status.Name,
+				"Applied":   applied,
+				"AppliedAt": appliedAt,
+			})
+		}
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var rollbackDBCmd = &cli.Command{
+	Name:  "rollback",
+	Usage: "revert the last migrations, stop messager before running it, the initial migration can not be reverted",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "steps",
+			Usage: "the number of migrations to revert",
+			Value: 1,
+		},
+		&cli.BoolFlag{
+			Name:  "really-do-it",
+			Usage: "specify this flag to confirm rollback, the data of the dropped tables or columns are lost",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if !ctx.Bool("really-do-it") {
+			return errors.New("confirm to exec this command, specify --really-do-it")
+		}
+		steps := ctx.Int("steps")
+		if steps <= 0 {
+			return errors.New("steps must be greater than 0")
+		}
+		migrator, err := getSchemaMigrator(ctx)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Rollback(ctx.Context, steps); err != nil {
+			return err
+		}
+		version, err := migrator.Version(ctx.Context)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("database is at version %d\n", version)
+		return nil
+	},
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/urfave/cli/v2"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var EthCmds = &cli.Command{
+	Name:  "eth",
+	Usage: "send eth transactions from delegated addresses",
+	Subcommands: []*cli.Command{
+		ethSendCmd,
+	},
+}
+
+var ethSendCmd = &cli.Command{
+	Name:      "send",
+	Usage:     "send an EIP-1559 transaction, the message can be searched by the eth tx hash once it is signed",
+	ArgsUsage: "[target eth address]",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "the delegated (f4) address which sends the transaction",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "value",
+			Usage: "the amount of FIL sent to the target",
+			Value: "0",
+		},
+		&cli.StringFlag{
+			Name:  "input-hex",
+			Usage: "the input of transaction in hex, the init code of contract if target is empty",
+		},
+		&cli.Int64Flag{
+			Name:  "gas-limit",
+			Usage: "the gas limit of transaction, estimated if not set",
+		},
+		&cli.StringFlag{
+			Name:  "max-fee-per-gas",
+			Usage: "the max fee per gas in attoFIL, estimated if not set",
+		},
+		&cli.StringFlag{
+			Name:  "max-priority-fee-per-gas",
+			Usage: "the max priority fee per gas in attoFIL, estimated if not set",
+		},
+	}, sendSpecFlags...),
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() > 1 {
+			return errors.New("must specify at most one target eth address, create a contract if it is empty")
+		}
+
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		params := &sophonTypes.EthTxParams{
+			GasLimit: ctx.Int64("gas-limit"),
+			Spec:     sendSpecOfFlags(ctx),
+		}
+		if params.From, err = address.NewFromString(ctx.String("from")); err != nil {
+			return fmt.Errorf("failed to parse from address: %w", err)
+		}
+		if ctx.NArg() == 1 {
+			to, err := venusTypes.ParseEthAddress(ctx.Args().Get(0))
+			if err != nil {
+				return fmt.Errorf("failed to parse target eth address: %w", err)
+			}
+			params.To = &to
+		}
+		val, err := venusTypes.ParseFIL(ctx.String("value"))
+		if err != nil {
+			return fmt.Errorf("failed to parse value: %w", err)
+		}
+		params.Value = abi.TokenAmount(val)
+		if ctx.IsSet("input-hex") {
+			if params.Input, err = hex.DecodeString(strings.TrimPrefix(ctx.String("input-hex"), "0x")); err != nil {
+				return fmt.Errorf("failed to decode hex input: %w", err)
+			}
+		}
+		if params.MaxFeePerGas, err = parseAttoFIL(ctx, "max-fee-per-gas"); err != nil {
+			return err
+		}
+		if params.MaxPriorityFeePerGas, err = parseAttoFIL(ctx, "max-priority-fee-per-gas"); err != nil {
+			return err
+		}
+
+		uuid, err := client.PushEthTransaction(ctx.Context, params)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("msg uuid %s \n", uuid)
+		return nil
+	},
+}
+
+func parseAttoFIL(ctx *cli.Context, name string) (big.Int, error) {
+	if !ctx.IsSet(name) {
+		return big.Zero(), nil
+	}
+	val, err := big.FromString(ctx.String(name))
+	if err != nil {
+		return big.Int{}, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return val, nil
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var ExitCodeRuleCmds = &cli.Command{
+	Name:  "exit-code-rule",
+	Usage: "rules applied to the messages landed on chain with a non-zero exit code, retry, alert or mark bad",
+	Subcommands: []*cli.Command{
+		listExitCodeRuleCmd,
+		setExitCodeRuleCmd,
+		deleteExitCodeRuleCmd,
+	},
+}
+
+var listExitCodeRuleCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list exit code rule",
+	Flags: []cli.Flag{
+		outputTypeFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		rules, err := client.ListExitCodeRule(ctx.Context)
+		if err != nil {
+			return err
+		}
+
+		if ctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col("ID"),
+				tablewriter.Col("ExitCode"),
+				tablewriter.Col("Code"),
+				tablewriter.Col("Methods"),
+				tablewriter.Col("Action"),
+				tablewriter.Col("GasMultiplier"),
+				tablewriter.Col("MaxRetries"),
+				tablewriter.Col("Description"),
+			)
+			for _, rule := range rules {
+				code := ""
+				if rule.Code.Defined() {
+					code = rule.Code.String()
+				}
+				methods := make([]string, 0, len(rule.Methods))
+				for _, method := range rule.Methods {
+					methods = append(methods, method.String())
+				}
+				tw.Write(map[string]interface{}{
+					"ID":            rule.ID,
+					"ExitCode":      rule.ExitCode,
+					"Code":          code,
+					"Methods":       strings.Join(methods, ","),
+					"Action":        rule.Action,
+					"GasMultiplier": rule.GasMultiplier,
+					"MaxRetries":    rule.MaxRetries,
+					"Description":   rule.Description,
+				})
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(rules, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var setExitCodeRuleCmd = &cli.Command{
+	Name: "set",
+	Usage: "create an exit code rule, or replace the rule with --id. When several rules match a message, " +
+		"the rule with --code takes precedence, then the rule with --methods",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "id",
+			Usage: "id of the rule to replace, create a new rule if not set",
+		},
+		&cli.Int64Flag{
+			Name:     "exit-code",
+			Usage:    "the exit code of failed message, eg. 7 (SysErrOutOfGas), 6 (SysErrInsufficientFunds), 18 (ErrForbidden)",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "code",
+			Usage: "the code cid of recipient actor, any actor if not set",
+		},
+		&cli.Uint64SliceFlag{
+			Name:  "methods",
+			Usage: "the methods of failed message, any method if not set",
+		},
+		&cli.StringFlag{
+			Name:     "action",
+			Usage:    "retry, alert or mark_bad",
+			Required: true,
+		},
+		&cli.Float64Flag{
+			Name:  "gas-multiplier",
+			Usage: "only for retry, the gas over estimation of the retry message is multiplied by it",
+			Value: 1.5,
+		},
+		&cli.IntFlag{
+			Name:  "max-retries",
+			Usage: "only for retry, the max number of retries following the first message",
+			Value: 3,
+		},
+		&cli.StringFlag{
+			Name:  "description",
+			Usage: "description of the rule",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		action, err := sophonTypes.ParseExitCodeAction(ctx.String("action"))
+		if err != nil {
+			return err
+		}
+		rule := &sophonTypes.ExitCodeRule{
+			ID:          ctx.String("id"),
+			ExitCode:    exitcode.ExitCode(ctx.Int64("exit-code")),
+			Action:      action,
+			Description: ctx.String("description"),
+		}
+		if action == sophonTypes.ExitCodeRetry {
+			rule.GasMultiplier = ctx.Float64("gas-multiplier")
+			rule.MaxRetries = ctx.Int("max-retries")
+		}
+		if ctx.IsSet("code") {
+			if rule.Code, err = cid.Decode(ctx.String("code")); err != nil {
+				return err
+			}
+		}
+		for _, method := range ctx.Uint64Slice("methods") {
+			rule.Methods = append(rule.Methods, abi.MethodNum(method))
+		}
+
+		id, err := client.SetExitCodeRule(ctx.Context, rule)
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var deleteExitCodeRuleCmd = &cli.Command{
+	Name:      "delete",
+	Usage:     "delete exit code rule",
+	ArgsUsage: "<id>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 1 {
+			return errors.New("must specify one id argument")
+		}
+		return client.DeleteExitCodeRule(ctx.Context, ctx.Args().Get(0))
+	},
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	types2 "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	"github.com/ipfs-force-community/sophon-messager/config"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var FeeBumpCmds = &cli.Command{
+	Name:  "fee-bump",
+	Usage: "automatic fee bumping of blocked messages",
+	Subcommands: []*cli.Command{
+		feeBumpPolicyCmds,
+		listFeeBumpRecordCmd,
+	},
+}
+
+var feeBumpPolicyCmds = &cli.Command{
+	Name:  "policy",
+	Usage: "fee bump policy of address or actor",
+	Subcommands: []*cli.Command{
+		listFeeBumpPolicyCmd,
+		setFeeBumpPolicyCmd,
+		deleteFeeBumpPolicyCmd,
+	},
+}
+
+var listFeeBumpPolicyCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list fee bump policy",
+	Flags: []cli.Flag{
+		outputTypeFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		policies, err := client.ListFeeBumpPolicy(ctx.Context)
+		if err != nil {
+			return err
+		}
+
+		if ctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col("ID"),
+				tablewriter.Col("Address"),
+				tablewriter.Col("Code"),
+				tablewriter.Col("Method"),
+				tablewriter.Col("MaxBumps"),
+				tablewriter.Col("BumpRatio"),
+				tablewriter.Col("MaxFeeCap"),
+				tablewriter.Col("UpdatedAt"),
+			)
+			for _, policy := range policies {
+				row := map[string]interface{}{
+					"ID":        policy.ID,
+					"MaxBumps":  policy.MaxBumps,
+					"BumpRatio": policy.BumpRatio,
+					"MaxFeeCap": policy.MaxFeeCap,
+					"UpdatedAt": policy.UpdatedAt.Format("2006-01-02 15:04:05"),
+				}
+				if policy.IsAddressPolicy() {
+					row["Address"] = policy.Addr
+				} else {
+					row["Code"] = policy.Code
+					row["Method"] = policy.Method
+				}
+				tw.Write(row)
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(policies, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var setFeeBumpPolicyCmd = &cli.Command{
+	Name:  "set",
+	Usage: "create or update the fee bump policy of an address, or an actor with --code and --method",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "address",
+			Usage: "the policy applies to the messages sent from the address",
+		},
+		&cli.StringFlag{
+			Name:  "code",
+			Usage: "the policy applies to the messages sent to the actor with the code",
+		},
+		&cli.Uint64Flag{
+			Name:  "method",
+			Usage: "method number of the actor",
+		},
+		&cli.IntFlag{
+			Name:  "max-bumps",
+			Usage: "the max number of automatic replacements of one message, 0 disables bumping",
+			Value: config.DefaultFeeBumpMaxBumps,
+		},
+		&cli.Uint64Flag{
+			Name:  "bump-ratio",
+			Usage: "the new gas premium is at least bump-ratio percent of the old one",
+			Value: config.DefaultFeeBumpRatio,
+		},
+		&cli.StringFlag{
+			Name:  "max-fee-cap",
+			Usage: "the ceiling of gas fee cap in attoFIL, 0 means only limited by the max fee",
+			Value: "0",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		policy := &sophonTypes.FeeBumpPolicy{
+			Addr:      address.Undef,
+			Code:      cid.Undef,
+			MaxBumps:  ctx.Int("max-bumps"),
+			BumpRatio: types2.Percent(ctx.Uint64("bump-ratio")),
+		}
+		switch {
+		case ctx.IsSet("address") && ctx.IsSet("code"):
+			return errors.New("can not set both address and code")
+		case ctx.IsSet("address"):
+			if policy.Addr, err = address.NewFromString(ctx.String("address")); err != nil {
+				return err
+			}
+		case ctx.IsSet("code"):
+			if policy.Code, err = cid.Decode(ctx.String("code")); err != nil {
+				return err
+			}
+			policy.Method = abi.MethodNum(ctx.Uint64("method"))
+		default:
+			return errors.New("must specify address or code")
+		}
+		if policy.MaxFeeCap, err = big.FromString(ctx.String("max-fee-cap")); err != nil {
+			return fmt.Errorf("parsing max fee cap failed %v", err)
+		}
+
+		return client.SetFeeBumpPolicy(ctx.Context, policy)
+	},
+}
+
+var deleteFeeBumpPolicyCmd = &cli.Command{
+	Name:      "delete",
+	Usage:     "delete fee bump policy",
+	ArgsUsage: "<id>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 1 {
+			return errors.New("must specify one id argument")
+		}
+		return client.DeleteFeeBumpPolicy(ctx.Context, ctx.Args().Get(0))
+	},
+}
+
+var listFeeBumpRecordCmd = &cli.Command{
+	Name:      "records",
+	Usage:     "list the automatic replacements of message",
+	ArgsUsage: "<message id>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 1 {
+			return errors.New("must specify one message id argument")
+		}
+		records, err := client.ListFeeBumpRecord(ctx.Context, ctx.Args().Get(0))
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(records, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+var LeaderCmd = &cli.Command{
+	Name:  "leader",
+	Usage: "show the instance holding the leader lease, only the leader selects and pushes messages",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		lease, err := client.GetLeader(ctx.Context)
+		if err != nil {
+			return err
+		}
+
+		bytes, err := json.MarshalIndent(lease, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 
@@ -11,11 +12,12 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/filecoin-project/go-address"
-	"github.com/ipfs-force-community/sophon-messager/utils"
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 
 	"github.com/filecoin-project/venus/pkg/constants"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
-	msgparser "github.com/filecoin-project/venus/venus-shared/utils/msg_parser"
 )
 
 var MsgCmds = &cli.Command{
@@ -31,6 +33,13 @@ var MsgCmds = &cli.Command{
 		replaceCmd,
 		waitMessagerCmd,
 		republishCmd,
+		setPriorityCmd,
+		simulateSelectCmd,
+		nonceGapCmd,
+		fillNonceGapCmd,
+		subscribeCmd,
+		historyCmd,
+		retriesCmd,
 		markBadCmd,
 		clearUnFillMessageCmd,
 		recoverFailedMsgCmd,
@@ -50,6 +59,10 @@ var searchCmd = &cli.Command{
 			Name:  "cid",
 			Usage: "message cid",
 		},
+		&cli.StringFlag{
+			Name:  "eth-hash",
+			Usage: "hash of eth transaction",
+		},
 	},
 	Action: func(ctx *cli.Context) error {
 		client, closer, err := getAPI(ctx)
@@ -83,6 +96,15 @@ var searchCmd = &cli.Command{
 			if err != nil {
 				return err
 			}
+		} else if hashStr := ctx.String("eth-hash"); len(hashStr) > 0 {
+			hash, err := venusTypes.ParseEthHash(hashStr)
+			if err != nil {
+				return err
+			}
+			msg, err = client.GetMessageByEthHash(ctx.Context, hash)
+			if err != nil {
+				return err
+			}
 		} else {
 			return fmt.Errorf("value of query must be entered")
 		}
@@ -93,40 +115,28 @@ var searchCmd = &cli.Command{
 		}
 		fmt.Printf("- message information:\n%s\n", string(bytes))
 
-		paser, err := msgparser.NewMessageParser(nodeAPI)
+		call, err := decodeContractCall(ctx.Context, client, msg)
 		if err != nil {
-			return nil
+			fmt.Printf("decode contract call failed %v\n", err)
+		} else if call != nil {
+			bytes, _ := json.MarshalIndent(call, "", "\t")
+			fmt.Printf("- contract call information:\n%s\n", string(bytes))
 		}
-		params, rets, err := paser.ParseMessage(ctx.Context, msg.VMMessage(), msg.Receipt)
+
+		decoded, err := client.GetDecodedMessage(ctx.Context, msg.ID)
 		if err != nil {
 			return err
 		}
-
-		if params != nil {
-			res, err := utils.TryConvertParams(params)
-			if err != nil {
-				fmt.Printf("try convert params failed %v\n", err)
-			} else {
-				params = res
-			}
-
-			bytes, _ := json.MarshalIndent(params, "", "\t")
-			if len(bytes) != 0 {
-				fmt.Printf("- params information:\n%s\n", string(bytes))
-			}
+		if len(decoded.DecodeError) > 0 {
+			fmt.Printf("decode message failed %s\n", decoded.DecodeError)
 		}
-
-		if rets != nil {
-			res, err := utils.TryConvertParams(rets)
-			if err != nil {
-				fmt.Printf("try convert ret failed %v\n", err)
-			} else {
-				rets = res
-			}
-			bytes, _ := json.MarshalIndent(rets, "", "\t")
-			if len(bytes) != 0 {
-				fmt.Printf("- returns information:\n%s\n", string(bytes))
-			}
+		if len(decoded.Params) > 0 {
+			bytes, _ := json.MarshalIndent(decoded.Params, "", "\t")
+			fmt.Printf("- params information:\n%s\n", string(bytes))
+		}
+		if len(decoded.Return) > 0 {
+			bytes, _ := json.MarshalIndent(decoded.Return, "", "\t")
+			fmt.Printf("- returns information:\n%s\n", string(bytes))
 		}
 
 		return nil
@@ -185,6 +195,10 @@ var listCmd = &cli.Command{
 		FromFlag,
 		outputTypeFlag,
 		verboseFlag,
+		&cli.BoolFlag{
+			Name:  "decode",
+			Usage: "decode the params and return of messages into json, the output type is always json",
+		},
 		&cli.IntFlag{
 			Name:  "state",
 			Value: int(types.UnFillMsg),
@@ -196,6 +210,9 @@ state:
   4:  FailedMsg
   5:  NonceConflictMsg
   6:  NoWalletMsg
+  7:  ExpiredMsg
+  8:  BatchedMsg
+  9:  FinalizedMsg
 `,
 		},
 	},
@@ -236,6 +253,19 @@ state:
 		pageIndex := ctx.Int("page-index")
 		pageSize := ctx.Int("page-size")
 
+		if ctx.Bool("decode") {
+			decoded, err := client.ListDecodedMessageByFromState(ctx.Context, from, state, false, pageIndex, pageSize, d)
+			if err != nil {
+				return err
+			}
+			bytes, err := json.MarshalIndent(decoded, " ", "\t")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(bytes))
+			return nil
+		}
+
 		msgs, err := client.ListMessageByFromState(ctx.Context, from, state, false, pageIndex, pageSize, d)
 		if err != nil {
 			return err
@@ -563,6 +593,313 @@ var republishCmd = &cli.Command{
 	},
 }
 
+var setPriorityCmd = &cli.Command{
+	Name:      "set-priority",
+	Usage:     "set the priority of unfill message, the message with higher priority will be selected first",
+	ArgsUsage: "<id> <priority>",
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if cctx.NArg() != 2 {
+			return errors.New("must has id and priority arguments")
+		}
+
+		id := cctx.Args().Get(0)
+		priority, err := strconv.Atoi(cctx.Args().Get(1))
+		if err != nil {
+			return fmt.Errorf("failed to parse priority: %w", err)
+		}
+
+		return client.SetMessagePriority(cctx.Context, id, priority)
+	},
+}
+
+var simulateSelectCmd = &cli.Command{
+	Name:  "simulate-select",
+	Usage: "simulate selecting messages of the address against the current head, nothing will be signed or saved",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "address to select message",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		addr, err := address.NewFromString(cctx.String("from"))
+		if err != nil {
+			return err
+		}
+
+		res, err := client.SimulateSelect(cctx.Context, addr)
+		if err != nil {
+			return err
+		}
+		bytes, err := json.MarshalIndent(res, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+
+		return nil
+	},
+}
+
+var nonceGapCmd = &cli.Command{
+	Name:  "nonce-gap",
+	Usage: "show the assigned nonces of the address which have no signed message, they block the messages with bigger nonce",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "address to check nonce gap",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		addr, err := address.NewFromString(cctx.String("from"))
+		if err != nil {
+			return err
+		}
+
+		res, err := client.NonceGapReport(cctx.Context, addr)
+		if err != nil {
+			return err
+		}
+		bytes, err := json.MarshalIndent(res, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+
+		return nil
+	},
+}
+
+var fillNonceGapCmd = &cli.Command{
+	Name:  "fill-nonce-gap",
+	Usage: "fill the nonce gaps of the address, re-sign the failed message or send zero value message to self",
+	Flags: []cli.Flag{
+		reallyDoItFlag,
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "address to fill nonce gap",
+			Required: true,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if !cctx.Bool("really-do-it") {
+			return errors.New("confirm to exec this command, specify --really-do-it")
+		}
+
+		addr, err := address.NewFromString(cctx.String("from"))
+		if err != nil {
+			return err
+		}
+
+		msgIDs, err := client.FillNonceGap(cctx.Context, addr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("fill nonce gap success: %v \n", msgIDs)
+
+		return nil
+	},
+}
+
+var subscribeCmd = &cli.Command{
+	Name:  "subscribe",
+	Usage: "print the events of message state changes, one event per line",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "from",
+			Usage: "only the messages from these addresses",
+		},
+		&cli.StringSliceFlag{
+			Name:  "id",
+			Usage: "only the messages with these ids",
+		},
+		&cli.IntSliceFlag{
+			Name: "state",
+			Usage: `only the events of these states,
+state:
+  1:  UnFillMsg
+  2:  FillMsg
+  3:  OnChainMsg
+  4:  FailedMsg
+  5:  NonceConflictMsg
+  6:  NoWalletMsg
+  7:  ExpiredMsg
+  8:  BatchedMsg
+  9:  FinalizedMsg
+`,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		filter := &sophonTypes.MessageEventFilter{IDs: cctx.StringSlice("id")}
+		for _, s := range cctx.StringSlice("from") {
+			addr, err := address.NewFromString(s)
+			if err != nil {
+				return err
+			}
+			filter.From = append(filter.From, addr)
+		}
+		for _, state := range cctx.IntSlice("state") {
+			filter.States = append(filter.States, types.MessageState(state))
+		}
+
+		events, err := client.SubscribeMessageEvents(cctx.Context, filter)
+		if err != nil {
+			return err
+		}
+		for event := range events {
+			bytes, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(bytes))
+		}
+
+		return nil
+	},
+}
+
+var historyCmd = &cli.Command{
+	Name:      "history",
+	Usage:     "list every signed version of the message, from the oldest to the newest",
+	ArgsUsage: "<message id>",
+	Flags: []cli.Flag{
+		outputTypeFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if cctx.NArg() != 1 {
+			return errors.New("must specify one message id argument")
+		}
+		revisions, err := client.GetMessageRevisions(cctx.Context, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		if cctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col("Revision"),
+				tablewriter.Col("SignedCid"),
+				tablewriter.Col("GasLimit"),
+				tablewriter.Col("GasFeeCap"),
+				tablewriter.Col("GasPremium"),
+				tablewriter.Col("Reason"),
+				tablewriter.Col("Operator"),
+				tablewriter.Col("CreatedAt"),
+			)
+			for idx, rev := range revisions {
+				tw.Write(map[string]interface{}{
+					"Revision":   idx + 1,
+					"SignedCid":  rev.SignedCid,
+					"GasLimit":   rev.GasLimit,
+					"GasFeeCap":  rev.GasFeeCap,
+					"GasPremium": rev.GasPremium,
+					"Reason":     rev.Reason,
+					"Operator":   rev.Operator,
+					"CreatedAt":  rev.CreatedAt.Format("2006-01-02 15:04:05"),
+				})
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(revisions, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+
+		return nil
+	},
+}
+
+var retriesCmd = &cli.Command{
+	Name:      "retries",
+	Usage:     "list the retries of the message pushed by exit code rules, from the first message to the last retry",
+	ArgsUsage: "<message id>",
+	Flags: []cli.Flag{
+		outputTypeFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if cctx.NArg() != 1 {
+			return errors.New("must specify one message id argument")
+		}
+		retries, err := client.ListMessageRetries(cctx.Context, cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		if cctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col("Attempt"),
+				tablewriter.Col("MsgID"),
+				tablewriter.Col("RetryMsgID"),
+				tablewriter.Col("RuleID"),
+				tablewriter.Col("CreatedAt"),
+			)
+			for _, retry := range retries {
+				tw.Write(map[string]interface{}{
+					"Attempt":    retry.Attempt,
+					"MsgID":      retry.MsgID,
+					"RetryMsgID": retry.RetryMsgID,
+					"RuleID":     retry.RuleID,
+					"CreatedAt":  retry.CreatedAt.Format("2006-01-02 15:04:05"),
+				})
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(retries, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+
+		return nil
+	},
+}
+
 var markBadCmd = &cli.Command{
 	Name:  "mark-bad",
 	Usage: "mark bad message",
@@ -747,6 +1084,9 @@ state:
   4:  FailedMsg
   5:  NonceConflictMsg
   6:  NoWalletMsg
+  7:  ExpiredMsg
+  8:  BatchedMsg
+  9:  FinalizedMsg
 `,
 		},
 		reallyDoItFlag,
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var MsigCmds = &cli.Command{
+	Name:  "msig",
+	Usage: "propose and approve multisig transactions",
+	Subcommands: []*cli.Command{
+		listMsigProposalCmd,
+		proposeMsigCmd,
+		approveMsigCmd,
+	},
+}
+
+var sendSpecFlags = []cli.Flag{
+	&cli.IntFlag{
+		Name:  "priority",
+		Usage: "specify the priority of message, the message with higher priority will be selected first",
+		Value: sophonTypes.DefaultPriority,
+	},
+	&cli.DurationFlag{
+		Name:  "expire-after",
+		Usage: "the message will be expired if it is not selected within the duration, 0 means no deadline",
+	},
+}
+
+func sendSpecOfFlags(ctx *cli.Context) *sophonTypes.SendSpec {
+	spec := &sophonTypes.SendSpec{
+		MessageOptions: sophonTypes.MessageOptions{
+			Priority: ctx.Int("priority"),
+		},
+	}
+	if d := ctx.Duration("expire-after"); d > 0 {
+		spec.ExpireAt = time.Now().Add(d)
+	}
+	return spec
+}
+
+var listMsigProposalCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list the multisig proposals created by messager",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "msig",
+			Usage: "only list the proposals of the multisig",
+		},
+		outputTypeFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		msig := address.Undef
+		if ctx.IsSet("msig") {
+			if msig, err = address.NewFromString(ctx.String("msig")); err != nil {
+				return err
+			}
+		}
+		proposals, err := client.ListMsigProposal(ctx.Context, msig)
+		if err != nil {
+			return err
+		}
+
+		if ctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col("ID"),
+				tablewriter.Col("Msig"),
+				tablewriter.Col("TxnID"),
+				tablewriter.Col("State"),
+				tablewriter.Col("Proposer"),
+				tablewriter.Col("To"),
+				tablewriter.Col("Value"),
+				tablewriter.Col("Method"),
+				tablewriter.Col("Approvers"),
+				tablewriter.Col("ExitCode"),
+			)
+			for _, proposal := range proposals {
+				row := map[string]interface{}{
+					"ID":        proposal.ID,
+					"Msig":      proposal.Msig,
+					"State":     proposal.State,
+					"Proposer":  proposal.Proposer,
+					"To":        proposal.To,
+					"Value":     venusTypes.FIL(proposal.Value),
+					"Method":    proposal.Method,
+					"Approvers": len(proposal.Approvers),
+				}
+				if proposal.TxnID != sophonTypes.NoTxnID {
+					row["TxnID"] = proposal.TxnID
+				}
+				if proposal.State == sophonTypes.MsigProposalApplied {
+					row["ExitCode"] = proposal.ExitCode
+				}
+				tw.Write(row)
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(proposals, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var proposeMsigCmd = &cli.Command{
+	Name:      "propose",
+	Usage:     "propose a multisig transaction, the transaction id is tracked once the propose message lands on chain",
+	ArgsUsage: "<multisig address> <target address> <amount>",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "the signer of multisig which proposes the transaction",
+			Required: true,
+		},
+		&cli.Uint64Flag{
+			Name:  "method",
+			Usage: "specify method of the target to invoke",
+			Value: uint64(builtin.MethodSend),
+		},
+		&cli.StringFlag{
+			Name:  "params-hex",
+			Usage: "specify invocation parameters of the target in hex",
+		},
+	}, sendSpecFlags...),
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() != 3 {
+			return errors.New("must specify multisig address, target address and amount")
+		}
+
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		params := &sophonTypes.MsigProposeParams{
+			Method: abi.MethodNum(ctx.Uint64("method")),
+			Spec:   sendSpecOfFlags(ctx),
+		}
+		if params.Msig, err = address.NewFromString(ctx.Args().Get(0)); err != nil {
+			return fmt.Errorf("failed to parse multisig address: %w", err)
+		}
+		if params.To, err = address.NewFromString(ctx.Args().Get(1)); err != nil {
+			return fmt.Errorf("failed to parse target address: %w", err)
+		}
+		val, err := venusTypes.ParseFIL(ctx.Args().Get(2))
+		if err != nil {
+			return fmt.Errorf("failed to parse amount: %w", err)
+		}
+		params.Value = abi.TokenAmount(val)
+		if params.From, err = address.NewFromString(ctx.String("from")); err != nil {
+			return fmt.Errorf("failed to parse from address: %w", err)
+		}
+		if ctx.IsSet("params-hex") {
+			if params.Params, err = hex.DecodeString(ctx.String("params-hex")); err != nil {
+				return fmt.Errorf("failed to decode hex params: %w", err)
+			}
+		}
+
+		id, err := client.ProposeMsig(ctx.Context, params)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("proposal id %s \n", id)
+		return nil
+	},
+}
+
+var approveMsigCmd = &cli.Command{
+	Name:      "approve",
+	Usage:     "approve a multisig proposal from another signer",
+	ArgsUsage: "<proposal id>",
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "the signer of multisig which approves the transaction",
+			Required: true,
+		},
+	}, sendSpecFlags...),
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() != 1 {
+			return errors.New("must specify one proposal id argument")
+		}
+
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		params := &sophonTypes.MsigApproveParams{
+			ProposalID: ctx.Args().Get(0),
+			Spec:       sendSpecOfFlags(ctx),
+		}
+		if params.From, err = address.NewFromString(ctx.String("from")); err != nil {
+			return fmt.Errorf("failed to parse from address: %w", err)
+		}
+
+		uuid, err := client.ApproveMsig(ctx.Context, params)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("msg uuid %s \n", uuid)
+		return nil
+	},
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var PolicyCmds = &cli.Command{
+	Name:  "policy",
+	Usage: "signer policies restricting the messages pushed from a signer",
+	Subcommands: []*cli.Command{
+		listPolicyCmd,
+		setPolicyCmd,
+		deletePolicyCmd,
+	},
+}
+
+var listPolicyCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list signer policy",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "signer",
+			Usage: "only list the policies of the signer",
+		},
+		outputTypeFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		signer := address.Undef
+		if ctx.IsSet("signer") {
+			if signer, err = address.NewFromString(ctx.String("signer")); err != nil {
+				return err
+			}
+		}
+		policies, err := client.ListSignerPolicy(ctx.Context, signer)
+		if err != nil {
+			return err
+		}
+
+		if ctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col("ID"),
+				tablewriter.Col("Signer"),
+				tablewriter.Col("To"),
+				tablewriter.Col("ActorType"),
+				tablewriter.Col("Methods"),
+				tablewriter.Col("OwnedMinerOnly"),
+				tablewriter.Col("AllowValue"),
+				tablewriter.Col("Description"),
+			)
+			for _, policy := range policies {
+				to := make([]string, 0, len(policy.To))
+				for _, addr := range policy.To {
+					to = append(to, addr.String())
+				}
+				methods := make([]string, 0, len(policy.Methods))
+				for _, method := range policy.Methods {
+					methods = append(methods, method.String())
+				}
+				tw.Write(map[string]interface{}{
+					"ID":             policy.ID,
+					"Signer":         policy.Signer,
+					"To":             strings.Join(to, ","),
+					"ActorType":      policy.ActorType,
+					"Methods":        strings.Join(methods, ","),
+					"OwnedMinerOnly": policy.OwnedMinerOnly,
+					"AllowValue":     policy.AllowValue,
+					"Description":    policy.Description,
+				})
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(policies, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
+
+var setPolicyCmd = &cli.Command{
+	Name: "set",
+	Usage: "create a signer policy, or replace the policy with --id. Once a signer has policies, " +
+		"its messages are rejected unless matching one of them",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "id",
+			Usage: "id of the policy to replace, create a new policy if not set",
+		},
+		&cli.StringFlag{
+			Name:     "signer",
+			Usage:    "the policy applies to the messages pushed from the signer",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "to",
+			Usage: "the recipients allowed, any recipient if not set",
+		},
+		&cli.StringFlag{
+			Name:  "actor-type",
+			Usage: "the type of recipient actor allowed, eg. account, storageminer, multisig, any type if not set",
+		},
+		&cli.Uint64SliceFlag{
+			Name:  "methods",
+			Usage: "the methods allowed, any method if not set",
+		},
+		&cli.BoolFlag{
+			Name:  "owned-miner-only",
+			Usage: "only allow the miners whose owner, worker or control address is the signer",
+		},
+		&cli.BoolFlag{
+			Name:  "allow-value",
+			Usage: "allow the messages transferring value",
+		},
+		&cli.StringFlag{
+			Name:  "description",
+			Usage: "description of the policy",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		policy := &sophonTypes.SignerPolicy{
+			ID:             ctx.String("id"),
+			ActorType:      ctx.String("actor-type"),
+			OwnedMinerOnly: ctx.Bool("owned-miner-only"),
+			AllowValue:     ctx.Bool("allow-value"),
+			Description:    ctx.String("description"),
+		}
+		if policy.Signer, err = address.NewFromString(ctx.String("signer")); err != nil {
+			return err
+		}
+		for _, s := range ctx.StringSlice("to") {
+			to, err := address.NewFromString(s)
+			if err != nil {
+				return err
+			}
+			policy.To = append(policy.To, to)
+		}
+		for _, method := range ctx.Uint64Slice("methods") {
+			policy.Methods = append(policy.Methods, abi.MethodNum(method))
+		}
+
+		id, err := client.SetSignerPolicy(ctx.Context, policy)
+		if err != nil {
+			return err
+		}
+		fmt.Println(id)
+		return nil
+	},
+}
+
+var deletePolicyCmd = &cli.Command{
+	Name:      "delete",
+	Usage:     "delete signer policy",
+	ArgsUsage: "<id>",
+	Action: func(ctx *cli.Context) error {
+		client, closer, err := getAPI(ctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		if ctx.NArg() != 1 {
+			return errors.New("must specify one id argument")
+		}
+		return client.DeleteSignerPolicy(ctx.Context, ctx.Args().Get(0))
+	},
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+const reportDateLayout = "2006-01-02"
+
+var ReportCmds = &cli.Command{
+	Name:  "report",
+	Usage: "reports of messages",
+	Subcommands: []*cli.Command{
+		feeReportCmd,
+	},
+}
+
+var feeReportCmd = &cli.Command{
+	Name:  "fees",
+	Usage: "sum the gas spent by on chain messages",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "from",
+			Usage: "only the messages from these addresses",
+		},
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "only the messages landed on chain since the UTC day, eg. 2006-01-02",
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Usage: "only the messages landed on chain before the UTC day, eg. 2006-01-02",
+		},
+		&cli.StringFlag{
+			Name:  "group-by",
+			Usage: "group fees by actor, method or day",
+			Value: string(sophonTypes.FeeReportByActor),
+		},
+		outputTypeFlag,
+	},
+	Action: func(cctx *cli.Context) error {
+		client, closer, err := getAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		params := &sophonTypes.FeeReportParams{GroupBy: sophonTypes.FeeReportGroupBy(cctx.String("group-by"))}
+		for _, s := range cctx.StringSlice("from") {
+			addr, err := address.NewFromString(s)
+			if err != nil {
+				return err
+			}
+			params.From = append(params.From, addr)
+		}
+		if cctx.IsSet("since") {
+			if params.Since, err = time.Parse(reportDateLayout, cctx.String("since")); err != nil {
+				return fmt.Errorf("invalid since: %w", err)
+			}
+		}
+		if cctx.IsSet("until") {
+			if params.Until, err = time.Parse(reportDateLayout, cctx.String("until")); err != nil {
+				return fmt.Errorf("invalid until: %w", err)
+			}
+		}
+
+		report, err := client.FeeReport(cctx.Context, params)
+		if err != nil {
+			return err
+		}
+
+		if cctx.String(outputTypeFlag.Name) == "table" {
+			tw := tablewriter.New(
+				tablewriter.Col(string(report.GroupBy)),
+				tablewriter.Col("Count"),
+				tablewriter.Col("GasUsed"),
+				tablewriter.Col("BaseFeeBurn"),
+				tablewriter.Col("OverEstimationBurn"),
+				tablewriter.Col("MinerTip"),
+				tablewriter.Col("TotalCost"),
+			)
+			for _, item := range append(report.Items, report.Total) {
+				tw.Write(map[string]interface{}{
+					string(report.GroupBy): item.Key,
+					"Count":                item.Count,
+					"GasUsed":              item.GasUsed,
+					"BaseFeeBurn":          venusTypes.FIL(item.BaseFeeBurn).String(),
+					"OverEstimationBurn":   venusTypes.FIL(item.OverEstimationBurn).String(),
+					"MinerTip":             venusTypes.FIL(item.MinerTip).String(),
+					"TotalCost":            venusTypes.FIL(item.TotalCost).String(),
+				})
+			}
+			return tw.Flush(os.Stdout)
+		}
+
+		bytes, err := json.MarshalIndent(report, " ", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(bytes))
+		return nil
+	},
+}
@@ -2,14 +2,18 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
 	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
 	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
 	"github.com/urfave/cli/v2"
 
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 var SendCmd = &cli.Command{
@@ -50,11 +54,28 @@ var SendCmd = &cli.Command{
 			Name:  "params-hex",
 			Usage: "specify invocation parameters in hex",
 		},
+		&cli.StringFlag{
+			Name:  "abi-method",
+			Usage: "call the method of contract by its abi set with 'abi set', eg. transfer or transfer(address,uint256), method defaults to InvokeContract",
+		},
+		&cli.StringFlag{
+			Name:  "args",
+			Usage: "the arguments of abi method in a json array, eg. '[\"0x...\", \"100\"]'",
+		},
 		&cli.StringFlag{
 			Name:     "account",
 			Usage:    "optionally specify the account to send",
 			Required: false,
 		},
+		&cli.IntFlag{
+			Name:  "priority",
+			Usage: "specify the priority of message, the message with higher priority will be selected first",
+			Value: sophonTypes.DefaultPriority,
+		},
+		&cli.DurationFlag{
+			Name:  "expire-after",
+			Usage: "the message will be expired if it is not selected within the duration, 0 means no deadline",
+		},
 	},
 	Action: func(ctx *cli.Context) error {
 		if ctx.Args().Len() != 2 {
@@ -124,8 +145,30 @@ var SendCmd = &cli.Command{
 			params.Params = ctx.String("params-hex")
 			params.ParamsType = types.QuickSendParamsCodecHex
 		}
+		abiMethod := ctx.String("abi-method")
+		if len(abiMethod) > 0 {
+			if len(params.Params) != 0 {
+				return fmt.Errorf("can not specify 'params-json' or 'params-hex' with 'abi-method'")
+			}
+			params.Params = ctx.String("args")
+			params.ParamsType = types.QuickSendParamsCodecJSON
+			if !ctx.IsSet("method") {
+				params.Method = builtintypes.MethodsEVM.InvokeContract
+			}
+		}
+
+		opts := sophonTypes.MessageOptions{
+			Priority: ctx.Int("priority"),
+		}
+		if d := ctx.Duration("expire-after"); d > 0 {
+			opts.ExpireAt = time.Now().Add(d)
+		}
 
-		uuid, err := client.Send(ctx.Context, params)
+		uuid, err := client.SendWithSpec(ctx.Context, sophonTypes.QuickSendParams{
+			QuickSendParams: params,
+			MessageOptions:  opts,
+			ABIMethod:       abiMethod,
+		})
 		if err != nil {
 			return err
 		}
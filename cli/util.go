@@ -15,6 +15,7 @@ import (
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
 	"github.com/filecoin-project/venus/venus-shared/utils"
 	"github.com/ipfs-force-community/sophon-messager/cli/tablewriter"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 	"github.com/ipfs/go-cid"
 )
 
@@ -136,7 +137,7 @@ func transformMessage(msg *types.Message, nodeAPI v1.FullNode) *message {
 		TipSetKey:   msg.TipSetKey,
 		Meta:        msg.Meta,
 		WalletName:  msg.WalletName,
-		State:       msg.State.String(),
+		State:       sophonTypes.MessageStateString(msg.State),
 		ErrorMsg:    msg.ErrorMsg,
 
 		UpdatedAt: msg.UpdatedAt,
@@ -19,6 +19,8 @@ type Config struct {
 	Metrics        *metrics.MetricsConfig `toml:"metrics"`
 	Libp2pNet      *Libp2pNetConfig       `toml:"libp2p"`
 	Publisher      *PublisherConfig       `toml:"publisher"`
+	Notifier       NotifierConfig         `toml:"notifier"`
+	FeeBump        FeeBumpConfig          `toml:"feeBump"`
 }
 
 type NodeConfig struct {
@@ -64,6 +66,8 @@ const (
 	DefWaitingChainHeadStableDuration = time.Second * 8
 )
 
+const DefaultSelectStrategy = "fifo"
+
 const (
 	DefaultTimeout         = time.Second
 	SignMessageTimeout     = time.Second * 3
@@ -79,6 +83,77 @@ type MessageServiceConfig struct {
 
 	SkipProcessHead bool `toml:"skipProcessHead"`
 	SkipPushMessage bool `toml:"skipPushMessage"`
+
+	// SelectStrategy the default strategy used to select message, can be overridden by address,
+	// supports: fifo, fee, deadline
+	SelectStrategy string `toml:"selectStrategy"`
+
+	// Batch collapses the small sends of the addresses which enable batch send into one message of aggregator
+	Batch BatchConfig `toml:"batch"`
+
+	// Finality promotes the on chain messages to FinalizedMsg once they can't be reverted
+	Finality FinalityConfig `toml:"finality"`
+
+	// LeaderElection elects one leader among the instances sharing the same mysql database, only the leader
+	// selects and pushes messages and processes the head changes, the others serve the read apis
+	LeaderElection LeaderElectionConfig `toml:"leaderElection"`
+
+	// Sharding splits the active addresses among the instances sharing the same mysql database, each instance
+	// only selects the messages of its own addresses, it requires LeaderElection
+	Sharding ShardingConfig `toml:"sharding"`
+}
+
+const (
+	DefaultBatchMinSize = 2
+	DefaultBatchMaxSize = 50
+)
+
+type BatchConfig struct {
+	// Aggregator the address of a multicall3 compatible EVM contract, empty means batching is disabled
+	Aggregator string `toml:"aggregator"`
+	// MinSize the unfill messages are batched only when there are at least MinSize of them
+	MinSize int `toml:"minSize"`
+	// MaxSize the max number of messages in one batch
+	MaxSize int `toml:"maxSize"`
+}
+
+const DefaultFinalityEpochs = 900
+
+type FinalityConfig struct {
+	// Enable the on chain messages are promoted to FinalizedMsg, clients which only know the states of venus
+	// should query messages by OnChainMsg and FinalizedMsg after enabling it
+	Enable bool `toml:"enable"`
+	// Epochs a message is finalized after the chain head is Epochs higher than the message
+	Epochs int `toml:"epochs"`
+	// EnableF3 a message is also finalized once its tipset is finalized by F3
+	EnableF3 bool `toml:"enableF3"`
+}
+
+const (
+	DefaultLeaseDuration = 30 * time.Second
+	DefaultRenewInterval = 10 * time.Second
+)
+
+type LeaderElectionConfig struct {
+	Enable bool `toml:"enable"`
+	// LeaseDuration another instance takes over the leader after the lease is not renewed for LeaseDuration
+	LeaseDuration time.Duration `toml:"leaseDuration"`
+	// RenewInterval the leader renews the lease every RenewInterval, the others try to acquire it at the same interval,
+	// it should be much shorter than LeaseDuration
+	RenewInterval time.Duration `toml:"renewInterval"`
+}
+
+const (
+	DefaultShardHeartbeatInterval = 10 * time.Second
+	DefaultShardMemberTimeout     = 30 * time.Second
+)
+
+type ShardingConfig struct {
+	Enable bool `toml:"enable"`
+	// HeartbeatInterval the instance renews its membership and rebalances the addresses every HeartbeatInterval
+	HeartbeatInterval time.Duration `toml:"heartbeatInterval"`
+	// MemberTimeout the addresses of an instance are taken over by the others after it is not renewed for MemberTimeout
+	MemberTimeout time.Duration `toml:"memberTimeout"`
 }
 
 type Libp2pNetConfig struct {
@@ -117,6 +192,51 @@ type MessageStateConfig struct {
 	DefaultExpiration, CleanupInterval int // message 缓存的有效时间和清理间隔
 }
 
+const (
+	DefaultNotifyMaxAttempts      = 10
+	DefaultNotifyRetryInterval    = time.Second * 10
+	DefaultNotifyMaxRetryInterval = time.Minute * 10
+)
+
+type NotifierConfig struct {
+	// Webhooks notifications are posted to all of them, the notifier is disabled if empty
+	Webhooks []WebhookConfig `toml:"webhooks"`
+
+	// MaxAttempts a notification is given up after failing to deliver MaxAttempts times
+	MaxAttempts int `toml:"maxAttempts"`
+	// RetryInterval the interval before the first retry, it is doubled after each failure up to MaxRetryInterval
+	RetryInterval    time.Duration `toml:"retryInterval"`
+	MaxRetryInterval time.Duration `toml:"maxRetryInterval"`
+}
+
+type WebhookConfig struct {
+	URL string `toml:"url"`
+	// Secret is used to sign the payload with HMAC-SHA256, the hex encoded signature is set in the header
+	// `X-Messager-Signature`
+	Secret string `toml:"secret"`
+}
+
+const (
+	DefaultFeeBumpBlockedEpochs = 10
+	DefaultFeeBumpCheckInterval = time.Minute
+	DefaultFeeBumpMaxBumps      = 3
+	DefaultFeeBumpRatio         = 125
+)
+
+type FeeBumpConfig struct {
+	// Enable replaces the blocked messages with higher fee automatically, it doesn't work when skipPushMessage is true
+	Enable bool `toml:"enable"`
+	// BlockedEpochs a message is bumped after it is blocked for BlockedEpochs since it was signed or bumped last time
+	BlockedEpochs int           `toml:"blockedEpochs"`
+	CheckInterval time.Duration `toml:"checkInterval"`
+
+	// MaxBumps, BumpRatio and MaxFeeCap are the default policy, which can be overridden by address or actor policy
+	MaxBumps  int    `toml:"maxBumps"`
+	BumpRatio uint64 `toml:"bumpRatio"`
+	// MaxFeeCap the ceiling of gas fee cap in attoFIL, empty means no ceiling
+	MaxFeeCap string `toml:"maxFeeCap"`
+}
+
 type GatewayConfig struct {
 	Token string   `toml:"token"`
 	Url   []string `toml:"url"`
@@ -161,6 +281,32 @@ func DefaultConfig() *Config {
 
 			SkipProcessHead: false,
 			SkipPushMessage: false,
+
+			SelectStrategy: DefaultSelectStrategy,
+
+			Batch: BatchConfig{
+				Aggregator: "",
+				MinSize:    DefaultBatchMinSize,
+				MaxSize:    DefaultBatchMaxSize,
+			},
+
+			Finality: FinalityConfig{
+				Enable:   false,
+				Epochs:   DefaultFinalityEpochs,
+				EnableF3: false,
+			},
+
+			LeaderElection: LeaderElectionConfig{
+				Enable:        false,
+				LeaseDuration: DefaultLeaseDuration,
+				RenewInterval: DefaultRenewInterval,
+			},
+
+			Sharding: ShardingConfig{
+				Enable:            false,
+				HeartbeatInterval: DefaultShardHeartbeatInterval,
+				MemberTimeout:     DefaultShardMemberTimeout,
+			},
 		},
 		Gateway: GatewayConfig{
 			Token: "",
@@ -181,5 +327,19 @@ func DefaultConfig() *Config {
 			EnableP2P:          false,
 			EnableMultiNode:    true,
 		},
+		Notifier: NotifierConfig{
+			Webhooks:         []WebhookConfig{},
+			MaxAttempts:      DefaultNotifyMaxAttempts,
+			RetryInterval:    DefaultNotifyRetryInterval,
+			MaxRetryInterval: DefaultNotifyMaxRetryInterval,
+		},
+		FeeBump: FeeBumpConfig{
+			Enable:        false,
+			BlockedEpochs: DefaultFeeBumpBlockedEpochs,
+			CheckInterval: DefaultFeeBumpCheckInterval,
+			MaxBumps:      DefaultFeeBumpMaxBumps,
+			BumpRatio:     DefaultFeeBumpRatio,
+			MaxFeeCap:     "",
+		},
 	}
 }
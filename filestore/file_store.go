@@ -20,6 +20,7 @@ type FSRepo interface {
 	Path() string
 	Config() *config.Config
 	ReplaceConfig(cfg *config.Config) error
+	// TipsetFile the tipsets were stored in it by the older versions, it is only read to import the tipsets into db
 	TipsetFile() string
 	SqliteFile() string
 	GetToken() ([]byte, error)
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"sync"
 
@@ -98,8 +99,17 @@ func (m *MockWalletProxy) WalletSign(ctx context.Context, addr address.Address,
 	if !has {
 		return nil, fmt.Errorf("failed to found %s", addr)
 	}
+	sigType := testhelper.AddressProtocolToSignType(addr.Protocol())
+	if sigType == crypto.SigTypeDelegated {
+		// the delegated signature is r, s and v of 65 bytes, so that it can be converted to an eth transaction
+		r, s := sha256.Sum256(toSign), sha256.Sum256(addr.Bytes())
+		return &crypto.Signature{
+			Type: sigType,
+			Data: append(append(r[:], s[:]...), 0),
+		}, nil
+	}
 	return &crypto.Signature{
-		Type: testhelper.AddressProtocolToSignType(addr.Protocol()),
+		Type: sigType,
 		Data: append(toSign, addr.Bytes()...),
 	}, nil
 }
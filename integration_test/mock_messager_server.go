@@ -18,9 +18,9 @@ import (
 
 	v1 "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
 	gatewayAPI "github.com/filecoin-project/venus/venus-shared/api/gateway/v2"
-	"github.com/filecoin-project/venus/venus-shared/api/messager"
 
 	"github.com/ipfs-force-community/sophon-messager/api"
+	"github.com/ipfs-force-community/sophon-messager/api/messager"
 	ccli "github.com/ipfs-force-community/sophon-messager/cli"
 	"github.com/ipfs-force-community/sophon-messager/config"
 	"github.com/ipfs-force-community/sophon-messager/filestore"
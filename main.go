@@ -55,10 +55,19 @@ func main() {
 			ccli.AddrCmds,
 			ccli.SharedParamsCmds,
 			ccli.ActorCfgCmds,
+			ccli.FeeBumpCmds,
+			ccli.ReportCmds,
+			ccli.PolicyCmds,
+			ccli.MsigCmds,
+			ccli.EthCmds,
+			ccli.ABICmds,
+			ccli.ExitCodeRuleCmds,
 			ccli.NodeCmds,
 			ccli.LogCmds,
 			ccli.SendCmd,
 			ccli.SwarmCmds,
+			ccli.LeaderCmd,
+			ccli.DBCmds,
 			runCmd,
 		},
 	}
@@ -88,7 +97,11 @@ var runCmd = &cli.Command{
 		// node
 		&cli.BoolFlag{
 			Name:  "disable-push",
-			Usage: "disable push messager function, Warn only one instance can used to push message",
+			Usage: "disable push messager function, Warn only one instance can used to push message, unless leader election is enabled",
+		},
+		&cli.BoolFlag{
+			Name:  "leader-election",
+			Usage: "elect one leader to push message among the instances sharing the same mysql database",
 		},
 		&cli.StringFlag{
 			Name:  "node-url",
@@ -293,6 +306,7 @@ func runAction(cctx *cli.Context) error {
 	invoker := fx.Options(
 		// invoke
 		fx.Invoke(service.StartNodeEvents),
+		fx.Invoke(service.StartNotifier),
 		fx.Invoke(metrics.SetupJaeger),
 		fx.Invoke(metrics.SetupMetrics),
 	)
@@ -351,6 +365,10 @@ func updateFlag(cfg *config.Config, ctx *cli.Context) error {
 		cfg.MessageService.SkipProcessHead = true
 	}
 
+	if ctx.IsSet("leader-election") {
+		cfg.MessageService.LeaderElection.Enable = ctx.Bool("leader-election")
+	}
+
 	if ctx.IsSet("auth-url") {
 		cfg.JWT.AuthURL = ctx.String("auth-url")
 	}
@@ -36,6 +36,10 @@ var (
 	SelectedMsgNumOfLastRound = metrics.NewInt64("selected_msg_num", "Number of selected messages in the last round", stats.UnitDimensionless, WalletAddress)
 	ToPushMsgNumOfLastRound   = metrics.NewInt64("topush_msg_num", "Number of to-push messages in the last round", stats.UnitDimensionless, WalletAddress)
 	ErrMsgNumOfLastRound      = metrics.NewInt64("err_msg_num", "Number of err messages in the last round", stats.UnitDimensionless, WalletAddress)
+	// LowBalanceMsgNumOfLastRound alert when it is not zero, the balance of address is too low to cover the cost of messages
+	LowBalanceMsgNumOfLastRound = metrics.NewInt64("low_balance_msg_num", "Number of messages held back by insufficient balance in the last round", stats.UnitDimensionless, WalletAddress)
+	// OverBudgetMsgNumOfLastRound the number of messages held back by the spend budget of address
+	OverBudgetMsgNumOfLastRound = metrics.NewInt64("over_budget_msg_num", "Number of messages held back by spend budget in the last round", stats.UnitDimensionless, WalletAddress)
 
 	AddressNumInState = metrics.NewInt64WithCategory("address/num", "Number of addresses in the vary state", "")
 )
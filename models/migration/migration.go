@@ -0,0 +1,224 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var log = logging.Logger("migration")
+
+var ErrSchemaTooNew = errors.New("schema of database is newer than the binary")
+
+// Migration a numbered change of the schema, Up applies it and Down reverts it, both run in a transaction which
+// also records the version in the schema_version table.
+// The transaction does not cover DDL of mysql, which commits implicitly, so a migration failed partway may leave the
+// schema half-applied without its version recorded. Each step of Up and Down must be idempotent, eg. skipped if it
+// was applied, so that the migration completes when it is run again.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// schemaVersion a migration applied to the database
+type schemaVersion struct {
+	Version   uint64    `gorm:"column:version;primaryKey"`
+	Name      string    `gorm:"column:name;type:varchar(256);not null"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+func (s schemaVersion) TableName() string {
+	return "schema_version"
+}
+
+type Status struct {
+	Version uint64
+	Name    string
+	Applied bool
+	// AppliedAt zero if the migration is not applied
+	AppliedAt time.Time
+	// Unknown the migration is applied by a newer binary
+	Unknown bool
+}
+
+// Migrator applies and reverts the migrations of a database in order of version
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB, migrations []Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+	}
+}
+
+// LatestVersion the version of the last migration known by the binary
+func (m *Migrator) LatestVersion() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) listApplied(ctx context.Context) ([]*schemaVersion, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&schemaVersion{}) {
+		return nil, nil
+	}
+	var list []*schemaVersion
+	if err := db.Order("version").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Version returns the version of the last migration applied to the database, 0 if none
+func (m *Migrator) Version(ctx context.Context) (uint64, error) {
+	list, err := m.listApplied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+	return list[len(list)-1].Version, nil
+}
+
+// Check returns ErrSchemaTooNew if the database was migrated by a newer binary
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version > m.LatestVersion() {
+		return fmt.Errorf("%w: database is at version %d, the latest version known is %d", ErrSchemaTooNew, version,
+			m.LatestVersion())
+	}
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	applied, err := m.listApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliedMap := make(map[uint64]*schemaVersion, len(applied))
+	for _, v := range applied {
+		appliedMap[v.Version] = v
+	}
+
+	list := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if v, ok := appliedMap[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = v.AppliedAt
+			delete(appliedMap, migration.Version)
+		}
+		list = append(list, status)
+	}
+	for _, v := range applied {
+		if _, ok := appliedMap[v.Version]; ok {
+			list = append(list, &Status{
+				Version:   v.Version,
+				Name:      v.Name,
+				Applied:   true,
+				AppliedAt: v.AppliedAt,
+				Unknown:   true,
+			})
+		}
+	}
+
+	return list, nil
+}
+
+// Migrate applies the migrations newer than the version of database, it refuses to run if the database was
+// migrated by a newer binary
+func (m *Migrator) Migrate(ctx context.Context) error {
+	if err := m.Check(ctx); err != nil {
+		return err
+	}
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&schemaVersion{}) {
+		if err := db.Migrator().CreateTable(&schemaVersion{}); err != nil {
+			return fmt.Errorf("create schema_version failed: %w", err)
+		}
+	}
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			// another instance may apply the same migration at the same time
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&schemaVersion{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("apply migration %d %s failed: %w", migration.Version, migration.Name, err)
+		}
+		log.Infof("apply migration %d %s", migration.Version, migration.Name)
+	}
+
+	return nil
+}
+
+// Rollback reverts the last steps migrations applied to the database
+func (m *Migrator) Rollback(ctx context.Context, steps int) error {
+	applied, err := m.listApplied(ctx)
+	if err != nil {
+		return err
+	}
+	migrationMap := make(map[uint64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		migrationMap[migration.Version] = migration
+	}
+
+	for i := len(applied) - 1; i >= 0 && i >= len(applied)-steps; i-- {
+		migration, ok := migrationMap[applied[i].Version]
+		if !ok {
+			return fmt.Errorf("%w: migration %d %s is unknown", ErrSchemaTooNew, applied[i].Version, applied[i].Name)
+		}
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaVersion{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback migration %d %s failed: %w", migration.Version, migration.Name, err)
+		}
+		log.Infof("rollback migration %d %s", migration.Version, migration.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID   uint64 `gorm:"column:id;primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (u user) TableName() string {
+	return "users"
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			Version: 2,
+			Name:    "rename name to nick",
+			Up: func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE users RENAME COLUMN name TO nick").Error
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE users RENAME COLUMN nick TO name").Error
+			},
+		},
+		{
+			Version: 1,
+			Name:    "create users",
+			Up: func(tx *gorm.DB) error {
+				return tx.Migrator().CreateTable(&user{})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&user{})
+			},
+		},
+	}
+}
+
+func hasColumn(t *testing.T, db *gorm.DB, column string) bool {
+	var count int64
+	require.NoError(t, db.Raw("SELECT count(*) FROM pragma_table_info('users') WHERE name = ?", column).Scan(&count).Error)
+	return count > 0
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	migrator := NewMigrator(db, testMigrations())
+	assert.Equal(t, uint64(2), migrator.LatestVersion())
+	version, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), version)
+
+	require.NoError(t, migrator.Migrate(ctx))
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+	assert.True(t, hasColumn(t, db, "nick"))
+	assert.False(t, hasColumn(t, db, "name"))
+	// nothing to apply
+	require.NoError(t, migrator.Migrate(ctx))
+
+	list, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	for i, status := range list {
+		assert.Equal(t, uint64(i+1), status.Version)
+		assert.True(t, status.Applied)
+		assert.False(t, status.AppliedAt.IsZero())
+	}
+
+	require.NoError(t, migrator.Rollback(ctx, 1))
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+	assert.True(t, hasColumn(t, db, "name"))
+	list, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, list[0].Applied)
+	assert.False(t, list[1].Applied)
+
+	// a failed migration is not recorded
+	failed := append(testMigrations(), Migration{
+		Version: 3,
+		Name:    "failed",
+		Up: func(tx *gorm.DB) error {
+			return errors.New("mock error")
+		},
+	})
+	migrator = NewMigrator(db, failed)
+	assert.Error(t, migrator.Migrate(ctx))
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+
+	// the binary knows only version 1
+	old := NewMigrator(db, testMigrations()[1:])
+	assert.ErrorIs(t, old.Check(ctx), ErrSchemaTooNew)
+	assert.ErrorIs(t, old.Migrate(ctx), ErrSchemaTooNew)
+	assert.ErrorIs(t, old.Rollback(ctx, 1), ErrSchemaTooNew)
+	list, err = old.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.False(t, list[0].Unknown)
+	assert.True(t, list[1].Unknown)
+	assert.Equal(t, "rename name to nick", list[1].Name)
+
+	migrator = NewMigrator(db, testMigrations())
+	require.NoError(t, migrator.Rollback(ctx, 5))
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), version)
+	assert.False(t, db.Migrator().HasTable(&user{}))
+}
+
+type userAge struct {
+	Age int `gorm:"column:age"`
+}
+
+func (u userAge) TableName() string {
+	return "users"
+}
+
+// the steps are run again after a migration failed partway, the DDL of mysql is not rolled back
+func TestSchemaStepsIdempotent(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+
+	steps := []func(tx *gorm.DB) error{
+		CreateTables(user{}),
+		AddColumn(userAge{}, "Age"),
+		DropColumn(userAge{}, "Age"),
+		DropTables(user{}),
+	}
+	for _, step := range steps {
+		require.NoError(t, step(db))
+		require.NoError(t, step(db))
+	}
+	assert.False(t, db.Migrator().HasTable(&user{}))
+
+	// a failed migration completes when it is run again
+	applied := 0
+	failed := Migration{
+		Version: 1,
+		Name:    "failed partway",
+		Up: func(tx *gorm.DB) error {
+			if err := CreateTables(user{})(tx); err != nil {
+				return err
+			}
+			if applied == 0 {
+				applied++
+				return errors.New("mock error")
+			}
+			return AddColumn(userAge{}, "Age")(tx)
+		},
+	}
+	migrator := NewMigrator(db, []Migration{failed})
+	assert.Error(t, migrator.Migrate(context.Background()))
+	require.NoError(t, migrator.Migrate(context.Background()))
+	version, err := migrator.Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+	assert.True(t, hasColumn(t, db, "age"))
+}
@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrIrreversible the migration can not be reverted without losing the data which existed before it
+var ErrIrreversible = errors.New("migration is irreversible")
+
+// Irreversible the Down of the migration which must not be reverted, eg. the initial schema
+func Irreversible(tx *gorm.DB) error {
+	return ErrIrreversible
+}
+
+// CreateTables creates the tables of models which do not exist, the models must be the copies frozen at the version
+// of migration, so the migration creates the same schema after the models of repo changed.
+// A table is created with its indexes by one statement, so the tables created before a failure are skipped on retry
+func CreateTables(models ...interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, model := range models {
+			// the database created by AutoMigrate of the older versions may have the table
+			if tx.Migrator().HasTable(model) {
+				continue
+			}
+			if err := tx.Migrator().CreateTable(model); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// DropTables drops the tables of models which exist
+func DropTables(models ...interface{}) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(models...)
+	}
+}
+
+// AddColumn adds the column of field in model if it does not exist, the indexes of field are not created
+func AddColumn(model interface{}, field string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		if tx.Migrator().HasColumn(model, field) {
+			return nil
+		}
+		return tx.Migrator().AddColumn(model, field)
+	}
+}
+
+// DropColumn drops the column of field in model if it exists, it alters the table in place rather than rebuilding it
+// like the migrator of sqlite, which loses the indexes
+func DropColumn(model interface{}, field string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		f := stmt.Schema.LookUpField(field)
+		if f == nil {
+			return fmt.Errorf("field %s not found in %s", field, stmt.Schema.Table)
+		}
+		if !tx.Migrator().HasColumn(model, field) {
+			return nil
+		}
+		return tx.Exec("ALTER TABLE ? DROP COLUMN ?", clause.Table{Name: stmt.Schema.Table}, clause.Column{Name: f.DBName}).Error
+	}
+}
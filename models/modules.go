@@ -30,8 +30,9 @@ func SetDataBase(fsRepo filestore.FSRepo) (repo.Repo, error) {
 	}
 }
 
-func AutoMigrate(repo repo.Repo) error {
-	if err := repo.AutoMigrate(); err != nil {
+// Migrate applies the pending migrations of schema, it refuses to start if the database was migrated by a newer version
+func Migrate(repo repo.Repo) error {
+	if err := repo.SchemaMigrator().Migrate(context.Background()); err != nil {
 		return fmt.Errorf("migrate: %w", err)
 	}
 	return MigrateAddress(repo)
@@ -40,7 +41,7 @@ func AutoMigrate(repo repo.Repo) error {
 func Options() fx.Option {
 	return fx.Options(
 		fx.Provide(SetDataBase),
-		fx.Invoke(AutoMigrate),
+		fx.Invoke(Migrate),
 		// repo
 		fx.Provide(repo.NewINodeRepo),
 		fx.Provide(repo.NewINodeProvider),
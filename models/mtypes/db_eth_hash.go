@@ -0,0 +1,46 @@
+package mtypes
+
+import (
+	"database/sql/driver"
+
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// DBEthHash stores an eth hash as a hex string, an empty string for the empty hash
+type DBEthHash venusTypes.EthHash
+
+func NewDBEthHash(hash venusTypes.EthHash) DBEthHash {
+	return DBEthHash(hash)
+}
+
+func (h *DBEthHash) Scan(value interface{}) error {
+	val, err := scanString(value)
+	if err != nil {
+		return err
+	}
+	if len(val) == 0 {
+		*h = DBEthHash(venusTypes.EmptyEthHash)
+		return nil
+	}
+	hash, err := venusTypes.ParseEthHash(val)
+	if err != nil {
+		return err
+	}
+	*h = DBEthHash(hash)
+	return nil
+}
+
+func (h DBEthHash) Value() (driver.Value, error) {
+	return h.String(), nil
+}
+
+func (h DBEthHash) String() string {
+	if h.EthHash() == venusTypes.EmptyEthHash {
+		return ""
+	}
+	return h.EthHash().String()
+}
+
+func (h DBEthHash) EthHash() venusTypes.EthHash {
+	return venusTypes.EthHash(h)
+}
@@ -0,0 +1,25 @@
+package mtypes
+
+import (
+	"testing"
+
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBEthHash(t *testing.T) {
+	hash := NewDBEthHash(venusTypes.EthHashFromTxBytes([]byte("tx")))
+	val, err := hash.Value()
+	assert.NoError(t, err)
+
+	var res DBEthHash
+	assert.NoError(t, res.Scan(val))
+	assert.Equal(t, hash, res)
+
+	val, err = NewDBEthHash(venusTypes.EmptyEthHash).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "", val)
+	assert.NoError(t, res.Scan(nil))
+	assert.Equal(t, venusTypes.EmptyEthHash, res.EthHash())
+	assert.Error(t, res.Scan("0x01"))
+}
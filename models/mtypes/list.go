@@ -0,0 +1,86 @@
+package mtypes
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+const listSeparator = ","
+
+func scanString(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case []byte:
+		return string(value), nil
+	case string:
+		return value, nil
+	case nil:
+		return "", nil
+	default:
+		return "", errors.New("list should be a `[]byte` or `string`")
+	}
+}
+
+// DBAddrs stores addresses as a comma separated string
+type DBAddrs []address.Address
+
+func (a *DBAddrs) Scan(value interface{}) error {
+	val, err := scanString(value)
+	if err != nil {
+		return err
+	}
+	*a = DBAddrs{}
+	if len(val) == 0 {
+		return nil
+	}
+	for _, s := range strings.Split(val, listSeparator) {
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return err
+		}
+		*a = append(*a, addr)
+	}
+	return nil
+}
+
+func (a DBAddrs) Value() (driver.Value, error) {
+	strs := make([]string, 0, len(a))
+	for _, addr := range a {
+		strs = append(strs, addr.String())
+	}
+	return strings.Join(strs, listSeparator), nil
+}
+
+// DBMethods stores method numbers as a comma separated string
+type DBMethods []abi.MethodNum
+
+func (m *DBMethods) Scan(value interface{}) error {
+	val, err := scanString(value)
+	if err != nil {
+		return err
+	}
+	*m = DBMethods{}
+	if len(val) == 0 {
+		return nil
+	}
+	for _, s := range strings.Split(val, listSeparator) {
+		method, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		*m = append(*m, abi.MethodNum(method))
+	}
+	return nil
+}
+
+func (m DBMethods) Value() (driver.Value, error) {
+	strs := make([]string, 0, len(m))
+	for _, method := range m {
+		strs = append(strs, strconv.FormatUint(uint64(method), 10))
+	}
+	return strings.Join(strs, listSeparator), nil
+}
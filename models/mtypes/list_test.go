@@ -0,0 +1,41 @@
+package mtypes
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBAddrs(t *testing.T) {
+	addrs := DBAddrs{testutil.AddressProvider()(t), testutil.AddressProvider()(t)}
+	val, err := addrs.Value()
+	assert.NoError(t, err)
+
+	var res DBAddrs
+	assert.NoError(t, res.Scan(val))
+	assert.Equal(t, addrs, res)
+	assert.NoError(t, res.Scan([]byte(val.(string))))
+	assert.Equal(t, addrs, res)
+
+	assert.NoError(t, res.Scan(""))
+	assert.Empty(t, res)
+	assert.Error(t, res.Scan("f0xx"))
+	assert.Error(t, res.Scan(1))
+}
+
+func TestDBMethods(t *testing.T) {
+	methods := DBMethods{abi.MethodNum(6), abi.MethodNum(7), abi.MethodNum(3844450837)}
+	val, err := methods.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "6,7,3844450837", val)
+
+	var res DBMethods
+	assert.NoError(t, res.Scan(val))
+	assert.Equal(t, methods, res)
+
+	assert.NoError(t, res.Scan(nil))
+	assert.Empty(t, res)
+	assert.Error(t, res.Scan("6,a"))
+}
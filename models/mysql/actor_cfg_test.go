@@ -11,6 +11,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/filecoin-project/venus/venus-shared/testutil"
+	shared "github.com/filecoin-project/venus/venus-shared/types"
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 	"gorm.io/gorm"
@@ -28,6 +29,7 @@ func Test_mysqlActorCfgRepo_SaveActorCfg(t *testing.T) {
 	t.Run("mysql test delete actor config by method types", wrapper(testDeleteActorCfgByMethodType, r, mock))
 	t.Run("mysql test delete actor config by id", wrapper(testDeleteActorCfgById, r, mock))
 	t.Run("mysql test update actor config", wrapper(testUpdateSelectSpec, r, mock))
+	t.Run("mysql test update preflight of actor config", wrapper(testUpdatePreflight, r, mock))
 	assert.NoError(t, closeDB(mock, sqlDB))
 }
 
@@ -266,3 +268,22 @@ func testUpdateSelectSpec(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 		})
 	assert.NoError(t, err)
 }
+
+func testUpdatePreflight(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	id := shared.NewUUID()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `actor_cfg` SET `preflight`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(true, anyTime{}, id).
+		WillReturnResult(driverResult{0, 1})
+	mock.ExpectCommit()
+	assert.NoError(t, r.ActorCfgRepo().UpdatePreflightById(ctx, id, true))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `actor_cfg` WHERE preflight = ?")).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id.String()))
+	ids, err := r.ActorCfgRepo().ListPreflightActorCfgID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.UUID{id}, ids)
+}
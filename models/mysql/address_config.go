@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlAddressConfig struct {
+	Addr             string     `gorm:"column:addr;type:varchar(256);primary_key"`
+	SelectStrategy   string     `gorm:"column:select_strategy;type:varchar(64);NOT NULL"`
+	AutoFillNonceGap bool       `gorm:"column:auto_fill_nonce_gap;default:false;NOT NULL"`
+	BalanceReserve   mtypes.Int `gorm:"column:balance_reserve;type:varchar(256);default:0"`
+
+	SpendMaxValue     mtypes.Int `gorm:"column:spend_max_value;type:varchar(256);default:0"`
+	SpendMaxGasFee    mtypes.Int `gorm:"column:spend_max_gas_fee;type:varchar(256);default:0"`
+	SpendWindow       int64      `gorm:"column:spend_window;default:0;NOT NULL"` // 单位：秒
+	SpendWindowEpochs int64      `gorm:"column:spend_window_epochs;default:0;NOT NULL"`
+
+	BatchSend bool `gorm:"column:batch_send;default:false;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
+}
+
+func (s mysqlAddressConfig) TableName() string {
+	return "address_configs"
+}
+
+func (s mysqlAddressConfig) AddressConfig() (*types.AddressConfig, error) {
+	addr, err := address.NewFromString(s.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	toBig := func(i mtypes.Int) big.Int {
+		if i.Int == nil {
+			return big.Zero()
+		}
+		return big.NewFromGo(i.Int)
+	}
+
+	return &types.AddressConfig{
+		Addr:             addr,
+		SelectStrategy:   s.SelectStrategy,
+		AutoFillNonceGap: s.AutoFillNonceGap,
+		BalanceReserve:   toBig(s.BalanceReserve),
+		SpendBudget: types.SpendBudget{
+			MaxValue:     toBig(s.SpendMaxValue),
+			MaxGasFee:    toBig(s.SpendMaxGasFee),
+			Window:       time.Duration(s.SpendWindow) * time.Second,
+			WindowEpochs: abi.ChainEpoch(s.SpendWindowEpochs),
+		},
+		BatchSend: s.BatchSend,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
+}
+
+type mysqlAddressConfigRepo struct {
+	*gorm.DB
+}
+
+var _ repo.AddressConfigRepo = (*mysqlAddressConfigRepo)(nil)
+
+func newMysqlAddressConfigRepo(db *gorm.DB) *mysqlAddressConfigRepo {
+	return &mysqlAddressConfigRepo{DB: db}
+}
+
+func (s mysqlAddressConfigRepo) GetAddressConfig(ctx context.Context, addr address.Address) (*types.AddressConfig, error) {
+	var a mysqlAddressConfig
+	if err := s.DB.WithContext(ctx).Take(&a, "addr = ?", addr.String()).Error; err != nil {
+		return nil, err
+	}
+
+	return a.AddressConfig()
+}
+
+// upsert insert a record of addr or update the specified columns if it already exists
+func (s mysqlAddressConfigRepo) upsert(ctx context.Context, cfg *mysqlAddressConfig, columns ...string) error {
+	now := time.Now()
+	cfg.CreatedAt = now
+	cfg.UpdatedAt = now
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "addr"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+	}).Create(cfg).Error
+}
+
+func (s mysqlAddressConfigRepo) UpdateSelectStrategy(ctx context.Context, addr address.Address, strategy string) error {
+	return s.upsert(ctx, &mysqlAddressConfig{Addr: addr.String(), SelectStrategy: strategy}, "select_strategy")
+}
+
+func (s mysqlAddressConfigRepo) UpdateAutoFillNonceGap(ctx context.Context, addr address.Address, enable bool) error {
+	return s.upsert(ctx, &mysqlAddressConfig{Addr: addr.String(), AutoFillNonceGap: enable}, "auto_fill_nonce_gap")
+}
+
+func (s mysqlAddressConfigRepo) UpdateBalanceReserve(ctx context.Context, addr address.Address, reserve big.Int) error {
+	return s.upsert(ctx, &mysqlAddressConfig{Addr: addr.String(), BalanceReserve: mtypes.SafeFromGo(reserve.Int)}, "balance_reserve")
+}
+
+func (s mysqlAddressConfigRepo) UpdateSpendBudget(ctx context.Context, addr address.Address, budget *types.SpendBudget) error {
+	return s.upsert(ctx, &mysqlAddressConfig{
+		Addr:              addr.String(),
+		SpendMaxValue:     mtypes.SafeFromGo(budget.MaxValue.Int),
+		SpendMaxGasFee:    mtypes.SafeFromGo(budget.MaxGasFee.Int),
+		SpendWindow:       int64(budget.Window / time.Second),
+		SpendWindowEpochs: int64(budget.WindowEpochs),
+	}, "spend_max_value", "spend_max_gas_fee", "spend_window", "spend_window_epochs")
+}
+
+func (s mysqlAddressConfigRepo) UpdateBatchSend(ctx context.Context, addr address.Address, enable bool) error {
+	return s.upsert(ctx, &mysqlAddressConfig{Addr: addr.String(), BatchSend: enable}, "batch_send")
+}
@@ -0,0 +1,122 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestAddressConfig(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test get address config", wrapper(testGetAddressConfig, r, mock))
+	t.Run("mysql test update select strategy", wrapper(testUpdateSelectStrategy, r, mock))
+	t.Run("mysql test update auto fill nonce gap", wrapper(testUpdateAutoFillNonceGap, r, mock))
+	t.Run("mysql test update balance reserve", wrapper(testUpdateBalanceReserve, r, mock))
+	t.Run("mysql test update spend budget", wrapper(testUpdateSpendBudget, r, mock))
+	t.Run("mysql test update batch send", wrapper(testUpdateBatchSend, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func testGetAddressConfig(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testutil.AddressProvider()(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `address_configs` WHERE addr = ? LIMIT 1")).
+		WithArgs(addr.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"addr", "select_strategy"}).AddRow(addr.String(), "fee"))
+
+	res, err := r.AddressConfigRepo().GetAddressConfig(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, addr, res.Addr)
+	assert.Equal(t, "fee", res.SelectStrategy)
+}
+
+func testUpdateSelectStrategy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testutil.AddressProvider()(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `address_configs` (`addr`,`select_strategy`,`auto_fill_nonce_gap`,`balance_reserve`,`spend_max_value`,`spend_max_gas_fee`,`spend_window`,`spend_window_epochs`,`batch_send`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `select_strategy`=VALUES(`select_strategy`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(addr.String(), "deadline", false, "0", "0", "0", 0, 0, false, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := r.AddressConfigRepo().UpdateSelectStrategy(ctx, addr, "deadline")
+	assert.NoError(t, err)
+}
+
+func testUpdateAutoFillNonceGap(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testutil.AddressProvider()(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `address_configs` (`addr`,`select_strategy`,`auto_fill_nonce_gap`,`balance_reserve`,`spend_max_value`,`spend_max_gas_fee`,`spend_window`,`spend_window_epochs`,`batch_send`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `auto_fill_nonce_gap`=VALUES(`auto_fill_nonce_gap`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(addr.String(), "", true, "0", "0", "0", 0, 0, false, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := r.AddressConfigRepo().UpdateAutoFillNonceGap(ctx, addr, true)
+	assert.NoError(t, err)
+}
+
+func testUpdateBalanceReserve(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testutil.AddressProvider()(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `address_configs` (`addr`,`select_strategy`,`auto_fill_nonce_gap`,`balance_reserve`,`spend_max_value`,`spend_max_gas_fee`,`spend_window`,`spend_window_epochs`,`batch_send`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `balance_reserve`=VALUES(`balance_reserve`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(addr.String(), "", false, "100", "0", "0", 0, 0, false, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := r.AddressConfigRepo().UpdateBalanceReserve(ctx, addr, big.NewInt(100))
+	assert.NoError(t, err)
+}
+
+func testUpdateSpendBudget(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testutil.AddressProvider()(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `address_configs` (`addr`,`select_strategy`,`auto_fill_nonce_gap`,`balance_reserve`,`spend_max_value`,`spend_max_gas_fee`,`spend_window`,`spend_window_epochs`,`batch_send`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `spend_max_value`=VALUES(`spend_max_value`),`spend_max_gas_fee`=VALUES(`spend_max_gas_fee`),`spend_window`=VALUES(`spend_window`),`spend_window_epochs`=VALUES(`spend_window_epochs`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(addr.String(), "", false, "0", "100", "10", int64(3600), int64(0), false, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := r.AddressConfigRepo().UpdateSpendBudget(ctx, addr, &types.SpendBudget{
+		MaxValue:  big.NewInt(100),
+		MaxGasFee: big.NewInt(10),
+		Window:    time.Hour,
+	})
+	assert.NoError(t, err)
+}
+
+func testUpdateBatchSend(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testutil.AddressProvider()(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `address_configs` (`addr`,`select_strategy`,`auto_fill_nonce_gap`,`balance_reserve`,`spend_max_value`,`spend_max_gas_fee`,`spend_window`,`spend_window_epochs`,`batch_send`,`created_at`,`updated_at`) VALUES (?,?,?,?,?,?,?,?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `batch_send`=VALUES(`batch_send`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(addr.String(), "", false, "0", "0", "0", 0, 0, true, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := r.AddressConfigRepo().UpdateBatchSend(ctx, addr, true)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,113 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlAddressShard struct {
+	Addr   string `gorm:"column:addr;type:varchar(256);primary_key"`
+	Holder string `gorm:"column:holder;type:varchar(256);NOT NULL"`
+	Token  uint64 `gorm:"column:token;type:bigint unsigned;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"` // 更新时间
+}
+
+func (s mysqlAddressShard) TableName() string {
+	return "address_shards"
+}
+
+func (s mysqlAddressShard) AddressShard() (*types.AddressShard, error) {
+	addr, err := address.NewFromString(s.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AddressShard{
+		Addr:      addr,
+		Holder:    s.Holder,
+		Token:     s.Token,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
+}
+
+var _ repo.AddressShardRepo = (*mysqlAddressShardRepo)(nil)
+
+type mysqlAddressShardRepo struct {
+	*gorm.DB
+}
+
+func newMysqlAddressShardRepo(db *gorm.DB) *mysqlAddressShardRepo {
+	return &mysqlAddressShardRepo{DB: db}
+}
+
+func (s *mysqlAddressShardRepo) ListAddressShard(ctx context.Context) ([]*types.AddressShard, error) {
+	var shards []*mysqlAddressShard
+	if err := s.DB.WithContext(ctx).Find(&shards).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.AddressShard, 0, len(shards))
+	for _, shard := range shards {
+		r, err := shard.AddressShard()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+func (s *mysqlAddressShardRepo) ClaimAddressShard(ctx context.Context, addr address.Address, holder string, from []string) (*types.AddressShard, error) {
+	now := time.Now()
+	res := s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&mysqlAddressShard{
+		Addr:      addr.String(),
+		Holder:    holder,
+		Token:     1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	if res.RowsAffected == 0 && len(from) > 0 {
+		err := s.DB.WithContext(ctx).Model((*mysqlAddressShard)(nil)).
+			Where("addr = ? AND holder IN ?", addr.String(), from).
+			UpdateColumns(map[string]interface{}{
+				"holder":     holder,
+				"token":      gorm.Expr("token + 1"),
+				"updated_at": now,
+			}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var shard mysqlAddressShard
+	if err := s.DB.WithContext(ctx).Take(&shard, "addr = ?", addr.String()).Error; err != nil {
+		return nil, err
+	}
+	return shard.AddressShard()
+}
+
+func (s *mysqlAddressShardRepo) LockAddressShard(ctx context.Context, addr address.Address) (*types.AddressShard, error) {
+	var shard mysqlAddressShard
+	if err := s.DB.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Take(&shard, "addr = ?", addr.String()).Error; err != nil {
+		return nil, err
+	}
+	return shard.AddressShard()
+}
+
+func (s *mysqlAddressShardRepo) ReleaseAddressShard(ctx context.Context, addr address.Address, holder string) error {
+	return s.DB.WithContext(ctx).Model((*mysqlAddressShard)(nil)).
+		Where("addr = ? AND holder = ?", addr.String(), holder).
+		UpdateColumns(map[string]interface{}{"holder": "", "updated_at": time.Now()}).Error
+}
@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+)
+
+func TestAddressShard(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test list address shard", wrapper(testListAddressShard, r, mock))
+	t.Run("mysql test claim address shard", wrapper(testClaimAddressShard, r, mock))
+	t.Run("mysql test lock address shard", wrapper(testLockAddressShard, r, mock))
+	t.Run("mysql test release address shard", wrapper(testReleaseAddressShard, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newAddressShard(addr address.Address, holder string, token uint64) *mysqlAddressShard {
+	now := time.Now()
+	return &mysqlAddressShard{
+		Addr:      addr.String(),
+		Holder:    holder,
+		Token:     token,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func testListAddressShard(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addrs := testhelper.RandAddresses(t, 2)
+	shards := []*mysqlAddressShard{newAddressShard(addrs[0], "a", 1), newAddressShard(addrs[1], "b", 2)}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `address_shards`")).
+		WillReturnRows(genSelectResult(shards))
+
+	res, err := r.AddressShardRepo().ListAddressShard(ctx)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	for idx, shard := range shards {
+		expect, err := shard.AddressShard()
+		require.NoError(t, err)
+		assert.Equal(t, expect, res[idx])
+	}
+}
+
+func testClaimAddressShard(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testhelper.RandAddresses(t, 1)[0]
+	insertSQL, _ := genInsertSQL(newAddressShard(addr, "a", 1))
+	insertSQL = regexp.QuoteMeta(insertSQL + " ON DUPLICATE KEY UPDATE `addr`=`addr`")
+	selectSQL := regexp.QuoteMeta("SELECT * FROM `address_shards` WHERE addr = ? LIMIT 1")
+
+	// create
+	shard := newAddressShard(addr, "a", 1)
+	mock.ExpectBegin()
+	mock.ExpectExec(insertSQL).
+		WithArgs(addr.String(), "a", 1, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(selectSQL).WithArgs(addr.String()).WillReturnRows(genSelectResult(shard))
+
+	res, err := r.AddressShardRepo().ClaimAddressShard(ctx, addr, "a", []string{""})
+	require.NoError(t, err)
+	expect, err := shard.AddressShard()
+	require.NoError(t, err)
+	assert.Equal(t, expect, res)
+
+	// take over
+	shard = newAddressShard(addr, "b", 2)
+	mock.ExpectBegin()
+	mock.ExpectExec(insertSQL).
+		WithArgs(addr.String(), "b", 1, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `address_shards` SET `holder`=?,`token`=token + 1,`updated_at`=? WHERE addr = ? AND holder IN (?,?)")).
+		WithArgs("b", anyTime{}, addr.String(), "", "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(selectSQL).WithArgs(addr.String()).WillReturnRows(genSelectResult(shard))
+
+	res, err = r.AddressShardRepo().ClaimAddressShard(ctx, addr, "b", []string{"", "a"})
+	require.NoError(t, err)
+	expect, err = shard.AddressShard()
+	require.NoError(t, err)
+	assert.Equal(t, expect, res)
+}
+
+func testLockAddressShard(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testhelper.RandAddresses(t, 1)[0]
+	shard := newAddressShard(addr, "a", 1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `address_shards` WHERE addr = ? LIMIT 1 FOR UPDATE")).
+		WithArgs(addr.String()).
+		WillReturnRows(genSelectResult(shard))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		res, err := txRepo.AddressShardRepo().LockAddressShard(ctx, addr)
+		if err != nil {
+			return err
+		}
+		expect, err := shard.AddressShard()
+		require.NoError(t, err)
+		assert.Equal(t, expect, res)
+		return nil
+	}))
+}
+
+func testReleaseAddressShard(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	addr := testhelper.RandAddresses(t, 1)[0]
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `address_shards` SET `holder`=?,`updated_at`=? WHERE addr = ? AND holder = ?")).
+		WithArgs("", anyTime{}, addr.String(), "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.AddressShardRepo().ReleaseAddressShard(ctx, addr, "a"))
+}
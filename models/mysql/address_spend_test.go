@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestAddressSpend(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save address spends", wrapper(testSaveAddressSpends, r, mock))
+	t.Run("mysql test list address spend", wrapper(testListAddressSpend, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newAddressSpend() *types.AddressSpend {
+	return types.NewAddressSpend(testhelper.NewSignedMessages(1)[0], 10)
+}
+
+func testSaveAddressSpends(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	spend := newAddressSpend()
+
+	insertSql, insertArgs := genInsertSQL(fromAddressSpend(spend))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `gas_fee`=VALUES(`gas_fee`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.AddressSpendRepo().SaveAddressSpends(ctx, []*types.AddressSpend{spend}))
+}
+
+func testListAddressSpend(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	spend := newAddressSpend()
+	since := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `address_spends` WHERE from_addr = ? AND created_at >= ?")).
+		WithArgs(spend.From.String(), since).
+		WillReturnRows(genSelectResult([]*mysqlAddressSpend{fromAddressSpend(spend)}))
+
+	res, err := r.AddressSpendRepo().ListAddressSpend(ctx, spend.From, since, 0)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, spend.MsgID, res[0].MsgID)
+	assert.Equal(t, spend.Value, res[0].Value)
+	assert.Equal(t, spend.GasFee, res[0].GasFee)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `address_spends` WHERE from_addr = ? AND height >= ?")).
+		WithArgs(spend.From.String(), int64(5)).
+		WillReturnRows(genSelectResult([]*mysqlAddressSpend{}))
+
+	res, err = r.AddressSpendRepo().ListAddressSpend(ctx, spend.From, time.Time{}, 5)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
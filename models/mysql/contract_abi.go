@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlContractABI struct {
+	Address     string `gorm:"column:address;type:varchar(256);primary_key"`
+	ABI         string `gorm:"column:abi;type:mediumtext;NOT NULL"`
+	Description string `gorm:"column:description;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"` // 更新时间
+}
+
+func (s mysqlContractABI) TableName() string {
+	return "contract_abis"
+}
+
+func fromContractABI(contractABI *types.ContractABI) *mysqlContractABI {
+	return &mysqlContractABI{
+		Address:     contractABI.Address.String(),
+		ABI:         contractABI.ABI,
+		Description: contractABI.Description,
+		CreatedAt:   contractABI.CreatedAt,
+		UpdatedAt:   contractABI.UpdatedAt,
+	}
+}
+
+func (s mysqlContractABI) ContractABI() (*types.ContractABI, error) {
+	addr, err := address.NewFromString(s.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ContractABI{
+		Address:     addr,
+		ABI:         s.ABI,
+		Description: s.Description,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}, nil
+}
+
+type mysqlContractABIRepo struct {
+	*gorm.DB
+}
+
+var _ repo.ContractABIRepo = (*mysqlContractABIRepo)(nil)
+
+func newMysqlContractABIRepo(db *gorm.DB) *mysqlContractABIRepo {
+	return &mysqlContractABIRepo{DB: db}
+}
+
+func (s *mysqlContractABIRepo) SaveContractABI(ctx context.Context, contractABI *types.ContractABI) error {
+	sc := fromContractABI(contractABI)
+	now := time.Now()
+	sc.CreatedAt = now
+	sc.UpdatedAt = now
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"abi", "description", "updated_at"}),
+	}).Create(sc).Error
+}
+
+func (s *mysqlContractABIRepo) GetContractABI(ctx context.Context, addr address.Address) (*types.ContractABI, error) {
+	var sc mysqlContractABI
+	if err := s.DB.WithContext(ctx).Take(&sc, "address = ?", addr.String()).Error; err != nil {
+		return nil, err
+	}
+	return sc.ContractABI()
+}
+
+func (s *mysqlContractABIRepo) ListContractABI(ctx context.Context) ([]*types.ContractABI, error) {
+	var scs []*mysqlContractABI
+	if err := s.DB.WithContext(ctx).Order("created_at").Find(&scs).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.ContractABI, 0, len(scs))
+	for _, sc := range scs {
+		contractABI, err := sc.ContractABI()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, contractABI)
+	}
+	return result, nil
+}
+
+func (s *mysqlContractABIRepo) DeleteContractABI(ctx context.Context, addr address.Address) error {
+	res := s.DB.WithContext(ctx).Delete(&mysqlContractABI{}, "address = ?", addr.String())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestContractABI(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save contract abi", wrapper(testSaveContractABI, r, mock))
+	t.Run("mysql test get contract abi", wrapper(testGetContractABI, r, mock))
+	t.Run("mysql test list contract abi", wrapper(testListContractABI, r, mock))
+	t.Run("mysql test delete contract abi", wrapper(testDeleteContractABI, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newContractABI(t *testing.T) *types.ContractABI {
+	addr, err := address.NewIDAddress(1024)
+	assert.NoError(t, err)
+	return &types.ContractABI{
+		Address:     addr,
+		ABI:         `[{"type":"function","name":"get","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`,
+		Description: "counter",
+	}
+}
+
+func testSaveContractABI(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	contractABI := newContractABI(t)
+
+	insertSql, insertArgs := genInsertSQL(fromContractABI(contractABI))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `abi`=VALUES(`abi`),`description`=VALUES(`description`)," +
+		"`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.ContractABIRepo().SaveContractABI(ctx, contractABI))
+}
+
+func testGetContractABI(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	contractABI := newContractABI(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contract_abis` WHERE address = ? LIMIT 1")).
+		WithArgs(contractABI.Address.String()).
+		WillReturnRows(genSelectResult([]*mysqlContractABI{fromContractABI(contractABI)}))
+
+	res, err := r.ContractABIRepo().GetContractABI(ctx, contractABI.Address)
+	assert.NoError(t, err)
+	assert.Equal(t, contractABI.Address, res.Address)
+	assert.Equal(t, contractABI.ABI, res.ABI)
+	assert.Equal(t, contractABI.Description, res.Description)
+}
+
+func testListContractABI(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	contractABI := newContractABI(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `contract_abis` ORDER BY created_at")).
+		WillReturnRows(genSelectResult([]*mysqlContractABI{fromContractABI(contractABI)}))
+
+	res, err := r.ContractABIRepo().ListContractABI(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, contractABI.Address, res[0].Address)
+}
+
+func testDeleteContractABI(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	contractABI := newContractABI(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `contract_abis` WHERE address = ?")).
+		WithArgs(contractABI.Address.String()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.ContractABIRepo().DeleteContractABI(ctx, contractABI.Address))
+}
@@ -8,6 +8,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/migration"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 )
 
@@ -27,6 +28,10 @@ func (d Repo) AddressRepo() repo.AddressRepo {
 	return newMysqlAddressRepo(d.DB)
 }
 
+func (d Repo) AddressConfigRepo() repo.AddressConfigRepo {
+	return newMysqlAddressConfigRepo(d.DB)
+}
+
 func (d Repo) SharedParamsRepo() repo.SharedParamsRepo {
 	return newMysqlSharedParamsRepo(d.DB)
 }
@@ -35,8 +40,60 @@ func (d Repo) NodeRepo() repo.NodeRepo {
 	return newMysqlNodeRepo(d.DB)
 }
 
-func (d Repo) AutoMigrate() error {
-	return d.GetDb().AutoMigrate(mysqlActorCfg{}, mysqlMessage{}, mysqlAddress{}, mysqlSharedParams{}, mysqlNode{})
+func (d Repo) NotificationRepo() repo.NotificationRepo {
+	return newMysqlNotificationRepo(d.DB)
+}
+
+func (d Repo) FeeBumpRepo() repo.FeeBumpRepo {
+	return newMysqlFeeBumpRepo(d.DB)
+}
+
+func (d Repo) MessageRevisionRepo() repo.MessageRevisionRepo {
+	return newMysqlMessageRevisionRepo(d.DB)
+}
+
+func (d Repo) MessageFeeRepo() repo.MessageFeeRepo {
+	return newMysqlMessageFeeRepo(d.DB)
+}
+
+func (d Repo) AddressSpendRepo() repo.AddressSpendRepo {
+	return newMysqlAddressSpendRepo(d.DB)
+}
+
+func (d Repo) SignerPolicyRepo() repo.SignerPolicyRepo {
+	return newMysqlSignerPolicyRepo(d.DB)
+}
+
+func (d Repo) MsigProposalRepo() repo.MsigProposalRepo {
+	return newMysqlMsigProposalRepo(d.DB)
+}
+
+func (d Repo) MessageBatchRepo() repo.MessageBatchRepo {
+	return newMysqlMessageBatchRepo(d.DB)
+}
+
+func (d Repo) ContractABIRepo() repo.ContractABIRepo {
+	return newMysqlContractABIRepo(d.DB)
+}
+
+func (d Repo) ExitCodeRuleRepo() repo.ExitCodeRuleRepo {
+	return newMysqlExitCodeRuleRepo(d.DB)
+}
+
+func (d Repo) TipsetRepo() repo.TipsetRepo {
+	return newMysqlTipsetRepo(d.DB)
+}
+
+func (d Repo) LeaseRepo() repo.LeaseRepo {
+	return newMysqlLeaseRepo(d.DB)
+}
+
+func (d Repo) AddressShardRepo() repo.AddressShardRepo {
+	return newMysqlAddressShardRepo(d.DB)
+}
+
+func (d Repo) SchemaMigrator() *migration.Migrator {
+	return migration.NewMigrator(d.DB, migrations)
 }
 
 func (d Repo) GetDb() *gorm.DB {
@@ -74,6 +131,58 @@ func (t *TxMysqlRepo) NodeRepo() repo.NodeRepo {
 	return newMysqlNodeRepo(t.DB)
 }
 
+func (t *TxMysqlRepo) NotificationRepo() repo.NotificationRepo {
+	return newMysqlNotificationRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) FeeBumpRepo() repo.FeeBumpRepo {
+	return newMysqlFeeBumpRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) MessageRevisionRepo() repo.MessageRevisionRepo {
+	return newMysqlMessageRevisionRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) MessageFeeRepo() repo.MessageFeeRepo {
+	return newMysqlMessageFeeRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) AddressSpendRepo() repo.AddressSpendRepo {
+	return newMysqlAddressSpendRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) SignerPolicyRepo() repo.SignerPolicyRepo {
+	return newMysqlSignerPolicyRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) MsigProposalRepo() repo.MsigProposalRepo {
+	return newMysqlMsigProposalRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) MessageBatchRepo() repo.MessageBatchRepo {
+	return newMysqlMessageBatchRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) ContractABIRepo() repo.ContractABIRepo {
+	return newMysqlContractABIRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) ExitCodeRuleRepo() repo.ExitCodeRuleRepo {
+	return newMysqlExitCodeRuleRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) TipsetRepo() repo.TipsetRepo {
+	return newMysqlTipsetRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) LeaseRepo() repo.LeaseRepo {
+	return newMysqlLeaseRepo(t.DB)
+}
+
+func (t *TxMysqlRepo) AddressShardRepo() repo.AddressShardRepo {
+	return newMysqlAddressShardRepo(t.DB)
+}
+
 func (t *TxMysqlRepo) MessageRepo() repo.MessageRepo {
 	return newMysqlMessageRepo(t.DB)
 }
@@ -82,6 +191,10 @@ func (t *TxMysqlRepo) AddressRepo() repo.AddressRepo {
 	return newMysqlAddressRepo(t.DB)
 }
 
+func (t *TxMysqlRepo) AddressConfigRepo() repo.AddressConfigRepo {
+	return newMysqlAddressConfigRepo(t.DB)
+}
+
 func OpenMysql(cfg *config.MySqlConfig) (repo.Repo, error) {
 	db, err := gorm.Open(mysql.Open(cfg.ConnectionString), &gorm.Config{
 		// Logger: logger.Default.LogMode(logger.Info), // 日志配置
@@ -0,0 +1,179 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlExitCodeRule struct {
+	ID       string `gorm:"column:id;type:varchar(256);primary_key"`
+	ExitCode int64  `gorm:"column:exit_code;type:bigint;index;NOT NULL"`
+	// Code is empty for any actor
+	Code    mtypes.DBCid     `gorm:"column:code;type:varchar(256)"`
+	Methods mtypes.DBMethods `gorm:"column:methods;type:text"`
+
+	Action        string  `gorm:"column:action;type:varchar(32);NOT NULL"`
+	GasMultiplier float64 `gorm:"column:gas_multiplier;type:double;default:0"`
+	MaxRetries    int     `gorm:"column:max_retries;type:int;default:0"`
+	Description   string  `gorm:"column:description;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
+}
+
+func (s mysqlExitCodeRule) TableName() string {
+	return "exit_code_rules"
+}
+
+func fromExitCodeRule(rule *types.ExitCodeRule) *mysqlExitCodeRule {
+	return &mysqlExitCodeRule{
+		ID:            rule.ID,
+		ExitCode:      int64(rule.ExitCode),
+		Code:          mtypes.NewDBCid(rule.Code),
+		Methods:       mtypes.DBMethods(rule.Methods),
+		Action:        string(rule.Action),
+		GasMultiplier: rule.GasMultiplier,
+		MaxRetries:    rule.MaxRetries,
+		Description:   rule.Description,
+		CreatedAt:     rule.CreatedAt,
+		UpdatedAt:     rule.UpdatedAt,
+	}
+}
+
+func (s mysqlExitCodeRule) ExitCodeRule() *types.ExitCodeRule {
+	return &types.ExitCodeRule{
+		ID:            s.ID,
+		ExitCode:      exitcode.ExitCode(s.ExitCode),
+		Code:          cid.Cid(s.Code),
+		Methods:       s.Methods,
+		Action:        types.ExitCodeAction(s.Action),
+		GasMultiplier: s.GasMultiplier,
+		MaxRetries:    s.MaxRetries,
+		Description:   s.Description,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+type mysqlMessageRetry struct {
+	MsgID      string `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	RetryMsgID string `gorm:"column:retry_msg_id;type:varchar(256);uniqueIndex;NOT NULL"`
+	RuleID     string `gorm:"column:rule_id;type:varchar(256)"`
+	Attempt    int    `gorm:"column:attempt;type:int;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"` // 创建时间
+}
+
+func (s mysqlMessageRetry) TableName() string {
+	return "message_retries"
+}
+
+func fromMessageRetry(retry *types.MessageRetry) *mysqlMessageRetry {
+	return &mysqlMessageRetry{
+		MsgID:      retry.MsgID,
+		RetryMsgID: retry.RetryMsgID,
+		RuleID:     retry.RuleID,
+		Attempt:    retry.Attempt,
+		CreatedAt:  retry.CreatedAt,
+	}
+}
+
+func (s mysqlMessageRetry) MessageRetry() *types.MessageRetry {
+	return &types.MessageRetry{
+		MsgID:      s.MsgID,
+		RetryMsgID: s.RetryMsgID,
+		RuleID:     s.RuleID,
+		Attempt:    s.Attempt,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+type mysqlExitCodeRuleRepo struct {
+	*gorm.DB
+}
+
+var _ repo.ExitCodeRuleRepo = (*mysqlExitCodeRuleRepo)(nil)
+
+func newMysqlExitCodeRuleRepo(db *gorm.DB) *mysqlExitCodeRuleRepo {
+	return &mysqlExitCodeRuleRepo{DB: db}
+}
+
+func (s *mysqlExitCodeRuleRepo) SaveExitCodeRule(ctx context.Context, rule *types.ExitCodeRule) error {
+	r := fromExitCodeRule(rule)
+	now := time.Now()
+	r.CreatedAt = now
+	r.UpdatedAt = now
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"exit_code", "code", "methods", "action", "gas_multiplier",
+			"max_retries", "description", "updated_at"}),
+	}).Create(r).Error
+}
+
+func (s *mysqlExitCodeRuleRepo) GetExitCodeRule(ctx context.Context, id string) (*types.ExitCodeRule, error) {
+	var r mysqlExitCodeRule
+	if err := s.DB.WithContext(ctx).Take(&r, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return r.ExitCodeRule(), nil
+}
+
+func (s *mysqlExitCodeRuleRepo) ListExitCodeRule(ctx context.Context) ([]*types.ExitCodeRule, error) {
+	var rs []*mysqlExitCodeRule
+	if err := s.DB.WithContext(ctx).Order("created_at").Find(&rs).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.ExitCodeRule, 0, len(rs))
+	for _, r := range rs {
+		result = append(result, r.ExitCodeRule())
+	}
+	return result, nil
+}
+
+func (s *mysqlExitCodeRuleRepo) DeleteExitCodeRule(ctx context.Context, id string) error {
+	res := s.DB.WithContext(ctx).Delete(&mysqlExitCodeRule{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *mysqlExitCodeRuleRepo) CreateMessageRetry(ctx context.Context, retry *types.MessageRetry) error {
+	r := fromMessageRetry(retry)
+	r.CreatedAt = time.Now()
+	return s.DB.WithContext(ctx).Create(r).Error
+}
+
+func (s *mysqlExitCodeRuleRepo) GetMessageRetry(ctx context.Context, msgID string) (*types.MessageRetry, error) {
+	var r mysqlMessageRetry
+	if err := s.DB.WithContext(ctx).Take(&r, "msg_id = ?", msgID).Error; err != nil {
+		return nil, err
+	}
+	return r.MessageRetry(), nil
+}
+
+func (s *mysqlExitCodeRuleRepo) GetMessageRetryByRetryMsgID(ctx context.Context, retryMsgID string) (*types.MessageRetry, error) {
+	var r mysqlMessageRetry
+	if err := s.DB.WithContext(ctx).Take(&r, "retry_msg_id = ?", retryMsgID).Error; err != nil {
+		return nil, err
+	}
+	return r.MessageRetry(), nil
+}
+
+func (s *mysqlExitCodeRuleRepo) DeleteMessageRetry(ctx context.Context, msgID string) error {
+	return s.DB.WithContext(ctx).Delete(&mysqlMessageRetry{}, "msg_id = ?", msgID).Error
+}
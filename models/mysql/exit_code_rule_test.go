@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestExitCodeRule(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save exit code rule", wrapper(testSaveExitCodeRule, r, mock))
+	t.Run("mysql test get exit code rule", wrapper(testGetExitCodeRule, r, mock))
+	t.Run("mysql test list exit code rule", wrapper(testListExitCodeRule, r, mock))
+	t.Run("mysql test delete exit code rule", wrapper(testDeleteExitCodeRule, r, mock))
+	t.Run("mysql test get message retry", wrapper(testGetMessageRetry, r, mock))
+	t.Run("mysql test delete message retry", wrapper(testDeleteMessageRetry, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newExitCodeRule(t *testing.T) *types.ExitCodeRule {
+	return &types.ExitCodeRule{
+		ID:            "r1",
+		ExitCode:      exitcode.SysErrOutOfGas,
+		Code:          testutil.CidProvider(32)(t),
+		Methods:       []abi.MethodNum{5},
+		Action:        types.ExitCodeRetry,
+		GasMultiplier: 1.5,
+		MaxRetries:    3,
+	}
+}
+
+func testSaveExitCodeRule(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	rule := newExitCodeRule(t)
+
+	insertSql, insertArgs := genInsertSQL(fromExitCodeRule(rule))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `exit_code`=VALUES(`exit_code`),`code`=VALUES(`code`)," +
+		"`methods`=VALUES(`methods`),`action`=VALUES(`action`),`gas_multiplier`=VALUES(`gas_multiplier`)," +
+		"`max_retries`=VALUES(`max_retries`),`description`=VALUES(`description`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.ExitCodeRuleRepo().SaveExitCodeRule(ctx, rule))
+}
+
+func testGetExitCodeRule(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	rule := newExitCodeRule(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `exit_code_rules` WHERE id = ? LIMIT 1")).
+		WithArgs(rule.ID).
+		WillReturnRows(genSelectResult([]*mysqlExitCodeRule{fromExitCodeRule(rule)}))
+
+	res, err := r.ExitCodeRuleRepo().GetExitCodeRule(ctx, rule.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, rule.ExitCode, res.ExitCode)
+	assert.Equal(t, rule.Code, res.Code)
+	assert.Equal(t, rule.Methods, res.Methods)
+	assert.Equal(t, rule.GasMultiplier, res.GasMultiplier)
+}
+
+func testListExitCodeRule(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	rule := newExitCodeRule(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `exit_code_rules` ORDER BY created_at")).
+		WillReturnRows(genSelectResult([]*mysqlExitCodeRule{fromExitCodeRule(rule)}))
+
+	res, err := r.ExitCodeRuleRepo().ListExitCodeRule(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, rule.ID, res[0].ID)
+}
+
+func testDeleteExitCodeRule(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `exit_code_rules` WHERE id = ?")).
+		WithArgs("r1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.ExitCodeRuleRepo().DeleteExitCodeRule(ctx, "r1"))
+}
+
+func testGetMessageRetry(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	retry := &types.MessageRetry{MsgID: "m1", RetryMsgID: "m2", RuleID: "r1", Attempt: 1}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_retries` WHERE msg_id = ? LIMIT 1")).
+		WithArgs(retry.MsgID).
+		WillReturnRows(genSelectResult([]*mysqlMessageRetry{fromMessageRetry(retry)}))
+
+	res, err := r.ExitCodeRuleRepo().GetMessageRetry(ctx, retry.MsgID)
+	assert.NoError(t, err)
+	assert.Equal(t, retry.RetryMsgID, res.RetryMsgID)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_retries` WHERE retry_msg_id = ? LIMIT 1")).
+		WithArgs(retry.RetryMsgID).
+		WillReturnRows(genSelectResult([]*mysqlMessageRetry{fromMessageRetry(retry)}))
+
+	res, err = r.ExitCodeRuleRepo().GetMessageRetryByRetryMsgID(ctx, retry.RetryMsgID)
+	assert.NoError(t, err)
+	assert.Equal(t, retry.MsgID, res.MsgID)
+	assert.Equal(t, 1, res.Attempt)
+}
+
+func testDeleteMessageRetry(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `message_retries` WHERE msg_id = ?")).
+		WithArgs("m1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.ExitCodeRuleRepo().DeleteMessageRetry(ctx, "m1"))
+}
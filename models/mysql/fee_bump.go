@@ -0,0 +1,236 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	shared "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlFeeBumpPolicy struct {
+	ID string `gorm:"column:id;type:varchar(256);primary_key"`
+	// Addr is empty for an actor policy, Code is empty for an address policy
+	Addr   string       `gorm:"column:addr;type:varchar(256);index:idx_addr_code_method,unique;NOT NULL"`
+	Code   mtypes.DBCid `gorm:"column:code;type:varchar(256);index:idx_addr_code_method,unique;NOT NULL"`
+	Method uint64       `gorm:"column:method;type:bigint unsigned;index:idx_addr_code_method,unique;NOT NULL"`
+
+	MaxBumps  int        `gorm:"column:max_bumps;type:int;NOT NULL"`
+	BumpRatio uint64     `gorm:"column:bump_ratio;type:int;NOT NULL"`
+	MaxFeeCap mtypes.Int `gorm:"column:max_fee_cap;type:varchar(256);default:0"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
+}
+
+func (s mysqlFeeBumpPolicy) TableName() string {
+	return "fee_bump_policies"
+}
+
+func fromFeeBumpPolicy(policy *types.FeeBumpPolicy) *mysqlFeeBumpPolicy {
+	s := &mysqlFeeBumpPolicy{
+		ID:        policy.ID,
+		Code:      mtypes.NewDBCid(policy.Code),
+		Method:    uint64(policy.Method),
+		MaxBumps:  policy.MaxBumps,
+		BumpRatio: uint64(policy.BumpRatio),
+		MaxFeeCap: mtypes.SafeFromGo(policy.MaxFeeCap.Int),
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+	if policy.Addr != address.Undef {
+		s.Addr = policy.Addr.String()
+	}
+	return s
+}
+
+func (s mysqlFeeBumpPolicy) FeeBumpPolicy() (*types.FeeBumpPolicy, error) {
+	addr := address.Undef
+	if len(s.Addr) != 0 {
+		var err error
+		if addr, err = address.NewFromString(s.Addr); err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.FeeBumpPolicy{
+		ID:        s.ID,
+		Addr:      addr,
+		Code:      s.Code.Cid(),
+		Method:    abi.MethodNum(s.Method),
+		MaxBumps:  s.MaxBumps,
+		BumpRatio: shared.Percent(s.BumpRatio),
+		MaxFeeCap: big.NewFromGo(s.MaxFeeCap.Int),
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
+}
+
+type mysqlFeeBumpRecord struct {
+	ID       string `gorm:"column:id;type:varchar(256);primary_key"`
+	MsgID    string `gorm:"column:msg_id;type:varchar(256);index;NOT NULL"`
+	From     string `gorm:"column:from_addr;type:varchar(256);NOT NULL"`
+	Nonce    uint64 `gorm:"column:nonce;type:bigint unsigned;NOT NULL"`
+	Bump     int    `gorm:"column:bump;type:int;NOT NULL"`
+	PolicyID string `gorm:"column:policy_id;type:varchar(256)"`
+
+	OldSignedCid  mtypes.DBCid `gorm:"column:old_signed_cid;type:varchar(256)"`
+	OldGasLimit   int64        `gorm:"column:old_gas_limit;type:bigint;NOT NULL"`
+	OldGasFeeCap  mtypes.Int   `gorm:"column:old_gas_fee_cap;type:varchar(256);NOT NULL"`
+	OldGasPremium mtypes.Int   `gorm:"column:old_gas_premium;type:varchar(256);NOT NULL"`
+
+	NewSignedCid  mtypes.DBCid `gorm:"column:new_signed_cid;type:varchar(256)"`
+	NewGasLimit   int64        `gorm:"column:new_gas_limit;type:bigint;NOT NULL"`
+	NewGasFeeCap  mtypes.Int   `gorm:"column:new_gas_fee_cap;type:varchar(256);NOT NULL"`
+	NewGasPremium mtypes.Int   `gorm:"column:new_gas_premium;type:varchar(256);NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+}
+
+func (s mysqlFeeBumpRecord) TableName() string {
+	return "fee_bump_records"
+}
+
+func fromFeeBumpRecord(record *types.FeeBumpRecord) *mysqlFeeBumpRecord {
+	return &mysqlFeeBumpRecord{
+		ID:            record.ID,
+		MsgID:         record.MsgID,
+		From:          record.From.String(),
+		Nonce:         record.Nonce,
+		Bump:          record.Bump,
+		PolicyID:      record.PolicyID,
+		OldSignedCid:  mtypes.NewDBCid(record.OldSignedCid),
+		OldGasLimit:   record.OldGasLimit,
+		OldGasFeeCap:  mtypes.SafeFromGo(record.OldGasFeeCap.Int),
+		OldGasPremium: mtypes.SafeFromGo(record.OldGasPremium.Int),
+		NewSignedCid:  mtypes.NewDBCid(record.NewSignedCid),
+		NewGasLimit:   record.NewGasLimit,
+		NewGasFeeCap:  mtypes.SafeFromGo(record.NewGasFeeCap.Int),
+		NewGasPremium: mtypes.SafeFromGo(record.NewGasPremium.Int),
+		CreatedAt:     record.CreatedAt,
+	}
+}
+
+func (s mysqlFeeBumpRecord) FeeBumpRecord() (*types.FeeBumpRecord, error) {
+	from, err := address.NewFromString(s.From)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.FeeBumpRecord{
+		ID:            s.ID,
+		MsgID:         s.MsgID,
+		From:          from,
+		Nonce:         s.Nonce,
+		Bump:          s.Bump,
+		PolicyID:      s.PolicyID,
+		OldSignedCid:  s.OldSignedCid.Cid(),
+		OldGasLimit:   s.OldGasLimit,
+		OldGasFeeCap:  big.NewFromGo(s.OldGasFeeCap.Int),
+		OldGasPremium: big.NewFromGo(s.OldGasPremium.Int),
+		NewSignedCid:  s.NewSignedCid.Cid(),
+		NewGasLimit:   s.NewGasLimit,
+		NewGasFeeCap:  big.NewFromGo(s.NewGasFeeCap.Int),
+		NewGasPremium: big.NewFromGo(s.NewGasPremium.Int),
+		CreatedAt:     s.CreatedAt,
+	}, nil
+}
+
+type mysqlFeeBumpRepo struct {
+	*gorm.DB
+}
+
+var _ repo.FeeBumpRepo = (*mysqlFeeBumpRepo)(nil)
+
+func newMysqlFeeBumpRepo(db *gorm.DB) *mysqlFeeBumpRepo {
+	return &mysqlFeeBumpRepo{DB: db}
+}
+
+func (s *mysqlFeeBumpRepo) SaveFeeBumpPolicy(ctx context.Context, policy *types.FeeBumpPolicy) error {
+	sp := fromFeeBumpPolicy(policy)
+	now := time.Now()
+	sp.CreatedAt = now
+	sp.UpdatedAt = now
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "addr"}, {Name: "code"}, {Name: "method"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_bumps", "bump_ratio", "max_fee_cap", "updated_at"}),
+	}).Create(sp).Error
+}
+
+func (s *mysqlFeeBumpRepo) GetFeeBumpPolicyByAddress(ctx context.Context, addr address.Address) (*types.FeeBumpPolicy, error) {
+	var sp mysqlFeeBumpPolicy
+	if err := s.DB.WithContext(ctx).Take(&sp, "addr = ?", addr.String()).Error; err != nil {
+		return nil, err
+	}
+	return sp.FeeBumpPolicy()
+}
+
+func (s *mysqlFeeBumpRepo) GetFeeBumpPolicyByActor(ctx context.Context, code cid.Cid, method abi.MethodNum) (*types.FeeBumpPolicy, error) {
+	var sp mysqlFeeBumpPolicy
+	if err := s.DB.WithContext(ctx).Take(&sp, "addr = ? and code = ? and method = ?", "", mtypes.NewDBCid(code),
+		uint64(method)).Error; err != nil {
+		return nil, err
+	}
+	return sp.FeeBumpPolicy()
+}
+
+func (s *mysqlFeeBumpRepo) ListFeeBumpPolicy(ctx context.Context) ([]*types.FeeBumpPolicy, error) {
+	var sps []*mysqlFeeBumpPolicy
+	if err := s.DB.WithContext(ctx).Order("created_at").Find(&sps).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.FeeBumpPolicy, 0, len(sps))
+	for _, sp := range sps {
+		policy, err := sp.FeeBumpPolicy()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, policy)
+	}
+	return result, nil
+}
+
+func (s *mysqlFeeBumpRepo) DeleteFeeBumpPolicy(ctx context.Context, id string) error {
+	res := s.DB.WithContext(ctx).Delete(&mysqlFeeBumpPolicy{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *mysqlFeeBumpRepo) CreateFeeBumpRecord(ctx context.Context, record *types.FeeBumpRecord) error {
+	sr := fromFeeBumpRecord(record)
+	sr.CreatedAt = time.Now()
+	return s.DB.WithContext(ctx).Create(sr).Error
+}
+
+func (s *mysqlFeeBumpRepo) ListFeeBumpRecord(ctx context.Context, msgID string) ([]*types.FeeBumpRecord, error) {
+	var srs []*mysqlFeeBumpRecord
+	if err := s.DB.WithContext(ctx).Order("bump").Find(&srs, "msg_id = ?", msgID).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.FeeBumpRecord, 0, len(srs))
+	for _, sr := range srs {
+		record, err := sr.FeeBumpRecord()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
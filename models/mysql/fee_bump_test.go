@@ -0,0 +1,155 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestFeeBump(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save fee bump policy", wrapper(testSaveFeeBumpPolicy, r, mock))
+	t.Run("mysql test get fee bump policy", wrapper(testGetFeeBumpPolicy, r, mock))
+	t.Run("mysql test delete fee bump policy", wrapper(testDeleteFeeBumpPolicy, r, mock))
+	t.Run("mysql test create fee bump record", wrapper(testCreateFeeBumpRecord, r, mock))
+	t.Run("mysql test list fee bump record", wrapper(testListFeeBumpRecord, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newFeeBumpPolicy(t *testing.T) *types.FeeBumpPolicy {
+	return &types.FeeBumpPolicy{
+		ID:        venusTypes.NewUUID().String(),
+		Addr:      testhelper.RandAddresses(t, 1)[0],
+		Code:      cid.Undef,
+		MaxBumps:  3,
+		BumpRatio: 125,
+		MaxFeeCap: big.NewInt(1000),
+	}
+}
+
+func newFeeBumpRecord(t *testing.T) *types.FeeBumpRecord {
+	oldMsg := testhelper.NewUnsignedMessage()
+	newMsg := testhelper.NewUnsignedMessage()
+	return &types.FeeBumpRecord{
+		ID:            venusTypes.NewUUID().String(),
+		MsgID:         venusTypes.NewUUID().String(),
+		From:          testhelper.RandAddresses(t, 1)[0],
+		Nonce:         1,
+		Bump:          1,
+		PolicyID:      venusTypes.NewUUID().String(),
+		OldSignedCid:  oldMsg.Cid(),
+		OldGasLimit:   100,
+		OldGasFeeCap:  big.NewInt(100),
+		OldGasPremium: big.NewInt(10),
+		NewSignedCid:  newMsg.Cid(),
+		NewGasLimit:   100,
+		NewGasFeeCap:  big.NewInt(200),
+		NewGasPremium: big.NewInt(20),
+	}
+}
+
+func testSaveFeeBumpPolicy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	policy := newFeeBumpPolicy(t)
+
+	insertSql, insertArgs := genInsertSQL(fromFeeBumpPolicy(policy))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `max_bumps`=VALUES(`max_bumps`),`bump_ratio`=VALUES(`bump_ratio`),`max_fee_cap`=VALUES(`max_fee_cap`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.FeeBumpRepo().SaveFeeBumpPolicy(ctx, policy))
+}
+
+func testGetFeeBumpPolicy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	policy := newFeeBumpPolicy(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `fee_bump_policies` WHERE addr = ? LIMIT 1")).
+		WithArgs(policy.Addr.String()).
+		WillReturnRows(genSelectResult([]*mysqlFeeBumpPolicy{fromFeeBumpPolicy(policy)}))
+
+	res, err := r.FeeBumpRepo().GetFeeBumpPolicyByAddress(ctx, policy.Addr)
+	assert.NoError(t, err)
+	assert.Equal(t, policy.ID, res.ID)
+	assert.Equal(t, policy.MaxFeeCap, res.MaxFeeCap)
+
+	actorPolicy := newFeeBumpPolicy(t)
+	actorPolicy.Addr = address.Undef
+	codeMsg := testhelper.NewUnsignedMessage()
+	actorPolicy.Code = codeMsg.Cid()
+	actorPolicy.Method = 2
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `fee_bump_policies` WHERE addr = ? and code = ? and method = ? LIMIT 1")).
+		WithArgs("", mtypes.NewDBCid(actorPolicy.Code), uint64(actorPolicy.Method)).
+		WillReturnRows(genSelectResult([]*mysqlFeeBumpPolicy{fromFeeBumpPolicy(actorPolicy)}))
+
+	res, err = r.FeeBumpRepo().GetFeeBumpPolicyByActor(ctx, actorPolicy.Code, actorPolicy.Method)
+	assert.NoError(t, err)
+	assert.Equal(t, actorPolicy.ID, res.ID)
+	assert.False(t, res.IsAddressPolicy())
+}
+
+func testDeleteFeeBumpPolicy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	id := venusTypes.NewUUID().String()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `fee_bump_policies` WHERE id = ?")).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, r.FeeBumpRepo().DeleteFeeBumpPolicy(ctx, id))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `fee_bump_policies` WHERE id = ?")).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	assert.ErrorIs(t, r.FeeBumpRepo().DeleteFeeBumpPolicy(ctx, id), repo.ErrRecordNotFound)
+}
+
+func testCreateFeeBumpRecord(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	record := newFeeBumpRecord(t)
+
+	insertSql, insertArgs := genInsertSQL(fromFeeBumpRecord(record))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql)).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.FeeBumpRepo().CreateFeeBumpRecord(ctx, record))
+}
+
+func testListFeeBumpRecord(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	record := newFeeBumpRecord(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `fee_bump_records` WHERE msg_id = ? ORDER BY bump")).
+		WithArgs(record.MsgID).
+		WillReturnRows(genSelectResult([]*mysqlFeeBumpRecord{fromFeeBumpRecord(record)}))
+
+	res, err := r.FeeBumpRepo().ListFeeBumpRecord(ctx, record.MsgID)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, record.ID, res[0].ID)
+	assert.Equal(t, record.NewSignedCid, res[0].NewSignedCid)
+	assert.Equal(t, record.NewGasPremium, res[0].NewGasPremium)
+}
@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlLease struct {
+	Name     string    `gorm:"column:name;type:varchar(256);primary_key"`
+	Holder   string    `gorm:"column:holder;type:varchar(256);NOT NULL"`
+	Token    uint64    `gorm:"column:token;type:bigint unsigned;NOT NULL"`
+	ExpireAt time.Time `gorm:"column:expire_at;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"` // 更新时间
+}
+
+func (s mysqlLease) TableName() string {
+	return "leases"
+}
+
+func (s mysqlLease) Lease() *types.Lease {
+	return &types.Lease{
+		Name:      s.Name,
+		Holder:    s.Holder,
+		Token:     s.Token,
+		ExpireAt:  s.ExpireAt,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+var _ repo.LeaseRepo = (*mysqlLeaseRepo)(nil)
+
+type mysqlLeaseRepo struct {
+	*gorm.DB
+}
+
+func newMysqlLeaseRepo(db *gorm.DB) *mysqlLeaseRepo {
+	return &mysqlLeaseRepo{DB: db}
+}
+
+func (s *mysqlLeaseRepo) AcquireLease(ctx context.Context, name, holder string, duration time.Duration) (*types.Lease, error) {
+	now := time.Now()
+	expireAt := now.Add(duration)
+	res := s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&mysqlLease{
+		Name:      name,
+		Holder:    holder,
+		Token:     1,
+		ExpireAt:  expireAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		// renew, the token is not changed
+		res = s.DB.WithContext(ctx).Model((*mysqlLease)(nil)).
+			Where("name = ? AND holder = ?", name, holder).
+			UpdateColumns(map[string]interface{}{"expire_at": expireAt, "updated_at": now})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+	}
+	if res.RowsAffected == 0 {
+		// take over the expired lease of another holder
+		res = s.DB.WithContext(ctx).Model((*mysqlLease)(nil)).
+			Where("name = ? AND expire_at < ?", name, now).
+			UpdateColumns(map[string]interface{}{
+				"holder":     holder,
+				"token":      gorm.Expr("token + 1"),
+				"expire_at":  expireAt,
+				"updated_at": now,
+			})
+		if res.Error != nil {
+			return nil, res.Error
+		}
+	}
+
+	return s.GetLease(ctx, name)
+}
+
+func (s *mysqlLeaseRepo) GetLease(ctx context.Context, name string) (*types.Lease, error) {
+	var lease mysqlLease
+	if err := s.DB.WithContext(ctx).Take(&lease, "name = ?", name).Error; err != nil {
+		return nil, err
+	}
+	return lease.Lease(), nil
+}
+
+func (s *mysqlLeaseRepo) ListLease(ctx context.Context, prefix string) ([]*types.Lease, error) {
+	var leases []*mysqlLease
+	if err := s.DB.WithContext(ctx).Find(&leases, "name LIKE ?", prefix+"%").Error; err != nil {
+		return nil, err
+	}
+	result := make([]*types.Lease, 0, len(leases))
+	for _, lease := range leases {
+		result = append(result, lease.Lease())
+	}
+	return result, nil
+}
+
+func (s *mysqlLeaseRepo) LockLease(ctx context.Context, name string) (*types.Lease, error) {
+	var lease mysqlLease
+	if err := s.DB.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Take(&lease, "name = ?", name).Error; err != nil {
+		return nil, err
+	}
+	return lease.Lease(), nil
+}
+
+func (s *mysqlLeaseRepo) ReleaseLease(ctx context.Context, name, holder string) error {
+	now := time.Now()
+	return s.DB.WithContext(ctx).Model((*mysqlLease)(nil)).
+		Where("name = ? AND holder = ?", name, holder).
+		UpdateColumns(map[string]interface{}{"expire_at": now, "updated_at": now}).Error
+}
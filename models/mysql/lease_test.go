@@ -0,0 +1,147 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+)
+
+func TestLease(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test acquire lease", wrapper(testAcquireLease, r, mock))
+	t.Run("mysql test list lease", wrapper(testListLease, r, mock))
+	t.Run("mysql test lock lease", wrapper(testLockLease, r, mock))
+	t.Run("mysql test release lease", wrapper(testReleaseLease, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newLease(name, holder string, token uint64) *mysqlLease {
+	now := time.Now()
+	return &mysqlLease{
+		Name:      name,
+		Holder:    holder,
+		Token:     token,
+		ExpireAt:  now.Add(time.Minute),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func testAcquireLease(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	insertSQL, _ := genInsertSQL(newLease("leader", "a", 1))
+	insertSQL = regexp.QuoteMeta(insertSQL + " ON DUPLICATE KEY UPDATE `name`=`name`")
+	renewSQL := regexp.QuoteMeta("UPDATE `leases` SET `expire_at`=?,`updated_at`=? WHERE name = ? AND holder = ?")
+	takeOverSQL := regexp.QuoteMeta("UPDATE `leases` SET `expire_at`=?,`holder`=?,`token`=token + 1,`updated_at`=? WHERE name = ? AND expire_at < ?")
+	selectSQL := regexp.QuoteMeta("SELECT * FROM `leases` WHERE name = ? LIMIT 1")
+
+	// create
+	lease := newLease("leader", "a", 1)
+	mock.ExpectBegin()
+	mock.ExpectExec(insertSQL).
+		WithArgs("leader", "a", 1, anyTime{}, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(selectSQL).WithArgs("leader").WillReturnRows(genSelectResult(lease))
+
+	res, err := r.LeaseRepo().AcquireLease(ctx, "leader", "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, lease.Lease(), res)
+
+	// renew
+	mock.ExpectBegin()
+	mock.ExpectExec(insertSQL).
+		WithArgs("leader", "a", 1, anyTime{}, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(renewSQL).
+		WithArgs(anyTime{}, anyTime{}, "leader", "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(selectSQL).WithArgs("leader").WillReturnRows(genSelectResult(lease))
+
+	res, err = r.LeaseRepo().AcquireLease(ctx, "leader", "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, lease.Lease(), res)
+
+	// take over
+	lease = newLease("leader", "b", 2)
+	mock.ExpectBegin()
+	mock.ExpectExec(insertSQL).
+		WithArgs("leader", "b", 1, anyTime{}, anyTime{}, anyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(renewSQL).
+		WithArgs(anyTime{}, anyTime{}, "leader", "b").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(takeOverSQL).
+		WithArgs(anyTime{}, "b", anyTime{}, "leader", anyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(selectSQL).WithArgs("leader").WillReturnRows(genSelectResult(lease))
+
+	res, err = r.LeaseRepo().AcquireLease(ctx, "leader", "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, lease.Lease(), res)
+}
+
+func testListLease(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	leases := []*mysqlLease{newLease("member/a", "a", 1), newLease("member/b", "b", 1)}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `leases` WHERE name LIKE ?")).
+		WithArgs("member/%").
+		WillReturnRows(genSelectResult(leases))
+
+	res, err := r.LeaseRepo().ListLease(ctx, "member/")
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	for idx, lease := range leases {
+		assert.Equal(t, lease.Lease(), res[idx])
+	}
+}
+
+func testLockLease(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	lease := newLease("leader", "a", 1)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `leases` WHERE name = ? LIMIT 1 FOR UPDATE")).
+		WithArgs("leader").
+		WillReturnRows(genSelectResult(lease))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		res, err := txRepo.LeaseRepo().LockLease(ctx, "leader")
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, lease.Lease(), res)
+		return nil
+	}))
+}
+
+func testReleaseLease(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `leases` SET `expire_at`=?,`updated_at`=? WHERE name = ? AND holder = ?")).
+		WithArgs(anyTime{}, anyTime{}, "leader", "a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.LeaseRepo().ReleaseLease(ctx, "leader", "a"))
+}
@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlMessageBatchItem struct {
+	MsgID    string `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	ParentID string `gorm:"column:parent_id;type:varchar(256);index;NOT NULL"`
+	Index    int    `gorm:"column:idx;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+}
+
+func (s mysqlMessageBatchItem) TableName() string {
+	return "message_batches"
+}
+
+func fromMessageBatchItem(item *types.MessageBatchItem) *mysqlMessageBatchItem {
+	return &mysqlMessageBatchItem{
+		MsgID:     item.MsgID,
+		ParentID:  item.ParentID,
+		Index:     item.Index,
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+func (s mysqlMessageBatchItem) MessageBatchItem() *types.MessageBatchItem {
+	return &types.MessageBatchItem{
+		MsgID:     s.MsgID,
+		ParentID:  s.ParentID,
+		Index:     s.Index,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+type mysqlMessageBatchRepo struct {
+	*gorm.DB
+}
+
+var _ repo.MessageBatchRepo = (*mysqlMessageBatchRepo)(nil)
+
+func newMysqlMessageBatchRepo(db *gorm.DB) *mysqlMessageBatchRepo {
+	return &mysqlMessageBatchRepo{DB: db}
+}
+
+func (s *mysqlMessageBatchRepo) SaveMessageBatch(ctx context.Context, items []*types.MessageBatchItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	now := time.Now()
+	records := make([]*mysqlMessageBatchItem, 0, len(items))
+	for _, item := range items {
+		record := fromMessageBatchItem(item)
+		record.CreatedAt = now
+		records = append(records, record)
+	}
+
+	return s.DB.WithContext(ctx).Create(records).Error
+}
+
+func (s *mysqlMessageBatchRepo) GetMessageBatchItem(ctx context.Context, msgID string) (*types.MessageBatchItem, error) {
+	var item mysqlMessageBatchItem
+	if err := s.DB.WithContext(ctx).Take(&item, "msg_id = ?", msgID).Error; err != nil {
+		return nil, err
+	}
+	return item.MessageBatchItem(), nil
+}
+
+func (s *mysqlMessageBatchRepo) ListMessageBatch(ctx context.Context, parentID string) ([]*types.MessageBatchItem, error) {
+	var records []*mysqlMessageBatchItem
+	if err := s.DB.WithContext(ctx).Where("parent_id = ?", parentID).Order("idx").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]*types.MessageBatchItem, 0, len(records))
+	for _, record := range records {
+		items = append(items, record.MessageBatchItem())
+	}
+	return items, nil
+}
+
+func (s *mysqlMessageBatchRepo) DeleteMessageBatch(ctx context.Context, parentID string) error {
+	return s.DB.WithContext(ctx).Delete(&mysqlMessageBatchItem{}, "parent_id = ?", parentID).Error
+}
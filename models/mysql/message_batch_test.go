@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageBatch(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save message batch", wrapper(testSaveMessageBatch, r, mock))
+	t.Run("mysql test get message batch item", wrapper(testGetMessageBatchItem, r, mock))
+	t.Run("mysql test list message batch", wrapper(testListMessageBatch, r, mock))
+	t.Run("mysql test delete message batch", wrapper(testDeleteMessageBatch, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func testSaveMessageBatch(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	item := &types.MessageBatchItem{MsgID: "m1", ParentID: "p1", Index: 0}
+
+	insertSql, insertArgs := genInsertSQL(fromMessageBatchItem(item))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql)).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageBatchRepo().SaveMessageBatch(ctx, []*types.MessageBatchItem{item}))
+}
+
+func testGetMessageBatchItem(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	item := &types.MessageBatchItem{MsgID: "m1", ParentID: "p1", Index: 1}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_batches` WHERE msg_id = ? LIMIT 1")).
+		WithArgs(item.MsgID).
+		WillReturnRows(genSelectResult([]*mysqlMessageBatchItem{fromMessageBatchItem(item)}))
+
+	res, err := r.MessageBatchRepo().GetMessageBatchItem(ctx, item.MsgID)
+	assert.NoError(t, err)
+	assert.Equal(t, item.ParentID, res.ParentID)
+	assert.Equal(t, item.Index, res.Index)
+}
+
+func testListMessageBatch(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	items := []*mysqlMessageBatchItem{
+		fromMessageBatchItem(&types.MessageBatchItem{MsgID: "m1", ParentID: "p1", Index: 0}),
+		fromMessageBatchItem(&types.MessageBatchItem{MsgID: "m2", ParentID: "p1", Index: 1}),
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_batches` WHERE parent_id = ? ORDER BY idx")).
+		WithArgs("p1").
+		WillReturnRows(genSelectResult(items))
+
+	res, err := r.MessageBatchRepo().ListMessageBatch(ctx, "p1")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.Equal(t, "m2", res[1].MsgID)
+}
+
+func testDeleteMessageBatch(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `message_batches` WHERE parent_id = ?")).
+		WithArgs("p1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageBatchRepo().DeleteMessageBatch(context.Background(), "p1"))
+}
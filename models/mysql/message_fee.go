@@ -0,0 +1,159 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlMessageFee struct {
+	MsgID       string    `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	From        string    `gorm:"column:from_addr;type:varchar(256);index:idx_fee_from_on_chain_time;NOT NULL"`
+	To          string    `gorm:"column:to_addr;type:varchar(256);NOT NULL"`
+	Method      uint64    `gorm:"column:method;type:bigint unsigned;NOT NULL"`
+	Height      int64     `gorm:"column:height;type:bigint;NOT NULL"`
+	OnChainTime time.Time `gorm:"column:on_chain_time;index:idx_fee_from_on_chain_time;index;NOT NULL"`
+
+	GasUsed    int64      `gorm:"column:gas_used;type:bigint;NOT NULL"`
+	GasLimit   int64      `gorm:"column:gas_limit;type:bigint;NOT NULL"`
+	GasFeeCap  mtypes.Int `gorm:"column:gas_fee_cap;type:varchar(256);NOT NULL"`
+	GasPremium mtypes.Int `gorm:"column:gas_premium;type:varchar(256);NOT NULL"`
+	BaseFee    mtypes.Int `gorm:"column:base_fee;type:varchar(256);NOT NULL"`
+
+	BaseFeeBurn        mtypes.Int `gorm:"column:base_fee_burn;type:varchar(256);NOT NULL"`
+	OverEstimationBurn mtypes.Int `gorm:"column:over_estimation_burn;type:varchar(256);NOT NULL"`
+	MinerTip           mtypes.Int `gorm:"column:miner_tip;type:varchar(256);NOT NULL"`
+	MinerPenalty       mtypes.Int `gorm:"column:miner_penalty;type:varchar(256);NOT NULL"`
+	TotalCost          mtypes.Int `gorm:"column:total_cost;type:varchar(256);NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"` // 创建时间
+}
+
+func (s mysqlMessageFee) TableName() string {
+	return "message_fees"
+}
+
+func fromMessageFee(fee *types.MessageFee) *mysqlMessageFee {
+	return &mysqlMessageFee{
+		MsgID:              fee.MsgID,
+		From:               fee.From.String(),
+		To:                 fee.To.String(),
+		Method:             uint64(fee.Method),
+		Height:             fee.Height,
+		OnChainTime:        fee.OnChainTime,
+		GasUsed:            fee.GasUsed,
+		GasLimit:           fee.GasLimit,
+		GasFeeCap:          mtypes.SafeFromGo(fee.GasFeeCap.Int),
+		GasPremium:         mtypes.SafeFromGo(fee.GasPremium.Int),
+		BaseFee:            mtypes.SafeFromGo(fee.BaseFee.Int),
+		BaseFeeBurn:        mtypes.SafeFromGo(fee.BaseFeeBurn.Int),
+		OverEstimationBurn: mtypes.SafeFromGo(fee.OverEstimationBurn.Int),
+		MinerTip:           mtypes.SafeFromGo(fee.MinerTip.Int),
+		MinerPenalty:       mtypes.SafeFromGo(fee.MinerPenalty.Int),
+		TotalCost:          mtypes.SafeFromGo(fee.TotalCost.Int),
+		CreatedAt:          fee.CreatedAt,
+	}
+}
+
+func (s mysqlMessageFee) MessageFee() (*types.MessageFee, error) {
+	from, err := address.NewFromString(s.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := address.NewFromString(s.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MessageFee{
+		MsgID:              s.MsgID,
+		From:               from,
+		To:                 to,
+		Method:             abi.MethodNum(s.Method),
+		Height:             s.Height,
+		OnChainTime:        s.OnChainTime,
+		GasUsed:            s.GasUsed,
+		GasLimit:           s.GasLimit,
+		GasFeeCap:          big.NewFromGo(s.GasFeeCap.Int),
+		GasPremium:         big.NewFromGo(s.GasPremium.Int),
+		BaseFee:            big.NewFromGo(s.BaseFee.Int),
+		BaseFeeBurn:        big.NewFromGo(s.BaseFeeBurn.Int),
+		OverEstimationBurn: big.NewFromGo(s.OverEstimationBurn.Int),
+		MinerTip:           big.NewFromGo(s.MinerTip.Int),
+		MinerPenalty:       big.NewFromGo(s.MinerPenalty.Int),
+		TotalCost:          big.NewFromGo(s.TotalCost.Int),
+		CreatedAt:          s.CreatedAt,
+	}, nil
+}
+
+type mysqlMessageFeeRepo struct {
+	*gorm.DB
+}
+
+var _ repo.MessageFeeRepo = (*mysqlMessageFeeRepo)(nil)
+
+func newMysqlMessageFeeRepo(db *gorm.DB) *mysqlMessageFeeRepo {
+	return &mysqlMessageFeeRepo{DB: db}
+}
+
+func (s *mysqlMessageFeeRepo) SaveMessageFee(ctx context.Context, fee *types.MessageFee) error {
+	sf := fromMessageFee(fee)
+	sf.CreatedAt = time.Now()
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "msg_id"}},
+		UpdateAll: true,
+	}).Create(sf).Error
+}
+
+func (s *mysqlMessageFeeRepo) GetMessageFee(ctx context.Context, msgID string) (*types.MessageFee, error) {
+	var sf mysqlMessageFee
+	if err := s.DB.WithContext(ctx).Take(&sf, "msg_id = ?", msgID).Error; err != nil {
+		return nil, err
+	}
+	return sf.MessageFee()
+}
+
+func (s *mysqlMessageFeeRepo) DeleteMessageFee(ctx context.Context, msgID string) error {
+	return s.DB.WithContext(ctx).Delete(&mysqlMessageFee{}, "msg_id = ?", msgID).Error
+}
+
+func (s *mysqlMessageFeeRepo) ListMessageFee(ctx context.Context, addrs []address.Address, since, until time.Time) ([]*types.MessageFee, error) {
+	query := s.DB.WithContext(ctx)
+	if len(addrs) > 0 {
+		strAddrs := make([]string, 0, len(addrs))
+		for _, addr := range addrs {
+			strAddrs = append(strAddrs, addr.String())
+		}
+		query = query.Where("from_addr in ?", strAddrs)
+	}
+	if !since.IsZero() {
+		query = query.Where("on_chain_time >= ?", since)
+	}
+	if !until.IsZero() {
+		query = query.Where("on_chain_time < ?", until)
+	}
+
+	var sfs []*mysqlMessageFee
+	if err := query.Order("on_chain_time").Find(&sfs).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.MessageFee, 0, len(sfs))
+	for _, sf := range sfs {
+		fee, err := sf.MessageFee()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, fee)
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageFee(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save message fee", wrapper(testSaveMessageFee, r, mock))
+	t.Run("mysql test get message fee", wrapper(testGetMessageFee, r, mock))
+	t.Run("mysql test delete message fee", wrapper(testDeleteMessageFee, r, mock))
+	t.Run("mysql test list message fee", wrapper(testListMessageFee, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newMessageFee() *types.MessageFee {
+	msg := testhelper.NewSignedMessages(1)[0]
+	return &types.MessageFee{
+		MsgID:              msg.ID,
+		From:               msg.From,
+		To:                 msg.To,
+		Method:             msg.Method,
+		Height:             10,
+		OnChainTime:        time.Now(),
+		GasUsed:            100,
+		GasLimit:           msg.GasLimit,
+		GasFeeCap:          msg.GasFeeCap,
+		GasPremium:         msg.GasPremium,
+		BaseFee:            big.NewInt(100),
+		BaseFeeBurn:        big.NewInt(10000),
+		OverEstimationBurn: big.NewInt(200),
+		MinerTip:           big.NewInt(300),
+		MinerPenalty:       big.Zero(),
+		TotalCost:          big.NewInt(10500),
+	}
+}
+
+func testSaveMessageFee(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	fee := newMessageFee()
+
+	insertSql, insertArgs := genInsertSQL(fromMessageFee(fee))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `from_addr`=VALUES(`from_addr`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageFeeRepo().SaveMessageFee(ctx, fee))
+}
+
+func testGetMessageFee(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	fee := newMessageFee()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_fees` WHERE msg_id = ? LIMIT 1")).
+		WithArgs(fee.MsgID).
+		WillReturnRows(genSelectResult([]*mysqlMessageFee{fromMessageFee(fee)}))
+
+	res, err := r.MessageFeeRepo().GetMessageFee(ctx, fee.MsgID)
+	assert.NoError(t, err)
+	assert.Equal(t, fee.MsgID, res.MsgID)
+	assert.Equal(t, fee.To, res.To)
+	assert.Equal(t, fee.TotalCost, res.TotalCost)
+}
+
+func testDeleteMessageFee(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	fee := newMessageFee()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `message_fees` WHERE msg_id = ?")).
+		WithArgs(fee.MsgID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageFeeRepo().DeleteMessageFee(ctx, fee.MsgID))
+}
+
+func testListMessageFee(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	fee := newMessageFee()
+	since := time.Now().Add(-time.Hour)
+	until := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_fees` WHERE from_addr in (?) AND on_chain_time >= ? AND on_chain_time < ? ORDER BY on_chain_time")).
+		WithArgs(fee.From.String(), since, until).
+		WillReturnRows(genSelectResult([]*mysqlMessageFee{fromMessageFee(fee)}))
+
+	res, err := r.MessageFeeRepo().ListMessageFee(ctx, []address.Address{fee.From}, since, until)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, fee.MsgID, res[0].MsgID)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_fees` ORDER BY on_chain_time")).
+		WillReturnRows(genSelectResult([]*mysqlMessageFee{}))
+
+	res, err = r.MessageFeeRepo().ListMessageFee(ctx, nil, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
@@ -0,0 +1,129 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlMessageRevision struct {
+	ID    string `gorm:"column:id;type:varchar(256);primary_key"`
+	MsgID string `gorm:"column:msg_id;type:varchar(256);index:idx_msg_id_created_at;NOT NULL"`
+	Nonce uint64 `gorm:"column:nonce;type:bigint unsigned;NOT NULL"`
+
+	UnsignedCid mtypes.DBCid       `gorm:"column:unsigned_cid;type:varchar(256)"`
+	SignedCid   mtypes.DBCid       `gorm:"column:signed_cid;type:varchar(256);index:idx_revision_signed_cid"`
+	Signature   *repo.SqlSignature `gorm:"column:signed_data;type:blob;"`
+	EthHash     mtypes.DBEthHash   `gorm:"column:eth_hash;type:varchar(256);index:idx_revision_eth_hash"`
+
+	GasLimit   int64      `gorm:"column:gas_limit;type:bigint;NOT NULL"`
+	GasFeeCap  mtypes.Int `gorm:"column:gas_fee_cap;type:varchar(256);NOT NULL"`
+	GasPremium mtypes.Int `gorm:"column:gas_premium;type:varchar(256);NOT NULL"`
+
+	Reason   string `gorm:"column:reason;type:varchar(64);NOT NULL"`
+	Operator string `gorm:"column:operator;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index:idx_msg_id_created_at;NOT NULL"` // 创建时间
+}
+
+func (s mysqlMessageRevision) TableName() string {
+	return "message_revisions"
+}
+
+func fromMessageRevision(rev *types.MessageRevision) *mysqlMessageRevision {
+	return &mysqlMessageRevision{
+		ID:          rev.ID,
+		MsgID:       rev.MsgID,
+		Nonce:       rev.Nonce,
+		UnsignedCid: mtypes.NewDBCid(rev.UnsignedCid),
+		SignedCid:   mtypes.NewDBCid(rev.SignedCid),
+		Signature:   (*repo.SqlSignature)(rev.Signature),
+		EthHash:     mtypes.NewDBEthHash(rev.EthHash),
+		GasLimit:    rev.GasLimit,
+		GasFeeCap:   mtypes.SafeFromGo(rev.GasFeeCap.Int),
+		GasPremium:  mtypes.SafeFromGo(rev.GasPremium.Int),
+		Reason:      string(rev.Reason),
+		Operator:    rev.Operator,
+		CreatedAt:   rev.CreatedAt,
+	}
+}
+
+func (s mysqlMessageRevision) MessageRevision() *types.MessageRevision {
+	return &types.MessageRevision{
+		ID:          s.ID,
+		MsgID:       s.MsgID,
+		Nonce:       s.Nonce,
+		UnsignedCid: s.UnsignedCid.Cid(),
+		SignedCid:   s.SignedCid.Cid(),
+		Signature:   (*crypto.Signature)(s.Signature),
+		EthHash:     s.EthHash.EthHash(),
+		GasLimit:    s.GasLimit,
+		GasFeeCap:   big.NewFromGo(s.GasFeeCap.Int),
+		GasPremium:  big.NewFromGo(s.GasPremium.Int),
+		Reason:      types.RevisionReason(s.Reason),
+		Operator:    s.Operator,
+		CreatedAt:   s.CreatedAt,
+	}
+}
+
+type mysqlMessageRevisionRepo struct {
+	*gorm.DB
+}
+
+var _ repo.MessageRevisionRepo = (*mysqlMessageRevisionRepo)(nil)
+
+func newMysqlMessageRevisionRepo(db *gorm.DB) *mysqlMessageRevisionRepo {
+	return &mysqlMessageRevisionRepo{DB: db}
+}
+
+func (s *mysqlMessageRevisionRepo) SaveMessageRevisions(ctx context.Context, revisions []*types.MessageRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	now := time.Now()
+	srs := make([]*mysqlMessageRevision, 0, len(revisions))
+	for _, rev := range revisions {
+		sr := fromMessageRevision(rev)
+		sr.CreatedAt = now
+		srs = append(srs, sr)
+	}
+	return s.DB.WithContext(ctx).Create(srs).Error
+}
+
+func (s *mysqlMessageRevisionRepo) ListMessageRevisions(ctx context.Context, msgID string) ([]*types.MessageRevision, error) {
+	var srs []*mysqlMessageRevision
+	if err := s.DB.WithContext(ctx).Order("created_at").Find(&srs, "msg_id = ?", msgID).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.MessageRevision, 0, len(srs))
+	for _, sr := range srs {
+		result = append(result, sr.MessageRevision())
+	}
+	return result, nil
+}
+
+func (s *mysqlMessageRevisionRepo) GetMessageRevisionBySignedCid(ctx context.Context, signedCid cid.Cid) (*types.MessageRevision, error) {
+	var sr mysqlMessageRevision
+	if err := s.DB.WithContext(ctx).Order("created_at desc").Take(&sr, "signed_cid = ?", mtypes.NewDBCid(signedCid)).Error; err != nil {
+		return nil, err
+	}
+	return sr.MessageRevision(), nil
+}
+
+func (s *mysqlMessageRevisionRepo) GetMessageRevisionByEthHash(ctx context.Context, hash venusTypes.EthHash) (*types.MessageRevision, error) {
+	var sr mysqlMessageRevision
+	if err := s.DB.WithContext(ctx).Order("created_at desc").Take(&sr, "eth_hash = ?", mtypes.NewDBEthHash(hash)).Error; err != nil {
+		return nil, err
+	}
+	return sr.MessageRevision(), nil
+}
@@ -0,0 +1,90 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageRevision(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save message revisions", wrapper(testSaveMessageRevisions, r, mock))
+	t.Run("mysql test list message revisions", wrapper(testListMessageRevisions, r, mock))
+	t.Run("mysql test get message revision by signed cid", wrapper(testGetMessageRevisionBySignedCid, r, mock))
+	t.Run("mysql test get message revision by eth hash", wrapper(testGetMessageRevisionByEthHash, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newMessageRevision() *types.MessageRevision {
+	return types.NewMessageRevision(testhelper.NewSignedMessages(1)[0], types.RevisionReplace, "alice")
+}
+
+func testSaveMessageRevisions(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	rev := newMessageRevision()
+
+	insertSql, insertArgs := genInsertSQL(fromMessageRevision(rev))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql)).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageRevisionRepo().SaveMessageRevisions(ctx, []*types.MessageRevision{rev}))
+}
+
+func testListMessageRevisions(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	rev := newMessageRevision()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_revisions` WHERE msg_id = ? ORDER BY created_at")).
+		WithArgs(rev.MsgID).
+		WillReturnRows(genSelectResult([]*mysqlMessageRevision{fromMessageRevision(rev)}))
+
+	res, err := r.MessageRevisionRepo().ListMessageRevisions(ctx, rev.MsgID)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, rev.ID, res[0].ID)
+	assert.Equal(t, rev.SignedCid, res[0].SignedCid)
+	assert.Equal(t, rev.Reason, res[0].Reason)
+}
+
+func testGetMessageRevisionBySignedCid(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	rev := newMessageRevision()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_revisions` WHERE signed_cid = ? ORDER BY created_at desc LIMIT 1")).
+		WithArgs(mtypes.NewDBCid(rev.SignedCid)).
+		WillReturnRows(genSelectResult([]*mysqlMessageRevision{fromMessageRevision(rev)}))
+
+	res, err := r.MessageRevisionRepo().GetMessageRevisionBySignedCid(ctx, rev.SignedCid)
+	assert.NoError(t, err)
+	assert.Equal(t, rev.ID, res.ID)
+	assert.Equal(t, rev.MsgID, res.MsgID)
+}
+
+func testGetMessageRevisionByEthHash(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	rev := newMessageRevision()
+	rev.EthHash = venusTypes.EthHashFromTxBytes([]byte(rev.ID))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `message_revisions` WHERE eth_hash = ? ORDER BY created_at desc LIMIT 1")).
+		WithArgs(mtypes.NewDBEthHash(rev.EthHash)).
+		WillReturnRows(genSelectResult([]*mysqlMessageRevision{fromMessageRevision(rev)}))
+
+	res, err := r.MessageRevisionRepo().GetMessageRevisionByEthHash(ctx, rev.EthHash)
+	assert.NoError(t, err)
+	assert.Equal(t, rev.ID, res.ID)
+	assert.Equal(t, rev.EthHash, res.EthHash)
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 func TestListMessageByParams(t *testing.T) {
@@ -72,6 +73,7 @@ func TestMessage(t *testing.T) {
 
 	t.Run("mysql test expire message", wrapper(testExpireMessage, r, mock))
 	t.Run("mysql test create message", wrapper(testCreateMessage, r, mock))
+	t.Run("mysql test create message with options", wrapper(testCreateMessageWithOptions, r, mock))
 	t.Run("mysql test update message", wrapper(testUpdateMessage, r, mock))
 	t.Run("mysql test update message by state", wrapper(testUpdateMessageByState, r, mock))
 	t.Run("mysql test batch save message", wrapper(testBatchSaveMessage, r, mock))
@@ -91,17 +93,24 @@ func TestMessage(t *testing.T) {
 	t.Run("mysql test list message by from state", wrapper(testListMessageByFromState, r, mock))
 	t.Run("mysql test list message by address", wrapper(testListMessageByAddress, r, mock))
 	t.Run("mysql test list unchain message by address", wrapper(testListUnChainMessageByAddress, r, mock))
+	t.Run("mysql test list unchain message priority", wrapper(testListUnChainMessagePriority, r, mock))
+	t.Run("mysql test list unchain message expire at", wrapper(testListUnChainMessageExpireAt, r, mock))
+	t.Run("mysql test list expired unfill message", wrapper(testListExpiredUnFillMessage, r, mock))
 	t.Run("mysql test list failed message by address", wrapper(testListFilledMessageByAddress, r, mock))
 	t.Run("mysql test list chain message by height", wrapper(testListChainMessageByHeight, r, mock))
+	t.Run("mysql test list chain message below height", wrapper(testListChainMessageBelowHeight, r, mock))
 	t.Run("mysql test list unfilled message", wrapper(testListUnFilledMessage, r, mock))
 	t.Run("mysql test list signed message", wrapper(testListSignedMsgs, r, mock))
 	t.Run("mysql test list filled message below nonce", wrapper(testListFilledMessageBelowNonce, r, mock))
 
 	t.Run("mysql test update message info by cid", wrapper(testUpdateMessageInfoByCid, r, mock))
+	t.Run("mysql test update message info by id", wrapper(testUpdateMessageInfoByID, r, mock))
 	t.Run("mysql test update message state by cid", wrapper(testUpdateMessageStateByCid, r, mock))
 	t.Run("mysql test update message state by id", wrapper(testUpdateMessageStateByID, r, mock))
 	t.Run("mysql test mark bad message", wrapper(testMarkBadMessage, r, mock))
+	t.Run("mysql test finalize message", wrapper(testFinalizeMessage, r, mock))
 	t.Run("mysql test update return value", wrapper(testUpdateErrMsg, r, mock))
+	t.Run("mysql test update message priority", wrapper(testUpdateMessagePriority, r, mock))
 
 	assert.NoError(t, closeDB(mock, sqlDB))
 }
@@ -112,7 +121,7 @@ func testExpireMessage(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	for i, msg := range msgs {
 		mock.ExpectBegin()
 		mock.ExpectExec(regexp.QuoteMeta("UPDATE `messages` SET `state`=?,`updated_at`=? WHERE id = ?")).
-			WithArgs(types.FailedMsg, anyTime{}, msg.ID).WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+			WithArgs(sophonTypes.ExpiredMsg, anyTime{}, msg.ID).WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
 		mock.ExpectCommit()
 	}
 
@@ -133,6 +142,23 @@ func testCreateMessage(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	assert.NoError(t, r.MessageRepo().CreateMessage(msg))
 }
 
+func testCreateMessageWithOptions(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	msg := testhelper.NewMessage()
+	opts := &sophonTypes.MessageOptions{Priority: 10, ExpireAt: time.Now().Add(time.Hour)}
+
+	mysqlMsg := fromMessage(msg)
+	mysqlMsg.Priority = opts.Priority
+	mysqlMsg.ExpireAt = &opts.ExpireAt
+	insertSql, insertArgs := genInsertSQL(mysqlMsg)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql)).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageRepo().CreateMessageWithOptions(msg, opts))
+}
+
 func testBatchSaveMessage(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	msgs := testhelper.NewMessages(10)
 
@@ -436,7 +462,7 @@ func TestListBlockedMessage(t *testing.T) {
 	blocked := time.Second * 3
 
 	t.Run("no param", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE state IN (?,?) AND created_at < ? ORDER BY updated_at desc,created_at")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE state IN (?,?) AND created_at < ? ORDER BY created_at,updated_at desc")).
 			WithArgs(types.FillMsg, types.UnFillMsg, anyTime{}).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
 
@@ -446,7 +472,7 @@ func TestListBlockedMessage(t *testing.T) {
 	})
 
 	t.Run("param with address", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE from_addr IN (?) AND state IN (?,?) AND created_at < ? ORDER BY updated_at desc,created_at")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE from_addr IN (?) AND state IN (?,?) AND created_at < ? ORDER BY created_at,updated_at desc")).
 			WithArgs(from.String(), types.FillMsg, types.UnFillMsg, anyTime{}).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
 
@@ -456,7 +482,7 @@ func TestListBlockedMessage(t *testing.T) {
 	})
 
 	t.Run("param with addresses", func(t *testing.T) {
-		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE from_addr IN (?,?) AND state IN (?,?) AND created_at < ? ORDER BY updated_at desc,created_at")).
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE from_addr IN (?,?) AND state IN (?,?) AND created_at < ? ORDER BY created_at,updated_at desc")).
 			WithArgs(from.String(), from.String(), types.FillMsg, types.UnFillMsg, anyTime{}).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
 
@@ -472,12 +498,12 @@ func testListUnChainMessageByAddress(t *testing.T, r repo.Repo, mock sqlmock.Sql
 	from := testutil.AddressProvider()(t)
 	topN := 3
 
-	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM `messages` WHERE from_addr=? AND state=? ORDER BY created_at DESC LIMIT %d", topN))).
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf("SELECT * FROM `messages` WHERE from_addr=? AND state=? ORDER BY priority DESC, created_at ASC LIMIT %d", topN))).
 		WithArgs(from.String(), types.UnFillMsg).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
 	zero := 0
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE from_addr=? AND state=? ORDER BY created_at DESC")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE from_addr=? AND state=? ORDER BY priority DESC, created_at ASC")).
 		WithArgs(from.String(), types.UnFillMsg).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
 
@@ -488,6 +514,48 @@ func testListUnChainMessageByAddress(t *testing.T, r repo.Repo, mock sqlmock.Sql
 	assert.NoError(t, err)
 }
 
+func testListExpiredUnFillMessage(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ids := []string{"msg1", "msg2"}
+	from := testutil.AddressProvider()(t)
+	height := abi.ChainEpoch(100)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE from_addr=? AND state=? AND ((meta_expire_epoch>0 AND meta_expire_epoch<=?) OR expire_at<=?)")).
+		WithArgs(from.String(), types.UnFillMsg, height, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
+
+	res, err := r.MessageRepo().ListExpiredUnFillMessage(from, height, now)
+	assert.NoError(t, err)
+	checkMsgWithIDs(t, res, ids)
+}
+
+func testListUnChainMessagePriority(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ids := []string{"msg1", "msg2"}
+	from := testutil.AddressProvider()(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`priority` FROM `messages` WHERE from_addr=? AND state=? AND priority<>?")).
+		WithArgs(from.String(), types.UnFillMsg, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "priority"}).AddRow(ids[0], 1).AddRow(ids[1], -1))
+
+	res, err := r.MessageRepo().ListUnChainMessagePriority(from)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{ids[0]: 1, ids[1]: -1}, res)
+}
+
+func testListUnChainMessageExpireAt(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	from := testutil.AddressProvider()(t)
+	expireAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`,`expire_at` FROM `messages` WHERE from_addr=? AND state=? AND expire_at IS NOT NULL")).
+		WithArgs(from.String(), types.UnFillMsg).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "expire_at"}).AddRow("msg1", expireAt))
+
+	res, err := r.MessageRepo().ListUnChainMessageExpireAt(from)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.True(t, expireAt.Equal(res["msg1"]))
+}
+
 func testListFilledMessageByAddress(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	ids := []string{"msg1", "msg2"}
 	from := testutil.AddressProvider()(t)
@@ -514,6 +582,19 @@ func testListChainMessageByHeight(t *testing.T, r repo.Repo, mock sqlmock.Sqlmoc
 	checkMsgWithIDs(t, res, ids)
 }
 
+func testListChainMessageBelowHeight(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ids := []string{"msg1", "msg2"}
+	height := abi.ChainEpoch(100)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `messages` WHERE height<=? AND state=? ORDER BY height LIMIT 10")).
+		WithArgs(height, types.OnChainMsg).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
+
+	res, err := r.MessageRepo().ListChainMessageBelowHeight(height, 10)
+	assert.NoError(t, err)
+	checkMsgWithIDs(t, res, ids)
+}
+
 func testListUnFilledMessage(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	ids := []string{"msg1", "msg2"}
 	addr := testutil.AddressProvider()(t)
@@ -573,6 +654,26 @@ func testUpdateMessageInfoByCid(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock)
 	assert.NoError(t, r.MessageRepo().UpdateMessageInfoByCid(cid.String(), receipt, height, state, key))
 }
 
+func testUpdateMessageInfoByID(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	id := venusTypes.NewUUID().String()
+	receipt := &venusTypes.MessageReceipt{
+		ExitCode: 0,
+		Return:   []byte("return"),
+	}
+	height := abi.ChainEpoch(1000)
+	state := types.OnChainMsg
+	key := venusTypes.NewTipSetKey(testutil.CidProvider(32)(t))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `messages` SET `height`=?,`receipt_exit_code`=?,`receipt_gas_used`=?,"+
+		"`receipt_return_value`=?,`state`=?,`tipset_key`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(height, receipt.ExitCode, receipt.GasUsed, receipt.Return, state, key.String(), anyTime{}, id).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageRepo().UpdateMessageInfoByID(id, receipt, height, state, key))
+}
+
 func testUpdateMessageStateByCid(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	cid := testutil.CidProvider(32)(t)
 	state := types.OnChainMsg
@@ -612,6 +713,18 @@ func testMarkBadMessage(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	assert.NoError(t, r.MessageRepo().MarkBadMessage(id))
 }
 
+func testFinalizeMessage(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ids := []string{"msg1", "msg2"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `messages` SET `state`=?,`updated_at`=? WHERE id IN (?,?) AND state = ?")).
+		WithArgs(sophonTypes.FinalizedMsg, anyTime{}, ids[0], ids[1], types.OnChainMsg).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageRepo().FinalizeMessage(ids))
+}
+
 func testUpdateErrMsg(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	id := venusTypes.NewUUID().String()
 	errMsg := "val"
@@ -625,6 +738,28 @@ func testUpdateErrMsg(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
 	assert.NoError(t, r.MessageRepo().UpdateErrMsg(id, errMsg))
 }
 
+func testUpdateMessagePriority(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	id := venusTypes.NewUUID().String()
+	priority := 5
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `messages` SET `priority`=?,`updated_at`=? WHERE id = ? AND state = ?")).
+		WithArgs(priority, anyTime{}, id, types.UnFillMsg).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MessageRepo().UpdateMessagePriority(id, priority))
+
+	// message not exist or not unfill
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `messages` SET `priority`=?,`updated_at`=? WHERE id = ? AND state = ?")).
+		WithArgs(priority, anyTime{}, id, types.UnFillMsg).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	assert.ErrorIs(t, r.MessageRepo().UpdateMessagePriority(id, priority), repo.ErrRecordNotFound)
+}
+
 func checkMsgWithIDs(t *testing.T, msgs []*types.Message, ids []string) {
 	assert.Equal(t, len(msgs), len(ids))
 	for i, msg := range msgs {
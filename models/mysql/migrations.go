@@ -0,0 +1,599 @@
+package mysql
+
+import (
+	"time"
+
+	"github.com/ipfs-force-community/sophon-messager/models/migration"
+)
+
+// migrations the schema changes of mysql in order of version, a change is added as a new migration instead of
+// editing the applied ones.
+// The migrations create the tables and columns by the copies of model structs frozen at their version, so changing
+// the model structs never changes an applied migration. Version 1 creates the tables which existed before the
+// migrations were introduced, it skips the tables created by AutoMigrate of the older versions and can not be reverted.
+var migrations = []migration.Migration{
+	{
+		Version: 1,
+		Name:    "init",
+		Up: migration.CreateTables(mysqlMessageV1{}, mysqlActorCfgV1{}, mysqlAddressV1{}, mysqlSharedParamsV1{},
+			mysqlNodeV1{}),
+		Down: migration.Irreversible,
+	},
+	{
+		Version: 2,
+		Name:    "add_address_configs",
+		Up:      migration.CreateTables(mysqlAddressConfigV2{}),
+		Down:    migration.DropTables(mysqlAddressConfigV2{}),
+	},
+	{
+		Version: 3,
+		Name:    "add_messages_priority",
+		Up:      migration.AddColumn(mysqlMessagePriorityV3{}, "Priority"),
+		Down:    migration.DropColumn(mysqlMessagePriorityV3{}, "Priority"),
+	},
+	{
+		Version: 4,
+		Name:    "add_messages_expire_at",
+		Up:      migration.AddColumn(mysqlMessageExpireAtV4{}, "ExpireAt"),
+		Down:    migration.DropColumn(mysqlMessageExpireAtV4{}, "ExpireAt"),
+	},
+	{
+		Version: 5,
+		Name:    "add_notification_outbox",
+		Up:      migration.CreateTables(mysqlNotificationV5{}),
+		Down:    migration.DropTables(mysqlNotificationV5{}),
+	},
+	{
+		Version: 6,
+		Name:    "add_fee_bump",
+		Up:      migration.CreateTables(mysqlFeeBumpPolicyV6{}, mysqlFeeBumpRecordV6{}),
+		Down:    migration.DropTables(mysqlFeeBumpPolicyV6{}, mysqlFeeBumpRecordV6{}),
+	},
+	{
+		Version: 7,
+		Name:    "add_message_revisions",
+		Up:      migration.CreateTables(mysqlMessageRevisionV7{}),
+		Down:    migration.DropTables(mysqlMessageRevisionV7{}),
+	},
+	{
+		Version: 8,
+		Name:    "add_message_fees",
+		Up:      migration.CreateTables(mysqlMessageFeeV8{}),
+		Down:    migration.DropTables(mysqlMessageFeeV8{}),
+	},
+	{
+		Version: 9,
+		Name:    "add_address_spends",
+		Up:      migration.CreateTables(mysqlAddressSpendV9{}),
+		Down:    migration.DropTables(mysqlAddressSpendV9{}),
+	},
+	{
+		Version: 10,
+		Name:    "add_signer_policies",
+		Up:      migration.CreateTables(mysqlSignerPolicyV10{}),
+		Down:    migration.DropTables(mysqlSignerPolicyV10{}),
+	},
+	{
+		Version: 11,
+		Name:    "add_msig_proposals",
+		Up:      migration.CreateTables(mysqlMsigProposalV11{}),
+		Down:    migration.DropTables(mysqlMsigProposalV11{}),
+	},
+	{
+		Version: 12,
+		Name:    "add_message_batches",
+		Up:      migration.CreateTables(mysqlMessageBatchItemV12{}),
+		Down:    migration.DropTables(mysqlMessageBatchItemV12{}),
+	},
+	{
+		Version: 13,
+		Name:    "add_contract_abis",
+		Up:      migration.CreateTables(mysqlContractABIV13{}),
+		Down:    migration.DropTables(mysqlContractABIV13{}),
+	},
+	{
+		Version: 14,
+		Name:    "add_exit_code_rules",
+		Up:      migration.CreateTables(mysqlExitCodeRuleV14{}, mysqlMessageRetryV14{}),
+		Down:    migration.DropTables(mysqlExitCodeRuleV14{}, mysqlMessageRetryV14{}),
+	},
+	{
+		Version: 15,
+		Name:    "add_actor_cfg_preflight",
+		Up:      migration.AddColumn(mysqlActorCfgPreflightV15{}, "Preflight"),
+		Down:    migration.DropColumn(mysqlActorCfgPreflightV15{}, "Preflight"),
+	},
+	{
+		Version: 16,
+		Name:    "add_tipsets",
+		Up:      migration.CreateTables(mysqlTipsetV16{}),
+		Down:    migration.DropTables(mysqlTipsetV16{}),
+	},
+	{
+		Version: 17,
+		Name:    "add_leases",
+		Up:      migration.CreateTables(mysqlLeaseV17{}),
+		Down:    migration.DropTables(mysqlLeaseV17{}),
+	},
+	{
+		Version: 18,
+		Name:    "add_address_shards",
+		Up:      migration.CreateTables(mysqlAddressShardV18{}),
+		Down:    migration.DropTables(mysqlAddressShardV18{}),
+	},
+}
+
+// version 1
+
+type feeSpecV1 struct {
+	GasOverEstimation float64 `gorm:"column:gas_over_estimation;type:decimal(10,2);NOT NULL"`
+	MaxFee            string  `gorm:"column:max_fee;type:varchar(256);default:0"`
+	GasFeeCap         string  `gorm:"column:gas_fee_cap;type:varchar(256);default:0"`
+	GasOverPremium    float64 `gorm:"column:gas_over_premium;type:decimal(10,2);NOT NULL"`
+	BaseFee           string  `gorm:"column:base_fee;type:varchar(256);default:0"`
+}
+
+type msgReceiptV1 struct {
+	ExitCode int64  `gorm:"column:exit_code;default:-1"`
+	Return   []byte `gorm:"column:return_value;type:blob;"`
+	GasUsed  int64  `gorm:"column:gas_used;type:bigint;NOT NULL"`
+}
+
+type msgMetaV1 struct {
+	ExpireEpoch       int64   `gorm:"column:expire_epoch;type:bigint;NOT NULL"`
+	GasOverEstimation float64 `gorm:"column:gas_over_estimation;type:decimal(10,2)"`
+	MaxFee            string  `gorm:"column:max_fee;type:varchar(256);default:0"`
+	GasOverPremium    float64 `gorm:"column:gas_over_premium;type:decimal(10,2);"`
+}
+
+type mysqlMessageV1 struct {
+	ID      string `gorm:"column:id;type:varchar(256);primary_key"`
+	Version uint64 `gorm:"column:version;type:bigint unsigned;NOT NULL"`
+
+	From  string `gorm:"column:from_addr;type:varchar(256);NOT NULL;index:msg_from;index:idx_from_nonce;index:msg_from_state;index:idx_messages_create_at_state_from_addr;"`
+	Nonce uint64 `gorm:"column:nonce;type:bigint unsigned;index:msg_nonce;index:idx_from_nonce;NOT NULL"`
+	To    string `gorm:"column:to;type:varchar(256);NOT NULL"`
+
+	Value string `gorm:"column:value;type:varchar(256);default:0"`
+
+	GasLimit   int64  `gorm:"column:gas_limit;type:bigint;NOT NULL"`
+	GasFeeCap  string `gorm:"column:gas_fee_cap;type:varchar(256);default:0"`
+	GasPremium string `gorm:"column:gas_premium;type:varchar(256);default:0"`
+
+	Method int `gorm:"column:method;type:int;NOT NULL"`
+
+	Params []byte `gorm:"column:params;type:blob;"`
+
+	Signature []byte `gorm:"column:signed_data;type:blob;"`
+
+	UnsignedCid string `gorm:"column:unsigned_cid;type:varchar(256);index:msg_unsigned_cid;"`
+	SignedCid   string `gorm:"column:signed_cid;type:varchar(256);index:msg_signed_cid"`
+
+	Height    int64         `gorm:"column:height;type:bigint;index:msg_height;NOT NULL"`
+	Receipt   *msgReceiptV1 `gorm:"embedded;embeddedPrefix:receipt_"`
+	TipsetKey string        `gorm:"column:tipset_key;type:varchar(2048);"`
+
+	Meta *msgMetaV1 `gorm:"embedded;embeddedPrefix:meta_"`
+
+	WalletName string `gorm:"column:wallet_name;type:varchar(256)"`
+
+	State int `gorm:"column:state;type:int;index:msg_state;index:msg_from_state;index:idx_messages_create_at_state_from_addr;NOT NULL"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"`
+	ErrorMsg  string    `gorm:"column:error_msg;type:varchar(2048);"`
+	CreatedAt time.Time `gorm:"column:created_at;index;index:idx_messages_create_at_state_from_addr;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlMessageV1) TableName() string {
+	return "messages"
+}
+
+type mysqlActorCfgV1 struct {
+	ID           string `gorm:"column:id;type:varchar(256);primary_key;"`
+	ActorVersion int    `gorm:"column:actor_v;type:int;NOT NULL"`
+	Code         string `gorm:"column:code;type:varchar(256);index:idx_code_method,unique;NOT NULL;"`
+	Method       uint64 `gorm:"column:method;type:bigint unsigned;index:idx_code_method,unique;NOT NULL"`
+
+	FeeSpec feeSpecV1 `gorm:"embedded"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlActorCfgV1) TableName() string {
+	return "actor_cfg"
+}
+
+type mysqlAddressV1 struct {
+	ID        string `gorm:"column:id;type:varchar(256);primary_key"`
+	Addr      string `gorm:"column:addr;type:varchar(256);uniqueIndex;NOT NULL"`
+	Nonce     uint64 `gorm:"column:nonce;type:bigint unsigned;index;NOT NULL"`
+	Weight    int64  `gorm:"column:weight;type:bigint;index;NOT NULL"`
+	State     int    `gorm:"column:state;type:int;index;default:1"`
+	SelMsgNum uint64 `gorm:"column:sel_msg_num;type:bigint unsigned;NOT NULL"`
+
+	FeeSpec feeSpecV1 `gorm:"embedded"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlAddressV1) TableName() string {
+	return "addresses"
+}
+
+type mysqlSharedParamsV1 struct {
+	ID        uint      `gorm:"primary_key;column:id;type:SMALLINT(2) unsigned AUTO_INCREMENT;NOT NULL"`
+	SelMsgNum uint64    `gorm:"column:sel_msg_num;type:bigint unsigned;NOT NULL"`
+	FeeSpec   feeSpecV1 `gorm:"embedded"`
+}
+
+func (mysqlSharedParamsV1) TableName() string {
+	return "shared_params"
+}
+
+type mysqlNodeV1 struct {
+	ID string `gorm:"column:id;type:varchar(256);primary_key;"`
+
+	Name  string `gorm:"column:name;type:varchar(256);NOT NULL"`
+	URL   string `gorm:"column:url;type:varchar(256);NOT NULL"`
+	Token string `gorm:"column:token;type:varchar(256);NOT NULL"`
+	Type  int    `gorm:"column:node_type;type:int;NOT NULL"`
+
+	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"`
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlNodeV1) TableName() string {
+	return "nodes"
+}
+
+// version 2
+
+type mysqlAddressConfigV2 struct {
+	Addr             string `gorm:"column:addr;type:varchar(256);primary_key"`
+	SelectStrategy   string `gorm:"column:select_strategy;type:varchar(64);NOT NULL"`
+	AutoFillNonceGap bool   `gorm:"column:auto_fill_nonce_gap;default:false;NOT NULL"`
+	BalanceReserve   string `gorm:"column:balance_reserve;type:varchar(256);default:0"`
+
+	SpendMaxValue     string `gorm:"column:spend_max_value;type:varchar(256);default:0"`
+	SpendMaxGasFee    string `gorm:"column:spend_max_gas_fee;type:varchar(256);default:0"`
+	SpendWindow       int64  `gorm:"column:spend_window;default:0;NOT NULL"`
+	SpendWindowEpochs int64  `gorm:"column:spend_window_epochs;default:0;NOT NULL"`
+
+	BatchSend bool `gorm:"column:batch_send;default:false;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlAddressConfigV2) TableName() string {
+	return "address_configs"
+}
+
+// version 3
+
+type mysqlMessagePriorityV3 struct {
+	Priority int `gorm:"column:priority;type:int;default:0;NOT NULL"`
+}
+
+func (mysqlMessagePriorityV3) TableName() string {
+	return "messages"
+}
+
+// version 4
+
+type mysqlMessageExpireAtV4 struct {
+	ExpireAt *time.Time `gorm:"column:expire_at"`
+}
+
+func (mysqlMessageExpireAtV4) TableName() string {
+	return "messages"
+}
+
+// version 5
+
+type mysqlNotificationV5 struct {
+	ID             string `gorm:"column:id;type:varchar(256);primary_key"`
+	NotificationID string `gorm:"column:notification_id;type:varchar(256);index:idx_notification_url,unique;NOT NULL"`
+	URL            string `gorm:"column:url;type:varchar(256);index:idx_notification_url,unique;NOT NULL"`
+	Payload        []byte `gorm:"column:payload;type:blob;NOT NULL"`
+
+	State         int       `gorm:"column:state;type:int;index:idx_state_next_attempt;NOT NULL"`
+	Attempts      int       `gorm:"column:attempts;type:int;NOT NULL"`
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at;index:idx_state_next_attempt;NOT NULL"`
+	LastError     string    `gorm:"column:last_error;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlNotificationV5) TableName() string {
+	return "notification_outbox"
+}
+
+// version 6
+
+type mysqlFeeBumpPolicyV6 struct {
+	ID     string `gorm:"column:id;type:varchar(256);primary_key"`
+	Addr   string `gorm:"column:addr;type:varchar(256);index:idx_addr_code_method,unique;NOT NULL"`
+	Code   string `gorm:"column:code;type:varchar(256);index:idx_addr_code_method,unique;NOT NULL"`
+	Method uint64 `gorm:"column:method;type:bigint unsigned;index:idx_addr_code_method,unique;NOT NULL"`
+
+	MaxBumps  int    `gorm:"column:max_bumps;type:int;NOT NULL"`
+	BumpRatio uint64 `gorm:"column:bump_ratio;type:int;NOT NULL"`
+	MaxFeeCap string `gorm:"column:max_fee_cap;type:varchar(256);default:0"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlFeeBumpPolicyV6) TableName() string {
+	return "fee_bump_policies"
+}
+
+type mysqlFeeBumpRecordV6 struct {
+	ID       string `gorm:"column:id;type:varchar(256);primary_key"`
+	MsgID    string `gorm:"column:msg_id;type:varchar(256);index;NOT NULL"`
+	From     string `gorm:"column:from_addr;type:varchar(256);NOT NULL"`
+	Nonce    uint64 `gorm:"column:nonce;type:bigint unsigned;NOT NULL"`
+	Bump     int    `gorm:"column:bump;type:int;NOT NULL"`
+	PolicyID string `gorm:"column:policy_id;type:varchar(256)"`
+
+	OldSignedCid  string `gorm:"column:old_signed_cid;type:varchar(256)"`
+	OldGasLimit   int64  `gorm:"column:old_gas_limit;type:bigint;NOT NULL"`
+	OldGasFeeCap  string `gorm:"column:old_gas_fee_cap;type:varchar(256);NOT NULL"`
+	OldGasPremium string `gorm:"column:old_gas_premium;type:varchar(256);NOT NULL"`
+
+	NewSignedCid  string `gorm:"column:new_signed_cid;type:varchar(256)"`
+	NewGasLimit   int64  `gorm:"column:new_gas_limit;type:bigint;NOT NULL"`
+	NewGasFeeCap  string `gorm:"column:new_gas_fee_cap;type:varchar(256);NOT NULL"`
+	NewGasPremium string `gorm:"column:new_gas_premium;type:varchar(256);NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+}
+
+func (mysqlFeeBumpRecordV6) TableName() string {
+	return "fee_bump_records"
+}
+
+// version 7
+
+type mysqlMessageRevisionV7 struct {
+	ID    string `gorm:"column:id;type:varchar(256);primary_key"`
+	MsgID string `gorm:"column:msg_id;type:varchar(256);index:idx_msg_id_created_at;NOT NULL"`
+	Nonce uint64 `gorm:"column:nonce;type:bigint unsigned;NOT NULL"`
+
+	UnsignedCid string `gorm:"column:unsigned_cid;type:varchar(256)"`
+	SignedCid   string `gorm:"column:signed_cid;type:varchar(256);index:idx_revision_signed_cid"`
+	Signature   []byte `gorm:"column:signed_data;type:blob;"`
+	EthHash     string `gorm:"column:eth_hash;type:varchar(256);index:idx_revision_eth_hash"`
+
+	GasLimit   int64  `gorm:"column:gas_limit;type:bigint;NOT NULL"`
+	GasFeeCap  string `gorm:"column:gas_fee_cap;type:varchar(256);NOT NULL"`
+	GasPremium string `gorm:"column:gas_premium;type:varchar(256);NOT NULL"`
+
+	Reason   string `gorm:"column:reason;type:varchar(64);NOT NULL"`
+	Operator string `gorm:"column:operator;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index:idx_msg_id_created_at;NOT NULL"`
+}
+
+func (mysqlMessageRevisionV7) TableName() string {
+	return "message_revisions"
+}
+
+// version 8
+
+type mysqlMessageFeeV8 struct {
+	MsgID       string    `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	From        string    `gorm:"column:from_addr;type:varchar(256);index:idx_fee_from_on_chain_time;NOT NULL"`
+	To          string    `gorm:"column:to_addr;type:varchar(256);NOT NULL"`
+	Method      uint64    `gorm:"column:method;type:bigint unsigned;NOT NULL"`
+	Height      int64     `gorm:"column:height;type:bigint;NOT NULL"`
+	OnChainTime time.Time `gorm:"column:on_chain_time;index:idx_fee_from_on_chain_time;index;NOT NULL"`
+
+	GasUsed    int64  `gorm:"column:gas_used;type:bigint;NOT NULL"`
+	GasLimit   int64  `gorm:"column:gas_limit;type:bigint;NOT NULL"`
+	GasFeeCap  string `gorm:"column:gas_fee_cap;type:varchar(256);NOT NULL"`
+	GasPremium string `gorm:"column:gas_premium;type:varchar(256);NOT NULL"`
+	BaseFee    string `gorm:"column:base_fee;type:varchar(256);NOT NULL"`
+
+	BaseFeeBurn        string `gorm:"column:base_fee_burn;type:varchar(256);NOT NULL"`
+	OverEstimationBurn string `gorm:"column:over_estimation_burn;type:varchar(256);NOT NULL"`
+	MinerTip           string `gorm:"column:miner_tip;type:varchar(256);NOT NULL"`
+	MinerPenalty       string `gorm:"column:miner_penalty;type:varchar(256);NOT NULL"`
+	TotalCost          string `gorm:"column:total_cost;type:varchar(256);NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+}
+
+func (mysqlMessageFeeV8) TableName() string {
+	return "message_fees"
+}
+
+// version 9
+
+type mysqlAddressSpendV9 struct {
+	MsgID  string `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	From   string `gorm:"column:from_addr;type:varchar(256);index:idx_spend_from_created_at;index:idx_spend_from_height;NOT NULL"`
+	Value  string `gorm:"column:value;type:varchar(256);NOT NULL"`
+	GasFee string `gorm:"column:gas_fee;type:varchar(256);NOT NULL"`
+	Height int64  `gorm:"column:height;type:bigint;index:idx_spend_from_height;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index:idx_spend_from_created_at;NOT NULL"`
+}
+
+func (mysqlAddressSpendV9) TableName() string {
+	return "address_spends"
+}
+
+// version 10
+
+type mysqlSignerPolicyV10 struct {
+	ID     string `gorm:"column:id;type:varchar(256);primary_key"`
+	Signer string `gorm:"column:signer;type:varchar(256);index;NOT NULL"`
+
+	To             string `gorm:"column:to_addrs;type:text"`
+	ActorType      string `gorm:"column:actor_type;type:varchar(64)"`
+	Methods        string `gorm:"column:methods;type:text"`
+	OwnedMinerOnly bool   `gorm:"column:owned_miner_only;default:false;NOT NULL"`
+	AllowValue     bool   `gorm:"column:allow_value;default:false;NOT NULL"`
+	Description    string `gorm:"column:description;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlSignerPolicyV10) TableName() string {
+	return "signer_policies"
+}
+
+// version 11
+
+type mysqlMsigProposalV11 struct {
+	ID       string `gorm:"column:id;type:varchar(256);primary_key"`
+	Msig     string `gorm:"column:msig;type:varchar(256);index:idx_msig_txn_id;NOT NULL"`
+	Proposer string `gorm:"column:proposer;type:varchar(256);NOT NULL"`
+
+	To     string `gorm:"column:to_addr;type:varchar(256);NOT NULL"`
+	Value  string `gorm:"column:value;type:varchar(256);NOT NULL"`
+	Method uint64 `gorm:"column:method;type:bigint unsigned;NOT NULL"`
+	Params []byte `gorm:"column:params;type:blob"`
+
+	ProposeMsgID string `gorm:"column:propose_msg_id;type:varchar(256);index;NOT NULL"`
+	TxnID        int64  `gorm:"column:txn_id;type:bigint;index:idx_msig_txn_id;NOT NULL"`
+	Approvers    string `gorm:"column:approvers;type:text"`
+	State        string `gorm:"column:state;type:varchar(32);NOT NULL"`
+	ExitCode     int64  `gorm:"column:exit_code;default:0"`
+	Return       []byte `gorm:"column:return_value;type:blob"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlMsigProposalV11) TableName() string {
+	return "msig_proposals"
+}
+
+// version 12
+
+type mysqlMessageBatchItemV12 struct {
+	MsgID    string `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	ParentID string `gorm:"column:parent_id;type:varchar(256);index;NOT NULL"`
+	Index    int    `gorm:"column:idx;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+}
+
+func (mysqlMessageBatchItemV12) TableName() string {
+	return "message_batches"
+}
+
+// version 13
+
+type mysqlContractABIV13 struct {
+	Address     string `gorm:"column:address;type:varchar(256);primary_key"`
+	ABI         string `gorm:"column:abi;type:mediumtext;NOT NULL"`
+	Description string `gorm:"column:description;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"`
+}
+
+func (mysqlContractABIV13) TableName() string {
+	return "contract_abis"
+}
+
+// version 14
+
+type mysqlExitCodeRuleV14 struct {
+	ID       string `gorm:"column:id;type:varchar(256);primary_key"`
+	ExitCode int64  `gorm:"column:exit_code;type:bigint;index;NOT NULL"`
+	Code     string `gorm:"column:code;type:varchar(256)"`
+	Methods  string `gorm:"column:methods;type:text"`
+
+	Action        string  `gorm:"column:action;type:varchar(32);NOT NULL"`
+	GasMultiplier float64 `gorm:"column:gas_multiplier;type:double;default:0"`
+	MaxRetries    int     `gorm:"column:max_retries;type:int;default:0"`
+	Description   string  `gorm:"column:description;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"`
+}
+
+func (mysqlExitCodeRuleV14) TableName() string {
+	return "exit_code_rules"
+}
+
+type mysqlMessageRetryV14 struct {
+	MsgID      string `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	RetryMsgID string `gorm:"column:retry_msg_id;type:varchar(256);uniqueIndex;NOT NULL"`
+	RuleID     string `gorm:"column:rule_id;type:varchar(256)"`
+	Attempt    int    `gorm:"column:attempt;type:int;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+}
+
+func (mysqlMessageRetryV14) TableName() string {
+	return "message_retries"
+}
+
+// version 15
+
+type mysqlActorCfgPreflightV15 struct {
+	Preflight bool `gorm:"column:preflight;default:false"`
+}
+
+func (mysqlActorCfgPreflightV15) TableName() string {
+	return "actor_cfg"
+}
+
+// version 16
+
+type mysqlTipsetV16 struct {
+	Height      int64  `gorm:"column:height;type:bigint;primary_key;autoIncrement:false"`
+	Key         string `gorm:"column:tipset_key;type:varchar(1024);NOT NULL"`
+	NetworkName string `gorm:"column:network_name;type:varchar(256);NOT NULL"`
+	Blocks      []byte `gorm:"column:blocks;type:mediumblob;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+}
+
+func (mysqlTipsetV16) TableName() string {
+	return "tipsets"
+}
+
+// version 17
+
+type mysqlLeaseV17 struct {
+	Name     string    `gorm:"column:name;type:varchar(256);primary_key"`
+	Holder   string    `gorm:"column:holder;type:varchar(256);NOT NULL"`
+	Token    uint64    `gorm:"column:token;type:bigint unsigned;NOT NULL"`
+	ExpireAt time.Time `gorm:"column:expire_at;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"`
+}
+
+func (mysqlLeaseV17) TableName() string {
+	return "leases"
+}
+
+// version 18
+
+type mysqlAddressShardV18 struct {
+	Addr   string `gorm:"column:addr;type:varchar(256);primary_key"`
+	Holder string `gorm:"column:holder;type:varchar(256);NOT NULL"`
+	Token  uint64 `gorm:"column:token;type:bigint unsigned;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"`
+	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"`
+}
+
+func (mysqlAddressShardV18) TableName() string {
+	return "address_shards"
+}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// migratedTables the current model structs and the frozen copies which create their table and columns in migrations,
+// the schema created by migrations must be the same as AutoMigrate of the model structs
+func migratedTables() []struct {
+	model  interface{}
+	frozen []interface{}
+} {
+	return []struct {
+		model  interface{}
+		frozen []interface{}
+	}{
+		{mysqlMessage{}, []interface{}{mysqlMessageV1{}, mysqlMessagePriorityV3{}, mysqlMessageExpireAtV4{}}},
+		{mysqlActorCfg{}, []interface{}{mysqlActorCfgV1{}, mysqlActorCfgPreflightV15{}}},
+		{mysqlAddress{}, []interface{}{mysqlAddressV1{}}},
+		{mysqlSharedParams{}, []interface{}{mysqlSharedParamsV1{}}},
+		{mysqlNode{}, []interface{}{mysqlNodeV1{}}},
+		{mysqlAddressConfig{}, []interface{}{mysqlAddressConfigV2{}}},
+		{mysqlNotification{}, []interface{}{mysqlNotificationV5{}}},
+		{mysqlFeeBumpPolicy{}, []interface{}{mysqlFeeBumpPolicyV6{}}},
+		{mysqlFeeBumpRecord{}, []interface{}{mysqlFeeBumpRecordV6{}}},
+		{mysqlMessageRevision{}, []interface{}{mysqlMessageRevisionV7{}}},
+		{mysqlMessageFee{}, []interface{}{mysqlMessageFeeV8{}}},
+		{mysqlAddressSpend{}, []interface{}{mysqlAddressSpendV9{}}},
+		{mysqlSignerPolicy{}, []interface{}{mysqlSignerPolicyV10{}}},
+		{mysqlMsigProposal{}, []interface{}{mysqlMsigProposalV11{}}},
+		{mysqlMessageBatchItem{}, []interface{}{mysqlMessageBatchItemV12{}}},
+		{mysqlContractABI{}, []interface{}{mysqlContractABIV13{}}},
+		{mysqlExitCodeRule{}, []interface{}{mysqlExitCodeRuleV14{}}},
+		{mysqlMessageRetry{}, []interface{}{mysqlMessageRetryV14{}}},
+		{mysqlTipset{}, []interface{}{mysqlTipsetV16{}}},
+		{mysqlLease{}, []interface{}{mysqlLeaseV17{}}},
+		{mysqlAddressShard{}, []interface{}{mysqlAddressShardV18{}}},
+	}
+}
+
+type indexInfo struct {
+	Class   string
+	Type    string
+	Option  string
+	Columns string
+}
+
+// tableSchema returns the table name, the definitions of columns, the primary keys and the indexes created by models,
+// the columns are defined by the same way as CREATE TABLE and ADD COLUMN of the mysql migrator
+func tableSchema(t *testing.T, db *gorm.DB, models ...interface{}) (string, map[string]string, []string, map[string]indexInfo) {
+	var table string
+	columns := make(map[string]string)
+	var primaryKeys []string
+	indexes := make(map[string]indexInfo)
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(model))
+		if len(table) == 0 {
+			table = stmt.Schema.Table
+		}
+		require.Equal(t, table, stmt.Schema.Table)
+
+		for _, field := range stmt.Schema.Fields {
+			if len(field.DBName) == 0 {
+				continue
+			}
+			_, ok := columns[field.DBName]
+			require.False(t, ok, "column %s of %s is created twice", field.DBName, table)
+			columns[field.DBName] = db.Migrator().FullDataTypeOf(field).SQL
+		}
+		primaryKeys = append(primaryKeys, stmt.Schema.PrimaryFieldDBNames...)
+		for name, idx := range stmt.Schema.ParseIndexes() {
+			fields := make([]string, 0, len(idx.Fields))
+			for _, opt := range idx.Fields {
+				fields = append(fields, opt.DBName)
+			}
+			indexes[name] = indexInfo{Class: idx.Class, Type: idx.Type, Option: idx.Option, Columns: strings.Join(fields, ",")}
+		}
+	}
+	sort.Strings(primaryKeys)
+
+	return table, columns, primaryKeys, indexes
+}
+
+func TestMigrationsSchema(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+	db := r.GetDb()
+
+	for _, table := range migratedTables() {
+		name, columns, primaryKeys, indexes := tableSchema(t, db, table.frozen...)
+		expectName, expectColumns, expectPrimaryKeys, expectIndexes := tableSchema(t, db, table.model)
+		assert.Equal(t, expectName, name)
+		assert.NotEmpty(t, columns, name)
+		assert.Equal(t, expectColumns, columns, name)
+		assert.Equal(t, expectPrimaryKeys, primaryKeys, name)
+		assert.Equal(t, expectIndexes, indexes, name)
+	}
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
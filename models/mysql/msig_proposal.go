@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlMsigProposal struct {
+	ID       string `gorm:"column:id;type:varchar(256);primary_key"`
+	Msig     string `gorm:"column:msig;type:varchar(256);index:idx_msig_txn_id;NOT NULL"`
+	Proposer string `gorm:"column:proposer;type:varchar(256);NOT NULL"`
+
+	To     string     `gorm:"column:to_addr;type:varchar(256);NOT NULL"`
+	Value  mtypes.Int `gorm:"column:value;type:varchar(256);NOT NULL"`
+	Method uint64     `gorm:"column:method;type:bigint unsigned;NOT NULL"`
+	Params []byte     `gorm:"column:params;type:blob"`
+
+	ProposeMsgID string            `gorm:"column:propose_msg_id;type:varchar(256);index;NOT NULL"`
+	TxnID        int64             `gorm:"column:txn_id;type:bigint;index:idx_msig_txn_id;NOT NULL"`
+	Approvers    mtypes.DBAddrs    `gorm:"column:approvers;type:text"`
+	State        string            `gorm:"column:state;type:varchar(32);NOT NULL"`
+	ExitCode     exitcode.ExitCode `gorm:"column:exit_code;default:0"`
+	Return       []byte            `gorm:"column:return_value;type:blob"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
+}
+
+func (s mysqlMsigProposal) TableName() string {
+	return "msig_proposals"
+}
+
+func fromMsigProposal(proposal *types.MsigProposal) *mysqlMsigProposal {
+	return &mysqlMsigProposal{
+		ID:           proposal.ID,
+		Msig:         proposal.Msig.String(),
+		Proposer:     proposal.Proposer.String(),
+		To:           proposal.To.String(),
+		Value:        mtypes.SafeFromGo(proposal.Value.Int),
+		Method:       uint64(proposal.Method),
+		Params:       proposal.Params,
+		ProposeMsgID: proposal.ProposeMsgID,
+		TxnID:        proposal.TxnID,
+		Approvers:    mtypes.DBAddrs(proposal.Approvers),
+		State:        string(proposal.State),
+		ExitCode:     proposal.ExitCode,
+		Return:       proposal.Return,
+		CreatedAt:    proposal.CreatedAt,
+		UpdatedAt:    proposal.UpdatedAt,
+	}
+}
+
+func (s mysqlMsigProposal) MsigProposal() (*types.MsigProposal, error) {
+	msig, err := address.NewFromString(s.Msig)
+	if err != nil {
+		return nil, err
+	}
+	proposer, err := address.NewFromString(s.Proposer)
+	if err != nil {
+		return nil, err
+	}
+	to, err := address.NewFromString(s.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MsigProposal{
+		ID:           s.ID,
+		Msig:         msig,
+		Proposer:     proposer,
+		To:           to,
+		Value:        big.NewFromGo(s.Value.Int),
+		Method:       abi.MethodNum(s.Method),
+		Params:       s.Params,
+		ProposeMsgID: s.ProposeMsgID,
+		TxnID:        s.TxnID,
+		Approvers:    s.Approvers,
+		State:        types.MsigProposalState(s.State),
+		ExitCode:     s.ExitCode,
+		Return:       s.Return,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}, nil
+}
+
+type mysqlMsigProposalRepo struct {
+	*gorm.DB
+}
+
+var _ repo.MsigProposalRepo = (*mysqlMsigProposalRepo)(nil)
+
+func newMysqlMsigProposalRepo(db *gorm.DB) *mysqlMsigProposalRepo {
+	return &mysqlMsigProposalRepo{DB: db}
+}
+
+func (s *mysqlMsigProposalRepo) SaveMsigProposal(ctx context.Context, proposal *types.MsigProposal) error {
+	mp := fromMsigProposal(proposal)
+	now := time.Now()
+	mp.CreatedAt = now
+	mp.UpdatedAt = now
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"txn_id", "approvers", "state", "exit_code", "return_value",
+			"updated_at"}),
+	}).Create(mp).Error
+}
+
+func (s *mysqlMsigProposalRepo) GetMsigProposal(ctx context.Context, id string) (*types.MsigProposal, error) {
+	return s.take(ctx, "id = ?", id)
+}
+
+func (s *mysqlMsigProposalRepo) GetMsigProposalByProposeMsg(ctx context.Context, msgID string) (*types.MsigProposal, error) {
+	return s.take(ctx, "propose_msg_id = ?", msgID)
+}
+
+func (s *mysqlMsigProposalRepo) GetMsigProposalByTxnID(ctx context.Context, msig address.Address, txnID int64) (*types.MsigProposal, error) {
+	return s.take(ctx, "msig = ? and txn_id = ?", msig.String(), txnID)
+}
+
+func (s *mysqlMsigProposalRepo) take(ctx context.Context, query string, args ...interface{}) (*types.MsigProposal, error) {
+	var mp mysqlMsigProposal
+	if err := s.DB.WithContext(ctx).Where(query, args...).Take(&mp).Error; err != nil {
+		return nil, err
+	}
+	return mp.MsigProposal()
+}
+
+func (s *mysqlMsigProposalRepo) ListMsigProposal(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error) {
+	query := s.DB.WithContext(ctx)
+	if msig != address.Undef {
+		query = query.Where("msig = ?", msig.String())
+	}
+
+	var mps []*mysqlMsigProposal
+	if err := query.Order("created_at").Find(&mps).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.MsigProposal, 0, len(mps))
+	for _, mp := range mps {
+		proposal, err := mp.MsigProposal()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, proposal)
+	}
+	return result, nil
+}
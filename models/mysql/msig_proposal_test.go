@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMsigProposal(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save msig proposal", wrapper(testSaveMsigProposal, r, mock))
+	t.Run("mysql test get msig proposal", wrapper(testGetMsigProposal, r, mock))
+	t.Run("mysql test list msig proposal", wrapper(testListMsigProposal, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newMsigProposal(t *testing.T) *types.MsigProposal {
+	return &types.MsigProposal{
+		ID:           "p1",
+		Msig:         testutil.AddressProvider()(t),
+		Proposer:     testutil.AddressProvider()(t),
+		To:           testutil.AddressProvider()(t),
+		Value:        big.NewInt(100),
+		ProposeMsgID: "m1",
+		TxnID:        3,
+		Approvers:    []address.Address{testutil.AddressProvider()(t)},
+		State:        types.MsigProposalProposed,
+	}
+}
+
+func testSaveMsigProposal(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	proposal := newMsigProposal(t)
+
+	insertSql, insertArgs := genInsertSQL(fromMsigProposal(proposal))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `txn_id`=VALUES(`txn_id`),`approvers`=VALUES(`approvers`)," +
+		"`state`=VALUES(`state`),`exit_code`=VALUES(`exit_code`),`return_value`=VALUES(`return_value`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.MsigProposalRepo().SaveMsigProposal(ctx, proposal))
+}
+
+func testGetMsigProposal(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	proposal := newMsigProposal(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `msig_proposals` WHERE id = ? LIMIT 1")).
+		WithArgs(proposal.ID).
+		WillReturnRows(genSelectResult([]*mysqlMsigProposal{fromMsigProposal(proposal)}))
+
+	res, err := r.MsigProposalRepo().GetMsigProposal(ctx, proposal.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, proposal.Msig, res.Msig)
+	assert.Equal(t, proposal.Value, res.Value)
+	assert.Equal(t, proposal.Approvers, res.Approvers)
+	assert.Equal(t, proposal.State, res.State)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `msig_proposals` WHERE propose_msg_id = ? LIMIT 1")).
+		WithArgs(proposal.ProposeMsgID).
+		WillReturnRows(genSelectResult([]*mysqlMsigProposal{fromMsigProposal(proposal)}))
+
+	res, err = r.MsigProposalRepo().GetMsigProposalByProposeMsg(ctx, proposal.ProposeMsgID)
+	assert.NoError(t, err)
+	assert.Equal(t, proposal.ID, res.ID)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `msig_proposals` WHERE msig = ? and txn_id = ? LIMIT 1")).
+		WithArgs(proposal.Msig.String(), proposal.TxnID).
+		WillReturnRows(genSelectResult([]*mysqlMsigProposal{fromMsigProposal(proposal)}))
+
+	res, err = r.MsigProposalRepo().GetMsigProposalByTxnID(ctx, proposal.Msig, proposal.TxnID)
+	assert.NoError(t, err)
+	assert.Equal(t, proposal.ID, res.ID)
+}
+
+func testListMsigProposal(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	proposal := newMsigProposal(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `msig_proposals` WHERE msig = ? ORDER BY created_at")).
+		WithArgs(proposal.Msig.String()).
+		WillReturnRows(genSelectResult([]*mysqlMsigProposal{fromMsigProposal(proposal)}))
+
+	res, err := r.MsigProposalRepo().ListMsigProposal(ctx, proposal.Msig)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, proposal.ID, res[0].ID)
+}
@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlNotification struct {
+	ID             string `gorm:"column:id;type:varchar(256);primary_key"`
+	NotificationID string `gorm:"column:notification_id;type:varchar(256);index:idx_notification_url,unique;NOT NULL"`
+	URL            string `gorm:"column:url;type:varchar(256);index:idx_notification_url,unique;NOT NULL"`
+	Payload        []byte `gorm:"column:payload;type:blob;NOT NULL"`
+
+	State         types.OutboxState `gorm:"column:state;type:int;index:idx_state_next_attempt;NOT NULL"`
+	Attempts      int               `gorm:"column:attempts;type:int;NOT NULL"`
+	NextAttemptAt time.Time         `gorm:"column:next_attempt_at;index:idx_state_next_attempt;NOT NULL"`
+	LastError     string            `gorm:"column:last_error;type:text"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
+}
+
+func fromNotification(n *types.OutboxNotification) *mysqlNotification {
+	return &mysqlNotification{
+		ID:             n.ID,
+		NotificationID: n.NotificationID,
+		URL:            n.URL,
+		Payload:        n.Payload,
+		State:          n.State,
+		Attempts:       n.Attempts,
+		NextAttemptAt:  n.NextAttemptAt,
+		LastError:      n.LastError,
+		CreatedAt:      n.CreatedAt,
+		UpdatedAt:      n.UpdatedAt,
+	}
+}
+
+func (s mysqlNotification) Notification() *types.OutboxNotification {
+	return &types.OutboxNotification{
+		ID:             s.ID,
+		NotificationID: s.NotificationID,
+		URL:            s.URL,
+		Payload:        s.Payload,
+		State:          s.State,
+		Attempts:       s.Attempts,
+		NextAttemptAt:  s.NextAttemptAt,
+		LastError:      s.LastError,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
+
+func (s mysqlNotification) TableName() string {
+	return "notification_outbox"
+}
+
+var _ repo.NotificationRepo = (*mysqlNotificationRepo)(nil)
+
+type mysqlNotificationRepo struct {
+	*gorm.DB
+}
+
+func newMysqlNotificationRepo(db *gorm.DB) *mysqlNotificationRepo {
+	return &mysqlNotificationRepo{DB: db}
+}
+
+func (s *mysqlNotificationRepo) SaveNotifications(ctx context.Context, notifications []*types.OutboxNotification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	now := time.Now()
+	mNotifications := make([]*mysqlNotification, 0, len(notifications))
+	for _, n := range notifications {
+		sn := fromNotification(n)
+		sn.CreatedAt = now
+		sn.UpdatedAt = now
+		mNotifications = append(mNotifications, sn)
+	}
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&mNotifications).Error
+}
+
+func (s *mysqlNotificationRepo) ListPendingNotifications(ctx context.Context, before time.Time, limit int) ([]*types.OutboxNotification, error) {
+	var mNotifications []*mysqlNotification
+	if err := s.DB.WithContext(ctx).Where("state = ? and next_attempt_at <= ?", types.OutboxPending, before).
+		Order("next_attempt_at").Limit(limit).Find(&mNotifications).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.OutboxNotification, 0, len(mNotifications))
+	for _, sn := range mNotifications {
+		result = append(result, sn.Notification())
+	}
+	return result, nil
+}
+
+func (s *mysqlNotificationRepo) UpdateNotificationDelivery(ctx context.Context, n *types.OutboxNotification) error {
+	return s.DB.WithContext(ctx).Model(&mysqlNotification{}).Where("id = ?", n.ID).
+		Updates(map[string]interface{}{
+			"state":           n.State,
+			"attempts":        n.Attempts,
+			"next_attempt_at": n.NextAttemptAt,
+			"last_error":      n.LastError,
+			"updated_at":      time.Now(),
+		}).Error
+}
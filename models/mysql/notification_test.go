@@ -0,0 +1,81 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestNotification(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save notifications", wrapper(testSaveNotifications, r, mock))
+	t.Run("mysql test list pending notifications", wrapper(testListPendingNotifications, r, mock))
+	t.Run("mysql test update notification delivery", wrapper(testUpdateNotificationDelivery, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newOutboxNotification() *types.OutboxNotification {
+	return &types.OutboxNotification{
+		ID:             venusTypes.NewUUID().String(),
+		NotificationID: venusTypes.NewUUID().String(),
+		URL:            "http://127.0.0.1/notify",
+		Payload:        []byte(`{}`),
+		State:          types.OutboxPending,
+		NextAttemptAt:  time.Now(),
+	}
+}
+
+func testSaveNotifications(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	notification := newOutboxNotification()
+
+	insertSql, insertArgs := genInsertSQL(fromNotification(notification))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `id`=`id`")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.NotificationRepo().SaveNotifications(ctx, []*types.OutboxNotification{notification}))
+}
+
+func testListPendingNotifications(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	notification := newOutboxNotification()
+	before := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `notification_outbox` WHERE state = ? and next_attempt_at <= ? ORDER BY next_attempt_at LIMIT 10")).
+		WithArgs(types.OutboxPending, before).
+		WillReturnRows(genSelectResult([]*mysqlNotification{fromNotification(notification)}))
+
+	res, err := r.NotificationRepo().ListPendingNotifications(ctx, before, 10)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, notification.ID, res[0].ID)
+	assert.Equal(t, notification.Payload, res[0].Payload)
+}
+
+func testUpdateNotificationDelivery(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	notification := newOutboxNotification()
+	notification.State = types.OutboxSent
+	notification.Attempts = 1
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `notification_outbox` SET `attempts`=?,`last_error`=?,`next_attempt_at`=?,`state`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs(notification.Attempts, notification.LastError, anyTime{}, notification.State, anyTime{}, notification.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.NotificationRepo().UpdateNotificationDelivery(ctx, notification))
+}
@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type mysqlSignerPolicy struct {
+	ID     string `gorm:"column:id;type:varchar(256);primary_key"`
+	Signer string `gorm:"column:signer;type:varchar(256);index;NOT NULL"`
+
+	To             mtypes.DBAddrs   `gorm:"column:to_addrs;type:text"`
+	ActorType      string           `gorm:"column:actor_type;type:varchar(64)"`
+	Methods        mtypes.DBMethods `gorm:"column:methods;type:text"`
+	OwnedMinerOnly bool             `gorm:"column:owned_miner_only;default:false;NOT NULL"`
+	AllowValue     bool             `gorm:"column:allow_value;default:false;NOT NULL"`
+	Description    string           `gorm:"column:description;type:varchar(256)"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
+}
+
+func (s mysqlSignerPolicy) TableName() string {
+	return "signer_policies"
+}
+
+func fromSignerPolicy(policy *types.SignerPolicy) *mysqlSignerPolicy {
+	return &mysqlSignerPolicy{
+		ID:             policy.ID,
+		Signer:         policy.Signer.String(),
+		To:             mtypes.DBAddrs(policy.To),
+		ActorType:      policy.ActorType,
+		Methods:        mtypes.DBMethods(policy.Methods),
+		OwnedMinerOnly: policy.OwnedMinerOnly,
+		AllowValue:     policy.AllowValue,
+		Description:    policy.Description,
+		CreatedAt:      policy.CreatedAt,
+		UpdatedAt:      policy.UpdatedAt,
+	}
+}
+
+func (s mysqlSignerPolicy) SignerPolicy() (*types.SignerPolicy, error) {
+	signer, err := address.NewFromString(s.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.SignerPolicy{
+		ID:             s.ID,
+		Signer:         signer,
+		To:             s.To,
+		ActorType:      s.ActorType,
+		Methods:        s.Methods,
+		OwnedMinerOnly: s.OwnedMinerOnly,
+		AllowValue:     s.AllowValue,
+		Description:    s.Description,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+	}, nil
+}
+
+type mysqlSignerPolicyRepo struct {
+	*gorm.DB
+}
+
+var _ repo.SignerPolicyRepo = (*mysqlSignerPolicyRepo)(nil)
+
+func newMysqlSignerPolicyRepo(db *gorm.DB) *mysqlSignerPolicyRepo {
+	return &mysqlSignerPolicyRepo{DB: db}
+}
+
+func (s *mysqlSignerPolicyRepo) SaveSignerPolicy(ctx context.Context, policy *types.SignerPolicy) error {
+	sp := fromSignerPolicy(policy)
+	now := time.Now()
+	sp.CreatedAt = now
+	sp.UpdatedAt = now
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"signer", "to_addrs", "actor_type", "methods", "owned_miner_only",
+			"allow_value", "description", "updated_at"}),
+	}).Create(sp).Error
+}
+
+func (s *mysqlSignerPolicyRepo) GetSignerPolicy(ctx context.Context, id string) (*types.SignerPolicy, error) {
+	var sp mysqlSignerPolicy
+	if err := s.DB.WithContext(ctx).Take(&sp, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return sp.SignerPolicy()
+}
+
+func (s *mysqlSignerPolicyRepo) ListSignerPolicy(ctx context.Context, signer address.Address) ([]*types.SignerPolicy, error) {
+	query := s.DB.WithContext(ctx)
+	if signer != address.Undef {
+		query = query.Where("signer = ?", signer.String())
+	}
+
+	var sps []*mysqlSignerPolicy
+	if err := query.Order("created_at").Find(&sps).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.SignerPolicy, 0, len(sps))
+	for _, sp := range sps {
+		policy, err := sp.SignerPolicy()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, policy)
+	}
+	return result, nil
+}
+
+func (s *mysqlSignerPolicyRepo) DeleteSignerPolicy(ctx context.Context, id string) error {
+	res := s.DB.WithContext(ctx).Delete(&mysqlSignerPolicy{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestSignerPolicy(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save signer policy", wrapper(testSaveSignerPolicy, r, mock))
+	t.Run("mysql test get signer policy", wrapper(testGetSignerPolicy, r, mock))
+	t.Run("mysql test list signer policy", wrapper(testListSignerPolicy, r, mock))
+	t.Run("mysql test delete signer policy", wrapper(testDeleteSignerPolicy, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newSignerPolicy(t *testing.T) *types.SignerPolicy {
+	return &types.SignerPolicy{
+		ID:         "p1",
+		Signer:     testutil.AddressProvider()(t),
+		To:         []address.Address{testutil.AddressProvider()(t)},
+		Methods:    []abi.MethodNum{6, 7},
+		AllowValue: true,
+	}
+}
+
+func testSaveSignerPolicy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	policy := newSignerPolicy(t)
+
+	insertSql, insertArgs := genInsertSQL(fromSignerPolicy(policy))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `signer`=VALUES(`signer`),`to_addrs`=VALUES(`to_addrs`)," +
+		"`actor_type`=VALUES(`actor_type`),`methods`=VALUES(`methods`),`owned_miner_only`=VALUES(`owned_miner_only`)," +
+		"`allow_value`=VALUES(`allow_value`),`description`=VALUES(`description`),`updated_at`=VALUES(`updated_at`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.SignerPolicyRepo().SaveSignerPolicy(ctx, policy))
+}
+
+func testGetSignerPolicy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	policy := newSignerPolicy(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `signer_policies` WHERE id = ? LIMIT 1")).
+		WithArgs(policy.ID).
+		WillReturnRows(genSelectResult([]*mysqlSignerPolicy{fromSignerPolicy(policy)}))
+
+	res, err := r.SignerPolicyRepo().GetSignerPolicy(ctx, policy.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, policy.Signer, res.Signer)
+	assert.Equal(t, policy.To, res.To)
+	assert.Equal(t, policy.Methods, res.Methods)
+	assert.True(t, res.AllowValue)
+}
+
+func testListSignerPolicy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	policy := newSignerPolicy(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `signer_policies` WHERE signer = ? ORDER BY created_at")).
+		WithArgs(policy.Signer.String()).
+		WillReturnRows(genSelectResult([]*mysqlSignerPolicy{fromSignerPolicy(policy)}))
+
+	res, err := r.SignerPolicyRepo().ListSignerPolicy(ctx, policy.Signer)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, policy.ID, res[0].ID)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `signer_policies` ORDER BY created_at")).
+		WillReturnRows(genSelectResult([]*mysqlSignerPolicy{}))
+
+	res, err = r.SignerPolicyRepo().ListSignerPolicy(ctx, address.Undef)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func testDeleteSignerPolicy(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `signer_policies` WHERE id = ?")).
+		WithArgs("p1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.SignerPolicyRepo().DeleteSignerPolicy(ctx, "p1"))
+}
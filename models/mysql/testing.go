@@ -109,7 +109,7 @@ func genUpdateSQL(obj interface{}, skipZero bool, where ...string) (string, []dr
 	var updateArgs []driver.Value
 	for _, dbName := range objSchema.DBNames {
 		field := objSchema.LookUpField(dbName)
-		if field.PrimaryKey {
+		if field.PrimaryKey || !field.Updatable {
 			continue
 		}
 		if field.FieldType == timeT {
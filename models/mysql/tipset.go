@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	venustypes "github.com/filecoin-project/venus/venus-shared/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+)
+
+type mysqlTipset struct {
+	Height      int64  `gorm:"column:height;type:bigint;primary_key;autoIncrement:false"`
+	Key         string `gorm:"column:tipset_key;type:varchar(1024);NOT NULL"`
+	NetworkName string `gorm:"column:network_name;type:varchar(256);NOT NULL"`
+	// Blocks the json encoded block headers of tipset
+	Blocks []byte `gorm:"column:blocks;type:mediumblob;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;NOT NULL"` // 创建时间
+}
+
+func (s mysqlTipset) TableName() string {
+	return "tipsets"
+}
+
+func fromTipset(networkName string, ts *venustypes.TipSet) (*mysqlTipset, error) {
+	blocks, err := json.Marshal(ts.Blocks())
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlTipset{
+		Height:      int64(ts.Height()),
+		Key:         ts.Key().String(),
+		NetworkName: networkName,
+		Blocks:      blocks,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func (s mysqlTipset) Tipset() (*venustypes.TipSet, error) {
+	var blocks []*venustypes.BlockHeader
+	if err := json.Unmarshal(s.Blocks, &blocks); err != nil {
+		return nil, err
+	}
+	return venustypes.NewTipSet(blocks)
+}
+
+type mysqlTipsetRepo struct {
+	*gorm.DB
+}
+
+var _ repo.TipsetRepo = (*mysqlTipsetRepo)(nil)
+
+func newMysqlTipsetRepo(db *gorm.DB) *mysqlTipsetRepo {
+	return &mysqlTipsetRepo{DB: db}
+}
+
+func (s *mysqlTipsetRepo) SaveTipset(ctx context.Context, networkName string, tsList ...*venustypes.TipSet) error {
+	if len(tsList) == 0 {
+		return nil
+	}
+	rows := make([]*mysqlTipset, 0, len(tsList))
+	for _, ts := range tsList {
+		row, err := fromTipset(networkName, ts)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "height"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tipset_key", "network_name", "blocks", "created_at"}),
+	}).Create(&rows).Error
+}
+
+func (s *mysqlTipsetRepo) ListTipset(ctx context.Context) ([]*venustypes.TipSet, error) {
+	var rows []*mysqlTipset
+	if err := s.DB.WithContext(ctx).Order("height desc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*venustypes.TipSet, 0, len(rows))
+	for _, row := range rows {
+		ts, err := row.Tipset()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ts)
+	}
+	return result, nil
+}
+
+func (s *mysqlTipsetRepo) GetTipsetNetworkName(ctx context.Context) (string, error) {
+	var row mysqlTipset
+	if err := s.DB.WithContext(ctx).Select("network_name").Order("height desc").Take(&row).Error; err != nil {
+		return "", err
+	}
+	return row.NetworkName, nil
+}
+
+func (s *mysqlTipsetRepo) DeleteTipsetBelowHeight(ctx context.Context, height abi.ChainEpoch) error {
+	return s.DB.WithContext(ctx).Delete(&mysqlTipset{}, "height < ?", height).Error
+}
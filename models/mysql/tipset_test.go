@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/filecoin-project/go-state-types/abi"
+	venustypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+)
+
+func TestTipset(t *testing.T) {
+	r, mock, sqlDB := setup(t)
+
+	t.Run("mysql test save tipset", wrapper(testSaveTipset, r, mock))
+	t.Run("mysql test list tipset", wrapper(testListTipset, r, mock))
+	t.Run("mysql test get tipset network name", wrapper(testGetTipsetNetworkName, r, mock))
+	t.Run("mysql test delete tipset below height", wrapper(testDeleteTipsetBelowHeight, r, mock))
+
+	assert.NoError(t, closeDB(mock, sqlDB))
+}
+
+func newTipset(t *testing.T, height abi.ChainEpoch) *venustypes.TipSet {
+	ts, err := testhelper.GenTipset(height, 2, nil)
+	require.NoError(t, err)
+	return ts
+}
+
+func testSaveTipset(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	ts := newTipset(t, 10)
+	row, err := fromTipset("mainnet", ts)
+	require.NoError(t, err)
+
+	insertSql, insertArgs := genInsertSQL(row)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(insertSql + " ON DUPLICATE KEY UPDATE `tipset_key`=VALUES(`tipset_key`)," +
+		"`network_name`=VALUES(`network_name`),`blocks`=VALUES(`blocks`),`created_at`=VALUES(`created_at`)")).
+		WithArgs(insertArgs...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.TipsetRepo().SaveTipset(ctx, "mainnet", ts))
+	// nothing to save
+	assert.NoError(t, r.TipsetRepo().SaveTipset(ctx, "mainnet"))
+}
+
+func testListTipset(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	tsList := []*venustypes.TipSet{newTipset(t, 11), newTipset(t, 10)}
+	rows := make([]*mysqlTipset, 0, len(tsList))
+	for _, ts := range tsList {
+		row, err := fromTipset("mainnet", ts)
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `tipsets` ORDER BY height desc")).
+		WillReturnRows(genSelectResult(rows))
+
+	res, err := r.TipsetRepo().ListTipset(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tsList, res)
+}
+
+func testGetTipsetNetworkName(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `network_name` FROM `tipsets` ORDER BY height desc LIMIT 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"network_name"}).AddRow("mainnet"))
+
+	networkName, err := r.TipsetRepo().GetTipsetNetworkName(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "mainnet", networkName)
+}
+
+func testDeleteTipsetBelowHeight(t *testing.T, r repo.Repo, mock sqlmock.Sqlmock) {
+	ctx := context.Background()
+	height := abi.ChainEpoch(100)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `tipsets` WHERE height < ?")).
+		WithArgs(height).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	assert.NoError(t, r.TipsetRepo().DeleteTipsetBelowHeight(ctx, height))
+}
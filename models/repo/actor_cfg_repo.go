@@ -16,4 +16,7 @@ type ActorCfgRepo interface {
 	DelActorCfgByMethodType(ctx context.Context, addr *types.MethodType) error
 	DelActorCfgById(ctx context.Context, id shared.UUID) error
 	UpdateSelectSpecById(ctx context.Context, id shared.UUID, spec *types.ChangeGasSpecParams) error
+	// UpdatePreflightById enables or disables the pre-flight StateCall of the messages matching the config
+	UpdatePreflightById(ctx context.Context, id shared.UUID, preflight bool) error
+	ListPreflightActorCfgID(ctx context.Context) ([]shared.UUID, error)
 }
@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type AddressConfigRepo interface {
+	GetAddressConfig(ctx context.Context, addr address.Address) (*types.AddressConfig, error)
+	UpdateSelectStrategy(ctx context.Context, addr address.Address, strategy string) error
+	UpdateAutoFillNonceGap(ctx context.Context, addr address.Address, enable bool) error
+	UpdateBalanceReserve(ctx context.Context, addr address.Address, reserve big.Int) error
+	UpdateSpendBudget(ctx context.Context, addr address.Address, budget *types.SpendBudget) error
+	UpdateBatchSend(ctx context.Context, addr address.Address, enable bool) error
+}
@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// AddressShardRepo the assignment of addresses to the messager instances sharing the same database
+type AddressShardRepo interface {
+	ListAddressShard(ctx context.Context) ([]*types.AddressShard, error)
+	// ClaimAddressShard creates the shard of addr held by holder, or takes it over if it is held by one of from,
+	// returns the current shard, whose holder is another one if it is not claimed
+	ClaimAddressShard(ctx context.Context, addr address.Address, holder string, from []string) (*types.AddressShard, error)
+	// LockAddressShard gets the shard and prevents it from being claimed until the transaction ends
+	LockAddressShard(ctx context.Context, addr address.Address) (*types.AddressShard, error)
+	// ReleaseAddressShard clears the holder of shard if it is held by holder, so that another one can claim it
+	ReleaseAddressShard(ctx context.Context, addr address.Address, holder string) error
+}
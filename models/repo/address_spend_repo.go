@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type AddressSpendRepo interface {
+	// SaveAddressSpends creates the spends of messages, only the gas fee is updated if the message was recorded before
+	SaveAddressSpends(ctx context.Context, spends []*types.AddressSpend) error
+	// ListAddressSpend lists the spends of addr recorded since the time and the height, zero means unlimited
+	ListAddressSpend(ctx context.Context, addr address.Address, since time.Time, sinceHeight abi.ChainEpoch) ([]*types.AddressSpend, error)
+}
@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type ContractABIRepo interface {
+	// SaveContractABI creates the abi or replaces the existing one of the same address
+	SaveContractABI(ctx context.Context, contractABI *types.ContractABI) error
+	GetContractABI(ctx context.Context, addr address.Address) (*types.ContractABI, error)
+	ListContractABI(ctx context.Context) ([]*types.ContractABI, error)
+	DeleteContractABI(ctx context.Context, addr address.Address) error
+}
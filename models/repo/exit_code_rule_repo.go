@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type ExitCodeRuleRepo interface {
+	// SaveExitCodeRule creates the rule or updates the existing one with the same id
+	SaveExitCodeRule(ctx context.Context, rule *types.ExitCodeRule) error
+	GetExitCodeRule(ctx context.Context, id string) (*types.ExitCodeRule, error)
+	ListExitCodeRule(ctx context.Context) ([]*types.ExitCodeRule, error)
+	DeleteExitCodeRule(ctx context.Context, id string) error
+
+	// CreateMessageRetry a failed message is retried at most once
+	CreateMessageRetry(ctx context.Context, retry *types.MessageRetry) error
+	// GetMessageRetry gets the retry of the failed message
+	GetMessageRetry(ctx context.Context, msgID string) (*types.MessageRetry, error)
+	// GetMessageRetryByRetryMsgID gets the retry which created the message
+	GetMessageRetryByRetryMsgID(ctx context.Context, retryMsgID string) (*types.MessageRetry, error)
+	// DeleteMessageRetry deletes the retry of the failed message, it can be retried again
+	DeleteMessageRetry(ctx context.Context, msgID string) error
+}
@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type FeeBumpRepo interface {
+	// SaveFeeBumpPolicy creates the policy or updates the existing one with the same address or actor
+	SaveFeeBumpPolicy(ctx context.Context, policy *types.FeeBumpPolicy) error
+	GetFeeBumpPolicyByAddress(ctx context.Context, addr address.Address) (*types.FeeBumpPolicy, error)
+	GetFeeBumpPolicyByActor(ctx context.Context, code cid.Cid, method abi.MethodNum) (*types.FeeBumpPolicy, error)
+	ListFeeBumpPolicy(ctx context.Context) ([]*types.FeeBumpPolicy, error)
+	DeleteFeeBumpPolicy(ctx context.Context, id string) error
+
+	CreateFeeBumpRecord(ctx context.Context, record *types.FeeBumpRecord) error
+	// ListFeeBumpRecord lists the records of message ordered by bump
+	ListFeeBumpRecord(ctx context.Context, msgID string) ([]*types.FeeBumpRecord, error)
+}
@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// LeaseRepo the leases used to elect the leader among the messager instances sharing the same database
+type LeaseRepo interface {
+	// AcquireLease creates the lease, renews it if it is held by holder, or takes it over if it is expired,
+	// returns the current lease, whose holder is another one if the lease is not acquired
+	AcquireLease(ctx context.Context, name, holder string, duration time.Duration) (*types.Lease, error)
+	GetLease(ctx context.Context, name string) (*types.Lease, error)
+	// ListLease lists the leases whose name starts with prefix
+	ListLease(ctx context.Context, prefix string) ([]*types.Lease, error)
+	// LockLease gets the lease and prevents it from being taken over until the transaction ends
+	LockLease(ctx context.Context, name string) (*types.Lease, error)
+	// ReleaseLease expires the lease if it is held by holder, so that the others can take it over at once
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
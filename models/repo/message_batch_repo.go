@@ -0,0 +1,17 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type MessageBatchRepo interface {
+	SaveMessageBatch(ctx context.Context, items []*types.MessageBatchItem) error
+	// GetMessageBatchItem returns the item of message collapsed into a batch, ErrRecordNotFound if it is not batched
+	GetMessageBatchItem(ctx context.Context, msgID string) (*types.MessageBatchItem, error)
+	// ListMessageBatch lists the items of batch message order by index
+	ListMessageBatch(ctx context.Context, parentID string) ([]*types.MessageBatchItem, error)
+	// DeleteMessageBatch removes the items of batch message, the messages are no longer collapsed into it
+	DeleteMessageBatch(ctx context.Context, parentID string) error
+}
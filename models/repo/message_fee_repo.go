@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type MessageFeeRepo interface {
+	// SaveMessageFee creates the fee of message or overwrites it if the message was applied before
+	SaveMessageFee(ctx context.Context, fee *types.MessageFee) error
+	GetMessageFee(ctx context.Context, msgID string) (*types.MessageFee, error)
+	DeleteMessageFee(ctx context.Context, msgID string) error
+	// ListMessageFee lists the fees of messages sent from addrs and landed on chain in [since, until),
+	// all addresses if addrs is empty, zero since or until means unlimited
+	ListMessageFee(ctx context.Context, addrs []address.Address, since, until time.Time) ([]*types.MessageFee, error)
+}
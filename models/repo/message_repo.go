@@ -10,14 +10,18 @@ import (
 
 	"github.com/filecoin-project/go-state-types/abi"
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 type MsgQueryParams = types.MsgQueryParams
 
 type MessageRepo interface {
+	// ExpireMessage moves messages to sophonTypes.ExpiredMsg state
 	ExpireMessage(msg []*types.Message) error
 	BatchSaveMessage(msg []*types.Message) error
 	CreateMessage(msg *types.Message) error
+	CreateMessageWithOptions(msg *types.Message, opts *sophonTypes.MessageOptions) error
 	UpdateMessage(msg *types.Message) error
 	UpdateMessageByState(msg *types.Message, state types.MessageState) error
 
@@ -38,17 +42,32 @@ type MessageRepo interface {
 	ListFailedMessage(*MsgQueryParams) ([]*types.Message, error)
 	// ListBlockedMessage returns filled messages and unfill messages
 	ListBlockedMessage(p *MsgQueryParams, d time.Duration) ([]*types.Message, error)
+	// ListUnChainMessageByAddress returns unfill messages order by priority, the messages of the same priority are in the order they were pushed
 	ListUnChainMessageByAddress(addr address.Address, topN int) ([]*types.Message, error)
+	// ListExpiredUnFillMessage returns unfill messages whose ExpireEpoch is not after height or ExpireAt is not after now
+	ListExpiredUnFillMessage(addr address.Address, height abi.ChainEpoch, now time.Time) ([]*types.Message, error)
+	// ListUnChainMessagePriority returns the priority of unfill messages which is not the default priority
+	ListUnChainMessagePriority(addr address.Address) (map[string]int, error)
+	// ListUnChainMessageExpireAt returns the wall-clock deadline of unfill messages which have one
+	ListUnChainMessageExpireAt(addr address.Address) (map[string]time.Time, error)
 	ListFilledMessageByAddress(addr address.Address) ([]*types.Message, error)
 	ListChainMessageByHeight(height abi.ChainEpoch) ([]*types.Message, error)
+	// ListChainMessageBelowHeight returns at most limit OnChainMsg messages whose height is not after height, lowest first
+	ListChainMessageBelowHeight(height abi.ChainEpoch, limit int) ([]*types.Message, error)
 	ListUnFilledMessage(addr address.Address) ([]*types.Message, error)
 	ListSignedMsgs() ([]*types.Message, error)
 	ListFilledMessageBelowNonce(addr address.Address, nonce uint64) ([]*types.Message, error)
 	ListMessageByParams(*MsgQueryParams) ([]*types.Message, error)
 
 	UpdateMessageInfoByCid(unsignedCid string, receipt *venustypes.MessageReceipt, height abi.ChainEpoch, state types.MessageState, tsKey venustypes.TipSetKey) error
+	// UpdateMessageInfoByID updates the message without cid, such as the message collapsed into a batch
+	UpdateMessageInfoByID(id string, receipt *venustypes.MessageReceipt, height abi.ChainEpoch, state types.MessageState, tsKey venustypes.TipSetKey) error
 	UpdateMessageStateByCid(unsignedCid string, state types.MessageState) error
 	UpdateMessageStateByID(id string, state types.MessageState) error
 	MarkBadMessage(id string) error
+	// FinalizeMessage moves the OnChainMsg messages of ids to sophonTypes.FinalizedMsg state
+	FinalizeMessage(ids []string) error
 	UpdateErrMsg(id string, errMsg string) error
+	// UpdateMessagePriority only update the priority of unfill message, returns ErrRecordNotFound if no message was updated
+	UpdateMessagePriority(id string, priority int) error
 }
@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type MessageRevisionRepo interface {
+	SaveMessageRevisions(ctx context.Context, revisions []*types.MessageRevision) error
+	// ListMessageRevisions lists the revisions of message from the oldest to the newest
+	ListMessageRevisions(ctx context.Context, msgID string) ([]*types.MessageRevision, error)
+	// GetMessageRevisionBySignedCid returns the latest revision with the signed cid
+	GetMessageRevisionBySignedCid(ctx context.Context, signedCid cid.Cid) (*types.MessageRevision, error)
+	// GetMessageRevisionByEthHash returns the latest revision with the hash of eth transaction
+	GetMessageRevisionByEthHash(ctx context.Context, hash venusTypes.EthHash) (*types.MessageRevision, error)
+}
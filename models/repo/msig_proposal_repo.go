@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type MsigProposalRepo interface {
+	// SaveMsigProposal creates the proposal or updates the existing one with the same id
+	SaveMsigProposal(ctx context.Context, proposal *types.MsigProposal) error
+	GetMsigProposal(ctx context.Context, id string) (*types.MsigProposal, error)
+	GetMsigProposalByProposeMsg(ctx context.Context, msgID string) (*types.MsigProposal, error)
+	GetMsigProposalByTxnID(ctx context.Context, msig address.Address, txnID int64) (*types.MsigProposal, error)
+	// ListMsigProposal lists the proposals of msig, all proposals if msig is address.Undef
+	ListMsigProposal(ctx context.Context, msig address.Address) ([]*types.MsigProposal, error)
+}
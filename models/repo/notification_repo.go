@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type NotificationRepo interface {
+	// SaveNotifications the notification which is already saved with the same url will be ignored
+	SaveNotifications(ctx context.Context, notifications []*types.OutboxNotification) error
+	// ListPendingNotifications lists pending notifications which should be delivered before the specified time
+	ListPendingNotifications(ctx context.Context, before time.Time, limit int) ([]*types.OutboxNotification, error)
+	UpdateNotificationDelivery(ctx context.Context, notification *types.OutboxNotification) error
+}
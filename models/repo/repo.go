@@ -9,6 +9,8 @@ import (
 	"github.com/filecoin-project/go-state-types/exitcode"
 	"github.com/filecoin-project/venus/venus-shared/types"
 	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/models/migration"
 )
 
 const (
@@ -20,7 +22,7 @@ type Repo interface {
 	GetDb() *gorm.DB
 	Transaction(func(txRepo TxRepo) error) error
 	DbClose() error
-	AutoMigrate() error
+	SchemaMigrator() *migration.Migrator
 
 	TxRepo
 }
@@ -29,8 +31,22 @@ type TxRepo interface {
 	ActorCfgRepo() ActorCfgRepo
 	MessageRepo() MessageRepo
 	AddressRepo() AddressRepo
+	AddressConfigRepo() AddressConfigRepo
 	SharedParamsRepo() SharedParamsRepo
 	NodeRepo() NodeRepo
+	NotificationRepo() NotificationRepo
+	FeeBumpRepo() FeeBumpRepo
+	MessageRevisionRepo() MessageRevisionRepo
+	MessageFeeRepo() MessageFeeRepo
+	AddressSpendRepo() AddressSpendRepo
+	SignerPolicyRepo() SignerPolicyRepo
+	MsigProposalRepo() MsigProposalRepo
+	MessageBatchRepo() MessageBatchRepo
+	ContractABIRepo() ContractABIRepo
+	ExitCodeRuleRepo() ExitCodeRuleRepo
+	TipsetRepo() TipsetRepo
+	LeaseRepo() LeaseRepo
+	AddressShardRepo() AddressShardRepo
 }
 
 type ISqlField interface {
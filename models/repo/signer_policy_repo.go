@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type SignerPolicyRepo interface {
+	// SaveSignerPolicy creates the policy or updates the existing one with the same id
+	SaveSignerPolicy(ctx context.Context, policy *types.SignerPolicy) error
+	GetSignerPolicy(ctx context.Context, id string) (*types.SignerPolicy, error)
+	// ListSignerPolicy lists the policies of signer, all policies if signer is address.Undef
+	ListSignerPolicy(ctx context.Context, signer address.Address) ([]*types.SignerPolicy, error)
+	DeleteSignerPolicy(ctx context.Context, id string) error
+}
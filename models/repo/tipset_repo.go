@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	venustypes "github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// TipsetRepo the tipsets processed by messager, used to find the reverted and missed tipsets after the head changes
+type TipsetRepo interface {
+	// SaveTipset saves the tipsets of network, replaces the tipset saved at the same height
+	SaveTipset(ctx context.Context, networkName string, tsList ...*venustypes.TipSet) error
+	// ListTipset returns the tipsets order by height desc
+	ListTipset(ctx context.Context) ([]*venustypes.TipSet, error)
+	// GetTipsetNetworkName returns the network name of the highest tipset, ErrRecordNotFound if no tipset was saved
+	GetTipsetNetworkName(ctx context.Context) (string, error)
+	// DeleteTipsetBelowHeight deletes the tipsets lower than height
+	DeleteTipsetBelowHeight(ctx context.Context, height abi.ChainEpoch) error
+}
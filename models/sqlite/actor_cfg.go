@@ -28,6 +28,9 @@ type sqliteActorCfg struct {
 	Method       sqliteUint64 `gorm:"column:method;type:INTEGER;index:idx_code_method,unique;NOT NULL"`
 
 	FeeSpec
+	// Preflight simulates the messages matching the config by StateCall before signing them,
+	// it is not included in messager.ActorCfg
+	Preflight bool `gorm:"column:preflight;default:false"`
 
 	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
 	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
@@ -169,3 +172,25 @@ func (s *sqliteActorCfgRepo) UpdateSelectSpecById(ctx context.Context, id shared
 
 	return s.DB.WithContext(ctx).Model((*sqliteActorCfg)(nil)).Where("id = ?", id).UpdateColumns(updateColumns).Error
 }
+
+func (s *sqliteActorCfgRepo) UpdatePreflightById(ctx context.Context, id shared.UUID, preflight bool) error {
+	res := s.DB.WithContext(ctx).Model((*sqliteActorCfg)(nil)).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"preflight":  preflight,
+		"updated_at": time.Now(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (s *sqliteActorCfgRepo) ListPreflightActorCfgID(ctx context.Context) ([]shared.UUID, error) {
+	var ids []shared.UUID
+	if err := s.DB.WithContext(ctx).Model((*sqliteActorCfg)(nil)).Where("preflight = ?", true).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -115,6 +115,24 @@ func Test_fromActorCfg(t *testing.T) {
 		})
 	}
 
+	// Preflight
+	ids, err := actorCfgRepo.ListPreflightActorCfgID(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	assert.NoError(t, actorCfgRepo.UpdatePreflightById(ctx, expectActorCfgs[0].ID, true))
+	assert.Error(t, actorCfgRepo.UpdatePreflightById(ctx, shared.NewUUID(), true))
+	ids, err = actorCfgRepo.ListPreflightActorCfgID(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.UUID{expectActorCfgs[0].ID}, ids)
+	// the fee params are kept
+	actorCfg2, err := actorCfgRepo.GetActorCfgByID(ctx, expectActorCfgs[0].ID)
+	assert.NoError(t, err)
+	assertActorCfgValue(t, expectActorCfgs[0], actorCfg2)
+	assert.NoError(t, actorCfgRepo.UpdatePreflightById(ctx, expectActorCfgs[0].ID, false))
+	ids, err = actorCfgRepo.ListPreflightActorCfgID(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
 	//Delete
 
 	for _, actorCfg := range expectActorCfgs[:5] {
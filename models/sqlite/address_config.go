@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type sqliteAddressConfig struct {
+	Addr             string     `gorm:"column:addr;type:varchar(256);primary_key"`
+	SelectStrategy   string     `gorm:"column:select_strategy;type:varchar(64);NOT NULL"`
+	AutoFillNonceGap bool       `gorm:"column:auto_fill_nonce_gap;default:false;NOT NULL"`
+	BalanceReserve   mtypes.Int `gorm:"column:balance_reserve;type:varchar(256);default:0"`
+
+	SpendMaxValue     mtypes.Int `gorm:"column:spend_max_value;type:varchar(256);default:0"`
+	SpendMaxGasFee    mtypes.Int `gorm:"column:spend_max_gas_fee;type:varchar(256);default:0"`
+	SpendWindow       int64      `gorm:"column:spend_window;default:0;NOT NULL"` // 单位：秒
+	SpendWindowEpochs int64      `gorm:"column:spend_window_epochs;default:0;NOT NULL"`
+
+	BatchSend bool `gorm:"column:batch_send;default:false;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;index;NOT NULL"` // 更新时间
+}
+
+func (s sqliteAddressConfig) TableName() string {
+	return "address_configs"
+}
+
+func (s sqliteAddressConfig) AddressConfig() (*types.AddressConfig, error) {
+	addr, err := address.NewFromString(s.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	toBig := func(i mtypes.Int) big.Int {
+		if i.Int == nil {
+			return big.Zero()
+		}
+		return big.NewFromGo(i.Int)
+	}
+
+	return &types.AddressConfig{
+		Addr:             addr,
+		SelectStrategy:   s.SelectStrategy,
+		AutoFillNonceGap: s.AutoFillNonceGap,
+		BalanceReserve:   toBig(s.BalanceReserve),
+		SpendBudget: types.SpendBudget{
+			MaxValue:     toBig(s.SpendMaxValue),
+			MaxGasFee:    toBig(s.SpendMaxGasFee),
+			Window:       time.Duration(s.SpendWindow) * time.Second,
+			WindowEpochs: abi.ChainEpoch(s.SpendWindowEpochs),
+		},
+		BatchSend: s.BatchSend,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
+}
+
+type sqliteAddressConfigRepo struct {
+	*gorm.DB
+}
+
+var _ repo.AddressConfigRepo = (*sqliteAddressConfigRepo)(nil)
+
+func newSqliteAddressConfigRepo(db *gorm.DB) *sqliteAddressConfigRepo {
+	return &sqliteAddressConfigRepo{DB: db}
+}
+
+func (s sqliteAddressConfigRepo) GetAddressConfig(ctx context.Context, addr address.Address) (*types.AddressConfig, error) {
+	var a sqliteAddressConfig
+	if err := s.DB.WithContext(ctx).Take(&a, "addr = ?", addr.String()).Error; err != nil {
+		return nil, err
+	}
+
+	return a.AddressConfig()
+}
+
+// upsert insert a record of addr or update the specified columns if it already exists
+func (s sqliteAddressConfigRepo) upsert(ctx context.Context, cfg *sqliteAddressConfig, columns ...string) error {
+	now := time.Now()
+	cfg.CreatedAt = now
+	cfg.UpdatedAt = now
+
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "addr"}},
+		DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+	}).Create(cfg).Error
+}
+
+func (s sqliteAddressConfigRepo) UpdateSelectStrategy(ctx context.Context, addr address.Address, strategy string) error {
+	return s.upsert(ctx, &sqliteAddressConfig{Addr: addr.String(), SelectStrategy: strategy}, "select_strategy")
+}
+
+func (s sqliteAddressConfigRepo) UpdateAutoFillNonceGap(ctx context.Context, addr address.Address, enable bool) error {
+	return s.upsert(ctx, &sqliteAddressConfig{Addr: addr.String(), AutoFillNonceGap: enable}, "auto_fill_nonce_gap")
+}
+
+func (s sqliteAddressConfigRepo) UpdateBalanceReserve(ctx context.Context, addr address.Address, reserve big.Int) error {
+	return s.upsert(ctx, &sqliteAddressConfig{Addr: addr.String(), BalanceReserve: mtypes.SafeFromGo(reserve.Int)}, "balance_reserve")
+}
+
+func (s sqliteAddressConfigRepo) UpdateSpendBudget(ctx context.Context, addr address.Address, budget *types.SpendBudget) error {
+	return s.upsert(ctx, &sqliteAddressConfig{
+		Addr:              addr.String(),
+		SpendMaxValue:     mtypes.SafeFromGo(budget.MaxValue.Int),
+		SpendMaxGasFee:    mtypes.SafeFromGo(budget.MaxGasFee.Int),
+		SpendWindow:       int64(budget.Window / time.Second),
+		SpendWindowEpochs: int64(budget.WindowEpochs),
+	}, "spend_max_value", "spend_max_gas_fee", "spend_window", "spend_window_epochs")
+}
+
+func (s sqliteAddressConfigRepo) UpdateBatchSend(ctx context.Context, addr address.Address, enable bool) error {
+	return s.upsert(ctx, &sqliteAddressConfig{Addr: addr.String(), BatchSend: enable}, "batch_send")
+}
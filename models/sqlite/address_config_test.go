@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestAddressConfig(t *testing.T) {
+	ctx := context.Background()
+	addrCfgRepo := setupRepo(t).AddressConfigRepo()
+
+	addrs := []address.Address{testutil.AddressProvider()(t), testutil.AddressProvider()(t)}
+
+	t.Run("GetAddressConfig", func(t *testing.T) {
+		_, err := addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("UpdateSelectStrategy", func(t *testing.T) {
+		assert.NoError(t, addrCfgRepo.UpdateSelectStrategy(ctx, addrs[0], "fee"))
+		cfg, err := addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, addrs[0], cfg.Addr)
+		assert.Equal(t, "fee", cfg.SelectStrategy)
+
+		// update an exist record
+		assert.NoError(t, addrCfgRepo.UpdateSelectStrategy(ctx, addrs[0], "deadline"))
+		cfg2, err := addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, "deadline", cfg2.SelectStrategy)
+		assert.Equal(t, cfg.CreatedAt.Unix(), cfg2.CreatedAt.Unix())
+
+		_, err = addrCfgRepo.GetAddressConfig(ctx, addrs[1])
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+	t.Run("UpdateAutoFillNonceGap", func(t *testing.T) {
+		assert.NoError(t, addrCfgRepo.UpdateAutoFillNonceGap(ctx, addrs[1], true))
+		cfg, err := addrCfgRepo.GetAddressConfig(ctx, addrs[1])
+		assert.NoError(t, err)
+		assert.True(t, cfg.AutoFillNonceGap)
+		assert.Empty(t, cfg.SelectStrategy)
+
+		// keep the select strategy
+		assert.NoError(t, addrCfgRepo.UpdateAutoFillNonceGap(ctx, addrs[0], true))
+		cfg, err = addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.True(t, cfg.AutoFillNonceGap)
+		assert.Equal(t, "deadline", cfg.SelectStrategy)
+
+		assert.NoError(t, addrCfgRepo.UpdateAutoFillNonceGap(ctx, addrs[0], false))
+		cfg, err = addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.False(t, cfg.AutoFillNonceGap)
+		assert.Equal(t, big.Zero(), cfg.BalanceReserve)
+	})
+	t.Run("UpdateBalanceReserve", func(t *testing.T) {
+		reserve := big.NewInt(1000)
+		assert.NoError(t, addrCfgRepo.UpdateBalanceReserve(ctx, addrs[0], reserve))
+		cfg, err := addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, reserve, cfg.BalanceReserve)
+		// keep the other configs
+		assert.Equal(t, "deadline", cfg.SelectStrategy)
+	})
+	t.Run("UpdateSpendBudget", func(t *testing.T) {
+		budget := types.SpendBudget{
+			MaxValue:     big.NewInt(100),
+			MaxGasFee:    big.Zero(),
+			WindowEpochs: 2880,
+		}
+		assert.NoError(t, addrCfgRepo.UpdateSpendBudget(ctx, addrs[0], &budget))
+		cfg, err := addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, budget, cfg.SpendBudget)
+		// keep the other configs
+		assert.Equal(t, big.NewInt(1000), cfg.BalanceReserve)
+
+		budget.WindowEpochs = 0
+		budget.Window = time.Hour
+		assert.NoError(t, addrCfgRepo.UpdateSpendBudget(ctx, addrs[0], &budget))
+		cfg, err = addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, budget, cfg.SpendBudget)
+	})
+	t.Run("UpdateBatchSend", func(t *testing.T) {
+		assert.NoError(t, addrCfgRepo.UpdateBatchSend(ctx, addrs[0], true))
+		cfg, err := addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.True(t, cfg.BatchSend)
+		// keep the other configs
+		assert.Equal(t, big.NewInt(1000), cfg.BalanceReserve)
+
+		assert.NoError(t, addrCfgRepo.UpdateBatchSend(ctx, addrs[0], false))
+		cfg, err = addrCfgRepo.GetAddressConfig(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.False(t, cfg.BatchSend)
+	})
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+)
+
+func TestAddressShard(t *testing.T) {
+	ctx := context.Background()
+	r := setupRepo(t)
+	shardRepo := r.AddressShardRepo()
+	addrs := testhelper.RandAddresses(t, 2)
+
+	res, err := shardRepo.ListAddressShard(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+	_, err = shardRepo.LockAddressShard(ctx, addrs[0])
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	shard, err := shardRepo.ClaimAddressShard(ctx, addrs[0], "a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, addrs[0], shard.Addr)
+	assert.Equal(t, "a", shard.Holder)
+	assert.Equal(t, uint64(1), shard.Token)
+	_, err = shardRepo.ClaimAddressShard(ctx, addrs[1], "b", nil)
+	require.NoError(t, err)
+
+	// held by a, not claimed
+	shard, err = shardRepo.ClaimAddressShard(ctx, addrs[0], "b", []string{"c"})
+	require.NoError(t, err)
+	assert.Equal(t, "a", shard.Holder)
+	assert.Equal(t, uint64(1), shard.Token)
+
+	// only released by the holder
+	require.NoError(t, shardRepo.ReleaseAddressShard(ctx, addrs[0], "b"))
+	require.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		shard, err = txRepo.AddressShardRepo().LockAddressShard(ctx, addrs[0])
+		return err
+	}))
+	assert.Equal(t, "a", shard.Holder)
+	require.NoError(t, shardRepo.ReleaseAddressShard(ctx, addrs[0], "a"))
+
+	// claimed after released
+	shard, err = shardRepo.ClaimAddressShard(ctx, addrs[0], "b", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, "b", shard.Holder)
+	assert.Equal(t, uint64(2), shard.Token)
+
+	res, err = shardRepo.ListAddressShard(ctx)
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+	for _, shard := range res {
+		assert.Equal(t, "b", shard.Holder)
+	}
+}
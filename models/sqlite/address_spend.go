@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type sqliteAddressSpend struct {
+	MsgID  string     `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	From   string     `gorm:"column:from_addr;type:varchar(256);index:idx_spend_from_created_at;index:idx_spend_from_height;NOT NULL"`
+	Value  mtypes.Int `gorm:"column:value;type:varchar(256);NOT NULL"`
+	GasFee mtypes.Int `gorm:"column:gas_fee;type:varchar(256);NOT NULL"`
+	Height int64      `gorm:"column:height;type:bigint;index:idx_spend_from_height;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index:idx_spend_from_created_at;NOT NULL"` // 创建时间
+}
+
+func (s sqliteAddressSpend) TableName() string {
+	return "address_spends"
+}
+
+func fromAddressSpend(spend *types.AddressSpend) *sqliteAddressSpend {
+	return &sqliteAddressSpend{
+		MsgID:     spend.MsgID,
+		From:      spend.From.String(),
+		Value:     mtypes.SafeFromGo(spend.Value.Int),
+		GasFee:    mtypes.SafeFromGo(spend.GasFee.Int),
+		Height:    int64(spend.Height),
+		CreatedAt: spend.CreatedAt,
+	}
+}
+
+func (s sqliteAddressSpend) AddressSpend() (*types.AddressSpend, error) {
+	from, err := address.NewFromString(s.From)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AddressSpend{
+		MsgID:     s.MsgID,
+		From:      from,
+		Value:     big.NewFromGo(s.Value.Int),
+		GasFee:    big.NewFromGo(s.GasFee.Int),
+		Height:    abi.ChainEpoch(s.Height),
+		CreatedAt: s.CreatedAt,
+	}, nil
+}
+
+type sqliteAddressSpendRepo struct {
+	*gorm.DB
+}
+
+var _ repo.AddressSpendRepo = (*sqliteAddressSpendRepo)(nil)
+
+func newSqliteAddressSpendRepo(db *gorm.DB) *sqliteAddressSpendRepo {
+	return &sqliteAddressSpendRepo{DB: db}
+}
+
+func (s *sqliteAddressSpendRepo) SaveAddressSpends(ctx context.Context, spends []*types.AddressSpend) error {
+	if len(spends) == 0 {
+		return nil
+	}
+	now := time.Now()
+	sss := make([]*sqliteAddressSpend, 0, len(spends))
+	for _, spend := range spends {
+		ss := fromAddressSpend(spend)
+		ss.CreatedAt = now
+		sss = append(sss, ss)
+	}
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "msg_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"gas_fee"}),
+	}).Create(sss).Error
+}
+
+func (s *sqliteAddressSpendRepo) ListAddressSpend(ctx context.Context, addr address.Address, since time.Time, sinceHeight abi.ChainEpoch) ([]*types.AddressSpend, error) {
+	query := s.DB.WithContext(ctx).Where("from_addr = ?", addr.String())
+	if !since.IsZero() {
+		query = query.Where("created_at >= ?", since)
+	}
+	if sinceHeight > 0 {
+		query = query.Where("height >= ?", sinceHeight)
+	}
+
+	var sss []*sqliteAddressSpend
+	if err := query.Find(&sss).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*types.AddressSpend, 0, len(sss))
+	for _, ss := range sss {
+		spend, err := ss.AddressSpend()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, spend)
+	}
+	return result, nil
+}
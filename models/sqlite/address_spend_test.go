@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestAddressSpend(t *testing.T) {
+	ctx := context.Background()
+	spendRepo := setupRepo(t).AddressSpendRepo()
+
+	addr := testutil.AddressProvider()(t)
+	msgs := testhelper.NewSignedMessages(3)
+	spends := make([]*types.AddressSpend, 0, len(msgs))
+	for i, msg := range msgs {
+		msg.From = addr
+		spends = append(spends, types.NewAddressSpend(msg, abi.ChainEpoch(10*i)))
+	}
+	// another address
+	spends[2].From = testutil.AddressProvider()(t)
+
+	t.Run("SaveAddressSpends", func(t *testing.T) {
+		assert.NoError(t, spendRepo.SaveAddressSpends(ctx, spends))
+		assert.NoError(t, spendRepo.SaveAddressSpends(ctx, nil))
+
+		res, err := spendRepo.ListAddressSpend(ctx, addr, time.Time{}, 0)
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+
+		// only update the gas fee of replaced message
+		replaced := *spends[0]
+		replaced.GasFee = big.Add(replaced.GasFee, big.NewInt(100))
+		replaced.Value = big.NewInt(1)
+		replaced.Height = 100
+		assert.NoError(t, spendRepo.SaveAddressSpends(ctx, []*types.AddressSpend{&replaced}))
+
+		res, err = spendRepo.ListAddressSpend(ctx, addr, time.Time{}, 0)
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		for _, r := range res {
+			if r.MsgID == replaced.MsgID {
+				assert.Equal(t, replaced.GasFee, r.GasFee)
+				assert.Equal(t, spends[0].Value, r.Value)
+				assert.Equal(t, spends[0].Height, r.Height)
+			}
+		}
+	})
+
+	t.Run("ListAddressSpend", func(t *testing.T) {
+		res, err := spendRepo.ListAddressSpend(ctx, addr, time.Time{}, 10)
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, spends[1].MsgID, res[0].MsgID)
+		assert.Equal(t, spends[1].Value, res[0].Value)
+		assert.Equal(t, spends[1].GasFee, res[0].GasFee)
+
+		res, err = spendRepo.ListAddressSpend(ctx, addr, time.Now().Add(-time.Minute), 0)
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+
+		res, err = spendRepo.ListAddressSpend(ctx, addr, time.Now().Add(time.Minute), 0)
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestContractABI(t *testing.T) {
+	ctx := context.Background()
+	abiRepo := setupRepo(t).ContractABIRepo()
+
+	var contractABIs []*types.ContractABI
+	for i := 0; i < 2; i++ {
+		addr, err := address.NewIDAddress(uint64(1024 + i))
+		require.NoError(t, err)
+		contractABIs = append(contractABIs, &types.ContractABI{
+			Address: addr,
+			ABI:     `[{"type":"function","name":"get","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`,
+		})
+	}
+
+	t.Run("SaveContractABI", func(t *testing.T) {
+		for _, contractABI := range contractABIs {
+			assert.NoError(t, abiRepo.SaveContractABI(ctx, contractABI))
+		}
+
+		res, err := abiRepo.GetContractABI(ctx, contractABIs[0].Address)
+		assert.NoError(t, err)
+		assert.Equal(t, contractABIs[0].ABI, res.ABI)
+
+		// replace the existing abi
+		contractABIs[0].ABI = `[]`
+		contractABIs[0].Description = "empty"
+		assert.NoError(t, abiRepo.SaveContractABI(ctx, contractABIs[0]))
+		res2, err := abiRepo.GetContractABI(ctx, contractABIs[0].Address)
+		assert.NoError(t, err)
+		assert.Equal(t, `[]`, res2.ABI)
+		assert.Equal(t, "empty", res2.Description)
+		assert.Equal(t, res.CreatedAt.Unix(), res2.CreatedAt.Unix())
+	})
+
+	t.Run("ListContractABI", func(t *testing.T) {
+		res, err := abiRepo.ListContractABI(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+	})
+
+	t.Run("DeleteContractABI", func(t *testing.T) {
+		addr := contractABIs[1].Address
+		assert.NoError(t, abiRepo.DeleteContractABI(ctx, addr))
+		_, err := abiRepo.GetContractABI(ctx, addr)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		assert.True(t, errors.Is(abiRepo.DeleteContractABI(ctx, addr), gorm.ErrRecordNotFound))
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/ipfs-force-community/sophon-messager/filestore"
+	"github.com/ipfs-force-community/sophon-messager/models/migration"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 )
 
@@ -26,6 +27,10 @@ func (d SqlLiteRepo) AddressRepo() repo.AddressRepo {
 	return newSqliteAddressRepo(d.DB)
 }
 
+func (d SqlLiteRepo) AddressConfigRepo() repo.AddressConfigRepo {
+	return newSqliteAddressConfigRepo(d.DB)
+}
+
 func (d SqlLiteRepo) SharedParamsRepo() repo.SharedParamsRepo {
 	return newSqliteSharedParamsRepo(d.DB)
 }
@@ -34,8 +39,60 @@ func (d SqlLiteRepo) NodeRepo() repo.NodeRepo {
 	return newSqliteNodeRepo(d.DB)
 }
 
-func (d SqlLiteRepo) AutoMigrate() error {
-	return d.GetDb().AutoMigrate(sqliteMessage{}, sqliteActorCfg{}, sqliteAddress{}, sqliteSharedParams{}, sqliteNode{})
+func (d SqlLiteRepo) NotificationRepo() repo.NotificationRepo {
+	return newSqliteNotificationRepo(d.DB)
+}
+
+func (d SqlLiteRepo) FeeBumpRepo() repo.FeeBumpRepo {
+	return newSqliteFeeBumpRepo(d.DB)
+}
+
+func (d SqlLiteRepo) MessageRevisionRepo() repo.MessageRevisionRepo {
+	return newSqliteMessageRevisionRepo(d.DB)
+}
+
+func (d SqlLiteRepo) MessageFeeRepo() repo.MessageFeeRepo {
+	return newSqliteMessageFeeRepo(d.DB)
+}
+
+func (d SqlLiteRepo) AddressSpendRepo() repo.AddressSpendRepo {
+	return newSqliteAddressSpendRepo(d.DB)
+}
+
+func (d SqlLiteRepo) SignerPolicyRepo() repo.SignerPolicyRepo {
+	return newSqliteSignerPolicyRepo(d.DB)
+}
+
+func (d SqlLiteRepo) MsigProposalRepo() repo.MsigProposalRepo {
+	return newSqliteMsigProposalRepo(d.DB)
+}
+
+func (d SqlLiteRepo) MessageBatchRepo() repo.MessageBatchRepo {
+	return newSqliteMessageBatchRepo(d.DB)
+}
+
+func (d SqlLiteRepo) ContractABIRepo() repo.ContractABIRepo {
+	return newSqliteContractABIRepo(d.DB)
+}
+
+func (d SqlLiteRepo) ExitCodeRuleRepo() repo.ExitCodeRuleRepo {
+	return newSqliteExitCodeRuleRepo(d.DB)
+}
+
+func (d SqlLiteRepo) TipsetRepo() repo.TipsetRepo {
+	return newSqliteTipsetRepo(d.DB)
+}
+
+func (d SqlLiteRepo) LeaseRepo() repo.LeaseRepo {
+	return newSqliteLeaseRepo(d.DB)
+}
+
+func (d SqlLiteRepo) AddressShardRepo() repo.AddressShardRepo {
+	return newSqliteAddressShardRepo(d.DB)
+}
+
+func (d SqlLiteRepo) SchemaMigrator() *migration.Migrator {
+	return migration.NewMigrator(d.DB, migrations)
 }
 
 func (d SqlLiteRepo) GetDb() *gorm.DB {
@@ -67,6 +124,58 @@ func (t *TxSqlliteRepo) NodeRepo() repo.NodeRepo {
 	return newSqliteNodeRepo(t.DB)
 }
 
+func (t *TxSqlliteRepo) NotificationRepo() repo.NotificationRepo {
+	return newSqliteNotificationRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) FeeBumpRepo() repo.FeeBumpRepo {
+	return newSqliteFeeBumpRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) MessageRevisionRepo() repo.MessageRevisionRepo {
+	return newSqliteMessageRevisionRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) MessageFeeRepo() repo.MessageFeeRepo {
+	return newSqliteMessageFeeRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) AddressSpendRepo() repo.AddressSpendRepo {
+	return newSqliteAddressSpendRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) SignerPolicyRepo() repo.SignerPolicyRepo {
+	return newSqliteSignerPolicyRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) MsigProposalRepo() repo.MsigProposalRepo {
+	return newSqliteMsigProposalRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) MessageBatchRepo() repo.MessageBatchRepo {
+	return newSqliteMessageBatchRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) ContractABIRepo() repo.ContractABIRepo {
+	return newSqliteContractABIRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) ExitCodeRuleRepo() repo.ExitCodeRuleRepo {
+	return newSqliteExitCodeRuleRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) TipsetRepo() repo.TipsetRepo {
+	return newSqliteTipsetRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) LeaseRepo() repo.LeaseRepo {
+	return newSqliteLeaseRepo(t.DB)
+}
+
+func (t *TxSqlliteRepo) AddressShardRepo() repo.AddressShardRepo {
+	return newSqliteAddressShardRepo(t.DB)
+}
+
 func (t *TxSqlliteRepo) MessageRepo() repo.MessageRepo {
 	return newSqliteMessageRepo(t.DB)
 }
@@ -75,6 +184,10 @@ func (t *TxSqlliteRepo) AddressRepo() repo.AddressRepo {
 	return newSqliteAddressRepo(t.DB)
 }
 
+func (t *TxSqlliteRepo) AddressConfigRepo() repo.AddressConfigRepo {
+	return newSqliteAddressConfigRepo(t.DB)
+}
+
 func (d SqlLiteRepo) DbClose() error {
 	// todo: if '*gorm.DB' need to dispose?
 	return nil
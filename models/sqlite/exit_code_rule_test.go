@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestExitCodeRule(t *testing.T) {
+	ctx := context.Background()
+	ruleRepo := setupRepo(t).ExitCodeRuleRepo()
+
+	rules := []*types.ExitCodeRule{
+		{
+			ID:            "r1",
+			ExitCode:      exitcode.SysErrOutOfGas,
+			Action:        types.ExitCodeRetry,
+			GasMultiplier: 1.5,
+			MaxRetries:    3,
+			Description:   "out of gas",
+		},
+		{
+			ID:       "r2",
+			ExitCode: exitcode.ErrForbidden,
+			Code:     testutil.CidProvider(32)(t),
+			Methods:  []abi.MethodNum{6, 7},
+			Action:   types.ExitCodeMarkBad,
+		},
+		{
+			ID:       "r3",
+			ExitCode: exitcode.SysErrInsufficientFunds,
+			Action:   types.ExitCodeAlert,
+		},
+	}
+
+	t.Run("SaveExitCodeRule", func(t *testing.T) {
+		for _, rule := range rules {
+			assert.NoError(t, ruleRepo.SaveExitCodeRule(ctx, rule))
+		}
+
+		res, err := ruleRepo.GetExitCodeRule(ctx, "r1")
+		assert.NoError(t, err)
+		assert.Equal(t, exitcode.SysErrOutOfGas, res.ExitCode)
+		assert.Equal(t, cid.Undef, res.Code)
+		assert.Empty(t, res.Methods)
+		assert.Equal(t, 1.5, res.GasMultiplier)
+		assert.Equal(t, 3, res.MaxRetries)
+
+		res, err = ruleRepo.GetExitCodeRule(ctx, "r2")
+		assert.NoError(t, err)
+		assert.Equal(t, rules[1].Code, res.Code)
+		assert.Equal(t, rules[1].Methods, res.Methods)
+
+		// update the existing rule
+		rules[0].GasMultiplier = 2
+		rules[0].MaxRetries = 1
+		assert.NoError(t, ruleRepo.SaveExitCodeRule(ctx, rules[0]))
+		res2, err := ruleRepo.GetExitCodeRule(ctx, "r1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2.0, res2.GasMultiplier)
+		assert.Equal(t, 1, res2.MaxRetries)
+		assert.Equal(t, "out of gas", res2.Description)
+	})
+
+	t.Run("ListExitCodeRule", func(t *testing.T) {
+		res, err := ruleRepo.ListExitCodeRule(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, res, 3)
+	})
+
+	t.Run("DeleteExitCodeRule", func(t *testing.T) {
+		assert.NoError(t, ruleRepo.DeleteExitCodeRule(ctx, "r3"))
+		_, err := ruleRepo.GetExitCodeRule(ctx, "r3")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		assert.True(t, errors.Is(ruleRepo.DeleteExitCodeRule(ctx, "r3"), gorm.ErrRecordNotFound))
+	})
+
+	t.Run("MessageRetry", func(t *testing.T) {
+		retries := []*types.MessageRetry{
+			{MsgID: "m1", RetryMsgID: "m2", RuleID: "r1", Attempt: 1},
+			{MsgID: "m2", RetryMsgID: "m3", RuleID: "r1", Attempt: 2},
+		}
+		for _, retry := range retries {
+			assert.NoError(t, ruleRepo.CreateMessageRetry(ctx, retry))
+		}
+		// a message is retried only once
+		assert.Error(t, ruleRepo.CreateMessageRetry(ctx, &types.MessageRetry{MsgID: "m1", RetryMsgID: "m4", Attempt: 1}))
+
+		res, err := ruleRepo.GetMessageRetry(ctx, "m2")
+		assert.NoError(t, err)
+		assert.Equal(t, "m3", res.RetryMsgID)
+		assert.Equal(t, 2, res.Attempt)
+
+		res, err = ruleRepo.GetMessageRetryByRetryMsgID(ctx, "m2")
+		assert.NoError(t, err)
+		assert.Equal(t, "m1", res.MsgID)
+
+		_, err = ruleRepo.GetMessageRetry(ctx, "m3")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+		assert.NoError(t, ruleRepo.DeleteMessageRetry(ctx, "m2"))
+		_, err = ruleRepo.GetMessageRetry(ctx, "m2")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		_, err = ruleRepo.GetMessageRetry(ctx, "m1")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestFeeBump(t *testing.T) {
+	ctx := context.Background()
+	feeBumpRepo := setupRepo(t).FeeBumpRepo()
+
+	addrs := testhelper.RandAddresses(t, 2)
+	randCid := func() cid.Cid {
+		msg := testhelper.NewUnsignedMessage()
+		return msg.Cid()
+	}
+	code := randCid()
+	addrPolicy := &types.FeeBumpPolicy{
+		ID:        venusTypes.NewUUID().String(),
+		Addr:      addrs[0],
+		Code:      cid.Undef,
+		MaxBumps:  3,
+		BumpRatio: 125,
+		MaxFeeCap: big.NewInt(1000),
+	}
+	actorPolicy := &types.FeeBumpPolicy{
+		ID:        venusTypes.NewUUID().String(),
+		Addr:      address.Undef,
+		Code:      code,
+		Method:    2,
+		MaxBumps:  1,
+		BumpRatio: 150,
+		MaxFeeCap: big.Zero(),
+	}
+
+	t.Run("SaveFeeBumpPolicy", func(t *testing.T) {
+		assert.NoError(t, feeBumpRepo.SaveFeeBumpPolicy(ctx, addrPolicy))
+		assert.NoError(t, feeBumpRepo.SaveFeeBumpPolicy(ctx, actorPolicy))
+
+		// update the policy of the same address
+		newAddrPolicy := *addrPolicy
+		newAddrPolicy.ID = venusTypes.NewUUID().String()
+		newAddrPolicy.MaxBumps = 5
+		assert.NoError(t, feeBumpRepo.SaveFeeBumpPolicy(ctx, &newAddrPolicy))
+
+		policies, err := feeBumpRepo.ListFeeBumpPolicy(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, policies, 2)
+	})
+
+	t.Run("GetFeeBumpPolicy", func(t *testing.T) {
+		policy, err := feeBumpRepo.GetFeeBumpPolicyByAddress(ctx, addrs[0])
+		assert.NoError(t, err)
+		assert.Equal(t, addrPolicy.ID, policy.ID)
+		assert.Equal(t, 5, policy.MaxBumps)
+		assert.Equal(t, addrPolicy.MaxFeeCap, policy.MaxFeeCap)
+		assert.True(t, policy.IsAddressPolicy())
+
+		_, err = feeBumpRepo.GetFeeBumpPolicyByAddress(ctx, addrs[1])
+		assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+
+		policy, err = feeBumpRepo.GetFeeBumpPolicyByActor(ctx, code, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, actorPolicy.ID, policy.ID)
+		assert.Equal(t, actorPolicy.BumpRatio, policy.BumpRatio)
+		assert.False(t, policy.IsAddressPolicy())
+
+		_, err = feeBumpRepo.GetFeeBumpPolicyByActor(ctx, code, 3)
+		assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+	})
+
+	t.Run("DeleteFeeBumpPolicy", func(t *testing.T) {
+		assert.NoError(t, feeBumpRepo.DeleteFeeBumpPolicy(ctx, actorPolicy.ID))
+		assert.ErrorIs(t, feeBumpRepo.DeleteFeeBumpPolicy(ctx, actorPolicy.ID), repo.ErrRecordNotFound)
+
+		_, err := feeBumpRepo.GetFeeBumpPolicyByActor(ctx, code, 2)
+		assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+	})
+
+	t.Run("FeeBumpRecord", func(t *testing.T) {
+		msgID := venusTypes.NewUUID().String()
+		for bump := 2; bump > 0; bump-- {
+			assert.NoError(t, feeBumpRepo.CreateFeeBumpRecord(ctx, &types.FeeBumpRecord{
+				ID:            venusTypes.NewUUID().String(),
+				MsgID:         msgID,
+				From:          addrs[0],
+				Nonce:         1,
+				Bump:          bump,
+				PolicyID:      addrPolicy.ID,
+				OldSignedCid:  randCid(),
+				OldGasLimit:   100,
+				OldGasFeeCap:  big.NewInt(int64(100 * bump)),
+				OldGasPremium: big.NewInt(int64(10 * bump)),
+				NewSignedCid:  randCid(),
+				NewGasLimit:   100,
+				NewGasFeeCap:  big.NewInt(int64(200 * bump)),
+				NewGasPremium: big.NewInt(int64(20 * bump)),
+			}))
+		}
+
+		records, err := feeBumpRepo.ListFeeBumpRecord(ctx, msgID)
+		assert.NoError(t, err)
+		assert.Len(t, records, 2)
+		assert.Equal(t, 1, records[0].Bump)
+		assert.Equal(t, 2, records[1].Bump)
+		assert.Equal(t, addrs[0], records[0].From)
+		assert.Equal(t, big.NewInt(20), records[0].NewGasPremium)
+
+		records, err = feeBumpRepo.ListFeeBumpRecord(ctx, venusTypes.NewUUID().String())
+		assert.NoError(t, err)
+		assert.Empty(t, records)
+	})
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+)
+
+func TestLease(t *testing.T) {
+	ctx := context.Background()
+	r := setupRepo(t)
+	leaseRepo := r.LeaseRepo()
+	name := "leader"
+
+	_, err := leaseRepo.GetLease(ctx, name)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	lease, err := leaseRepo.AcquireLease(ctx, name, "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "a", lease.Holder)
+	assert.Equal(t, uint64(1), lease.Token)
+	assert.False(t, lease.IsExpired(time.Now()))
+
+	// renew, the token is not changed
+	renewed, err := leaseRepo.AcquireLease(ctx, name, "a", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "a", renewed.Holder)
+	assert.Equal(t, uint64(1), renewed.Token)
+	assert.True(t, renewed.ExpireAt.After(lease.ExpireAt))
+
+	// not expired, can't be taken over
+	res, err := leaseRepo.AcquireLease(ctx, name, "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "a", res.Holder)
+	assert.Equal(t, uint64(1), res.Token)
+
+	// only released by the holder
+	require.NoError(t, leaseRepo.ReleaseLease(ctx, name, "b"))
+	res, err = leaseRepo.GetLease(ctx, name)
+	require.NoError(t, err)
+	assert.False(t, res.IsExpired(time.Now()))
+	require.NoError(t, leaseRepo.ReleaseLease(ctx, name, "a"))
+
+	// taken over after expired
+	res, err = leaseRepo.AcquireLease(ctx, name, "b", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "b", res.Holder)
+	assert.Equal(t, uint64(2), res.Token)
+
+	require.NoError(t, r.Transaction(func(txRepo repo.TxRepo) error {
+		locked, err := txRepo.LeaseRepo().LockLease(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, res, locked)
+		return nil
+	}))
+	_, err = leaseRepo.AcquireLease(ctx, "member/a", "a", time.Minute)
+	require.NoError(t, err)
+	_, err = leaseRepo.AcquireLease(ctx, "member/b", "b", time.Minute)
+	require.NoError(t, err)
+	leases, err := leaseRepo.ListLease(ctx, "member/")
+	require.NoError(t, err)
+	assert.Len(t, leases, 2)
+	for _, lease := range leases {
+		assert.Equal(t, "member/"+lease.Holder, lease.Name)
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/ipfs-force-community/sophon-messager/models/mtypes"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 	"github.com/ipfs-force-community/sophon-messager/utils"
 
 	venustypes "github.com/filecoin-project/venus/venus-shared/types"
@@ -52,6 +53,10 @@ type sqliteMessage struct {
 
 	State    types.MessageState `gorm:"column:state;type:int;index:msg_state;index:msg_from_state;index:idx_messages_create_at_state_from_addr;NOT NULL"`
 	ErrorMsg string             `gorm:"column:error_msg;type:varchar(2048);"`
+	// Priority only can be set when creating message or by UpdateMessagePriority, the unfill message with higher priority will be selected first
+	Priority int `gorm:"column:priority;type:int;default:0;NOT NULL;<-:create"`
+	// ExpireAt only can be set when creating message, nil means no deadline
+	ExpireAt *time.Time `gorm:"column:expire_at;<-:create"`
 
 	IsDeleted int       `gorm:"column:is_deleted;index;default:-1;NOT NULL"` // 是否删除 1:是  -1:否
 	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"`            // 创建时间
@@ -181,7 +186,7 @@ func (m *sqliteMessageRepo) HasMessageByUid(id string) (bool, error) {
 func (m *sqliteMessageRepo) ExpireMessage(msgs []*types.Message) error {
 	for _, msg := range msgs {
 		updateColumns := map[string]interface{}{
-			"state":      types.FailedMsg,
+			"state":      sophonTypes.ExpiredMsg,
 			"updated_at": time.Now(),
 		}
 		err := m.DB.Table("messages").Where("id=?", msg.ID).UpdateColumns(updateColumns).Error
@@ -248,10 +253,38 @@ func (m *sqliteMessageRepo) ListChainMessageByHeight(height abi.ChainEpoch) ([]*
 	return result, nil
 }
 
+// ListChainMessageBelowHeight returns at most limit OnChainMsg messages whose height is not after height, lowest first
+func (m *sqliteMessageRepo) ListChainMessageBelowHeight(height abi.ChainEpoch, limit int) ([]*types.Message, error) {
+	var sqlMsgs []*sqliteMessage
+	err := m.DB.Order("height").Limit(limit).Find(&sqlMsgs, "height<=? AND state=?", height, types.OnChainMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
 // ListUnChainMessageByAddress if topN is less than or equal to 0, `Limit` has no effect
 func (m *sqliteMessageRepo) ListUnChainMessageByAddress(addr address.Address, topN int) ([]*types.Message, error) {
 	var sqlMsgs []*sqliteMessage
-	err := m.DB.Limit(topN).Order("created_at DESC").Find(&sqlMsgs, "from_addr=? AND state=?", addr.String(), types.UnFillMsg).Error
+	err := m.DB.Limit(topN).Order("priority DESC, created_at ASC").Find(&sqlMsgs, "from_addr=? AND state=?", addr.String(), types.UnFillMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*types.Message, len(sqlMsgs))
+	for index, sqlMsg := range sqlMsgs {
+		result[index] = sqlMsg.Message()
+	}
+	return result, nil
+}
+
+func (m *sqliteMessageRepo) ListExpiredUnFillMessage(addr address.Address, height abi.ChainEpoch, now time.Time) ([]*types.Message, error) {
+	var sqlMsgs []*sqliteMessage
+	err := m.DB.Find(&sqlMsgs, "from_addr=? AND state=? AND ((meta_expire_epoch>0 AND meta_expire_epoch<=?) OR expire_at<=?)",
+		addr.String(), types.UnFillMsg, height, now).Error
 	if err != nil {
 		return nil, err
 	}
@@ -262,6 +295,34 @@ func (m *sqliteMessageRepo) ListUnChainMessageByAddress(addr address.Address, to
 	return result, nil
 }
 
+func (m *sqliteMessageRepo) ListUnChainMessagePriority(addr address.Address) (map[string]int, error) {
+	var sqlMsgs []*sqliteMessage
+	err := m.DB.Select("id", "priority").Find(&sqlMsgs, "from_addr=? AND state=? AND priority<>?", addr.String(), types.UnFillMsg, 0).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int, len(sqlMsgs))
+	for _, sqlMsg := range sqlMsgs {
+		result[sqlMsg.ID] = sqlMsg.Priority
+	}
+	return result, nil
+}
+
+func (m *sqliteMessageRepo) ListUnChainMessageExpireAt(addr address.Address) (map[string]time.Time, error) {
+	var sqlMsgs []*sqliteMessage
+	err := m.DB.Select("id", "expire_at").Find(&sqlMsgs, "from_addr=? AND state=? AND expire_at IS NOT NULL", addr.String(), types.UnFillMsg).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]time.Time, len(sqlMsgs))
+	for _, sqlMsg := range sqlMsgs {
+		if sqlMsg.ExpireAt != nil {
+			result[sqlMsg.ID] = *sqlMsg.ExpireAt
+		}
+	}
+	return result, nil
+}
+
 // todo better batch update
 func (m *sqliteMessageRepo) BatchSaveMessage(msgs []*types.Message) error {
 	for _, msg := range msgs {
@@ -278,6 +339,15 @@ func (m *sqliteMessageRepo) CreateMessage(msg *types.Message) error {
 	return m.DB.Create(sqlMsg).Error
 }
 
+func (m *sqliteMessageRepo) CreateMessageWithOptions(msg *types.Message, opts *sophonTypes.MessageOptions) error {
+	sqlMsg := fromMessage(msg)
+	sqlMsg.Priority = opts.Priority
+	if !opts.ExpireAt.IsZero() {
+		sqlMsg.ExpireAt = &opts.ExpireAt
+	}
+	return m.DB.Create(sqlMsg).Error
+}
+
 // UpdateMessage used to update message and create message with CreateMessage
 func (m *sqliteMessageRepo) UpdateMessage(msg *types.Message) error {
 	sqlMsg := fromMessage(msg)
@@ -454,9 +524,8 @@ func (m *sqliteMessageRepo) ListBlockedMessage(params *repo.MsgQueryParams, d ti
 	t := time.Now().Add(-d)
 	params.State = []types.MessageState{types.FillMsg, types.UnFillMsg}
 
-	query := parseQueryParams(m.DB, params)
-
-	query.Order("created_at")
+	query := m.DB.Order("created_at")
+	query = parseQueryParams(query, params)
 	query.Where("created_at < ?", t)
 
 	err := query.Find(&sqlMsgs).Error
@@ -506,6 +575,25 @@ func (m *sqliteMessageRepo) UpdateMessageInfoByCid(unsignedCid string,
 	height abi.ChainEpoch,
 	state types.MessageState,
 	tsKey venustypes.TipSetKey,
+) error {
+	return m.updateMessageInfo("unsigned_cid = ?", unsignedCid, receipt, height, state, tsKey)
+}
+
+func (m *sqliteMessageRepo) UpdateMessageInfoByID(id string,
+	receipt *venustypes.MessageReceipt,
+	height abi.ChainEpoch,
+	state types.MessageState,
+	tsKey venustypes.TipSetKey,
+) error {
+	return m.updateMessageInfo("id = ?", id, receipt, height, state, tsKey)
+}
+
+func (m *sqliteMessageRepo) updateMessageInfo(query string,
+	arg interface{},
+	receipt *venustypes.MessageReceipt,
+	height abi.ChainEpoch,
+	state types.MessageState,
+	tsKey venustypes.TipSetKey,
 ) error {
 	rcp := repo.FromMsgReceipt(receipt)
 	updateClause := map[string]interface{}{
@@ -518,7 +606,7 @@ func (m *sqliteMessageRepo) UpdateMessageInfoByCid(unsignedCid string,
 		"updated_at":           time.Now(),
 	}
 	return m.DB.Model(&sqliteMessage{}).
-		Where("unsigned_cid = ?", unsignedCid).
+		Where(query, arg).
 		UpdateColumns(updateClause).Error
 }
 
@@ -540,6 +628,16 @@ func (m *sqliteMessageRepo) UpdateMessageStateByID(id string, state types.Messag
 		Where("id = ?", id).UpdateColumns(updateColumns).Error
 }
 
+// FinalizeMessage moves the OnChainMsg messages of ids to sophonTypes.FinalizedMsg state
+func (m *sqliteMessageRepo) FinalizeMessage(ids []string) error {
+	updateColumns := map[string]interface{}{
+		"state":      sophonTypes.FinalizedMsg,
+		"updated_at": time.Now(),
+	}
+	return m.DB.Model(&sqliteMessage{}).
+		Where("id IN ? AND state = ?", ids, types.OnChainMsg).UpdateColumns(updateColumns).Error
+}
+
 func (m *sqliteMessageRepo) MarkBadMessage(id string) error {
 	updateColumns := map[string]interface{}{
 		"state":      types.FailedMsg,
@@ -556,6 +654,22 @@ func (m *sqliteMessageRepo) UpdateErrMsg(id string, errMsg string) error {
 	return m.DB.Model(&sqliteMessage{}).Where("id = ?", id).UpdateColumns(updateColumns).Error
 }
 
+func (m *sqliteMessageRepo) UpdateMessagePriority(id string, priority int) error {
+	updateColumns := map[string]interface{}{
+		"priority":   priority,
+		"updated_at": time.Now(),
+	}
+	// priority is not updatable through the model, so update the table directly
+	res := m.DB.Table("messages").Where("id = ? AND state = ?", id, types.UnFillMsg).UpdateColumns(updateColumns)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return repo.ErrRecordNotFound
+	}
+	return nil
+}
+
 func parseQueryParams(query *gorm.DB, params *repo.MsgQueryParams) *gorm.DB {
 	if !params.Asc {
 		query = query.Order("updated_at desc")
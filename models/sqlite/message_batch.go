@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type sqliteMessageBatchItem struct {
+	MsgID    string `gorm:"column:msg_id;type:varchar(256);primary_key"`
+	ParentID string `gorm:"column:parent_id;type:varchar(256);index;NOT NULL"`
+	Index    int    `gorm:"column:idx;NOT NULL"`
+
+	CreatedAt time.Time `gorm:"column:created_at;index;NOT NULL"` // 创建时间
+}
+
+func (s sqliteMessageBatchItem) TableName() string {
+	return "message_batches"
+}
+
+func fromMessageBatchItem(item *types.MessageBatchItem) *sqliteMessageBatchItem {
+	return &sqliteMessageBatchItem{
+		MsgID:     item.MsgID,
+		ParentID:  item.ParentID,
+		Index:     item.Index,
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+func (s sqliteMessageBatchItem) MessageBatchItem() *types.MessageBatchItem {
+	return &types.MessageBatchItem{
+		MsgID:     s.MsgID,
+		ParentID:  s.ParentID,
+		Index:     s.Index,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+type sqliteMessageBatchRepo struct {
+	*gorm.DB
+}
+
+var _ repo.MessageBatchRepo = (*sqliteMessageBatchRepo)(nil)
+
+func newSqliteMessageBatchRepo(db *gorm.DB) *sqliteMessageBatchRepo {
+	return &sqliteMessageBatchRepo{DB: db}
+}
+
+func (s *sqliteMessageBatchRepo) SaveMessageBatch(ctx context.Context, items []*types.MessageBatchItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	now := time.Now()
+	records := make([]*sqliteMessageBatchItem, 0, len(items))
+	for _, item := range items {
+		record := fromMessageBatchItem(item)
+		record.CreatedAt = now
+		records = append(records, record)
+	}
+
+	return s.DB.WithContext(ctx).Create(records).Error
+}
+
+func (s *sqliteMessageBatchRepo) GetMessageBatchItem(ctx context.Context, msgID string) (*types.MessageBatchItem, error) {
+	var item sqliteMessageBatchItem
+	if err := s.DB.WithContext(ctx).Take(&item, "msg_id = ?", msgID).Error; err != nil {
+		return nil, err
+	}
+	return item.MessageBatchItem(), nil
+}
+
+func (s *sqliteMessageBatchRepo) ListMessageBatch(ctx context.Context, parentID string) ([]*types.MessageBatchItem, error) {
+	var records []*sqliteMessageBatchItem
+	if err := s.DB.WithContext(ctx).Where("parent_id = ?", parentID).Order("idx").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]*types.MessageBatchItem, 0, len(records))
+	for _, record := range records {
+		items = append(items, record.MessageBatchItem())
+	}
+	return items, nil
+}
+
+func (s *sqliteMessageBatchRepo) DeleteMessageBatch(ctx context.Context, parentID string) error {
+	return s.DB.WithContext(ctx).Delete(&sqliteMessageBatchItem{}, "parent_id = ?", parentID).Error
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageBatch(t *testing.T) {
+	ctx := context.Background()
+	batchRepo := setupRepo(t).MessageBatchRepo()
+
+	items := []*types.MessageBatchItem{
+		{MsgID: "m2", ParentID: "p1", Index: 1},
+		{MsgID: "m1", ParentID: "p1", Index: 0},
+		{MsgID: "m3", ParentID: "p2", Index: 0},
+	}
+	assert.NoError(t, batchRepo.SaveMessageBatch(ctx, items))
+	assert.NoError(t, batchRepo.SaveMessageBatch(ctx, nil))
+
+	t.Run("GetMessageBatchItem", func(t *testing.T) {
+		item, err := batchRepo.GetMessageBatchItem(ctx, "m2")
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", item.ParentID)
+		assert.Equal(t, 1, item.Index)
+
+		_, err = batchRepo.GetMessageBatchItem(ctx, "m4")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+	t.Run("ListMessageBatch", func(t *testing.T) {
+		res, err := batchRepo.ListMessageBatch(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "m1", res[0].MsgID)
+		assert.Equal(t, "m2", res[1].MsgID)
+
+		res, err = batchRepo.ListMessageBatch(ctx, "p3")
+		assert.NoError(t, err)
+		assert.Len(t, res, 0)
+	})
+	t.Run("DeleteMessageBatch", func(t *testing.T) {
+		assert.NoError(t, batchRepo.DeleteMessageBatch(ctx, "p1"))
+		res, err := batchRepo.ListMessageBatch(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Len(t, res, 0)
+		res, err = batchRepo.ListMessageBatch(ctx, "p2")
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+	})
+}
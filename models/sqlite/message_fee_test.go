@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageFee(t *testing.T) {
+	ctx := context.Background()
+	feeRepo := setupRepo(t).MessageFeeRepo()
+
+	now := time.Now().Truncate(time.Second)
+	msgs := testhelper.NewSignedMessages(3)
+	fees := make([]*types.MessageFee, 0, len(msgs))
+	for i, msg := range msgs {
+		fees = append(fees, &types.MessageFee{
+			MsgID:              msg.ID,
+			From:               msg.From,
+			To:                 msg.To,
+			Method:             msg.Method,
+			Height:             int64(i),
+			OnChainTime:        now.Add(time.Duration(i) * time.Hour),
+			GasUsed:            100,
+			GasLimit:           msg.GasLimit,
+			GasFeeCap:          msg.GasFeeCap,
+			GasPremium:         msg.GasPremium,
+			BaseFee:            big.NewInt(100),
+			BaseFeeBurn:        big.NewInt(10000),
+			OverEstimationBurn: big.NewInt(200),
+			MinerTip:           big.NewInt(300),
+			MinerPenalty:       big.Zero(),
+			TotalCost:          big.NewInt(10500),
+		})
+	}
+
+	t.Run("SaveMessageFee", func(t *testing.T) {
+		for _, fee := range fees {
+			assert.NoError(t, feeRepo.SaveMessageFee(ctx, fee))
+		}
+
+		// apply again after reverted
+		fees[0].Height = 100
+		fees[0].TotalCost = big.NewInt(20000)
+		assert.NoError(t, feeRepo.SaveMessageFee(ctx, fees[0]))
+		res, err := feeRepo.GetMessageFee(ctx, fees[0].MsgID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(100), res.Height)
+		assert.Equal(t, fees[0].TotalCost, res.TotalCost)
+		assert.Equal(t, fees[0].To, res.To)
+		assert.Equal(t, fees[0].Method, res.Method)
+		assert.True(t, fees[0].OnChainTime.Equal(res.OnChainTime))
+	})
+
+	t.Run("ListMessageFee", func(t *testing.T) {
+		res, err := feeRepo.ListMessageFee(ctx, nil, time.Time{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Len(t, res, 3)
+		for i := range res {
+			assert.Equal(t, fees[i].MsgID, res[i].MsgID)
+		}
+
+		res, err = feeRepo.ListMessageFee(ctx, nil, now.Add(time.Hour), time.Time{})
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+
+		res, err = feeRepo.ListMessageFee(ctx, nil, now, now.Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, fees[0].MsgID, res[0].MsgID)
+
+		res, err = feeRepo.ListMessageFee(ctx, []address.Address{fees[1].From, fees[2].From}, time.Time{}, time.Time{})
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+	})
+
+	t.Run("DeleteMessageFee", func(t *testing.T) {
+		assert.NoError(t, feeRepo.DeleteMessageFee(ctx, fees[0].MsgID))
+		_, err := feeRepo.GetMessageFee(ctx, fees[0].MsgID)
+		assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+	})
+}
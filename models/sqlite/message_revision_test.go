@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageRevision(t *testing.T) {
+	ctx := context.Background()
+	revisionRepo := setupRepo(t).MessageRevisionRepo()
+
+	msgs := testhelper.NewSignedMessages(2)
+	msg := msgs[0]
+	first := types.NewMessageRevision(msg, types.RevisionSelect, "")
+
+	msg.GasPremium = big.Add(msg.GasPremium, big.NewInt(100))
+	signedMsg := testhelper.NewShareSignedMessage()
+	signedCid := signedMsg.Cid()
+	msg.SignedCid = &signedCid
+	msg.Signature = &crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: []byte("replaced")}
+	second := types.NewMessageRevision(msg, types.RevisionReplace, "alice")
+	second.EthHash = venusTypes.EthHashFromTxBytes([]byte(second.ID))
+
+	t.Run("SaveMessageRevisions", func(t *testing.T) {
+		assert.NoError(t, revisionRepo.SaveMessageRevisions(ctx, []*types.MessageRevision{
+			first,
+			types.NewMessageRevision(msgs[1], types.RevisionSelect, ""),
+		}))
+		time.Sleep(time.Millisecond)
+		assert.NoError(t, revisionRepo.SaveMessageRevisions(ctx, []*types.MessageRevision{second}))
+		assert.NoError(t, revisionRepo.SaveMessageRevisions(ctx, nil))
+	})
+
+	t.Run("ListMessageRevisions", func(t *testing.T) {
+		revisions, err := revisionRepo.ListMessageRevisions(ctx, msg.ID)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, first.ID, revisions[0].ID)
+		assert.Equal(t, first.SignedCid, revisions[0].SignedCid)
+		assert.Equal(t, first.GasPremium, revisions[0].GasPremium)
+		assert.Equal(t, second.ID, revisions[1].ID)
+		assert.Equal(t, types.RevisionReplace, revisions[1].Reason)
+		assert.Equal(t, "alice", revisions[1].Operator)
+		assert.Equal(t, second.Signature, revisions[1].Signature)
+
+		revisions, err = revisionRepo.ListMessageRevisions(ctx, venusTypes.NewUUID().String())
+		assert.NoError(t, err)
+		assert.Empty(t, revisions)
+	})
+
+	t.Run("GetMessageRevisionBySignedCid", func(t *testing.T) {
+		rev, err := revisionRepo.GetMessageRevisionBySignedCid(ctx, first.SignedCid)
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, rev.ID)
+		assert.Equal(t, msg.ID, rev.MsgID)
+		assert.Equal(t, first.UnsignedCid, rev.UnsignedCid)
+
+		other := testhelper.NewShareSignedMessage()
+		_, err = revisionRepo.GetMessageRevisionBySignedCid(ctx, other.Cid())
+		assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+	})
+
+	t.Run("GetMessageRevisionByEthHash", func(t *testing.T) {
+		rev, err := revisionRepo.GetMessageRevisionByEthHash(ctx, second.EthHash)
+		assert.NoError(t, err)
+		assert.Equal(t, second.ID, rev.ID)
+		assert.Equal(t, second.EthHash, rev.EthHash)
+
+		_, err = revisionRepo.GetMessageRevisionByEthHash(ctx, venusTypes.EthHashFromTxBytes([]byte("other")))
+		assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+	})
+}
@@ -15,6 +15,7 @@ import (
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 	"github.com/ipfs-force-community/sophon-messager/utils"
 )
 
@@ -125,7 +126,51 @@ func TestExpireMessage(t *testing.T) {
 
 	msg2, err := messageRepo.GetMessageByUid(msg.ID)
 	assert.NoError(t, err)
-	assert.Equal(t, types.FailedMsg, msg2.State)
+	assert.Equal(t, sophonTypes.ExpiredMsg, msg2.State)
+}
+
+func TestListExpiredUnFillMessage(t *testing.T) {
+	messageRepo := setupRepo(t).MessageRepo()
+
+	addr, err := address.NewActorAddress(uuid.New().NodeID())
+	assert.NoError(t, err)
+
+	now := time.Now()
+	msgs := testhelper.NewMessages(5)
+	for _, msg := range msgs {
+		msg.Message.From = addr
+		msg.State = types.UnFillMsg
+		msg.Meta = &types.SendSpec{}
+	}
+	// expired by epoch
+	msgs[0].Meta.ExpireEpoch = 100
+	// not expired
+	msgs[1].Meta.ExpireEpoch = 101
+	// no deadline
+	assert.NoError(t, messageRepo.CreateMessage(msgs[2]))
+	// expired by time
+	assert.NoError(t, messageRepo.CreateMessageWithOptions(msgs[3], &sophonTypes.MessageOptions{ExpireAt: now.Add(-time.Minute)}))
+	// not expired
+	assert.NoError(t, messageRepo.CreateMessageWithOptions(msgs[4], &sophonTypes.MessageOptions{ExpireAt: now.Add(time.Minute)}))
+	assert.NoError(t, messageRepo.CreateMessage(msgs[0]))
+	assert.NoError(t, messageRepo.CreateMessage(msgs[1]))
+
+	res, err := messageRepo.ListExpiredUnFillMessage(addr, 100, now)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{msgs[0].ID, msgs[3].ID}, msgIDs(res))
+
+	deadlines, err := messageRepo.ListUnChainMessageExpireAt(addr)
+	assert.NoError(t, err)
+	assert.Len(t, deadlines, 2)
+	assert.True(t, now.Add(time.Minute).Equal(deadlines[msgs[4].ID]))
+	assert.Contains(t, deadlines, msgs[3].ID)
+
+	// the expire time should not be changed by updating message
+	assert.NoError(t, messageRepo.UpdateMessage(msgs[3]))
+	assert.NoError(t, messageRepo.ExpireMessage(res))
+	res, err = messageRepo.ListExpiredUnFillMessage(addr, 200, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{msgs[1].ID, msgs[4].ID}, msgIDs(res))
 }
 
 func TestGetMessageState(t *testing.T) {
@@ -458,8 +503,9 @@ func TestListBlockedMessage(t *testing.T) {
 		&repo.MsgQueryParams{From: []address.Address{msgState[types.FillMsg][0].From, msgState[types.UnFillMsg][0].From}}, time.Second*2)
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(msgList))
-	testhelper.Equal(t, msgState[types.UnFillMsg][0], msgList[0])
-	testhelper.Equal(t, msgState[types.FillMsg][0], msgList[1])
+	// the earlier created message goes first
+	testhelper.Equal(t, msgState[types.FillMsg][0], msgList[0])
+	testhelper.Equal(t, msgState[types.UnFillMsg][0], msgList[1])
 }
 
 func TestListUnChainMessageByAddress(t *testing.T) {
@@ -490,7 +536,7 @@ func TestListUnChainMessageByAddress(t *testing.T) {
 	checkMsgList(t, msgList, testhelper.SliceToMap(msgs))
 
 	sorted := sort.SliceIsSorted(msgList, func(i, j int) bool {
-		return msgList[i].CreatedAt.After(msgList[j].CreatedAt)
+		return msgList[i].CreatedAt.Before(msgList[j].CreatedAt)
 	})
 	assert.True(t, sorted)
 
@@ -499,6 +545,48 @@ func TestListUnChainMessageByAddress(t *testing.T) {
 	assert.Equal(t, unChainMsgCount, len(msgList))
 }
 
+func TestMessagePriority(t *testing.T) {
+	messageRepo := setupRepo(t).MessageRepo()
+
+	addr, err := address.NewActorAddress(uuid.New().NodeID())
+	assert.NoError(t, err)
+
+	msgs := testhelper.NewMessages(4)
+	for _, msg := range msgs {
+		msg.Message.From = addr
+		msg.State = types.UnFillMsg
+	}
+	assert.NoError(t, messageRepo.CreateMessage(msgs[0]))
+	assert.NoError(t, messageRepo.CreateMessageWithOptions(msgs[1], &sophonTypes.MessageOptions{Priority: 1}))
+	assert.NoError(t, messageRepo.CreateMessage(msgs[2]))
+	assert.NoError(t, messageRepo.CreateMessageWithOptions(msgs[3], &sophonTypes.MessageOptions{Priority: -1}))
+
+	msgList, err := messageRepo.ListUnChainMessageByAddress(addr, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[1].ID, msgs[0].ID, msgs[2].ID, msgs[3].ID}, msgIDs(msgList))
+
+	priorities, err := messageRepo.ListUnChainMessagePriority(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{msgs[1].ID: 1, msgs[3].ID: -1}, priorities)
+
+	assert.NoError(t, messageRepo.UpdateMessagePriority(msgs[0].ID, 2))
+	msgList, err = messageRepo.ListUnChainMessageByAddress(addr, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[0].ID}, msgIDs(msgList))
+
+	// updating message should not change the priority
+	msgs[0].State = types.FillMsg
+	assert.NoError(t, messageRepo.UpdateMessage(msgs[0]))
+	assert.ErrorIs(t, messageRepo.UpdateMessagePriority(msgs[0].ID, 3), repo.ErrRecordNotFound)
+	msgs[0].State = types.UnFillMsg
+	assert.NoError(t, messageRepo.UpdateMessage(msgs[0]))
+	priorities, err = messageRepo.ListUnChainMessagePriority(addr)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, priorities[msgs[0].ID])
+
+	assert.ErrorIs(t, messageRepo.UpdateMessagePriority("not-exist", 1), repo.ErrRecordNotFound)
+}
+
 func TestListFilledMessageByAddress(t *testing.T) {
 	messageRepo := setupRepo(t).MessageRepo()
 
@@ -546,6 +634,51 @@ func TestListChainMessageByHeight(t *testing.T) {
 	checkMsgList(t, msgList, testhelper.SliceToMap(msgs))
 }
 
+func TestFinalizeMessage(t *testing.T) {
+	messageRepo := setupRepo(t).MessageRepo()
+
+	msgs := testhelper.NewSignedMessages(4)
+	for idx, msg := range msgs {
+		msg.Height = int64(100 + idx)
+		msg.State = types.OnChainMsg
+		assert.NoError(t, messageRepo.CreateMessage(msg))
+	}
+	// not on chain
+	msgs[3].State = types.FillMsg
+	assert.NoError(t, messageRepo.UpdateMessage(msgs[3]))
+
+	msgList, err := messageRepo.ListChainMessageBelowHeight(102, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgList, 3)
+	checkMsgList(t, msgList, testhelper.SliceToMap(msgs[:3]))
+
+	// the lowest first
+	msgList, err = messageRepo.ListChainMessageBelowHeight(102, 2)
+	assert.NoError(t, err)
+	assert.Len(t, msgList, 2)
+	assert.Equal(t, msgs[0].ID, msgList[0].ID)
+	assert.Equal(t, msgs[1].ID, msgList[1].ID)
+
+	assert.NoError(t, messageRepo.FinalizeMessage([]string{msgs[0].ID, msgs[1].ID, msgs[3].ID}))
+	for idx, msg := range msgs {
+		state, err := messageRepo.GetMessageState(msg.ID)
+		assert.NoError(t, err)
+		switch idx {
+		case 0, 1:
+			assert.Equal(t, sophonTypes.FinalizedMsg, state)
+		case 2:
+			assert.Equal(t, types.OnChainMsg, state)
+		default:
+			assert.Equal(t, types.FillMsg, state)
+		}
+	}
+
+	msgList, err = messageRepo.ListChainMessageBelowHeight(102, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgList, 1)
+	assert.Equal(t, msgs[2].ID, msgList[0].ID)
+}
+
 func TestListUnFilledMessage(t *testing.T) {
 	messageRepo := setupRepo(t).MessageRepo()
 
@@ -639,6 +772,29 @@ func TestUpdateMessageInfoByCid(t *testing.T) {
 	assert.Equal(t, tsKeyStr, msg2.TipSetKey.String())
 }
 
+func TestUpdateMessageInfoByID(t *testing.T) {
+	messageRepo := setupRepo(t).MessageRepo()
+
+	msg := testhelper.NewMessage()
+	assert.NoError(t, messageRepo.CreateMessage(msg))
+
+	rec := &venustypes.MessageReceipt{
+		ExitCode: 0,
+		Return:   []byte{'g', 'd'},
+	}
+	tsKey := venustypes.NewTipSetKey(msg.Message.Cid())
+	height := abi.ChainEpoch(10)
+	err := messageRepo.UpdateMessageInfoByID(msg.ID, rec, height, types.OnChainMsg, tsKey)
+	assert.NoError(t, err)
+
+	msg2, err := messageRepo.GetMessageByUid(msg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(height), msg2.Height)
+	assert.Equal(t, rec, msg2.Receipt)
+	assert.Equal(t, types.OnChainMsg, msg2.State)
+	assert.Equal(t, tsKey, msg2.TipSetKey)
+}
+
 func TestUpdateMessageStateByCid(t *testing.T) {
 	messageRepo := setupRepo(t).MessageRepo()
 
@@ -751,3 +907,11 @@ func checkMsgList(t *testing.T, msgs []*types.Message, msgsMap map[string]interf
 		testhelper.Equal(t, msgsMap[msg.ID], msg)
 	}
 }
+
+func msgIDs(msgs []*types.Message) []string {
+	ids := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
@@ -0,0 +1,200 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/filestore"
+	"github.com/ipfs-force-community/sophon-messager/models/migration"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// tables the current model structs, the schema created by migrations must be the same as AutoMigrate of them
+func tables() []interface{} {
+	return []interface{}{sqliteMessage{}, sqliteActorCfg{}, sqliteAddress{}, sqliteSharedParams{}, sqliteNode{}, sqliteAddressConfig{},
+		sqliteNotification{}, sqliteFeeBumpPolicy{}, sqliteFeeBumpRecord{}, sqliteMessageRevision{}, sqliteMessageFee{},
+		sqliteAddressSpend{}, sqliteSignerPolicy{}, sqliteMsigProposal{}, sqliteMessageBatchItem{}, sqliteContractABI{},
+		sqliteExitCodeRule{}, sqliteMessageRetry{}, sqliteTipset{}, sqliteLease{}, sqliteAddressShard{}}
+}
+
+func baselineTables() []interface{} {
+	return []interface{}{sqliteMessageV1{}, sqliteActorCfgV1{}, sqliteAddressV1{}, sqliteSharedParamsV1{}, sqliteNodeV1{}}
+}
+
+func openMigrationRepo(t *testing.T) repo.Repo {
+	r, err := OpenSqlite(filestore.NewMockFileStore(t.TempDir()))
+	require.NoError(t, err)
+	return r
+}
+
+type columnInfo struct {
+	Name    string
+	Type    string
+	NotNull bool
+	Dflt    *string
+	Pk      int
+}
+
+type indexInfo struct {
+	Name    string
+	Unique  bool
+	Columns string
+}
+
+func tableName(t *testing.T, db *gorm.DB, model interface{}) string {
+	stmt := &gorm.Statement{DB: db}
+	require.NoError(t, stmt.Parse(model))
+	return stmt.Schema.Table
+}
+
+func tableSchema(t *testing.T, db *gorm.DB, table string) ([]columnInfo, []indexInfo) {
+	var columns []columnInfo
+	require.NoError(t, db.Raw(`SELECT name, type, "notnull" AS not_null, dflt_value AS dflt, pk FROM pragma_table_info(?) ORDER BY name`,
+		table).Scan(&columns).Error)
+	var indexes []indexInfo
+	require.NoError(t, db.Raw(`SELECT il.name AS name, il."unique" AS "unique", group_concat(ii.name) AS columns
+FROM pragma_index_list(?) il, pragma_index_info(il.name) ii GROUP BY il.name ORDER BY il.name`, table).Scan(&indexes).Error)
+	return columns, indexes
+}
+
+func TestMigrationsSchema(t *testing.T) {
+	ctx := context.Background()
+	migrated := openMigrationRepo(t)
+	require.NoError(t, migrated.SchemaMigrator().Migrate(ctx))
+	autoMigrated := openMigrationRepo(t)
+	require.NoError(t, autoMigrated.GetDb().AutoMigrate(tables()...))
+
+	for _, table := range tables() {
+		name := tableName(t, migrated.GetDb(), table)
+		columns, indexes := tableSchema(t, migrated.GetDb(), name)
+		expectColumns, expectIndexes := tableSchema(t, autoMigrated.GetDb(), name)
+		assert.NotEmpty(t, columns, name)
+		assert.Equal(t, expectColumns, columns, name)
+		assert.Equal(t, expectIndexes, indexes, name)
+	}
+}
+
+func TestMigrateFromBaseline(t *testing.T) {
+	ctx := context.Background()
+	r := openMigrationRepo(t)
+
+	// the database created by AutoMigrate of the versions before migrations
+	require.NoError(t, r.GetDb().AutoMigrate(baselineTables()...))
+	msg := testhelper.NewMessage()
+	now := time.Now()
+	require.NoError(t, r.GetDb().Create(&sqliteMessageV1{
+		ID:        msg.ID,
+		From:      msg.From.String(),
+		To:        msg.To.String(),
+		Value:     "0",
+		Receipt:   &msgReceiptV1{ExitCode: -1},
+		Meta:      &msgMetaV1{},
+		State:     int(types.UnFillMsg),
+		IsDeleted: repo.NotDeleted,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error)
+
+	migrator := r.SchemaMigrator()
+	require.NoError(t, migrator.Migrate(ctx))
+	version, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, migrator.LatestVersion(), version)
+
+	// the data is kept and the new columns have their defaults
+	res, err := r.MessageRepo().GetMessageByUid(msg.ID)
+	require.NoError(t, err)
+	assert.Equal(t, msg.ID, res.ID)
+	assert.Equal(t, types.UnFillMsg, res.State)
+	require.NoError(t, r.MessageRepo().UpdateMessagePriority(msg.ID, 1))
+	priorities, err := r.MessageRepo().ListUnChainMessagePriority(msg.From)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{msg.ID: 1}, priorities)
+}
+
+func TestMigrateFromAutoMigrate(t *testing.T) {
+	ctx := context.Background()
+	r := openMigrationRepo(t)
+
+	// the database created by AutoMigrate of the current model structs
+	require.NoError(t, r.GetDb().AutoMigrate(tables()...))
+	addr := testhelper.RandAddresses(t, 1)[0]
+	_, err := r.AddressShardRepo().ClaimAddressShard(ctx, addr, "a", nil)
+	require.NoError(t, err)
+
+	migrator := r.SchemaMigrator()
+	version, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), version)
+
+	require.NoError(t, migrator.Migrate(ctx))
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, migrator.LatestVersion(), version)
+
+	// the data is kept
+	shards, err := r.AddressShardRepo().ListAddressShard(ctx)
+	require.NoError(t, err)
+	require.Len(t, shards, 1)
+	assert.Equal(t, &sophonTypes.AddressShard{Addr: addr, Holder: "a", Token: 1, CreatedAt: shards[0].CreatedAt,
+		UpdatedAt: shards[0].UpdatedAt}, shards[0])
+}
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	r := openMigrationRepo(t)
+	db := r.GetDb()
+	migrator := r.SchemaMigrator()
+	require.NoError(t, migrator.Migrate(ctx))
+
+	baseline := openMigrationRepo(t)
+	require.NoError(t, baseline.GetDb().AutoMigrate(baselineTables()...))
+
+	// rollback to the baseline drops the new tables and columns only
+	require.NoError(t, migrator.Rollback(ctx, len(migrations)-1))
+	version, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+	for _, table := range tables() {
+		name := tableName(t, db, table)
+		isBaseline := false
+		for _, baselineTable := range baselineTables() {
+			if tableName(t, db, baselineTable) == name {
+				isBaseline = true
+			}
+		}
+		assert.Equal(t, isBaseline, db.Migrator().HasTable(name), name)
+		if isBaseline {
+			columns, indexes := tableSchema(t, db, name)
+			expectColumns, expectIndexes := tableSchema(t, baseline.GetDb(), name)
+			assert.Equal(t, expectColumns, columns, name)
+			assert.Equal(t, expectIndexes, indexes, name)
+		}
+	}
+
+	// the initial migration can not be reverted
+	err = migrator.Rollback(ctx, 1)
+	assert.ErrorIs(t, err, migration.ErrIrreversible)
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+	for _, table := range baselineTables() {
+		assert.True(t, db.Migrator().HasTable(table))
+	}
+
+	require.NoError(t, migrator.Migrate(ctx))
+	for _, table := range tables() {
+		assert.True(t, db.Migrator().HasTable(table))
+	}
+	assert.True(t, db.Migrator().HasColumn(&sqliteMessage{}, "Priority"))
+	assert.True(t, db.Migrator().HasColumn(&sqliteActorCfg{}, "Preflight"))
+}
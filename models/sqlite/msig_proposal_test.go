@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMsigProposal(t *testing.T) {
+	ctx := context.Background()
+	proposalRepo := setupRepo(t).MsigProposalRepo()
+
+	msigs := []address.Address{testutil.AddressProvider()(t), testutil.AddressProvider()(t)}
+	proposals := []*types.MsigProposal{
+		{
+			ID:           "p1",
+			Msig:         msigs[0],
+			Proposer:     testutil.AddressProvider()(t),
+			To:           testutil.AddressProvider()(t),
+			Value:        big.NewInt(100),
+			Method:       builtin.MethodSend,
+			ProposeMsgID: "m1",
+			TxnID:        types.NoTxnID,
+			State:        types.MsigProposalPending,
+		},
+		{
+			ID:           "p2",
+			Msig:         msigs[0],
+			Proposer:     testutil.AddressProvider()(t),
+			To:           testutil.AddressProvider()(t),
+			Value:        big.Zero(),
+			Method:       builtin.MethodsMiner.ChangeOwnerAddress,
+			Params:       []byte{1, 2, 3},
+			ProposeMsgID: "m2",
+			TxnID:        types.NoTxnID,
+			State:        types.MsigProposalPending,
+		},
+		{
+			ID:           "p3",
+			Msig:         msigs[1],
+			Proposer:     testutil.AddressProvider()(t),
+			To:           testutil.AddressProvider()(t),
+			Value:        big.Zero(),
+			ProposeMsgID: "m3",
+			TxnID:        0,
+			State:        types.MsigProposalProposed,
+		},
+	}
+
+	t.Run("SaveMsigProposal", func(t *testing.T) {
+		for _, proposal := range proposals {
+			assert.NoError(t, proposalRepo.SaveMsigProposal(ctx, proposal))
+		}
+
+		res, err := proposalRepo.GetMsigProposal(ctx, "p2")
+		assert.NoError(t, err)
+		assert.Equal(t, proposals[1].Msig, res.Msig)
+		assert.Equal(t, proposals[1].To, res.To)
+		assert.Equal(t, proposals[1].Params, res.Params)
+		assert.Equal(t, proposals[1].Method, res.Method)
+		assert.Equal(t, types.NoTxnID, res.TxnID)
+		assert.Empty(t, res.Approvers)
+
+		// the propose message landed on chain and approved by another signer
+		proposals[1].TxnID = 5
+		proposals[1].State = types.MsigProposalProposed
+		proposals[1].Approvers = []address.Address{testutil.AddressProvider()(t)}
+		assert.NoError(t, proposalRepo.SaveMsigProposal(ctx, proposals[1]))
+		res2, err := proposalRepo.GetMsigProposal(ctx, "p2")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), res2.TxnID)
+		assert.Equal(t, types.MsigProposalProposed, res2.State)
+		assert.Equal(t, proposals[1].Approvers, res2.Approvers)
+		assert.Equal(t, res.CreatedAt.Unix(), res2.CreatedAt.Unix())
+	})
+
+	t.Run("GetMsigProposal", func(t *testing.T) {
+		res, err := proposalRepo.GetMsigProposalByProposeMsg(ctx, "m1")
+		assert.NoError(t, err)
+		assert.Equal(t, "p1", res.ID)
+		assert.Equal(t, proposals[0].Value, res.Value)
+
+		res, err = proposalRepo.GetMsigProposalByTxnID(ctx, msigs[0], 5)
+		assert.NoError(t, err)
+		assert.Equal(t, "p2", res.ID)
+
+		_, err = proposalRepo.GetMsigProposalByTxnID(ctx, msigs[1], 5)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("ListMsigProposal", func(t *testing.T) {
+		res, err := proposalRepo.ListMsigProposal(ctx, address.Undef)
+		assert.NoError(t, err)
+		assert.Len(t, res, 3)
+
+		res, err = proposalRepo.ListMsigProposal(ctx, msigs[0])
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+	})
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestNotification(t *testing.T) {
+	ctx := context.Background()
+	notificationRepo := setupRepo(t).NotificationRepo()
+
+	now := time.Now()
+	newNotification := func(notificationID, url string, nextAttemptAt time.Time) *types.OutboxNotification {
+		return &types.OutboxNotification{
+			ID:             venusTypes.NewUUID().String(),
+			NotificationID: notificationID,
+			URL:            url,
+			Payload:        []byte(`{"ID":"` + notificationID + `"}`),
+			State:          types.OutboxPending,
+			NextAttemptAt:  nextAttemptAt,
+		}
+	}
+	notifications := []*types.OutboxNotification{
+		newNotification("n1", "http://a", now.Add(-time.Second)),
+		newNotification("n1", "http://b", now.Add(-2*time.Second)),
+		newNotification("n2", "http://a", now.Add(time.Minute)),
+	}
+
+	t.Run("SaveNotifications", func(t *testing.T) {
+		assert.NoError(t, notificationRepo.SaveNotifications(ctx, notifications))
+		// the same notification and url is ignored
+		assert.NoError(t, notificationRepo.SaveNotifications(ctx, []*types.OutboxNotification{newNotification("n1", "http://a", now)}))
+		assert.NoError(t, notificationRepo.SaveNotifications(ctx, nil))
+
+		pending, err := notificationRepo.ListPendingNotifications(ctx, now.Add(time.Hour), 10)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 3)
+	})
+
+	t.Run("ListPendingNotifications", func(t *testing.T) {
+		pending, err := notificationRepo.ListPendingNotifications(ctx, now, 10)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 2)
+		// order by next attempt time
+		assert.Equal(t, notifications[1].ID, pending[0].ID)
+		assert.Equal(t, notifications[0].ID, pending[1].ID)
+		assert.Equal(t, notifications[1].Payload, pending[0].Payload)
+
+		pending, err = notificationRepo.ListPendingNotifications(ctx, now, 1)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+	})
+
+	t.Run("UpdateNotificationDelivery", func(t *testing.T) {
+		sent := notifications[0]
+		sent.State = types.OutboxSent
+		sent.Attempts = 1
+		assert.NoError(t, notificationRepo.UpdateNotificationDelivery(ctx, sent))
+
+		retry := notifications[1]
+		retry.Attempts = 1
+		retry.LastError = "unexpected status code 500"
+		retry.NextAttemptAt = now.Add(time.Minute)
+		assert.NoError(t, notificationRepo.UpdateNotificationDelivery(ctx, retry))
+
+		pending, err := notificationRepo.ListPendingNotifications(ctx, now, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+
+		pending, err = notificationRepo.ListPendingNotifications(ctx, now.Add(time.Hour), 10)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 2)
+		for _, n := range pending {
+			if n.ID == retry.ID {
+				assert.Equal(t, 1, n.Attempts)
+				assert.Equal(t, retry.LastError, n.LastError)
+			}
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestSignerPolicy(t *testing.T) {
+	ctx := context.Background()
+	policyRepo := setupRepo(t).SignerPolicyRepo()
+
+	signers := []address.Address{testutil.AddressProvider()(t), testutil.AddressProvider()(t)}
+	policies := []*types.SignerPolicy{
+		{
+			ID:             "p1",
+			Signer:         signers[0],
+			ActorType:      "storageminer",
+			Methods:        []abi.MethodNum{6, 7, 26},
+			OwnedMinerOnly: true,
+			Description:    "miner methods",
+		},
+		{
+			ID:         "p2",
+			Signer:     signers[0],
+			To:         []address.Address{testutil.AddressProvider()(t), testutil.AddressProvider()(t)},
+			AllowValue: true,
+		},
+		{
+			ID:     "p3",
+			Signer: signers[1],
+		},
+	}
+
+	t.Run("SaveSignerPolicy", func(t *testing.T) {
+		for _, policy := range policies {
+			assert.NoError(t, policyRepo.SaveSignerPolicy(ctx, policy))
+		}
+
+		res, err := policyRepo.GetSignerPolicy(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, policies[0].Signer, res.Signer)
+		assert.Equal(t, policies[0].Methods, res.Methods)
+		assert.Empty(t, res.To)
+		assert.True(t, res.OwnedMinerOnly)
+		assert.False(t, res.AllowValue)
+
+		// update the existing policy
+		policies[0].Methods = []abi.MethodNum{6}
+		policies[0].AllowValue = true
+		assert.NoError(t, policyRepo.SaveSignerPolicy(ctx, policies[0]))
+		res2, err := policyRepo.GetSignerPolicy(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, policies[0].Methods, res2.Methods)
+		assert.True(t, res2.AllowValue)
+		assert.Equal(t, "miner methods", res2.Description)
+		assert.Equal(t, res.CreatedAt.Unix(), res2.CreatedAt.Unix())
+	})
+
+	t.Run("ListSignerPolicy", func(t *testing.T) {
+		res, err := policyRepo.ListSignerPolicy(ctx, address.Undef)
+		assert.NoError(t, err)
+		assert.Len(t, res, 3)
+
+		res, err = policyRepo.ListSignerPolicy(ctx, signers[0])
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		for _, policy := range res {
+			if policy.ID == "p2" {
+				assert.Equal(t, policies[1].To, policy.To)
+				assert.Empty(t, policy.Methods)
+			}
+		}
+	})
+
+	t.Run("DeleteSignerPolicy", func(t *testing.T) {
+		assert.NoError(t, policyRepo.DeleteSignerPolicy(ctx, "p3"))
+		_, err := policyRepo.GetSignerPolicy(ctx, "p3")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		assert.True(t, errors.Is(policyRepo.DeleteSignerPolicy(ctx, "p3"), gorm.ErrRecordNotFound))
+	})
+}
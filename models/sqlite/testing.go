@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,7 +17,7 @@ func setupRepo(t *testing.T) repo.Repo {
 	// assert.NoError(t, fs.ReplaceConfig(cfg))
 	sqliteRepo, err := OpenSqlite(fs)
 	assert.NoError(t, err)
-	assert.NoError(t, sqliteRepo.AutoMigrate())
+	assert.NoError(t, sqliteRepo.SchemaMigrator().Migrate(context.Background()))
 
 	return sqliteRepo
 }
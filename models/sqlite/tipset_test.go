@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	venustypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+)
+
+func TestTipset(t *testing.T) {
+	ctx := context.Background()
+	tsRepo := setupRepo(t).TipsetRepo()
+
+	_, err := tsRepo.GetTipsetNetworkName(ctx)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	res, err := tsRepo.ListTipset(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+
+	var tsList []*venustypes.TipSet
+	for height := abi.ChainEpoch(10); height < 13; height++ {
+		ts, err := testhelper.GenTipset(height, 2, nil)
+		require.NoError(t, err)
+		tsList = append(tsList, ts)
+	}
+	assert.NoError(t, tsRepo.SaveTipset(ctx, "butterflynet", tsList...))
+
+	res, err = tsRepo.ListTipset(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*venustypes.TipSet{tsList[2], tsList[1], tsList[0]}, res)
+
+	// replace the tipset at the same height
+	forked, err := testhelper.GenTipset(12, 1, nil)
+	require.NoError(t, err)
+	assert.NoError(t, tsRepo.SaveTipset(ctx, "mainnet", forked))
+	res, err = tsRepo.ListTipset(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, res, 3)
+	assert.Equal(t, forked, res[0])
+	networkName, err := tsRepo.GetTipsetNetworkName(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "mainnet", networkName)
+
+	assert.NoError(t, tsRepo.DeleteTipsetBelowHeight(ctx, 12))
+	res, err = tsRepo.ListTipset(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*venustypes.TipSet{forked}, res)
+}
@@ -30,7 +30,7 @@ func TestMainNodePublishMessage(t *testing.T) {
 	fs := filestore.NewMockFileStore(t.TempDir())
 	sqliteRepo, err := sqlite.OpenSqlite(fs)
 	assert.NoError(t, err)
-	assert.NoError(t, sqliteRepo.AutoMigrate())
+	assert.NoError(t, sqliteRepo.SchemaMigrator().Migrate(context.Background()))
 
 	rpcPublisher := NewRpcPublisher(ctx, mainNode, nil, false, sqliteRepo.MessageRepo())
 	publisher := NewMergePublisher(ctx, rpcPublisher)
@@ -72,7 +72,7 @@ func TestMultiNodePublishMessage(t *testing.T) {
 	fs := filestore.NewMockFileStore(t.TempDir())
 	sqliteRepo, err := sqlite.OpenSqlite(fs)
 	assert.NoError(t, err)
-	assert.NoError(t, sqliteRepo.AutoMigrate())
+	assert.NoError(t, sqliteRepo.SchemaMigrator().Migrate(context.Background()))
 	nodeProvider := mocks.NewMockNodeRepo(ctrl)
 	rpcPublisher := NewRpcPublisher(ctx, mainNode, nodeProvider, true, sqliteRepo.MessageRepo())
 
@@ -213,7 +213,7 @@ func TestPublishMessageFailed(t *testing.T) {
 	fs := filestore.NewMockFileStore(t.TempDir())
 	sqliteRepo, err := sqlite.OpenSqlite(fs)
 	assert.NoError(t, err)
-	assert.NoError(t, sqliteRepo.AutoMigrate())
+	assert.NoError(t, sqliteRepo.SchemaMigrator().Migrate(context.Background()))
 
 	rpcPublisher := NewRpcPublisher(ctx, mainNode, nil, false, sqliteRepo.MessageRepo())
 	publisher := NewMergePublisher(ctx, rpcPublisher)
@@ -16,6 +16,7 @@ import (
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
 
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 var errAddressNotExists = errors.New("address not exists")
@@ -37,6 +38,12 @@ type IAddressService interface {
 	ActiveAddress(ctx context.Context, addr address.Address) error
 	SetSelectMsgNum(ctx context.Context, addr address.Address, num uint64) error
 	SetFeeParams(ctx context.Context, params *types.AddressSpec) error
+	SetSelectStrategy(ctx context.Context, addr address.Address, strategy string) error
+	GetAddressConfig(ctx context.Context, addr address.Address) (*sophonTypes.AddressConfig, error)
+	SetAutoFillNonceGap(ctx context.Context, addr address.Address, enable bool) error
+	SetBalanceReserve(ctx context.Context, addr address.Address, reserve big.Int) error
+	SetSpendBudget(ctx context.Context, addr address.Address, budget *sophonTypes.SpendBudget) error
+	SetBatchSend(ctx context.Context, addr address.Address, enable bool) error
 	ActiveAddresses(ctx context.Context) map[address.Address]struct{}
 	GetAccountsOfSigner(ctx context.Context, addr address.Address) ([]string, error)
 }
@@ -183,6 +190,128 @@ func (addressService *AddressService) SetFeeParams(ctx context.Context, params *
 	return addressService.repo.AddressRepo().UpdateFeeParams(ctx, params.Address, params.GasOverEstimation, params.GasOverPremium, maxFee, gasFeeCap, baseFee)
 }
 
+func (addressService *AddressService) SetSelectStrategy(ctx context.Context, addr address.Address, strategy string) error {
+	has, err := addressService.repo.AddressRepo().HasAddress(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return errAddressNotExists
+	}
+	// empty strategy means use the global config
+	if len(strategy) != 0 {
+		if _, err := GetSelectionStrategy(strategy); err != nil {
+			return err
+		}
+	}
+	if err := addressService.repo.AddressConfigRepo().UpdateSelectStrategy(ctx, addr, strategy); err != nil {
+		return err
+	}
+	log.Infof("set select strategy: %s %s", addr.String(), strategy)
+
+	return nil
+}
+
+func (addressService *AddressService) SetAutoFillNonceGap(ctx context.Context, addr address.Address, enable bool) error {
+	has, err := addressService.repo.AddressRepo().HasAddress(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return errAddressNotExists
+	}
+	if err := addressService.repo.AddressConfigRepo().UpdateAutoFillNonceGap(ctx, addr, enable); err != nil {
+		return err
+	}
+	log.Infof("set auto fill nonce gap: %s %v", addr.String(), enable)
+
+	return nil
+}
+
+func (addressService *AddressService) SetBalanceReserve(ctx context.Context, addr address.Address, reserve big.Int) error {
+	has, err := addressService.repo.AddressRepo().HasAddress(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return errAddressNotExists
+	}
+	if reserve.Nil() {
+		reserve = big.Zero()
+	}
+	if reserve.LessThan(big.Zero()) {
+		return fmt.Errorf("balance reserve %s must not be negative", reserve)
+	}
+	if err := addressService.repo.AddressConfigRepo().UpdateBalanceReserve(ctx, addr, reserve); err != nil {
+		return err
+	}
+	log.Infof("set balance reserve: %s %s", addr.String(), reserve)
+
+	return nil
+}
+
+func (addressService *AddressService) SetSpendBudget(ctx context.Context, addr address.Address, budget *sophonTypes.SpendBudget) error {
+	has, err := addressService.repo.AddressRepo().HasAddress(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return errAddressNotExists
+	}
+	if budget.MaxValue.Nil() {
+		budget.MaxValue = big.Zero()
+	}
+	if budget.MaxGasFee.Nil() {
+		budget.MaxGasFee = big.Zero()
+	}
+	if budget.MaxValue.LessThan(big.Zero()) || budget.MaxGasFee.LessThan(big.Zero()) {
+		return fmt.Errorf("max value %s and max gas fee %s must not be negative", budget.MaxValue, budget.MaxGasFee)
+	}
+	if budget.Window < 0 || budget.WindowEpochs < 0 {
+		return fmt.Errorf("window %s and window epochs %d must not be negative", budget.Window, budget.WindowEpochs)
+	}
+	if err := addressService.repo.AddressConfigRepo().UpdateSpendBudget(ctx, addr, budget); err != nil {
+		return err
+	}
+	log.Infof("set spend budget: %s max value %s max gas fee %s window %s window epochs %d", addr.String(),
+		budget.MaxValue, budget.MaxGasFee, budget.Window, budget.WindowEpochs)
+
+	return nil
+}
+
+func (addressService *AddressService) SetBatchSend(ctx context.Context, addr address.Address, enable bool) error {
+	has, err := addressService.repo.AddressRepo().HasAddress(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return errAddressNotExists
+	}
+	if err := addressService.repo.AddressConfigRepo().UpdateBatchSend(ctx, addr, enable); err != nil {
+		return err
+	}
+	log.Infof("set batch send: %s %v", addr.String(), enable)
+
+	return nil
+}
+
+// GetAddressConfig returns an empty config when the address has not been configured
+func (addressService *AddressService) GetAddressConfig(ctx context.Context, addr address.Address) (*sophonTypes.AddressConfig, error) {
+	addrCfg, err := addressService.repo.AddressConfigRepo().GetAddressConfig(ctx, addr)
+	if err != nil {
+		if errors.Is(err, repo.ErrRecordNotFound) {
+			return &sophonTypes.AddressConfig{
+				Addr:           addr,
+				BalanceReserve: big.Zero(),
+				SpendBudget:    sophonTypes.SpendBudget{MaxValue: big.Zero(), MaxGasFee: big.Zero()},
+			}, nil
+		}
+		return nil, err
+	}
+
+	return addrCfg, nil
+}
+
 func (addressService *AddressService) ActiveAddresses(ctx context.Context) map[address.Address]struct{} {
 	addrs := make(map[address.Address]struct{})
 	addrList, err := addressService.ListActiveAddress(ctx)
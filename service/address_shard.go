@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+const (
+	// shardMemberPrefix the prefix of the lease names of the instances joining the sharding
+	shardMemberPrefix = "shard-member/"
+	// shardReplicas the virtual nodes of each instance in the hash ring, spreads the addresses evenly
+	shardReplicas = 100
+)
+
+var errNotInShard = errors.New("address is not in the shard of this instance")
+
+var shardLog = logging.Logger("address-shard")
+
+// hashRing the consistent hashing of addresses to instances, only the addresses next to an instance on the ring
+// are moved when it joins or leaves
+type hashRing struct {
+	hashes []uint32
+	nodes  map[uint32]string
+}
+
+// newHashRing members must be in the same order on all instances, so that they build the same ring
+func newHashRing(members []string) *hashRing {
+	r := &hashRing{
+		hashes: make([]uint32, 0, len(members)*shardReplicas),
+		nodes:  make(map[uint32]string, len(members)*shardReplicas),
+	}
+	for _, member := range members {
+		for i := 0; i < shardReplicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(member + "#" + strconv.Itoa(i)))
+			if _, ok := r.nodes[h]; ok {
+				continue
+			}
+			r.hashes = append(r.hashes, h)
+			r.nodes[h] = member
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool {
+		return r.hashes[i] < r.hashes[j]
+	})
+
+	return r
+}
+
+// get returns the member of key, empty if there is no member
+func (r *hashRing) get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	idx := sort.Search(len(r.hashes), func(i int) bool {
+		return r.hashes[i] >= h
+	})
+	if idx == len(r.hashes) {
+		idx = 0
+	}
+	return r.nodes[r.hashes[idx]]
+}
+
+// addressSharder splits the active addresses among the instances sharing the same database. Each instance renews
+// a member lease and the addresses are assigned to the live members by consistent hashing, the assignment is saved
+// in db. An address is claimed only after the previous holder released it or the lease of holder expired, and the
+// token of shard is checked when assigning nonces, so the messages of an address are never selected by two instances.
+// A nil addressSharder means sharding is disabled and the instance holds all addresses.
+type addressSharder struct {
+	repo           repo.Repo
+	addressService *AddressService
+	cfg            config.ShardingConfig
+	holder         string
+
+	lk sync.RWMutex
+	// member the latest member lease, the instance holds no address after it expired
+	member *sophonTypes.Lease
+	// owned the tokens of the addresses held by the instance
+	owned map[address.Address]uint64
+}
+
+func newAddressSharder(repo repo.Repo, addressService *AddressService, cfg config.ShardingConfig, holder string) *addressSharder {
+	if cfg.MemberTimeout <= 0 {
+		cfg.MemberTimeout = config.DefaultShardMemberTimeout
+	}
+	if cfg.HeartbeatInterval <= 0 || cfg.HeartbeatInterval >= cfg.MemberTimeout {
+		cfg.HeartbeatInterval = cfg.MemberTimeout / 3
+	}
+
+	return &addressSharder{
+		repo:           repo,
+		addressService: addressService,
+		cfg:            cfg,
+		holder:         holder,
+		owned:          make(map[address.Address]uint64),
+	}
+}
+
+func (s *addressSharder) owns(addr address.Address) bool {
+	if s == nil {
+		return true
+	}
+	s.lk.RLock()
+	defer s.lk.RUnlock()
+	if s.member == nil || s.member.IsExpired(time.Now()) {
+		return false
+	}
+	_, ok := s.owned[addr]
+	return ok
+}
+
+func (s *addressSharder) run(ctx context.Context) {
+	tm := time.NewTicker(s.cfg.HeartbeatInterval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			shardLog.Warnf("stop address sharding: %v", ctx.Err())
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.release(releaseCtx)
+			cancel()
+			return
+		case <-tm.C:
+			if err := s.rebalance(ctx); err != nil {
+				shardLog.Errorf("rebalance addresses failed: %v", err)
+			}
+		}
+	}
+}
+
+// rebalance renews the member lease, releases the addresses assigned to other instances and claims the addresses
+// assigned to the instance
+func (s *addressSharder) rebalance(ctx context.Context) error {
+	leaseRepo := s.repo.LeaseRepo()
+	shardRepo := s.repo.AddressShardRepo()
+
+	member, err := leaseRepo.AcquireLease(ctx, shardMemberPrefix+s.holder, s.holder, s.cfg.MemberTimeout)
+	if err != nil {
+		return fmt.Errorf("renew member lease failed: %w", err)
+	}
+	leases, err := leaseRepo.ListLease(ctx, shardMemberPrefix)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	members := make([]string, 0, len(leases))
+	live := make(map[string]struct{}, len(leases))
+	for _, lease := range leases {
+		if !lease.IsExpired(now) {
+			members = append(members, lease.Holder)
+			live[lease.Holder] = struct{}{}
+		}
+	}
+	sort.Strings(members)
+	ring := newHashRing(members)
+
+	activeAddrs, err := s.addressService.ListActiveAddress(ctx)
+	if err != nil {
+		return err
+	}
+	// the active addresses assigned to the instance
+	assigned := make(map[address.Address]struct{}, len(activeAddrs))
+	for _, addrInfo := range activeAddrs {
+		if ring.get(addrInfo.Addr.String()) == s.holder {
+			assigned[addrInfo.Addr] = struct{}{}
+		}
+	}
+	shards, err := shardRepo.ListAddressShard(ctx)
+	if err != nil {
+		return err
+	}
+	shardMap := make(map[address.Address]*sophonTypes.AddressShard, len(shards))
+	for _, shard := range shards {
+		shardMap[shard.Addr] = shard
+	}
+
+	// stop selecting the addresses before releasing them
+	s.lk.Lock()
+	s.member = member
+	for addr := range s.owned {
+		if _, ok := assigned[addr]; !ok {
+			delete(s.owned, addr)
+		}
+	}
+	s.lk.Unlock()
+	for _, shard := range shards {
+		if _, ok := assigned[shard.Addr]; !ok && shard.Holder == s.holder {
+			if err := shardRepo.ReleaseAddressShard(ctx, shard.Addr, s.holder); err != nil {
+				return fmt.Errorf("release %s failed: %w", shard.Addr, err)
+			}
+			shardLog.Infof("release address %s", shard.Addr)
+		}
+	}
+
+	owned := make(map[address.Address]uint64, len(assigned))
+	for addr := range assigned {
+		shard, ok := shardMap[addr]
+		if ok && shard.Holder == s.holder {
+			owned[addr] = shard.Token
+			continue
+		}
+		var from []string
+		if ok {
+			// wait for the live holder to release it
+			if _, isLive := live[shard.Holder]; isLive {
+				continue
+			}
+			from = []string{shard.Holder}
+		}
+		shard, err := shardRepo.ClaimAddressShard(ctx, addr, s.holder, from)
+		if err != nil {
+			return fmt.Errorf("claim %s failed: %w", addr, err)
+		}
+		if shard.Holder == s.holder {
+			shardLog.Infof("claim address %s, token %d", addr, shard.Token)
+			owned[addr] = shard.Token
+		}
+	}
+
+	s.lk.Lock()
+	s.owned = owned
+	s.lk.Unlock()
+
+	return nil
+}
+
+// release releases all addresses and the member lease, so that the others take over without waiting for it to expire
+func (s *addressSharder) release(ctx context.Context) {
+	s.lk.Lock()
+	owned := s.owned
+	s.owned = make(map[address.Address]uint64)
+	s.member = nil
+	s.lk.Unlock()
+
+	for addr := range owned {
+		if err := s.repo.AddressShardRepo().ReleaseAddressShard(ctx, addr, s.holder); err != nil {
+			shardLog.Errorf("release %s failed: %v", addr, err)
+		}
+	}
+	if err := s.repo.LeaseRepo().ReleaseLease(ctx, shardMemberPrefix+s.holder, s.holder); err != nil {
+		shardLog.Errorf("release member lease failed: %v", err)
+	}
+}
+
+// checkFence returns an error if addr was claimed by another instance. It must be called in the transaction which
+// assigns nonces, the shard is locked so that it can't be claimed until the transaction ends.
+func (s *addressSharder) checkFence(ctx context.Context, txRepo repo.TxRepo, addr address.Address) error {
+	s.lk.RLock()
+	token, ok := s.owned[addr]
+	s.lk.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", errNotInShard, addr)
+	}
+
+	shard, err := txRepo.AddressShardRepo().LockAddressShard(ctx, addr)
+	if err != nil {
+		return fmt.Errorf("lock shard of %s failed: %w", addr, err)
+	}
+	if shard.Holder != s.holder || shard.Token != token {
+		return fmt.Errorf("%w: %s is held by %s, token %d", errNotInShard, addr, shard.Holder, shard.Token)
+	}
+	return nil
+}
+
+func (ms *MessageService) ListAddressShard(ctx context.Context) ([]*sophonTypes.AddressShard, error) {
+	return ms.repo.AddressShardRepo().ListAddressShard(ctx)
+}
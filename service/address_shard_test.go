@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+)
+
+func TestHashRing(t *testing.T) {
+	assert.Empty(t, newHashRing(nil).get("f01000"))
+
+	ring := newHashRing([]string{"a", "b", "c"})
+	ring2 := newHashRing([]string{"a", "b"})
+	count := 3000
+	counts := make(map[string]int)
+	for i := 0; i < count; i++ {
+		key := fmt.Sprintf("f0%d", i)
+		member := ring.get(key)
+		counts[member]++
+		// only the keys of c are moved after c left
+		if member != "c" {
+			assert.Equal(t, member, ring2.get(key))
+		}
+	}
+	assert.Len(t, counts, 3)
+	for _, c := range counts {
+		assert.Greater(t, c, count/10)
+	}
+}
+
+func TestAddressSharding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := testhelper.ResolveAddrs(t, testhelper.RandAddresses(t, 30))
+	msh.addAddresses(addrs)
+	ms := msh.MessageService
+	require.NoError(t, pushMessage(ctx, ms, genMessages(addrs, len(addrs))))
+
+	cfg := config.ShardingConfig{Enable: true, HeartbeatInterval: time.Second, MemberTimeout: 3 * time.Second}
+	a := newAddressSharder(ms.repo, ms.addressService, cfg, "a")
+	b := newAddressSharder(ms.repo, ms.addressService, cfg, "b")
+	c := newAddressSharder(ms.repo, ms.addressService, cfg, "c")
+	all := []*addressSharder{a, b, c}
+
+	// holders checks every address is held by exactly one of sharders
+	holders := func(sharders ...*addressSharder) map[address.Address]string {
+		res := make(map[address.Address]string, len(addrs))
+		for _, addr := range addrs {
+			for _, s := range sharders {
+				if s.owns(addr) {
+					assert.NotContains(t, res, addr, "%s is held by %s and %s", addr, res[addr], s.holder)
+					res[addr] = s.holder
+				}
+			}
+		}
+		assert.Len(t, res, len(addrs))
+		return res
+	}
+	// rebalance runs rounds of rebalance, an address is never held by two sharders
+	rebalance := func(rounds int, sharders ...*addressSharder) {
+		for i := 0; i < rounds; i++ {
+			for _, s := range sharders {
+				require.NoError(t, s.rebalance(ctx))
+				for _, addr := range addrs {
+					owners := 0
+					for _, other := range all {
+						if other.owns(addr) {
+							owners++
+						}
+					}
+					assert.LessOrEqual(t, owners, 1)
+				}
+			}
+		}
+	}
+
+	// the addresses held by others are claimed after they are released
+	rebalance(2, all...)
+	before := holders(all...)
+	for _, s := range all {
+		assert.NotEmpty(t, s.owned)
+	}
+
+	// only select the messages of the addresses held by the instance
+	ms.msgSelectMgr.lk.Lock()
+	ms.msgSelectMgr.shard = a
+	activeAddrs, err := ms.addressService.ListActiveAddress(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ms.msgSelectMgr.tryUpdateWorks(addressMap(activeAddrs)))
+	assert.Len(t, ms.msgSelectMgr.works, len(a.owned))
+	for addr := range ms.msgSelectMgr.works {
+		assert.True(t, a.owns(addr))
+	}
+	ms.msgSelectMgr.lk.Unlock()
+	_, err = ms.msgSelectMgr.FillNonceGap(ctx, addrs[0])
+	if !a.owns(addrs[0]) {
+		assert.True(t, errors.Is(err, errNotInShard))
+	}
+	// the unfill messages are only cleared by the holder of address
+	ms.shard = a
+	for _, addr := range addrs {
+		if !a.owns(addr) {
+			_, err = ms.ClearUnFillMessage(ctx, addr)
+			assert.True(t, errors.Is(err, errNotInShard))
+			break
+		}
+	}
+	ms.shard = nil
+
+	// c leaves, only its addresses are moved
+	c.release(ctx)
+	rebalance(1, a, b)
+	after := holders(a, b)
+	for addr, holder := range before {
+		if holder != c.holder {
+			assert.Equal(t, holder, after[addr])
+		}
+	}
+
+	// b stops renewing, its addresses are claimed by a after its member lease expired
+	tokens := make(map[address.Address]uint64)
+	for addr, token := range b.owned {
+		tokens[addr] = token
+	}
+	require.NotEmpty(t, tokens)
+	time.Sleep(cfg.MemberTimeout)
+	rebalance(1, a)
+	holders(a)
+	for addr, token := range tokens {
+		assert.False(t, b.owns(addr))
+		assert.Equal(t, token+1, a.owned[addr])
+		// the stale holder can't assign nonces
+		assert.True(t, errors.Is(b.checkFence(ctx, ms.repo, addr), errNotInShard))
+		assert.NoError(t, a.checkFence(ctx, ms.repo, addr))
+	}
+}
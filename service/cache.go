@@ -1,13 +1,17 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"sync"
 
+	"github.com/filecoin-project/go-state-types/abi"
 	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
 
-	"github.com/ipfs-force-community/sophon-messager/utils"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
 )
 
 const maxStoreTipsetCount = 900
@@ -26,25 +30,45 @@ func newTipsetCache() *TipsetCache {
 	}
 }
 
-func (tsCache *TipsetCache) Load(path string) error {
-	b, err := os.ReadFile(path)
+// Load replaces the cache with the tipsets saved in db, the network name is kept if there is none in db
+func (tsCache *TipsetCache) Load(ctx context.Context, tsRepo repo.TipsetRepo) error {
+	list, err := tsRepo.ListTipset(ctx)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
 		return err
 	}
-	var tmp TipsetCache
-	if err := json.Unmarshal(b, &tmp); err != nil {
+	networkName, err := tsRepo.GetTipsetNetworkName(ctx)
+	if err != nil && !errors.Is(err, repo.ErrRecordNotFound) {
 		return err
 	}
-	tsCache.Cache = tmp.Cache
-	tsCache.CurrHeight = tmp.CurrHeight
-	tsCache.NetworkName = tmp.NetworkName
+
+	tsCache.l.Lock()
+	defer tsCache.l.Unlock()
+	tsCache.Cache = make(map[int64]*venusTypes.TipSet, maxStoreTipsetCount)
+	for _, ts := range list {
+		tsCache.Cache[int64(ts.Height())] = ts
+	}
+	if len(list) > 0 {
+		tsCache.CurrHeight = int64(list[0].Height())
+	}
+	if len(networkName) != 0 {
+		tsCache.NetworkName = networkName
+	}
 
 	return nil
 }
 
+func (tsCache *TipsetCache) getNetworkName() string {
+	tsCache.l.Lock()
+	defer tsCache.l.Unlock()
+	return tsCache.NetworkName
+}
+
+func (tsCache *TipsetCache) setNetworkName(networkName string) {
+	tsCache.l.Lock()
+	defer tsCache.l.Unlock()
+	tsCache.NetworkName = networkName
+}
+
 func (tsCache *TipsetCache) Add(list ...*venusTypes.TipSet) {
 	tsCache.l.Lock()
 	defer tsCache.l.Unlock()
@@ -78,8 +102,47 @@ func (tsCache *TipsetCache) List() []*venusTypes.TipSet {
 	return list
 }
 
-// Save original data will be cleared
-func (tsCache *TipsetCache) Save(filePath string) error {
+// Save adds the tipsets to cache and saves them to db, the tipsets lower than CurrHeight - maxStoreTipsetCount
+// are removed from both
+func (tsCache *TipsetCache) Save(ctx context.Context, tsRepo repo.TipsetRepo, list ...*venusTypes.TipSet) error {
+	tsCache.Add(list...)
 	tsCache.reduce()
-	return utils.WriteJson(filePath, tsCache)
+	if err := tsRepo.SaveTipset(ctx, tsCache.getNetworkName(), list...); err != nil {
+		return err
+	}
+	return tsRepo.DeleteTipsetBelowHeight(ctx, abi.ChainEpoch(tsCache.CurrHeight-maxStoreTipsetCount))
+}
+
+// importTipsetFile imports the tipsets of file written by the older versions into db if there is no tipset in db,
+// the file is renamed after imported so that it is only imported once
+func importTipsetFile(ctx context.Context, path string, tsRepo repo.TipsetRepo) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	_, err = tsRepo.GetTipsetNetworkName(ctx)
+	if err == nil {
+		log.Infof("tipsets have been saved in db, skip importing %s", path)
+	} else if errors.Is(err, repo.ErrRecordNotFound) {
+		var tmp TipsetCache
+		if err := json.Unmarshal(b, &tmp); err != nil {
+			return err
+		}
+		list := make([]*venusTypes.TipSet, 0, len(tmp.Cache))
+		for _, ts := range tmp.Cache {
+			list = append(list, ts)
+		}
+		if err := tsRepo.SaveTipset(ctx, tmp.NetworkName, list...); err != nil {
+			return fmt.Errorf("save tipsets failed: %v", err)
+		}
+		log.Infof("import %d tipsets from %s", len(list), path)
+	} else {
+		return err
+	}
+
+	return os.Rename(path, path+".imported")
 }
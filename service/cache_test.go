@@ -2,27 +2,33 @@ package service
 
 import (
 	"context"
-	"path/filepath"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/ipfs-force-community/sophon-messager/utils"
 )
 
 func TestReadAndWriteTipset(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	tsCache := &TipsetCache{Cache: map[int64]*venusTypes.TipSet{}, CurrHeight: 0}
+	tsCache := newTipsetCache()
+	tsCache.NetworkName = string(venusTypes.NetworkNameMain)
+	var list []*venusTypes.TipSet
 
 	addTS := func(ts *venusTypes.TipSet) {
-		tsCache.Cache[int64(ts.Height())] = ts
+		list = append(list, ts)
 		tsCache.CurrHeight = int64(ts.Height())
 	}
 
 	msh := newMessageServiceHelper(ctx, t)
+	tsRepo := msh.MessageService.repo.TipsetRepo()
 	count := 2
 	currTS, err := msh.fullNode.ChainHead(ctx)
 	assert.NoError(t, err)
@@ -39,11 +45,57 @@ func TestReadAndWriteTipset(t *testing.T) {
 		time.Sleep(msh.blockDelay)
 	}
 
-	filePath := filepath.Join(t.TempDir(), "tipset.json")
-	assert.NoError(t, tsCache.Save(filePath))
+	assert.NoError(t, tsCache.Save(ctx, tsRepo, list...))
 
 	cache2 := newTipsetCache()
-	err = cache2.Load(filePath)
+	err = cache2.Load(ctx, tsRepo)
 	assert.NoError(t, err)
 	assert.Equal(t, tsCache, cache2)
+
+	// the tipsets lower than CurrHeight - maxStoreTipsetCount are removed
+	tsCache.CurrHeight = int64(currTS.Height()) + maxStoreTipsetCount
+	assert.NoError(t, tsCache.Save(ctx, tsRepo, currTS))
+	cache3 := newTipsetCache()
+	assert.NoError(t, cache3.Load(ctx, tsRepo))
+	assert.Len(t, cache3.Cache, 1)
+	assert.Equal(t, currTS, cache3.Cache[int64(currTS.Height())])
+}
+
+func TestImportTipsetFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t)
+	tsRepo := msh.MessageService.repo.TipsetRepo()
+	path := msh.fsRepo.TipsetFile()
+
+	// no file
+	require.NoError(t, importTipsetFile(ctx, path, tsRepo))
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	require.NoError(t, err)
+	fileCache := &TipsetCache{
+		Cache:       map[int64]*venusTypes.TipSet{int64(ts.Height()): ts},
+		CurrHeight:  int64(ts.Height()),
+		NetworkName: string(venusTypes.NetworkNameMain),
+	}
+	require.NoError(t, utils.WriteJson(path, fileCache))
+
+	require.NoError(t, importTipsetFile(ctx, path, tsRepo))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path + ".imported")
+	assert.NoError(t, err)
+
+	tsCache := newTipsetCache()
+	require.NoError(t, tsCache.Load(ctx, tsRepo))
+	assert.Equal(t, fileCache, tsCache)
+
+	// not imported again if there are tipsets in db
+	fileCache.NetworkName = string(venusTypes.NetworkNameButterfly)
+	require.NoError(t, utils.WriteJson(path, fileCache))
+	require.NoError(t, importTipsetFile(ctx, path, tsRepo))
+	networkName, err := tsRepo.GetTipsetNetworkName(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, string(venusTypes.NetworkNameMain), networkName)
 }
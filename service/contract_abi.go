@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+	"github.com/ipfs-force-community/sophon-messager/utils/ethabi"
+)
+
+// SetContractABI saves the abi of an EVM actor, the address is stored as its ID address,
+// so the abi is found by either the ID address or the f4 address of actor
+func (ms *MessageService) SetContractABI(ctx context.Context, contractABI *sophonTypes.ContractABI) error {
+	if contractABI == nil {
+		return fmt.Errorf("contract abi is nil")
+	}
+	if _, err := ethabi.Parse([]byte(contractABI.ABI)); err != nil {
+		return err
+	}
+	idAddr, err := ms.nodeClient.StateLookupID(ctx, contractABI.Address, venusTypes.EmptyTSK)
+	if err != nil {
+		return fmt.Errorf("look up ID address of %s failed: %w", contractABI.Address, err)
+	}
+	actor, err := ms.nodeClient.StateGetActor(ctx, idAddr, venusTypes.EmptyTSK)
+	if err != nil {
+		return fmt.Errorf("get actor %s failed: %w", idAddr, err)
+	}
+	if !builtin.IsEvmActor(actor.Code) {
+		return fmt.Errorf("%s is not an EVM actor", contractABI.Address)
+	}
+
+	save := *contractABI
+	save.Address = idAddr
+	if err := ms.repo.ContractABIRepo().SaveContractABI(ctx, &save); err != nil {
+		return err
+	}
+	log.Infof("set abi of contract %s", idAddr)
+	return nil
+}
+
+func (ms *MessageService) GetContractABI(ctx context.Context, addr address.Address) (*sophonTypes.ContractABI, error) {
+	idAddr, err := ms.nodeClient.StateLookupID(ctx, addr, venusTypes.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("look up ID address of %s failed: %w", addr, err)
+	}
+	return ms.repo.ContractABIRepo().GetContractABI(ctx, idAddr)
+}
+
+func (ms *MessageService) ListContractABI(ctx context.Context) ([]*sophonTypes.ContractABI, error) {
+	return ms.repo.ContractABIRepo().ListContractABI(ctx)
+}
+
+func (ms *MessageService) DeleteContractABI(ctx context.Context, addr address.Address) error {
+	idAddr, err := ms.nodeClient.StateLookupID(ctx, addr, venusTypes.EmptyTSK)
+	if err != nil {
+		return fmt.Errorf("look up ID address of %s failed: %w", addr, err)
+	}
+	return ms.repo.ContractABIRepo().DeleteContractABI(ctx, idAddr)
+}
+
+// encodeContractCall encodes the json args of the abi method into the params of `InvokeContract`
+func (ms *MessageService) encodeContractCall(ctx context.Context, to address.Address, method abi.MethodNum, abiMethod, args string) ([]byte, error) {
+	if method != builtintypes.MethodsEVM.InvokeContract {
+		return nil, fmt.Errorf("abi method must be called by InvokeContract(%d), not %d", builtintypes.MethodsEVM.InvokeContract, method)
+	}
+	contractABI, err := ms.GetContractABI(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("get abi of %s failed: %w", to, err)
+	}
+	parsed, err := ethabi.Parse([]byte(contractABI.ABI))
+	if err != nil {
+		return nil, err
+	}
+	m, err := parsed.Method(abiMethod)
+	if err != nil {
+		return nil, err
+	}
+	calldata, err := m.EncodeCallJSON(args)
+	if err != nil {
+		return nil, err
+	}
+
+	params := abi.CborBytes(calldata)
+	buf := new(bytes.Buffer)
+	if err := params.MarshalCBOR(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/venus/venus-shared/actors"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+const erc20ABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"event","name":"Transfer","inputs":[]}
+]`
+
+func TestContractABI(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+	from := addrs[0]
+
+	contract, err := address.NewIDAddress(60000)
+	require.NoError(t, err)
+	account, err := address.NewIDAddress(60001)
+	require.NoError(t, err)
+	require.NoError(t, msh.fullNode.AddActors([]address.Address{contract, account}))
+	evmCode, ok := actors.GetActorCodeID(actorstypes.Version12, manifest.EvmKey)
+	require.True(t, ok)
+	require.NoError(t, msh.fullNode.SetActorCode(contract, evmCode))
+
+	// only the valid abi of EVM actor is accepted
+	assert.Error(t, ms.SetContractABI(ctx, &sophonTypes.ContractABI{Address: account, ABI: erc20ABI}))
+	assert.Error(t, ms.SetContractABI(ctx, &sophonTypes.ContractABI{Address: contract, ABI: "{}"}))
+	require.NoError(t, ms.SetContractABI(ctx, &sophonTypes.ContractABI{Address: contract, ABI: erc20ABI, Description: "token"}))
+
+	contractABI, err := ms.GetContractABI(ctx, contract)
+	require.NoError(t, err)
+	assert.Equal(t, erc20ABI, contractABI.ABI)
+	assert.Equal(t, "token", contractABI.Description)
+	list, err := ms.ListContractABI(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	send := func(abiMethod, args string) (string, error) {
+		return ms.SendWithSpec(ctx, sophonTypes.QuickSendParams{
+			QuickSendParams: types.QuickSendParams{
+				From:       from,
+				To:         contract,
+				Val:        big.Zero(),
+				Method:     builtin.MethodsEVM.InvokeContract,
+				Params:     args,
+				ParamsType: types.QuickSendParamsCodecJSON,
+			},
+			ABIMethod: abiMethod,
+		})
+	}
+	id, err := send("transfer", `["0x1111111111111111111111111111111111111111", "100"]`)
+	require.NoError(t, err)
+	msg, err := ms.GetMessageByUid(ctx, id)
+	require.NoError(t, err)
+
+	var calldata abi.CborBytes
+	require.NoError(t, calldata.UnmarshalCBOR(bytes.NewReader(msg.Params)))
+	require.Len(t, calldata, 4+32*2)
+	assert.Equal(t, []byte{0xa9, 0x05, 0x9c, 0xbb}, []byte(calldata[:4]))
+	assert.Equal(t, bytes.Repeat([]byte{0x11}, 20), []byte(calldata[4+12:4+32]))
+	assert.Equal(t, big.NewInt(100).Int.Bytes(), []byte(calldata[4+32*2-1:]))
+
+	_, err = send("approve", `[]`)
+	assert.Error(t, err)
+	_, err = send("transfer(address,uint256)", `["0x1111111111111111111111111111111111111111"]`)
+	assert.Error(t, err)
+
+	require.NoError(t, ms.DeleteContractABI(ctx, contract))
+	_, err = ms.GetContractABI(ctx, contract)
+	assert.Error(t, err)
+	_, err = send("transfer", `["0x1111111111111111111111111111111111111111", "100"]`)
+	assert.Error(t, err)
+	_, err = ms.SendWithSpec(ctx, sophonTypes.QuickSendParams{
+		QuickSendParams: types.QuickSendParams{From: from, To: contract, Method: 2, ParamsType: types.QuickSendParamsCodecJSON},
+		ABIMethod:       "transfer",
+	})
+	assert.Error(t, err)
+}
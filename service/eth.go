@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// PushEthTransaction builds the filecoin message of an eth transaction and pushes it, the message is signed
+// with the delegated signature when it is selected, returns the id of message
+func (ms *MessageService) PushEthTransaction(ctx context.Context, params *sophonTypes.EthTxParams) (string, error) {
+	if params == nil {
+		return "", fmt.Errorf("params is nil")
+	}
+	msg, err := params.Message()
+	if err != nil {
+		return "", fmt.Errorf("build message of eth transaction failed: %w", err)
+	}
+	return ms.PushMessageWithSpec(ctx, "", msg, params.Spec)
+}
+
+// GetMessageByEthHash finds the message by the hash of any eth transaction signed for it,
+// a replaced message can be found by the hash of each revision
+func (ms *MessageService) GetMessageByEthHash(ctx context.Context, hash venusTypes.EthHash) (*types.Message, error) {
+	rev, err := ms.repo.MessageRevisionRepo().GetMessageRevisionByEthHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("get revision of eth transaction %s failed: %w", hash, err)
+	}
+	return ms.GetMessageByUid(ctx, rev.MsgID)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/crypto"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestPushEthTransaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	ms := msh.MessageService
+
+	newEthAddress := func(b byte) venusTypes.EthAddress {
+		var addr venusTypes.EthAddress
+		copy(addr[:], bytes.Repeat([]byte{b}, len(addr)))
+		return addr
+	}
+	from, err := newEthAddress(1).ToFilecoinAddress()
+	require.NoError(t, err)
+	other := testhelper.ResolveAddrs(t, testhelper.RandAddresses(t, 1))[0]
+	msh.addAddresses([]address.Address{from, other})
+	contract := newEthAddress(2)
+	contractAddr, err := contract.ToFilecoinAddress()
+	require.NoError(t, err)
+	require.NoError(t, msh.fullNode.AddActors([]address.Address{contractAddr, builtin.EthereumAddressManagerActorAddr}))
+
+	input := []byte("calldata")
+	id, err := ms.PushEthTransaction(ctx, &sophonTypes.EthTxParams{
+		From:  from,
+		To:    &contract,
+		Value: big.NewInt(10),
+		Input: input,
+	})
+	require.NoError(t, err)
+	msg, err := ms.GetMessageByUid(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, contractAddr, msg.To)
+	assert.Equal(t, builtin.MethodsEVM.InvokeContract, msg.Method)
+	assert.Equal(t, big.NewInt(10), msg.Value)
+	params := abi.CborBytes(input)
+	buf := new(bytes.Buffer)
+	require.NoError(t, params.MarshalCBOR(buf))
+	assert.Equal(t, buf.Bytes(), msg.Params)
+
+	// creates a contract
+	createID, err := ms.PushEthTransaction(ctx, &sophonTypes.EthTxParams{From: from, Input: input})
+	require.NoError(t, err)
+	createMsg, err := ms.GetMessageByUid(ctx, createID)
+	require.NoError(t, err)
+	assert.Equal(t, builtin.EthereumAddressManagerActorAddr, createMsg.To)
+	assert.Equal(t, builtin.MethodsEAM.CreateExternal, createMsg.Method)
+
+	_, err = ms.PushEthTransaction(ctx, &sophonTypes.EthTxParams{From: other, To: &contract})
+	assert.Error(t, err)
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	require.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, []address.Address{from}, ts)
+	require.Len(t, selectResult.SelectMsg, 2)
+	for _, selected := range selectResult.SelectMsg {
+		assert.Equal(t, crypto.SigTypeDelegated, selected.Signature.Type)
+
+		hash, err := sophonTypes.EthTxHash(selected)
+		require.NoError(t, err)
+		revisions, err := ms.GetMessageRevisions(ctx, selected.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, hash, revisions[0].EthHash)
+
+		res, err := ms.GetMessageByEthHash(ctx, hash)
+		require.NoError(t, err)
+		assert.Equal(t, selected.ID, res.ID)
+		assert.Equal(t, selected.SignedCid, res.SignedCid)
+	}
+
+	_, err = ms.GetMessageByEthHash(ctx, venusTypes.EthHashFromTxBytes([]byte("other")))
+	assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// SetExitCodeRule creates a rule if its id is empty, otherwise updates the rule with the id
+func (ms *MessageService) SetExitCodeRule(ctx context.Context, rule *sophonTypes.ExitCodeRule) (string, error) {
+	if rule == nil {
+		return "", fmt.Errorf("rule is nil")
+	}
+	if rule.ExitCode.IsSuccess() {
+		return "", fmt.Errorf("exit code must not be zero")
+	}
+	if _, err := sophonTypes.ParseExitCodeAction(string(rule.Action)); err != nil {
+		return "", err
+	}
+	if rule.Action == sophonTypes.ExitCodeRetry {
+		if rule.GasMultiplier < 1 {
+			return "", fmt.Errorf("gas multiplier of retry must not be less than 1")
+		}
+		if rule.MaxRetries <= 0 {
+			return "", fmt.Errorf("max retries of retry must be positive")
+		}
+	}
+	if len(rule.ID) == 0 {
+		rule.ID = venusTypes.NewUUID().String()
+	} else if _, err := ms.repo.ExitCodeRuleRepo().GetExitCodeRule(ctx, rule.ID); err != nil {
+		return "", fmt.Errorf("get rule %s failed: %w", rule.ID, err)
+	}
+
+	if err := ms.repo.ExitCodeRuleRepo().SaveExitCodeRule(ctx, rule); err != nil {
+		return "", err
+	}
+	return rule.ID, nil
+}
+
+func (ms *MessageService) ListExitCodeRule(ctx context.Context) ([]*sophonTypes.ExitCodeRule, error) {
+	return ms.repo.ExitCodeRuleRepo().ListExitCodeRule(ctx)
+}
+
+func (ms *MessageService) DeleteExitCodeRule(ctx context.Context, id string) error {
+	return ms.repo.ExitCodeRuleRepo().DeleteExitCodeRule(ctx, id)
+}
+
+// ListMessageRetries lists the retries linked with the message, from the retry of the first message to the last retry
+func (ms *MessageService) ListMessageRetries(ctx context.Context, id string) ([]*sophonTypes.MessageRetry, error) {
+	ruleRepo := ms.repo.ExitCodeRuleRepo()
+	first := id
+	for {
+		retry, err := ruleRepo.GetMessageRetryByRetryMsgID(ctx, first)
+		if errors.Is(err, repo.ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		first = retry.MsgID
+	}
+
+	var retries []*sophonTypes.MessageRetry
+	for next := first; ; {
+		retry, err := ruleRepo.GetMessageRetry(ctx, next)
+		if errors.Is(err, repo.ErrRecordNotFound) {
+			return retries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		retries = append(retries, retry)
+		next = retry.RetryMsgID
+	}
+}
+
+// resolveActorCodes resolves the codes of the actors which the failed messages are sent to, for the rules matching
+// by actor code. It is called before the transaction updating the state of messages to keep rpc calls out of it
+func (ms *MessageService) resolveActorCodes(ctx context.Context, applyMsgs []applyMessage) map[address.Address]cid.Cid {
+	codes := make(map[address.Address]cid.Cid)
+	rules, err := ms.repo.ExitCodeRuleRepo().ListExitCodeRule(ctx)
+	if err != nil {
+		msgStateLog.Warnf("list exit code rule failed: %v", err)
+		return codes
+	}
+	byCode := false
+	for _, rule := range rules {
+		byCode = byCode || rule.Code.Defined()
+	}
+	if !byCode {
+		return codes
+	}
+
+	for _, msg := range applyMsgs {
+		if msg.receipt.ExitCode.IsSuccess() {
+			continue
+		}
+		if _, ok := codes[msg.msg.To]; ok {
+			continue
+		}
+		actor, err := ms.nodeClient.StateGetActor(ctx, msg.msg.To, venusTypes.EmptyTSK)
+		if err != nil {
+			msgStateLog.Warnf("get actor %s failed: %v", msg.msg.To, err)
+			continue
+		}
+		codes[msg.msg.To] = actor.Code
+	}
+	return codes
+}
+
+// applyExitCodeRules applies the matched rule to each message landed on chain with a non-zero exit code, it runs in the
+// transaction updating the state of messages, so the rule is applied exactly when the state is saved.
+// codes are the actor codes resolved by resolveActorCodes
+func (ms *MessageService) applyExitCodeRules(ctx context.Context,
+	txRepo repo.TxRepo,
+	msgs []*types.Message,
+	codes map[address.Address]cid.Cid,
+) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	rules, err := txRepo.ExitCodeRuleRepo().ListExitCodeRule(ctx)
+	if err != nil {
+		return fmt.Errorf("list exit code rule failed: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	for _, msg := range msgs {
+		rule, err := matchExitCodeRule(rules, msg, codes)
+		if err != nil {
+			msgStateLog.Warnf("match exit code rule of message %s failed: %v", msg.ID, err)
+			continue
+		}
+		if rule == nil {
+			continue
+		}
+		msgStateLog.Infof("message %s failed with exit code %d, apply rule %s: %s", msg.ID, msg.Receipt.ExitCode, rule.ID, rule.Action)
+
+		switch rule.Action {
+		case sophonTypes.ExitCodeRetry:
+			err = ms.retryMessage(ctx, txRepo, rule, msg)
+		case sophonTypes.ExitCodeAlert:
+			err = ms.alertMessage(ctx, txRepo, rule, msg)
+		case sophonTypes.ExitCodeMarkBad:
+			err = markBadByRule(txRepo, rule, msg)
+		}
+		if err != nil {
+			return fmt.Errorf("apply rule %s to message %s failed: %w", rule.ID, msg.ID, err)
+		}
+	}
+	return nil
+}
+
+// matchExitCodeRule returns the most specific rule matching msg, nil if none matches
+func matchExitCodeRule(rules []*sophonTypes.ExitCodeRule, msg *types.Message, codes map[address.Address]cid.Cid) (*sophonTypes.ExitCodeRule, error) {
+	var matched *sophonTypes.ExitCodeRule
+	matchedScore := -1
+	for _, rule := range rules {
+		if rule.ExitCode != msg.Receipt.ExitCode {
+			continue
+		}
+		if len(rule.Methods) != 0 && !containsMethod(rule.Methods, msg.Method) {
+			continue
+		}
+		score := 0
+		if len(rule.Methods) != 0 {
+			score++
+		}
+		if rule.Code.Defined() {
+			code, ok := codes[msg.To]
+			if !ok {
+				return nil, fmt.Errorf("code of actor %s is not resolved", msg.To)
+			}
+			if !rule.Code.Equals(code) {
+				continue
+			}
+			score += 2
+		}
+		// the earlier rule wins the tie since rules are listed by creation time
+		if score > matchedScore {
+			matched, matchedScore = rule, score
+		}
+	}
+	return matched, nil
+}
+
+// retryMessage pushes a new message with the same content as msg, which is linked to msg by a retry record
+func (ms *MessageService) retryMessage(ctx context.Context, txRepo repo.TxRepo, rule *sophonTypes.ExitCodeRule, msg *types.Message) error {
+	ruleRepo := txRepo.ExitCodeRuleRepo()
+	if _, err := ruleRepo.GetMessageRetry(ctx, msg.ID); err == nil {
+		// the message was reverted after its retry was signed, and landed on chain again
+		return nil
+	} else if !errors.Is(err, repo.ErrRecordNotFound) {
+		return err
+	}
+	if msg.Method == builtin.MethodsEVM.InvokeContract {
+		items, err := txRepo.MessageBatchRepo().ListMessageBatch(ctx, msg.ID)
+		if err != nil {
+			return err
+		}
+		if len(items) != 0 {
+			msgStateLog.Warnf("batch message %s can not be retried", msg.ID)
+			return nil
+		}
+	}
+
+	attempt := 1
+	if prev, err := ruleRepo.GetMessageRetryByRetryMsgID(ctx, msg.ID); err == nil {
+		attempt = prev.Attempt + 1
+	} else if !errors.Is(err, repo.ErrRecordNotFound) {
+		return err
+	}
+	if attempt > rule.MaxRetries {
+		msgStateLog.Warnf("message %s has been retried %d times, give up", msg.ID, attempt-1)
+		return nil
+	}
+
+	gasOverEstimation, err := gasOverEstimationOf(ctx, txRepo, msg)
+	if err != nil {
+		return err
+	}
+	meta := &types.SendSpec{GasOverEstimation: gasOverEstimation * rule.GasMultiplier}
+	if msg.Meta != nil {
+		meta.MaxFee = msg.Meta.MaxFee
+		meta.GasOverPremium = msg.Meta.GasOverPremium
+	}
+	retryMsg := &types.Message{
+		ID: venusTypes.NewUUID().String(),
+		Message: venusTypes.Message{
+			Version: msg.Version,
+			From:    msg.From,
+			To:      msg.To,
+			Value:   msg.Value,
+			Method:  msg.Method,
+			Params:  msg.Params,
+		},
+		Meta:       meta,
+		WalletName: msg.WalletName,
+		State:      types.UnFillMsg,
+	}
+	// the sender of msg has been checked when msg was pushed
+	if err := createMessage(txRepo, retryMsg, nil); err != nil {
+		return fmt.Errorf("push retry message failed: %w", err)
+	}
+	if err := ruleRepo.CreateMessageRetry(ctx, &sophonTypes.MessageRetry{
+		MsgID:      msg.ID,
+		RetryMsgID: retryMsg.ID,
+		RuleID:     rule.ID,
+		Attempt:    attempt,
+	}); err != nil {
+		return fmt.Errorf("save retry of message %s failed: %w", msg.ID, err)
+	}
+	msgStateLog.Infof("retry message %s by %s, attempt %d, gas over estimation %v", msg.ID, retryMsg.ID, attempt, meta.GasOverEstimation)
+	return nil
+}
+
+// cancelMessageRetry the message retried is reverted and may land on chain again, so the retry is canceled if it has
+// not been signed, otherwise the call could be executed twice. The retry record is deleted, so that the message can be
+// retried again if it fails again. Returns the retry message canceled, nil if there is none.
+func cancelMessageRetry(ctx context.Context, txRepo repo.TxRepo, msg *types.Message) (*types.Message, error) {
+	ruleRepo := txRepo.ExitCodeRuleRepo()
+	retry, err := ruleRepo.GetMessageRetry(ctx, msg.ID)
+	if errors.Is(err, repo.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	retryMsg, err := txRepo.MessageRepo().GetMessageByUid(retry.RetryMsgID)
+	if err != nil {
+		return nil, fmt.Errorf("get retry message %s failed: %w", retry.RetryMsgID, err)
+	}
+	if retryMsg.State != types.UnFillMsg {
+		msgStateLog.Warnf("message %s is reverted, but its retry %s has been signed", msg.ID, retryMsg.ID)
+		return nil, nil
+	}
+
+	retryMsg.State = types.FailedMsg
+	retryMsg.ErrorMsg = fmt.Sprintf("canceled since message %s was reverted", msg.ID)
+	if err := txRepo.MessageRepo().MarkBadMessage(retryMsg.ID); err != nil {
+		return nil, err
+	}
+	if err := txRepo.MessageRepo().UpdateErrMsg(retryMsg.ID, retryMsg.ErrorMsg); err != nil {
+		return nil, err
+	}
+	if err := ruleRepo.DeleteMessageRetry(ctx, msg.ID); err != nil {
+		return nil, fmt.Errorf("delete retry of message %s failed: %w", msg.ID, err)
+	}
+	msgStateLog.Infof("message %s is reverted, cancel its retry %s", msg.ID, retryMsg.ID)
+	return retryMsg, nil
+}
+
+// gasOverEstimationOf the gas over estimation used by msg, from the spec of message, address or shared params
+func gasOverEstimationOf(ctx context.Context, txRepo repo.TxRepo, msg *types.Message) (float64, error) {
+	if msg.Meta != nil && msg.Meta.GasOverEstimation != 0 {
+		return msg.Meta.GasOverEstimation, nil
+	}
+	addrInfo, err := txRepo.AddressRepo().GetAddress(ctx, msg.From)
+	if err != nil {
+		return 0, err
+	}
+	if addrInfo.GasOverEstimation != 0 {
+		return addrInfo.GasOverEstimation, nil
+	}
+	sharedParams, err := txRepo.SharedParamsRepo().GetSharedParams(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return sharedParams.GasOverEstimation, nil
+}
+
+func (ms *MessageService) alertMessage(ctx context.Context, txRepo repo.TxRepo, rule *sophonTypes.ExitCodeRule, msg *types.Message) error {
+	msgStateLog.Errorf("alert: message %s from %s nonce %d failed with exit code %d, rule %s", msg.ID, msg.From, msg.Nonce,
+		msg.Receipt.ExitCode, rule.ID)
+
+	notification := &sophonTypes.Notification{
+		Kind:      sophonTypes.NotifyAlert,
+		MsgID:     msg.ID,
+		From:      msg.From,
+		Nonce:     msg.Nonce,
+		SignedCid: msg.SignedCid,
+		Height:    abi.ChainEpoch(msg.Height),
+		ExitCode:  msg.Receipt.ExitCode,
+		RuleID:    rule.ID,
+		CreatedAt: time.Now(),
+	}
+	notification.ID = fmt.Sprintf("%s/%s/%d", msg.ID, notification.Kind, msg.Height)
+	return saveNotifications(ctx, txRepo, ms.fsRepo.Config().Notifier.Webhooks, notification)
+}
+
+// markBadByRule records the failure in the error message, the message keeps its state since it has landed on chain
+func markBadByRule(txRepo repo.TxRepo, rule *sophonTypes.ExitCodeRule, msg *types.Message) error {
+	msg.ErrorMsg = fmt.Sprintf("exit code %d, marked bad by rule %s", msg.Receipt.ExitCode, rule.ID)
+	return txRepo.MessageRepo().UpdateErrMsg(msg.ID, msg.ErrorMsg)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/venus/venus-shared/actors"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestExitCodeRule(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	// invalid rules
+	_, err := ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{Action: sophonTypes.ExitCodeAlert})
+	assert.Error(t, err)
+	_, err = ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{ExitCode: exitcode.SysErrOutOfGas, Action: "unknown"})
+	assert.Error(t, err)
+	_, err = ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{ExitCode: exitcode.SysErrOutOfGas, Action: sophonTypes.ExitCodeRetry})
+	assert.Error(t, err)
+	_, err = ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{ID: "not-exist", ExitCode: exitcode.ErrForbidden, Action: sophonTypes.ExitCodeAlert})
+	assert.Error(t, err)
+
+	msgs := genMessages(addrs[:1], 3)
+	for _, msg := range msgs {
+		msg.Meta = &types.SendSpec{GasOverEstimation: 1.2}
+	}
+	retryID, err := ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{
+		ExitCode:      exitcode.SysErrOutOfGas,
+		Action:        sophonTypes.ExitCodeRetry,
+		GasMultiplier: 1.5,
+		MaxRetries:    1,
+	})
+	require.NoError(t, err)
+	_, err = ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{ExitCode: exitcode.ErrForbidden, Action: sophonTypes.ExitCodeAlert})
+	require.NoError(t, err)
+	// takes precedence over the rule without methods
+	markBadID, err := ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{
+		ExitCode: exitcode.ErrForbidden,
+		Methods:  []abi.MethodNum{msgs[1].Method},
+		Action:   sophonTypes.ExitCodeMarkBad,
+	})
+	require.NoError(t, err)
+	alertID, err := ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{ExitCode: exitcode.SysErrInsufficientFunds, Action: sophonTypes.ExitCodeAlert})
+	require.NoError(t, err)
+	rules, err := ms.ListExitCodeRule(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 4)
+
+	applyOnChain := func(exitCodes []exitcode.ExitCode) []*types.Message {
+		ts, err := msh.fullNode.ChainHead(ctx)
+		require.NoError(t, err)
+		selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+		require.Len(t, selectResult.SelectMsg, len(exitCodes))
+
+		var applyMsgs []applyMessage
+		for idx, msg := range selectResult.SelectMsg {
+			applyMsgs = append(applyMsgs, applyMessage{
+				signedCID: *msg.SignedCid,
+				msg:       &msg.Message,
+				height:    abi.ChainEpoch(10),
+				tsk:       ts.Key(),
+				receipt:   &venusTypes.MessageReceipt{ExitCode: exitCodes[idx]},
+			})
+		}
+		_, _, err = ms.updateMessageState(applyMsgs, nil)
+		require.NoError(t, err)
+		return selectResult.SelectMsg
+	}
+
+	require.NoError(t, pushMessage(ctx, ms, msgs))
+	applied := applyOnChain([]exitcode.ExitCode{exitcode.SysErrOutOfGas, exitcode.ErrForbidden, exitcode.SysErrInsufficientFunds})
+
+	// out of gas, retried with a larger gas over estimation
+	firstID := applied[0].ID
+	retries, err := ms.ListMessageRetries(ctx, firstID)
+	require.NoError(t, err)
+	require.Len(t, retries, 1)
+	assert.Equal(t, retryID, retries[0].RuleID)
+	assert.Equal(t, 1, retries[0].Attempt)
+	retryMsg, err := ms.GetMessageByUid(ctx, retries[0].RetryMsgID)
+	require.NoError(t, err)
+	assert.Equal(t, types.UnFillMsg, retryMsg.State)
+	assert.Equal(t, applied[0].To, retryMsg.To)
+	assert.Equal(t, applied[0].Params, retryMsg.Params)
+	assert.InDelta(t, 1.8, retryMsg.Meta.GasOverEstimation, 1e-9)
+
+	// forbidden, marked bad by the rule with methods, the state stays on chain
+	msg, err := ms.GetMessageByUid(ctx, applied[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, msg.State)
+	assert.Contains(t, msg.ErrorMsg, markBadID)
+
+	// insufficient funds, only alerted
+	msg, err = ms.GetMessageByUid(ctx, applied[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, msg.State)
+	retries, err = ms.ListMessageRetries(ctx, applied[2].ID)
+	require.NoError(t, err)
+	assert.Empty(t, retries)
+
+	// the retry message runs out of gas again, not retried since reaching the max retries
+	applied = applyOnChain([]exitcode.ExitCode{exitcode.SysErrOutOfGas})
+	assert.Equal(t, retryMsg.ID, applied[0].ID)
+	retries, err = ms.ListMessageRetries(ctx, retryMsg.ID)
+	require.NoError(t, err)
+	require.Len(t, retries, 1)
+	assert.Equal(t, firstID, retries[0].MsgID)
+
+	// the rule is not applied after deleted
+	require.NoError(t, ms.DeleteExitCodeRule(ctx, alertID))
+	assert.Error(t, ms.DeleteExitCodeRule(ctx, alertID))
+	rules, err = ms.ListExitCodeRule(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 3)
+	matched, err := matchExitCodeRule(rules, &types.Message{
+		Message: venusTypes.Message{To: address.TestAddress},
+		Receipt: &venusTypes.MessageReceipt{ExitCode: exitcode.SysErrInsufficientFunds},
+	}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, matched)
+}
+
+func TestRevertRetriedMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	// the code of actor is resolved before the transaction updating the message state
+	accountCode, ok := actors.GetActorCodeID(actorstypes.Version12, manifest.AccountKey)
+	require.True(t, ok)
+	require.NoError(t, msh.fullNode.SetActorCode(addrs[0], accountCode))
+	_, err := ms.SetExitCodeRule(ctx, &sophonTypes.ExitCodeRule{
+		ExitCode:      exitcode.SysErrOutOfGas,
+		Code:          accountCode,
+		Action:        sophonTypes.ExitCodeRetry,
+		GasMultiplier: 1.5,
+		MaxRetries:    2,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, pushMessage(ctx, ms, genMessages(addrs[:1], 1)))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	require.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	require.Len(t, selectResult.SelectMsg, 1)
+	msg := selectResult.SelectMsg[0]
+	apply := []applyMessage{{
+		signedCID: *msg.SignedCid,
+		msg:       &msg.Message,
+		height:    abi.ChainEpoch(10),
+		tsk:       ts.Key(),
+		receipt:   &venusTypes.MessageReceipt{ExitCode: exitcode.SysErrOutOfGas},
+	}}
+	revert := map[cid.Cid]struct{}{*msg.UnsignedCid: {}}
+
+	_, _, err = ms.updateMessageState(apply, nil)
+	require.NoError(t, err)
+	retries, err := ms.ListMessageRetries(ctx, msg.ID)
+	require.NoError(t, err)
+	require.Len(t, retries, 1)
+	firstRetryID := retries[0].RetryMsgID
+
+	// reverted before the retry is signed, the retry is canceled
+	_, _, err = ms.updateMessageState(nil, revert)
+	require.NoError(t, err)
+	retryMsg, err := ms.GetMessageByUid(ctx, firstRetryID)
+	require.NoError(t, err)
+	assert.Equal(t, types.FailedMsg, retryMsg.State)
+	assert.Contains(t, retryMsg.ErrorMsg, msg.ID)
+	retries, err = ms.ListMessageRetries(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Empty(t, retries)
+
+	// fails again after landing on chain again, retried again
+	_, _, err = ms.updateMessageState(apply, nil)
+	require.NoError(t, err)
+	retries, err = ms.ListMessageRetries(ctx, msg.ID)
+	require.NoError(t, err)
+	require.Len(t, retries, 1)
+	assert.NotEqual(t, firstRetryID, retries[0].RetryMsgID)
+	assert.Equal(t, 1, retries[0].Attempt)
+
+	// the retry has been signed, it is kept
+	selectResult = selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	require.Len(t, selectResult.SelectMsg, 1)
+	assert.Equal(t, retries[0].RetryMsgID, selectResult.SelectMsg[0].ID)
+	_, _, err = ms.updateMessageState(nil, revert)
+	require.NoError(t, err)
+	retryMsg, err = ms.GetMessageByUid(ctx, retries[0].RetryMsgID)
+	require.NoError(t, err)
+	assert.Equal(t, types.FillMsg, retryMsg.State)
+	retries, err = ms.ListMessageRetries(ctx, msg.ID)
+	require.NoError(t, err)
+	assert.Len(t, retries, 1)
+}
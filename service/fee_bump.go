@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// DefaultFeeBumpPolicyID the id of policy built from config, it is used when no address or actor policy matched
+const DefaultFeeBumpPolicyID = "default"
+
+func (ms *MessageService) feeBumpProc(ctx context.Context) {
+	cfg := ms.fsRepo.Config().FeeBump
+	interval := cfg.CheckInterval
+	if interval <= 0 {
+		interval = config.DefaultFeeBumpCheckInterval
+	}
+	tm := time.NewTicker(interval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Warnf("stop bump fee: %v", ctx.Err())
+			return
+		case <-tm.C:
+			// with sharding, each instance bumps the messages of its own addresses
+			if ms.shard == nil && !ms.leader.isLeader() {
+				continue
+			}
+			if err := ms.bumpBlockedMessages(ctx); err != nil {
+				log.Errorf("bump fee of blocked messages failed: %v", err)
+			}
+		}
+	}
+}
+
+// feeBumpInterval a message is bumped after it is blocked longer than the interval
+func (ms *MessageService) feeBumpInterval() time.Duration {
+	epochs := ms.fsRepo.Config().FeeBump.BlockedEpochs
+	if epochs <= 0 {
+		epochs = config.DefaultFeeBumpBlockedEpochs
+	}
+	return time.Duration(epochs) * ms.blockDelay
+}
+
+// bumpBlockedMessages replaces the signed messages which are blocked longer than feeBumpInterval since they were
+// signed with higher fee
+func (ms *MessageService) bumpBlockedMessages(ctx context.Context) error {
+	interval := ms.feeBumpInterval()
+	msgs, err := ms.repo.MessageRepo().ListBlockedMessage(&repo.MsgQueryParams{}, interval)
+	if err != nil {
+		return fmt.Errorf("list blocked message failed: %w", err)
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	mpoolCfg, err := ms.nodeClient.MpoolGetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to lookup the message pool config: %w", err)
+	}
+
+	actors := make(map[address.Address]*venusTypes.Actor)
+	for _, msg := range msgs {
+		if msg.State != types.FillMsg || !ms.shard.owns(msg.From) {
+			continue
+		}
+		actor, ok := actors[msg.From]
+		if !ok {
+			actor, err = ms.nodeClient.StateGetActor(ctx, msg.From, venusTypes.EmptyTSK)
+			if err != nil {
+				log.Warnf("get actor %s failed: %v", msg.From, err)
+				continue
+			}
+			actors[msg.From] = actor
+		}
+		// already on chain, wait for updating state
+		if msg.Nonce < actor.Nonce {
+			continue
+		}
+
+		record, err := ms.bumpMessage(ctx, msg, mpoolCfg.ReplaceByFeeRatio, interval)
+		if err != nil {
+			log.Warnf("bump fee of message %s failed: %v", msg.ID, err)
+			continue
+		}
+		if record != nil {
+			log.Infof("bump fee of message %s, bump %d, old cid %s, new cid %s, gas premium %s -> %s, gas fee cap %s -> %s",
+				msg.ID, record.Bump, record.OldSignedCid, record.NewSignedCid, record.OldGasPremium, record.NewGasPremium,
+				record.OldGasFeeCap, record.NewGasFeeCap)
+		}
+	}
+
+	return nil
+}
+
+// bumpMessage re-prices, re-signs and republishes msg according to its policy, returns nil if the message is skipped
+func (ms *MessageService) bumpMessage(ctx context.Context,
+	msg *types.Message,
+	replaceByFeeRatio venusTypes.Percent,
+	interval time.Duration,
+) (*sophonTypes.FeeBumpRecord, error) {
+	policy, err := ms.matchFeeBumpPolicy(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	records, err := ms.repo.FeeBumpRepo().ListFeeBumpRecord(ctx, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) >= policy.MaxBumps {
+		log.Debugf("message %s reaches the max bumps %d of policy %s", msg.ID, policy.MaxBumps, policy.ID)
+		return nil, nil
+	}
+	// measured from when the message was signed the last time rather than created, it may wait long in UnFillMsg,
+	// a revision is saved each time it is signed, including by bumping
+	revisions, err := ms.repo.MessageRevisionRepo().ListMessageRevisions(ctx, msg.ID)
+	if err != nil {
+		return nil, err
+	}
+	signedAt := msg.UpdatedAt
+	if len(revisions) > 0 {
+		signedAt = revisions[len(revisions)-1].CreatedAt
+	}
+	if time.Since(signedAt) < interval {
+		return nil, nil
+	}
+
+	record := &sophonTypes.FeeBumpRecord{
+		ID:            venusTypes.NewUUID().String(),
+		MsgID:         msg.ID,
+		From:          msg.From,
+		Nonce:         msg.Nonce,
+		Bump:          len(records) + 1,
+		PolicyID:      policy.ID,
+		OldGasLimit:   msg.GasLimit,
+		OldGasFeeCap:  msg.GasFeeCap,
+		OldGasPremium: msg.GasPremium,
+	}
+	if msg.SignedCid != nil {
+		record.OldSignedCid = *msg.SignedCid
+	}
+
+	bumpRatio := policy.BumpRatio
+	if bumpRatio < replaceByFeeRatio {
+		bumpRatio = replaceByFeeRatio
+	}
+	mss := &venusTypes.MessageSendSpec{}
+	if msg.Meta != nil {
+		mss.MaxFee = msg.Meta.MaxFee
+		mss.GasOverPremium = msg.Meta.GasOverPremium
+	}
+	if err := ms.repriceMessage(ctx, msg, bumpRatio, mss); err != nil {
+		return nil, err
+	}
+	if !policy.MaxFeeCap.NilOrZero() && big.Cmp(msg.GasFeeCap, policy.MaxFeeCap) > 0 {
+		msg.GasFeeCap = policy.MaxFeeCap
+		msg.GasPremium = big.Min(msg.GasFeeCap, msg.GasPremium)
+	}
+	// the replacement will be rejected by mpool if the premium is too low
+	if minRBF := computeRBF(record.OldGasPremium, replaceByFeeRatio); big.Cmp(msg.GasPremium, minRBF) < 0 {
+		return nil, fmt.Errorf("gas premium %s is lower than the min replace premium %s, limited by max fee %s or max fee cap %s",
+			msg.GasPremium, minRBF, mss.MaxFee, policy.MaxFeeCap)
+	}
+
+	accounts, err := ms.addressService.GetAccountsOfSigner(ctx, msg.From)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ToSignedMsg(ctx, ms.walletClient, msg, accounts); err != nil {
+		return nil, err
+	}
+	record.NewSignedCid = *msg.SignedCid
+	record.NewGasLimit = msg.GasLimit
+	record.NewGasFeeCap = msg.GasFeeCap
+	record.NewGasPremium = msg.GasPremium
+
+	if err := ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+		// a stale leader or holder of address must not replace messages after another instance took over
+		if err := ms.checkFence(ctx, txRepo, msg.From); err != nil {
+			return err
+		}
+		if err := txRepo.MessageRepo().UpdateMessageByState(msg, types.FillMsg); err != nil {
+			return err
+		}
+		revision := sophonTypes.NewMessageRevision(msg, sophonTypes.RevisionFeeBump, "")
+		if err := txRepo.MessageRevisionRepo().SaveMessageRevisions(ctx, []*sophonTypes.MessageRevision{revision}); err != nil {
+			return err
+		}
+		spend := sophonTypes.NewAddressSpend(msg, abi.ChainEpoch(ms.tsCache.CurrHeight))
+		if err := txRepo.AddressSpendRepo().SaveAddressSpends(ctx, []*sophonTypes.AddressSpend{spend}); err != nil {
+			return err
+		}
+		return txRepo.FeeBumpRepo().CreateFeeBumpRecord(ctx, record)
+	}); err != nil {
+		return nil, fmt.Errorf("save message failed: %w", err)
+	}
+	ms.eventHub.publishMessages(msg)
+
+	// the replacement is saved, it will be pushed again by the republish loop if failed here
+	if err := ms.RepublishMessage(ctx, msg.ID); err != nil {
+		log.Warnf("republish message %s failed: %v", msg.ID, err)
+	}
+
+	return record, nil
+}
+
+// matchFeeBumpPolicy returns the policy of address, or the policy of the actor msg sent to, or the default policy
+func (ms *MessageService) matchFeeBumpPolicy(ctx context.Context, msg *types.Message) (*sophonTypes.FeeBumpPolicy, error) {
+	policy, err := ms.repo.FeeBumpRepo().GetFeeBumpPolicyByAddress(ctx, msg.From)
+	if err == nil {
+		return policy, nil
+	}
+	if !errors.Is(err, repo.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	actor, err := ms.nodeClient.StateGetActor(ctx, msg.To, venusTypes.EmptyTSK)
+	if err == nil {
+		policy, err = ms.repo.FeeBumpRepo().GetFeeBumpPolicyByActor(ctx, actor.Code, msg.Method)
+		if err == nil {
+			return policy, nil
+		}
+		if !errors.Is(err, repo.ErrRecordNotFound) {
+			return nil, err
+		}
+	} else {
+		log.Debugf("get actor %s failed: %v", msg.To, err)
+	}
+
+	return ms.defaultFeeBumpPolicy()
+}
+
+func (ms *MessageService) defaultFeeBumpPolicy() (*sophonTypes.FeeBumpPolicy, error) {
+	cfg := ms.fsRepo.Config().FeeBump
+	policy := &sophonTypes.FeeBumpPolicy{
+		ID:        DefaultFeeBumpPolicyID,
+		Addr:      address.Undef,
+		Code:      cid.Undef,
+		MaxBumps:  cfg.MaxBumps,
+		BumpRatio: venusTypes.Percent(cfg.BumpRatio),
+		MaxFeeCap: big.Zero(),
+	}
+	if len(cfg.MaxFeeCap) != 0 {
+		maxFeeCap, err := big.FromString(cfg.MaxFeeCap)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max fee cap %s: %w", cfg.MaxFeeCap, err)
+		}
+		policy.MaxFeeCap = maxFeeCap
+	}
+	return policy, nil
+}
+
+// SetFeeBumpPolicy creates or updates the policy of address or actor
+func (ms *MessageService) SetFeeBumpPolicy(ctx context.Context, policy *sophonTypes.FeeBumpPolicy) error {
+	if policy == nil {
+		return fmt.Errorf("policy is nil")
+	}
+	if policy.IsAddressPolicy() == policy.Code.Defined() {
+		return fmt.Errorf("one of address and actor code must be set")
+	}
+	if policy.MaxBumps < 0 {
+		return fmt.Errorf("max bumps %d must not be negative", policy.MaxBumps)
+	}
+	if policy.BumpRatio < replaceByFeePercentageMinimum {
+		return fmt.Errorf("bump ratio %d must be at least %d", policy.BumpRatio, replaceByFeePercentageMinimum)
+	}
+	if policy.MaxFeeCap.Nil() {
+		policy.MaxFeeCap = big.Zero()
+	}
+	if policy.IsAddressPolicy() {
+		policy.Method = 0
+	}
+	policy.ID = venusTypes.NewUUID().String()
+
+	return ms.repo.FeeBumpRepo().SaveFeeBumpPolicy(ctx, policy)
+}
+
+func (ms *MessageService) ListFeeBumpPolicy(ctx context.Context) ([]*sophonTypes.FeeBumpPolicy, error) {
+	return ms.repo.FeeBumpRepo().ListFeeBumpPolicy(ctx)
+}
+
+func (ms *MessageService) DeleteFeeBumpPolicy(ctx context.Context, id string) error {
+	return ms.repo.FeeBumpRepo().DeleteFeeBumpPolicy(ctx, id)
+}
+
+func (ms *MessageService) ListFeeBumpRecord(ctx context.Context, id string) ([]*sophonTypes.FeeBumpRecord, error) {
+	return ms.repo.FeeBumpRepo().ListFeeBumpRecord(ctx, id)
+}
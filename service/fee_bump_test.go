@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestFeeBump(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	cfg := ms.fsRepo.Config()
+	cfg.FeeBump.MaxBumps = 2
+	cfg.FeeBump.BumpRatio = 150
+
+	msgs := genMessages(addrs[:2], 4)
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:2], ts)
+	assert.Len(t, selectResult.SelectMsg, 4)
+
+	oldMsgs := make(map[string]*types.Message)
+	for _, msg := range selectResult.SelectMsg {
+		oldMsgs[msg.ID] = msg
+	}
+
+	ageMessages := func(table string) {
+		assert.NoError(t, ms.repo.GetDb().Table(table).Where("1 = 1").
+			Update("created_at", time.Now().Add(-time.Hour)).Error)
+	}
+	bumpRecords := func(id string) []*sophonTypes.FeeBumpRecord {
+		records, err := ms.ListFeeBumpRecord(ctx, id)
+		assert.NoError(t, err)
+		return records
+	}
+
+	// not blocked long enough
+	assert.NoError(t, ms.bumpBlockedMessages(ctx))
+	for id := range oldMsgs {
+		assert.Empty(t, bumpRecords(id))
+	}
+
+	// the ceiling of addrs[1] is lower than the fee cap after bumping
+	maxFeeCap := big.Zero()
+	for _, msg := range oldMsgs {
+		if msg.From == addrs[1] {
+			maxFeeCap = big.Max(maxFeeCap, big.Add(computeRBF(msg.GasPremium, 150), big.NewInt(1)))
+		}
+	}
+	assert.NoError(t, ms.SetFeeBumpPolicy(ctx, &sophonTypes.FeeBumpPolicy{
+		Addr:      addrs[1],
+		Code:      cid.Undef,
+		MaxBumps:  1,
+		BumpRatio: 150,
+		MaxFeeCap: maxFeeCap,
+	}))
+
+	t.Run("stale leader", func(t *testing.T) {
+		// the lease was taken over by another instance
+		ms.leader = newLeaderElector(ms.repo, config.LeaderElectionConfig{Enable: true}, newInstanceID())
+		defer func() { ms.leader = nil }()
+
+		ageMessages("message_revisions")
+		assert.NoError(t, ms.bumpBlockedMessages(ctx))
+		for id, oldMsg := range oldMsgs {
+			assert.Empty(t, bumpRecords(id))
+			msg, err := ms.GetMessageByUid(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, oldMsg.SignedCid, msg.SignedCid)
+		}
+		assert.NoError(t, ms.repo.GetDb().Table("message_revisions").Where("1 = 1").
+			Update("created_at", time.Now()).Error)
+	})
+
+	t.Run("bump blocked messages", func(t *testing.T) {
+		// waited long in UnFillMsg, but signed recently
+		ageMessages("messages")
+		assert.NoError(t, ms.bumpBlockedMessages(ctx))
+		for id := range oldMsgs {
+			assert.Empty(t, bumpRecords(id))
+		}
+
+		ageMessages("message_revisions")
+		assert.NoError(t, ms.bumpBlockedMessages(ctx))
+
+		for id, oldMsg := range oldMsgs {
+			records := bumpRecords(id)
+			assert.Len(t, records, 1)
+			record := records[0]
+			assert.Equal(t, 1, record.Bump)
+			assert.Equal(t, oldMsg.Nonce, record.Nonce)
+			assert.Equal(t, *oldMsg.SignedCid, record.OldSignedCid)
+			assert.Equal(t, oldMsg.GasPremium, record.OldGasPremium)
+			assert.True(t, record.NewGasPremium.GreaterThanEqual(computeRBF(oldMsg.GasPremium, 150)))
+
+			msg, err := ms.GetMessageByUid(ctx, id)
+			assert.NoError(t, err)
+			assert.Equal(t, types.FillMsg, msg.State)
+			assert.Equal(t, record.NewSignedCid, *msg.SignedCid)
+			assert.Equal(t, record.NewGasPremium, msg.GasPremium)
+			assert.Equal(t, record.NewGasFeeCap, msg.GasFeeCap)
+
+			revisions, err := ms.GetMessageRevisions(ctx, id)
+			assert.NoError(t, err)
+			assert.Len(t, revisions, 2)
+			assert.Equal(t, sophonTypes.RevisionFeeBump, revisions[1].Reason)
+			assert.Equal(t, record.NewSignedCid, revisions[1].SignedCid)
+
+			if oldMsg.From == addrs[1] {
+				assert.NotEqual(t, DefaultFeeBumpPolicyID, record.PolicyID)
+				assert.True(t, msg.GasFeeCap.LessThanEqual(maxFeeCap))
+				assert.True(t, oldMsg.GasFeeCap.GreaterThan(maxFeeCap))
+			} else {
+				assert.Equal(t, DefaultFeeBumpPolicyID, record.PolicyID)
+			}
+		}
+
+		// bumped recently
+		assert.NoError(t, ms.bumpBlockedMessages(ctx))
+		for id := range oldMsgs {
+			assert.Len(t, bumpRecords(id), 1)
+		}
+	})
+
+	t.Run("max bumps", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			ageMessages("message_revisions")
+			assert.NoError(t, ms.bumpBlockedMessages(ctx))
+		}
+
+		for id, oldMsg := range oldMsgs {
+			records := bumpRecords(id)
+			if oldMsg.From == addrs[1] {
+				assert.Len(t, records, 1)
+				continue
+			}
+			assert.Len(t, records, 2)
+			assert.Equal(t, 2, records[1].Bump)
+			assert.Equal(t, records[0].NewSignedCid, records[1].OldSignedCid)
+			assert.True(t, records[1].NewGasPremium.GreaterThan(records[0].NewGasPremium))
+		}
+	})
+}
+
+func TestFeeBumpPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	ms := msh.MessageService
+	addr := testhelper.RandAddresses(t, 1)[0]
+	msg := testhelper.NewUnsignedMessage()
+	code := msg.Cid()
+
+	// both or neither of address and code
+	assert.Error(t, ms.SetFeeBumpPolicy(ctx, &sophonTypes.FeeBumpPolicy{Addr: addr, Code: code, BumpRatio: 125}))
+	assert.Error(t, ms.SetFeeBumpPolicy(ctx, &sophonTypes.FeeBumpPolicy{Addr: address.Undef, Code: cid.Undef, BumpRatio: 125}))
+	// ratio lower than the min replace ratio
+	assert.Error(t, ms.SetFeeBumpPolicy(ctx, &sophonTypes.FeeBumpPolicy{Addr: addr, Code: cid.Undef, BumpRatio: 100}))
+
+	assert.NoError(t, ms.SetFeeBumpPolicy(ctx, &sophonTypes.FeeBumpPolicy{Addr: addr, Code: cid.Undef, MaxBumps: 1, BumpRatio: 125}))
+	assert.NoError(t, ms.SetFeeBumpPolicy(ctx, &sophonTypes.FeeBumpPolicy{Addr: address.Undef, Code: code, Method: 2, MaxBumps: 1, BumpRatio: 125}))
+	// update
+	assert.NoError(t, ms.SetFeeBumpPolicy(ctx, &sophonTypes.FeeBumpPolicy{Addr: addr, Code: cid.Undef, MaxBumps: 2, BumpRatio: 125}))
+
+	policies, err := ms.ListFeeBumpPolicy(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, policies, 2)
+	for _, policy := range policies {
+		if policy.IsAddressPolicy() {
+			assert.Equal(t, 2, policy.MaxBumps)
+			assert.True(t, policy.MaxFeeCap.IsZero())
+		}
+	}
+
+	assert.NoError(t, ms.DeleteFeeBumpPolicy(ctx, policies[0].ID))
+	policies, err = ms.ListFeeBumpPolicy(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, policies, 1)
+
+	// the default policy built from config
+	cfg := ms.fsRepo.Config()
+	cfg.FeeBump.MaxFeeCap = "1000"
+	policy, err := ms.defaultFeeBumpPolicy()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultFeeBumpPolicyID, policy.ID)
+	assert.Equal(t, cfg.FeeBump.MaxBumps, policy.MaxBumps)
+	assert.Equal(t, big.NewInt(1000), policy.MaxFeeCap)
+
+	cfg.FeeBump.MaxFeeCap = "invalid"
+	_, err = ms.defaultFeeBumpPolicy()
+	assert.Error(t, err)
+}
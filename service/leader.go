@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// leaderLeaseName the name of lease held by the leader
+const leaderLeaseName = "leader"
+
+var errNotLeader = errors.New("not the leader")
+
+var leaderLog = logging.Logger("leader")
+
+// leaderElector elects the leader among the instances sharing the same database by a lease in db, the leader renews
+// the lease periodically and the others take it over after it expires.
+// A nil leaderElector means leader election is disabled and the instance is always the leader.
+type leaderElector struct {
+	repo   repo.Repo
+	cfg    config.LeaderElectionConfig
+	holder string
+
+	lk sync.RWMutex
+	// lease the latest lease acquired by holder, nil if another one holds the lease
+	lease *sophonTypes.Lease
+
+	// onElected called before the instance becomes the leader, isLeader returns true after it returns
+	onElected func(ctx context.Context)
+	// onDeposed called after the instance is no longer the leader
+	onDeposed func()
+}
+
+// newInstanceID returns a unique id of the instance, used as the holder of leases
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%s", hostname, venusTypes.NewUUID())
+}
+
+func newLeaderElector(repo repo.Repo, cfg config.LeaderElectionConfig, holder string) *leaderElector {
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = config.DefaultLeaseDuration
+	}
+	if cfg.RenewInterval <= 0 || cfg.RenewInterval >= cfg.LeaseDuration {
+		cfg.RenewInterval = cfg.LeaseDuration / 3
+	}
+
+	return &leaderElector{
+		repo:   repo,
+		cfg:    cfg,
+		holder: holder,
+	}
+}
+
+func (le *leaderElector) isLeader() bool {
+	if le == nil {
+		return true
+	}
+	le.lk.RLock()
+	defer le.lk.RUnlock()
+	return le.lease != nil && !le.lease.IsExpired(time.Now())
+}
+
+func (le *leaderElector) run(ctx context.Context) {
+	tm := time.NewTicker(le.cfg.RenewInterval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			leaderLog.Warnf("stop leader election: %v", ctx.Err())
+			le.release()
+			return
+		case <-tm.C:
+			if err := le.elect(ctx); err != nil {
+				leaderLog.Errorf("acquire lease failed: %v", err)
+			}
+		}
+	}
+}
+
+// elect acquires or renews the lease, if it fails, the instance keeps being the leader until the lease expires
+func (le *leaderElector) elect(ctx context.Context) error {
+	lease, err := le.repo.LeaseRepo().AcquireLease(ctx, leaderLeaseName, le.holder, le.cfg.LeaseDuration)
+	if err != nil {
+		return err
+	}
+
+	wasLeader := le.isLeader()
+	elected := lease.Holder == le.holder
+	if elected && !wasLeader {
+		leaderLog.Infof("%s becomes the leader, token %d", le.holder, lease.Token)
+		// the work of leader must not start before its state is ready
+		if le.onElected != nil {
+			le.onElected(ctx)
+		}
+	}
+
+	le.lk.Lock()
+	if elected {
+		le.lease = lease
+	} else {
+		le.lease = nil
+	}
+	le.lk.Unlock()
+
+	if !elected && wasLeader {
+		leaderLog.Warnf("%s is no longer the leader, the lease is held by %s", le.holder, lease.Holder)
+		if le.onDeposed != nil {
+			le.onDeposed()
+		}
+	}
+
+	return nil
+}
+
+// release expires the lease held by the instance, so that another one takes over without waiting for it to expire
+func (le *leaderElector) release() {
+	if !le.isLeader() {
+		return
+	}
+	le.lk.Lock()
+	le.lease = nil
+	le.lk.Unlock()
+	if le.onDeposed != nil {
+		le.onDeposed()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := le.repo.LeaseRepo().ReleaseLease(ctx, leaderLeaseName, le.holder); err != nil {
+		leaderLog.Errorf("release lease failed: %v", err)
+	}
+}
+
+// checkFence returns an error if the lease in db was taken over by another instance. It must be called in the
+// transaction which assigns nonces, the lease is locked so that it can't be taken over until the transaction ends.
+func (le *leaderElector) checkFence(ctx context.Context, txRepo repo.TxRepo) error {
+	if le == nil {
+		return nil
+	}
+	le.lk.RLock()
+	lease := le.lease
+	le.lk.RUnlock()
+	if lease == nil {
+		return errNotLeader
+	}
+
+	current, err := txRepo.LeaseRepo().LockLease(ctx, leaderLeaseName)
+	if err != nil {
+		return fmt.Errorf("lock lease failed: %w", err)
+	}
+	if current.Holder != le.holder || current.Token != lease.Token {
+		return fmt.Errorf("%w, the lease is held by %s, token %d", errNotLeader, current.Holder, current.Token)
+	}
+	return nil
+}
+
+// checkFence returns an error if the instance is no longer the holder of addr, or the leader if sharding is disabled,
+// the same as the fence of work
+func (ms *MessageService) checkFence(ctx context.Context, txRepo repo.TxRepo, addr address.Address) error {
+	if ms.shard != nil {
+		return ms.shard.checkFence(ctx, txRepo, addr)
+	}
+	return ms.leader.checkFence(ctx, txRepo)
+}
+
+// onElected reloads the tipsets processed by the previous leader
+func (ms *MessageService) onElected(ctx context.Context) {
+	if err := ms.tsCache.Load(ctx, ms.repo.TipsetRepo()); err != nil {
+		leaderLog.Errorf("load tipset failed: %v", err)
+	}
+}
+
+// onDeposed closes the event subscriptions, the events are only published by the leader, so the subscribers should
+// subscribe to the new leader
+func (ms *MessageService) onDeposed() {
+	ms.eventHub.closeAll()
+}
+
+func (ms *MessageService) GetLeader(ctx context.Context) (*sophonTypes.Lease, error) {
+	if ms.leader == nil {
+		return nil, errors.New("leader election is disabled")
+	}
+	return ms.repo.LeaseRepo().GetLease(ctx, leaderLeaseName)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+)
+
+func TestLeaderElection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	// disabled
+	assert.Nil(t, ms.leader)
+	assert.True(t, ms.leader.isLeader())
+	assert.NoError(t, ms.leader.checkFence(ctx, ms.repo))
+
+	cfg := config.LeaderElectionConfig{Enable: true, LeaseDuration: time.Second, RenewInterval: time.Second}
+	a := newLeaderElector(ms.repo, cfg, newInstanceID())
+	b := newLeaderElector(ms.repo, cfg, newInstanceID())
+	elected := 0
+	a.onElected = func(context.Context) {
+		// the instance is not the leader until the hook returns
+		assert.False(t, a.isLeader())
+		elected++
+	}
+
+	require.NoError(t, a.elect(ctx))
+	require.NoError(t, b.elect(ctx))
+	assert.True(t, a.isLeader())
+	assert.False(t, b.isLeader())
+	assert.Equal(t, 1, elected)
+	assert.NoError(t, a.checkFence(ctx, ms.repo))
+	assert.True(t, errors.Is(b.checkFence(ctx, ms.repo), errNotLeader))
+
+	// renew
+	require.NoError(t, a.elect(ctx))
+	assert.Equal(t, 1, elected)
+
+	// b takes over after the lease of a expired
+	time.Sleep(cfg.LeaseDuration)
+	assert.False(t, a.isLeader())
+	require.NoError(t, b.elect(ctx))
+	assert.True(t, b.isLeader())
+	lease, err := ms.repo.LeaseRepo().GetLease(ctx, leaderLeaseName)
+	require.NoError(t, err)
+	assert.Equal(t, b.holder, lease.Holder)
+	assert.Equal(t, uint64(2), lease.Token)
+
+	// the stale leader can't assign nonces
+	msgs := genMessages(addrs[:1], 2)
+	require.NoError(t, pushMessage(ctx, ms, msgs))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	require.NoError(t, err)
+	sharedParams, err := ms.sps.GetSharedParams(ctx)
+	require.NoError(t, err)
+	appliedNonce, err := ms.msgSelectMgr.getNonceInTipset(ctx, ts)
+	require.NoError(t, err)
+
+	selectAndSave := func(leader *leaderElector) error {
+		w := newWork(ctx, addrs[0], ms.msgSelectMgr.cfg, msh.fullNode, ms.repo, ms.addressService, ms.walletClient, ms.msgReceiver, ms.eventHub, leader, nil)
+		addrInfo, err := ms.addressService.GetAddress(ctx, addrs[0])
+		require.NoError(t, err)
+		selectResult, err := w.selectMessage(ctx, appliedNonce, addrInfo, ts, sharedParams.SelMsgNum, sharedParams)
+		require.NoError(t, err)
+		require.Len(t, selectResult.SelectMsg, len(msgs))
+		return w.saveSelectedMessages(selectResult)
+	}
+
+	assert.True(t, errors.Is(selectAndSave(a), errNotLeader))
+	for _, msg := range msgs {
+		res, err := ms.GetMessageByUid(ctx, msg.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.UnFillMsg, res.State)
+	}
+
+	require.NoError(t, selectAndSave(b))
+	for _, msg := range msgs {
+		res, err := ms.GetMessageByUid(ctx, msg.ID)
+		require.NoError(t, err)
+		assert.Equal(t, types.FillMsg, res.State)
+	}
+
+	// a can take over at once after b released the lease
+	b.release()
+	assert.False(t, b.isLeader())
+	require.NoError(t, a.elect(ctx))
+	assert.True(t, a.isLeader())
+	assert.Equal(t, 2, elected)
+	assert.NoError(t, ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+		return a.checkFence(ctx, txRepo)
+	}))
+}
+
+func TestSubscribeMessageEventsOnStandby(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	ms := msh.MessageService
+
+	cfg := config.LeaderElectionConfig{Enable: true, LeaseDuration: time.Minute, RenewInterval: time.Second}
+	other := newLeaderElector(ms.repo, cfg, newInstanceID())
+	ms.leader = newLeaderElector(ms.repo, cfg, newInstanceID())
+	ms.leader.onDeposed = ms.onDeposed
+	require.NoError(t, ms.leader.elect(ctx))
+	require.True(t, ms.leader.isLeader())
+
+	events, err := ms.SubscribeMessageEvents(ctx, nil)
+	require.NoError(t, err)
+
+	// the subscriptions are closed after the instance released the lease
+	ms.leader.release()
+	assert.Eventually(t, func() bool {
+		_, ok := <-events
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+
+	// standby rejects subscriptions
+	require.NoError(t, other.elect(ctx))
+	require.NoError(t, ms.leader.elect(ctx))
+	assert.False(t, ms.leader.isLeader())
+	_, err = ms.SubscribeMessageEvents(ctx, nil)
+	assert.ErrorIs(t, err, errNotLeader)
+	assert.ErrorContains(t, err, other.holder)
+}
@@ -0,0 +1,430 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	gobig "math/big"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// aggregate3Value((address,bool,uint256,bytes)[]) of multicall3
+var aggregate3ValueSelector = []byte{0x17, 0x4d, 0xea, 0x71}
+
+const abiWordSize = 32
+
+// multicall a call of aggregate3Value, the failure of any call reverts the whole batch
+type multicall struct {
+	target venusTypes.EthAddress
+	value  big.Int
+	data   []byte
+}
+
+// batchMessages collapses the unfill sends of address into messages of the aggregator if batching is enabled
+// globally and the address enables batch send, the collapsed messages are moved to BatchedMsg state
+func (w *work) batchMessages(ctx context.Context) error {
+	cfg := w.cfg.Batch
+	if len(cfg.Aggregator) == 0 {
+		return nil
+	}
+	addrCfg, err := w.addressService.GetAddressConfig(ctx, w.addr)
+	if err != nil {
+		return fmt.Errorf("get address config failed: %v", err)
+	}
+	if !addrCfg.BatchSend {
+		return nil
+	}
+	aggregator, err := address.NewFromString(cfg.Aggregator)
+	if err != nil {
+		return fmt.Errorf("parse aggregator %s failed: %v", cfg.Aggregator, err)
+	}
+	minSize, maxSize := cfg.MinSize, cfg.MaxSize
+	if minSize < config.DefaultBatchMinSize {
+		minSize = config.DefaultBatchMinSize
+	}
+	if maxSize <= 0 {
+		maxSize = config.DefaultBatchMaxSize
+	}
+
+	msgs, err := w.repo.MessageRepo().ListUnFilledMessage(w.addr)
+	if err != nil {
+		return fmt.Errorf("list unfill message failed: %v", err)
+	}
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].CreatedAt.Before(msgs[j].CreatedAt)
+	})
+	deadlines, err := w.repo.MessageRepo().ListUnChainMessageExpireAt(w.addr)
+	if err != nil {
+		return fmt.Errorf("list message deadline failed: %v", err)
+	}
+	var children []*types.Message
+	var calls []multicall
+	for _, msg := range msgs {
+		if _, ok := deadlines[msg.ID]; ok || !batchable(msg) {
+			continue
+		}
+		target, err := w.ethAddress(ctx, msg.To)
+		if err != nil {
+			w.log.Debugf("skip batching message %s: %v", msg.ID, err)
+			continue
+		}
+		children = append(children, msg)
+		calls = append(calls, multicall{target: target, value: msg.Value})
+	}
+	if len(children) < minSize {
+		return nil
+	}
+	priorities, err := w.repo.MessageRepo().ListUnChainMessagePriority(w.addr)
+	if err != nil {
+		return fmt.Errorf("list message priority failed: %v", err)
+	}
+
+	for len(children) >= minSize {
+		size := len(children)
+		if size > maxSize {
+			size = maxSize
+		}
+		if err := w.createBatch(ctx, aggregator, children[:size], calls[:size], priorities); err != nil {
+			return err
+		}
+		children, calls = children[size:], calls[size:]
+	}
+	return nil
+}
+
+// batchable only the plain sends without deadline can be collapsed, the deadline of batch is unknown,
+// the wall-clock deadline is not loaded with message and checked by caller
+func batchable(msg *types.Message) bool {
+	if msg.State != types.UnFillMsg || msg.Method != builtin.MethodSend || len(msg.Params) != 0 {
+		return false
+	}
+	return msg.Meta == nil || msg.Meta.ExpireEpoch == 0
+}
+
+// ethAddress converts addr to the address used in EVM, the key address must have been created on chain
+func (w *work) ethAddress(ctx context.Context, addr address.Address) (venusTypes.EthAddress, error) {
+	if addr.Protocol() != address.ID && addr.Protocol() != address.Delegated {
+		id, err := w.fullNode.StateLookupID(ctx, addr, venusTypes.EmptyTSK)
+		if err != nil {
+			return venusTypes.EthAddress{}, err
+		}
+		addr = id
+	}
+	return venusTypes.EthAddressFromFilecoinAddress(addr)
+}
+
+func (w *work) createBatch(ctx context.Context, aggregator address.Address, children []*types.Message, calls []multicall, priorities map[string]int) error {
+	params := abi.CborBytes(encodeAggregate3Value(calls))
+	buf := new(bytes.Buffer)
+	if err := params.MarshalCBOR(buf); err != nil {
+		return fmt.Errorf("marshal params of batch failed: %v", err)
+	}
+
+	// the batch is selected as soon as the most urgent message in it, the message not in priorities has the default priority
+	value := big.Zero()
+	priority := priorities[children[0].ID]
+	for _, child := range children {
+		value = big.Add(value, child.Value)
+		if p := priorities[child.ID]; p > priority {
+			priority = p
+		}
+	}
+	parent := &types.Message{
+		ID: venusTypes.NewUUID().String(),
+		Message: venusTypes.Message{
+			From:   w.addr,
+			To:     aggregator,
+			Value:  value,
+			Method: builtin.MethodsEVM.InvokeContract,
+			Params: buf.Bytes(),
+		},
+		WalletName: children[0].WalletName,
+		State:      types.UnFillMsg,
+	}
+	items := make([]*sophonTypes.MessageBatchItem, 0, len(children))
+	for i, child := range children {
+		items = append(items, &sophonTypes.MessageBatchItem{MsgID: child.ID, ParentID: parent.ID, Index: i})
+	}
+
+	if err := w.repo.Transaction(func(txRepo repo.TxRepo) error {
+		if err := txRepo.MessageRepo().CreateMessageWithOptions(parent, &sophonTypes.MessageOptions{Priority: priority}); err != nil {
+			return err
+		}
+		if err := txRepo.MessageBatchRepo().SaveMessageBatch(ctx, items); err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := txRepo.MessageRepo().UpdateMessageStateByID(child.ID, sophonTypes.BatchedMsg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("save batch of %d messages failed: %v", len(children), err)
+	}
+	w.log.Infof("batch %d messages into message %s, value %s", len(children), parent.ID, value)
+
+	events := make([]*sophonTypes.MessageEvent, 0, len(children))
+	for _, child := range children {
+		event := sophonTypes.NewMessageEvent(child)
+		event.State = sophonTypes.BatchedMsg
+		events = append(events, event)
+	}
+	w.eventHub.publish(events...)
+
+	return nil
+}
+
+// updateBatchedMessages lands the collapsed messages with their slice of the batch receipt when the batch message
+// lands on chain, or moves them back to BatchedMsg when it is reverted, returns the updated messages
+func (ms *MessageService) updateBatchedMessages(ctx context.Context, txRepo repo.TxRepo, msg *types.Message, reverted bool) ([]*types.Message, error) {
+	if msg.Method != builtin.MethodsEVM.InvokeContract {
+		return nil, nil
+	}
+	items, err := txRepo.MessageBatchRepo().ListMessageBatch(ctx, msg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list batch of message %s failed: %v", msg.ID, err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var results [][]byte
+	if !reverted && msg.Receipt != nil && msg.Receipt.ExitCode == exitcode.Ok {
+		results, err = decodeBatchReturn(msg.Receipt.Return, len(items))
+		if err != nil {
+			log.Warnf("decode return of batch message %s failed: %v", msg.ID, err)
+		}
+	}
+
+	children := make([]*types.Message, 0, len(items))
+	for _, item := range items {
+		child, err := txRepo.MessageRepo().GetMessageByUid(item.MsgID)
+		if err != nil {
+			return nil, fmt.Errorf("get batched message %s failed: %v", item.MsgID, err)
+		}
+		if reverted {
+			child.State = sophonTypes.BatchedMsg
+			child.Receipt = &venusTypes.MessageReceipt{ExitCode: -1}
+			child.Height = 0
+			child.TipSetKey = venusTypes.EmptyTSK
+		} else {
+			child.State = types.OnChainMsg
+			child.Receipt = &venusTypes.MessageReceipt{ExitCode: -1}
+			if msg.Receipt != nil {
+				child.Receipt.ExitCode = msg.Receipt.ExitCode
+			}
+			if item.Index < len(results) {
+				child.Receipt.Return = results[item.Index]
+			}
+			child.Height = msg.Height
+			child.TipSetKey = msg.TipSetKey
+		}
+		if err := txRepo.MessageRepo().UpdateMessageInfoByID(child.ID, child.Receipt, abi.ChainEpoch(child.Height),
+			child.State, child.TipSetKey); err != nil {
+			return nil, fmt.Errorf("update batched message %s failed: %v", child.ID, err)
+		}
+		children = append(children, child)
+	}
+	log.Infof("update %d messages of batch %s to %s", len(children), msg.ID, sophonTypes.MessageStateString(children[0].State))
+
+	return children, nil
+}
+
+// listBatchedChildren returns the messages collapsed into msg which are still waiting for it
+func listBatchedChildren(ctx context.Context, txRepo repo.TxRepo, msg *types.Message) ([]*types.Message, error) {
+	if msg.Method != builtin.MethodsEVM.InvokeContract {
+		return nil, nil
+	}
+	items, err := txRepo.MessageBatchRepo().ListMessageBatch(ctx, msg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list batch of message %s failed: %v", msg.ID, err)
+	}
+	children := make([]*types.Message, 0, len(items))
+	for _, item := range items {
+		child, err := txRepo.MessageRepo().GetMessageByUid(item.MsgID)
+		if err != nil {
+			return nil, fmt.Errorf("get batched message %s failed: %v", item.MsgID, err)
+		}
+		if child.State == sophonTypes.BatchedMsg {
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+// failBatchedMessages marks the messages collapsed into msg bad together with it, the batch is kept so that
+// they still land with msg if it is recovered
+func (ms *MessageService) failBatchedMessages(ctx context.Context, txRepo repo.TxRepo, msg *types.Message) ([]*types.Message, error) {
+	children, err := listBatchedChildren(ctx, txRepo, msg)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if err := txRepo.MessageRepo().MarkBadMessage(child.ID); err != nil {
+			return nil, fmt.Errorf("mark bad batched message %s failed: %v", child.ID, err)
+		}
+		child.State = types.FailedMsg
+	}
+	if len(children) > 0 {
+		log.Infof("mark bad %d messages of batch %s", len(children), msg.ID)
+	}
+	return children, nil
+}
+
+// releaseBatchedMessages moves the messages collapsed into msg back to UnFillMsg when msg can never land,
+// they are selected again on their own or in another batch
+func (ms *MessageService) releaseBatchedMessages(ctx context.Context, txRepo repo.TxRepo, msg *types.Message) ([]*types.Message, error) {
+	children, err := listBatchedChildren(ctx, txRepo, msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) == 0 {
+		return nil, nil
+	}
+	if err := txRepo.MessageBatchRepo().DeleteMessageBatch(ctx, msg.ID); err != nil {
+		return nil, fmt.Errorf("delete batch of message %s failed: %v", msg.ID, err)
+	}
+	for _, child := range children {
+		if err := txRepo.MessageRepo().UpdateMessageStateByID(child.ID, types.UnFillMsg); err != nil {
+			return nil, fmt.Errorf("release batched message %s failed: %v", child.ID, err)
+		}
+		child.State = types.UnFillMsg
+	}
+	log.Infof("release %d messages of batch %s", len(children), msg.ID)
+	return children, nil
+}
+
+// resolveBatchedMessage fills msg collapsed into a batch with the nonce and cid of batch message,
+// the state follows the batch message before the batch lands on chain
+func (ms *MessageService) resolveBatchedMessage(ctx context.Context, msg *types.Message) error {
+	if msg.UnsignedCid != nil || (msg.State != sophonTypes.BatchedMsg && !sophonTypes.IsOnChain(msg.State)) {
+		return nil
+	}
+	item, err := ms.repo.MessageBatchRepo().GetMessageBatchItem(ctx, msg.ID)
+	if err != nil {
+		if errors.Is(err, repo.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	parent, err := ms.repo.MessageRepo().GetMessageByUid(item.ParentID)
+	if err != nil {
+		return fmt.Errorf("get batch message %s failed: %v", item.ParentID, err)
+	}
+
+	msg.Nonce = parent.Nonce
+	msg.UnsignedCid = parent.UnsignedCid
+	msg.SignedCid = parent.SignedCid
+	if msg.State == sophonTypes.BatchedMsg {
+		msg.State = parent.State
+		msg.Height = parent.Height
+		msg.TipSetKey = parent.TipSetKey
+	}
+	return nil
+}
+
+// encodeAggregate3Value encodes the calldata of aggregate3Value with allowFailure false for all calls
+func encodeAggregate3Value(calls []multicall) []byte {
+	tuples := make([][]byte, 0, len(calls))
+	for _, call := range calls {
+		tuple := make([]byte, 0, abiWordSize*5+len(call.data))
+		tuple = append(tuple, make([]byte, abiWordSize-len(call.target))...)
+		tuple = append(tuple, call.target[:]...)
+		tuple = append(tuple, abiUint(0)...) // allowFailure
+		tuple = append(tuple, call.value.Int.FillBytes(make([]byte, abiWordSize))...)
+		tuple = append(tuple, abiUint(abiWordSize*4)...)
+		tuple = append(tuple, abiBytes(call.data)...)
+		tuples = append(tuples, tuple)
+	}
+
+	buf := append([]byte{}, aggregate3ValueSelector...)
+	buf = append(buf, abiUint(abiWordSize)...)
+	buf = append(buf, abiUint(uint64(len(calls)))...)
+	offset := abiWordSize * len(calls)
+	for _, tuple := range tuples {
+		buf = append(buf, abiUint(uint64(offset))...)
+		offset += len(tuple)
+	}
+	for _, tuple := range tuples {
+		buf = append(buf, tuple...)
+	}
+	return buf
+}
+
+func abiUint(v uint64) []byte {
+	return new(gobig.Int).SetUint64(v).FillBytes(make([]byte, abiWordSize))
+}
+
+func abiBytes(data []byte) []byte {
+	padded := (len(data) + abiWordSize - 1) / abiWordSize * abiWordSize
+	buf := abiUint(uint64(len(data)))
+	buf = append(buf, data...)
+	return append(buf, make([]byte, padded-len(data))...)
+}
+
+// decodeBatchReturn decodes the returnData of each call from the return of aggregate3Value, which is (bool,bytes)[]
+func decodeBatchReturn(ret []byte, count int) ([][]byte, error) {
+	var data abi.CborBytes
+	if err := data.UnmarshalCBOR(bytes.NewReader(ret)); err != nil {
+		return nil, err
+	}
+
+	word := func(pos int) (int, error) {
+		if pos < 0 || pos+abiWordSize > len(data) {
+			return 0, fmt.Errorf("read word at %d out of range %d", pos, len(data))
+		}
+		v := new(gobig.Int).SetBytes(data[pos : pos+abiWordSize])
+		if !v.IsUint64() || v.Uint64() > uint64(len(data)) {
+			return 0, fmt.Errorf("invalid word at %d", pos)
+		}
+		return int(v.Uint64()), nil
+	}
+
+	start, err := word(0)
+	if err != nil {
+		return nil, err
+	}
+	length, err := word(start)
+	if err != nil {
+		return nil, err
+	}
+	if length != count {
+		return nil, fmt.Errorf("expect %d results, got %d", count, length)
+	}
+	base := start + abiWordSize
+	results := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		offset, err := word(base + abiWordSize*i)
+		if err != nil {
+			return nil, err
+		}
+		dataOffset, err := word(base + offset + abiWordSize)
+		if err != nil {
+			return nil, err
+		}
+		dataStart := base + offset + dataOffset
+		size, err := word(dataStart)
+		if err != nil {
+			return nil, err
+		}
+		if dataStart+abiWordSize+size > len(data) {
+			return nil, fmt.Errorf("return data of call %d out of range", i)
+		}
+		results = append(results, data[dataStart+abiWordSize:dataStart+abiWordSize+size])
+	}
+	return results, nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// encodeBatchReturn encodes the return of aggregate3Value like the EVM actor
+func encodeBatchReturn(t *testing.T, results [][]byte) []byte {
+	var tuples [][]byte
+	for _, res := range results {
+		tuple := append(abiUint(1), abiUint(abiWordSize*2)...)
+		tuples = append(tuples, append(tuple, abiBytes(res)...))
+	}
+	data := append(abiUint(abiWordSize), abiUint(uint64(len(results)))...)
+	offset := abiWordSize * len(results)
+	for _, tuple := range tuples {
+		data = append(data, abiUint(uint64(offset))...)
+		offset += len(tuple)
+	}
+	for _, tuple := range tuples {
+		data = append(data, tuple...)
+	}
+
+	ret := abi.CborBytes(data)
+	buf := new(bytes.Buffer)
+	require.NoError(t, ret.MarshalCBOR(buf))
+	return buf.Bytes()
+}
+
+func TestMulticallEncoding(t *testing.T) {
+	var target venusTypes.EthAddress
+	copy(target[:], bytes.Repeat([]byte{0x11}, len(target)))
+
+	data := encodeAggregate3Value([]multicall{{target: target, value: big.NewInt(5)}})
+	require.Len(t, data, 4+abiWordSize*3+abiWordSize*5)
+	assert.Equal(t, aggregate3ValueSelector, data[:4])
+	words := data[4:]
+	word := func(i int) []byte {
+		return words[i*abiWordSize : (i+1)*abiWordSize]
+	}
+	assert.Equal(t, abiUint(abiWordSize), word(0))
+	assert.Equal(t, abiUint(1), word(1))
+	assert.Equal(t, abiUint(abiWordSize), word(2))
+	assert.Equal(t, target[:], word(3)[abiWordSize-len(target):])
+	assert.Equal(t, abiUint(0), word(4))
+	assert.Equal(t, abiUint(5), word(5))
+	assert.Equal(t, abiUint(abiWordSize*4), word(6))
+	assert.Equal(t, abiUint(0), word(7))
+
+	results := [][]byte{{}, []byte("ret"), bytes.Repeat([]byte{1}, 40)}
+	res, err := decodeBatchReturn(encodeBatchReturn(t, results), len(results))
+	require.NoError(t, err)
+	assert.Equal(t, results, res)
+
+	_, err = decodeBatchReturn(encodeBatchReturn(t, results), 2)
+	assert.Error(t, err)
+	ret := encodeBatchReturn(t, results)
+	_, err = decodeBatchReturn(ret[:len(ret)-40], len(results))
+	assert.Error(t, err)
+}
+
+func TestMessageBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+	from := addrs[0]
+
+	aggregator, err := address.NewIDAddress(50000)
+	require.NoError(t, err)
+	var recipients []address.Address
+	for i := 0; i < 3; i++ {
+		to, err := address.NewIDAddress(uint64(50001 + i))
+		require.NoError(t, err)
+		recipients = append(recipients, to)
+	}
+	require.NoError(t, msh.fullNode.AddActors(append([]address.Address{aggregator}, recipients...)))
+	ms.msgSelectMgr.cfg.Batch.Aggregator = aggregator.String()
+
+	push := func(to address.Address, value int64, method abi.MethodNum) string {
+		id, err := ms.PushMessage(ctx, &venusTypes.Message{
+			From:   from,
+			To:     to,
+			Value:  big.NewInt(value),
+			Method: method,
+		}, nil)
+		require.NoError(t, err)
+		return id
+	}
+	var childIDs []string
+	for i, to := range recipients {
+		childIDs = append(childIDs, push(to, int64(i+1), builtin.MethodSend))
+	}
+	// the message to the key address not on chain and the message invoking a method are sent alone
+	alone := []string{push(addrs[1], 10, builtin.MethodSend), push(recipients[0], 0, 2)}
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ms.addressService.SetBatchSend(ctx, from, true))
+
+	selectResult := selectMsgWithAddress(ctx, t, msh, []address.Address{from}, ts)
+	require.Len(t, selectResult.SelectMsg, 3)
+	var batch *types.Message
+	var selected []string
+	for _, msg := range selectResult.SelectMsg {
+		selected = append(selected, msg.ID)
+		if msg.To == aggregator {
+			batch = msg
+		}
+	}
+	require.NotNil(t, batch)
+	assert.Subset(t, selected, alone)
+	assert.Equal(t, builtin.MethodsEVM.InvokeContract, batch.Method)
+	assert.Equal(t, big.NewInt(6), batch.Value)
+
+	items, err := ms.repo.MessageBatchRepo().ListMessageBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	require.Len(t, items, len(childIDs))
+	for i, item := range items {
+		assert.Equal(t, childIDs[i], item.MsgID)
+		assert.Equal(t, i, item.Index)
+
+		state, err := ms.GetMessageState(ctx, item.MsgID)
+		require.NoError(t, err)
+		assert.Equal(t, sophonTypes.BatchedMsg, state)
+		// follows the batch message
+		msg, err := ms.GetMessageByUid(ctx, item.MsgID)
+		require.NoError(t, err)
+		assert.Equal(t, types.FillMsg, msg.State)
+		assert.Equal(t, batch.Nonce, msg.Nonce)
+		assert.Equal(t, batch.SignedCid, msg.SignedCid)
+		assert.Equal(t, recipients[i], msg.To)
+	}
+
+	// the batch message lands on chain
+	results := [][]byte{[]byte("r0"), []byte("r1"), []byte("r2")}
+	_, _, err = ms.updateMessageState([]applyMessage{
+		{
+			signedCID: *batch.SignedCid,
+			msg:       &batch.Message,
+			height:    ts.Height(),
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{ExitCode: exitcode.Ok, Return: encodeBatchReturn(t, results), GasUsed: 100},
+		},
+	}, nil)
+	require.NoError(t, err)
+	for i, id := range childIDs {
+		msg, err := ms.GetMessageByUid(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, types.OnChainMsg, msg.State)
+		assert.Equal(t, exitcode.Ok, msg.Receipt.ExitCode)
+		assert.Equal(t, results[i], msg.Receipt.Return)
+		assert.Equal(t, int64(ts.Height()), msg.Height)
+		assert.Equal(t, batch.SignedCid, msg.SignedCid)
+	}
+
+	// the batch message is reverted
+	_, _, err = ms.updateMessageState(nil, map[cid.Cid]struct{}{*batch.UnsignedCid: {}})
+	require.NoError(t, err)
+	for _, id := range childIDs {
+		state, err := ms.GetMessageState(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, sophonTypes.BatchedMsg, state)
+		msg, err := ms.GetMessageByUid(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, types.FillMsg, msg.State)
+		assert.Equal(t, exitcode.ExitCode(-1), msg.Receipt.ExitCode)
+	}
+
+	// the batch message fails
+	_, err = ms.UpdateMessageInfoByCid(batch.UnsignedCid.String(), &venusTypes.MessageReceipt{ExitCode: exitcode.ErrInsufficientFunds},
+		ts.Height(), types.OnChainMsg, ts.Key())
+	require.NoError(t, err)
+	for _, id := range childIDs {
+		msg, err := ms.GetMessageByUid(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, types.OnChainMsg, msg.State)
+		assert.Equal(t, exitcode.ErrInsufficientFunds, msg.Receipt.ExitCode)
+		assert.Empty(t, msg.Receipt.Return)
+	}
+}
+
+func TestMessageBatchFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+	from := addrs[0]
+
+	aggregator, err := address.NewIDAddress(50000)
+	require.NoError(t, err)
+	var recipients []address.Address
+	for i := 0; i < 4; i++ {
+		to, err := address.NewIDAddress(uint64(50001 + i))
+		require.NoError(t, err)
+		recipients = append(recipients, to)
+	}
+	require.NoError(t, msh.fullNode.AddActors(append([]address.Address{aggregator}, recipients...)))
+	ms.msgSelectMgr.cfg.Batch.Aggregator = aggregator.String()
+
+	var childIDs []string
+	for i, to := range recipients[:3] {
+		id, err := ms.PushMessage(ctx, &venusTypes.Message{From: from, To: to, Value: big.NewInt(int64(i + 1))}, nil)
+		require.NoError(t, err)
+		childIDs = append(childIDs, id)
+	}
+	// the message with a wall-clock deadline is sent alone
+	deadlineID := venusTypes.NewUUID().String()
+	_, err = ms.PushMessageWithSpec(ctx, deadlineID, &venusTypes.Message{From: from, To: recipients[3], Value: big.NewInt(1)},
+		&sophonTypes.SendSpec{MessageOptions: sophonTypes.MessageOptions{ExpireAt: time.Now().Add(time.Hour)}})
+	require.NoError(t, err)
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	require.NoError(t, err)
+	require.NoError(t, ms.addressService.SetBatchSend(ctx, from, true))
+
+	selectBatch := func() *types.Message {
+		selectResult := selectMsgWithAddress(ctx, t, msh, []address.Address{from}, ts)
+		var batch *types.Message
+		for _, msg := range selectResult.SelectMsg {
+			if msg.To == aggregator {
+				batch = msg
+			}
+		}
+		require.NotNil(t, batch)
+		items, err := ms.repo.MessageBatchRepo().ListMessageBatch(ctx, batch.ID)
+		require.NoError(t, err)
+		require.Len(t, items, len(childIDs))
+		return batch
+	}
+	assertState := func(state types.MessageState) {
+		for _, id := range childIDs {
+			res, err := ms.GetMessageState(ctx, id)
+			require.NoError(t, err)
+			assert.Equal(t, state, res)
+		}
+	}
+
+	batch := selectBatch()
+	state, err := ms.GetMessageState(ctx, deadlineID)
+	require.NoError(t, err)
+	assert.Equal(t, types.FillMsg, state)
+	assertState(sophonTypes.BatchedMsg)
+
+	// another message with the nonce of batch lands on chain, the children are sent again
+	replace := batch.Message
+	replace.Value = big.NewInt(100)
+	replaced, _, err := ms.updateMessageState([]applyMessage{
+		{
+			signedCID: replace.Cid(),
+			msg:       &replace,
+			height:    ts.Height(),
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{ExitCode: exitcode.Ok},
+		},
+	}, nil)
+	require.NoError(t, err)
+	assert.Contains(t, replaced, batch.ID)
+	assertState(types.UnFillMsg)
+	items, err := ms.repo.MessageBatchRepo().ListMessageBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Len(t, items, 0)
+
+	// the children fail with the batch marked bad
+	batch = selectBatch()
+	assertState(sophonTypes.BatchedMsg)
+	require.NoError(t, ms.MarkBadMessage(ctx, batch.ID))
+	assertState(types.FailedMsg)
+}
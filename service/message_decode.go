@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	msgparser "github.com/filecoin-project/venus/venus-shared/utils/msg_parser"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+	"github.com/ipfs-force-community/sophon-messager/utils"
+)
+
+// DecodeMessages decodes the params and return of messages by the methods of recipient actors, bitfields and eth addresses
+// are converted to readable forms. The failure of decoding a message is recorded in its DecodeError instead of returned
+func (ms *MessageService) DecodeMessages(ctx context.Context, msgs []*types.Message) ([]*sophonTypes.DecodedMessage, error) {
+	parser, err := msgparser.NewMessageParser(ms.nodeClient)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := make([]*sophonTypes.DecodedMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		res := &sophonTypes.DecodedMessage{Message: msg}
+		if err := decodeMessage(ctx, parser, res); err != nil {
+			res.DecodeError = err.Error()
+		}
+		decoded = append(decoded, res)
+	}
+	return decoded, nil
+}
+
+func decodeMessage(ctx context.Context, parser *msgparser.MessagePaser, res *sophonTypes.DecodedMessage) error {
+	params, ret, err := parser.ParseMessage(ctx, res.Message.VMMessage(), res.Message.Receipt)
+	if err != nil {
+		return err
+	}
+	if res.Params, err = convertToJSON(params); err != nil {
+		return err
+	}
+	res.Return, err = convertToJSON(ret)
+	return err
+}
+
+func convertToJSON(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	converted, err := utils.TryConvertParams(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(converted)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/builtin/v12/miner"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/venus/venus-shared/actors"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+)
+
+func TestDecodeMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	ms := msh.MessageService
+
+	minerAddr, err := address.NewIDAddress(70000)
+	require.NoError(t, err)
+	require.NoError(t, msh.fullNode.AddActors([]address.Address{minerAddr}))
+	minerCode, ok := actors.GetActorCodeID(actorstypes.Version12, manifest.MinerKey)
+	require.True(t, ok)
+	require.NoError(t, msh.fullNode.SetActorCode(minerAddr, minerCode))
+
+	marshal := func(v cbg.CBORMarshaler) []byte {
+		buf := new(bytes.Buffer)
+		require.NoError(t, v.MarshalCBOR(buf))
+		return buf.Bytes()
+	}
+	newMsg := func(method abi.MethodNum, params []byte, receipt *venusTypes.MessageReceipt) *types.Message {
+		return &types.Message{
+			ID: venusTypes.NewUUID().String(),
+			Message: venusTypes.Message{
+				To:     minerAddr,
+				Value:  big.Zero(),
+				Method: method,
+				Params: params,
+			},
+			Receipt: receipt,
+		}
+	}
+
+	amount := big.NewInt(100)
+	ret := abi.TokenAmount(amount)
+	withdraw := newMsg(builtin.MethodsMiner.WithdrawBalance, marshal(&miner.WithdrawBalanceParams{AmountRequested: amount}),
+		&venusTypes.MessageReceipt{ExitCode: exitcode.Ok, Return: marshal(&ret)})
+	// not executed, the return is not decoded
+	pending := newMsg(builtin.MethodsMiner.WithdrawBalance, withdraw.Params, &venusTypes.MessageReceipt{ExitCode: -1})
+	recoveries := newMsg(builtin.MethodsMiner.DeclareFaultsRecovered, marshal(&miner.DeclareFaultsRecoveredParams{
+		Recoveries: []miner.RecoveryDeclaration{{Deadline: 1, Partition: 0, Sectors: bitfield.NewFromSet([]uint64{1, 2, 3, 5})}},
+	}), nil)
+	invalid := newMsg(builtin.MethodsMiner.WithdrawBalance, []byte{0x01}, nil)
+	send := newMsg(builtin.MethodSend, nil, nil)
+
+	decoded, err := ms.DecodeMessages(ctx, []*types.Message{withdraw, pending, recoveries, invalid, send})
+	require.NoError(t, err)
+	require.Len(t, decoded, 5)
+
+	assert.Equal(t, withdraw, decoded[0].Message)
+	assert.Empty(t, decoded[0].DecodeError)
+	assert.JSONEq(t, `{"AmountRequested":"100"}`, string(decoded[0].Params))
+	assert.JSONEq(t, `"100"`, string(decoded[0].Return))
+
+	assert.Empty(t, decoded[1].DecodeError)
+	assert.NotEmpty(t, decoded[1].Params)
+	assert.Empty(t, decoded[1].Return)
+
+	// the bitfield is converted to ranges
+	assert.Empty(t, decoded[2].DecodeError)
+	var params struct {
+		Recoveries []struct {
+			Sectors string
+		}
+	}
+	require.NoError(t, json.Unmarshal(decoded[2].Params, &params))
+	require.Len(t, params.Recoveries, 1)
+	assert.Equal(t, "1-3, 5", params.Recoveries[0].Sectors)
+
+	assert.NotEmpty(t, decoded[3].DecodeError)
+	assert.Empty(t, decoded[3].Params)
+
+	assert.Empty(t, decoded[4].DecodeError)
+	assert.Empty(t, decoded[4].Params)
+	assert.Empty(t, decoded[4].Return)
+}
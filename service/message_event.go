@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	logging "github.com/ipfs/go-log/v2"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var msgEventLog = logging.Logger("msg-event")
+
+// messageEventBufSize the events will be dropped when the subscriber is too slow to consume them
+const messageEventBufSize = 1000
+
+type messageEventSub struct {
+	filter *sophonTypes.MessageEventFilter
+	ch     chan *sophonTypes.MessageEvent
+	cancel context.CancelFunc
+}
+
+// messageEventHub dispatches the events of message state to subscribers, publishing never blocks
+type messageEventHub struct {
+	lk     sync.RWMutex
+	nextID uint64
+	subs   map[uint64]*messageEventSub
+}
+
+func newMessageEventHub() *messageEventHub {
+	return &messageEventHub{
+		subs: make(map[uint64]*messageEventSub),
+	}
+}
+
+// subscribe the returned channel will be closed when ctx is done or closeAll is called
+func (hub *messageEventHub) subscribe(ctx context.Context, filter *sophonTypes.MessageEventFilter) <-chan *sophonTypes.MessageEvent {
+	ctx, cancel := context.WithCancel(ctx)
+	sub := &messageEventSub{
+		filter: filter,
+		ch:     make(chan *sophonTypes.MessageEvent, messageEventBufSize),
+		cancel: cancel,
+	}
+
+	hub.lk.Lock()
+	id := hub.nextID
+	hub.nextID++
+	hub.subs[id] = sub
+	hub.lk.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		hub.lk.Lock()
+		delete(hub.subs, id)
+		close(sub.ch)
+		hub.lk.Unlock()
+	}()
+
+	return sub.ch
+}
+
+// closeAll closes the channels of all subscribers, they should subscribe again
+func (hub *messageEventHub) closeAll() {
+	if hub == nil {
+		return
+	}
+	hub.lk.RLock()
+	defer hub.lk.RUnlock()
+	for _, sub := range hub.subs {
+		sub.cancel()
+	}
+}
+
+func (hub *messageEventHub) publish(events ...*sophonTypes.MessageEvent) {
+	// the hub is nil in some tests
+	if hub == nil || len(events) == 0 {
+		return
+	}
+
+	hub.lk.RLock()
+	defer hub.lk.RUnlock()
+
+	for id, sub := range hub.subs {
+		for _, event := range events {
+			if !sub.filter.Match(event) {
+				continue
+			}
+			select {
+			case sub.ch <- event:
+			default:
+				msgEventLog.Warnf("subscriber %d is too slow, drop event of message %s, state %s", id, event.ID,
+					sophonTypes.MessageStateString(event.State))
+			}
+		}
+	}
+}
+
+// publishMessages publishes the current state of msgs
+func (hub *messageEventHub) publishMessages(msgs ...*types.Message) {
+	if hub == nil || len(msgs) == 0 {
+		return
+	}
+	events := make([]*sophonTypes.MessageEvent, 0, len(msgs))
+	for _, msg := range msgs {
+		events = append(events, sophonTypes.NewMessageEvent(msg))
+	}
+	hub.publish(events...)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageEventHub(t *testing.T) {
+	hub := newMessageEventHub()
+	addrs := testhelper.RandAddresses(t, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	all := hub.subscribe(ctx, nil)
+	byFrom := hub.subscribe(ctx, &sophonTypes.MessageEventFilter{From: addrs[:1]})
+	byIDAndState := hub.subscribe(ctx, &sophonTypes.MessageEventFilter{IDs: []string{"2", "3"}, States: []types.MessageState{types.OnChainMsg}})
+
+	events := []*sophonTypes.MessageEvent{
+		{ID: "1", From: addrs[0], State: types.FillMsg},
+		{ID: "2", From: addrs[1], State: types.FillMsg},
+		{ID: "2", From: addrs[1], State: types.OnChainMsg},
+		{ID: "3", From: addrs[0], State: types.OnChainMsg},
+	}
+	hub.publish(events...)
+
+	assert.Equal(t, events, receiveEvents(t, all, 4))
+	assert.Equal(t, []*sophonTypes.MessageEvent{events[0], events[3]}, receiveEvents(t, byFrom, 2))
+	assert.Equal(t, events[2:], receiveEvents(t, byIDAndState, 2))
+
+	cancel()
+	for _, ch := range []<-chan *sophonTypes.MessageEvent{all, byFrom, byIDAndState} {
+		assert.Eventually(t, func() bool {
+			_, ok := <-ch
+			return !ok
+		}, time.Second, 10*time.Millisecond)
+	}
+	hub.lk.RLock()
+	assert.Len(t, hub.subs, 0)
+	hub.lk.RUnlock()
+
+	// nil hub does nothing
+	var nilHub *messageEventHub
+	nilHub.publish(events...)
+}
+
+func TestMessageEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	addr := addrs[0]
+	events, err := ms.SubscribeMessageEvents(ctx, &sophonTypes.MessageEventFilter{From: []address.Address{addr}})
+	assert.NoError(t, err)
+
+	msgs := genMessages(addrs[:2], 6)
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:2], ts)
+	assert.Len(t, selectResult.SelectMsg, 6)
+
+	// only the messages of addr are received
+	selected := make(map[string]*types.Message)
+	for _, msg := range selectResult.SelectMsg {
+		if msg.From == addr {
+			selected[msg.ID] = msg
+		}
+	}
+	for _, event := range receiveEvents(t, events, 3) {
+		msg, ok := selected[event.ID]
+		assert.True(t, ok)
+		assert.Equal(t, types.FillMsg, event.State)
+		assert.Equal(t, msg.Nonce, event.Nonce)
+		assert.Equal(t, msg.SignedCid, event.SignedCid)
+	}
+
+	// on chain and revert
+	var onChainMsg *types.Message
+	for _, msg := range selected {
+		onChainMsg = msg
+		break
+	}
+	receipt := &venusTypes.MessageReceipt{ExitCode: 0, GasUsed: 100}
+	_, _, err = ms.updateMessageState([]applyMessage{{
+		signedCID: *onChainMsg.SignedCid,
+		msg:       &onChainMsg.Message,
+		height:    abi.ChainEpoch(10),
+		tsk:       ts.Key(),
+		receipt:   receipt,
+	}}, nil)
+	assert.NoError(t, err)
+	event := receiveEvents(t, events, 1)[0]
+	assert.Equal(t, onChainMsg.ID, event.ID)
+	assert.Equal(t, types.OnChainMsg, event.State)
+	assert.Equal(t, abi.ChainEpoch(10), event.Height)
+	assert.Equal(t, ts.Key(), event.TipSetKey)
+	assert.Equal(t, receipt, event.Receipt)
+
+	_, _, err = ms.updateMessageState(nil, map[cid.Cid]struct{}{*onChainMsg.UnsignedCid: {}})
+	assert.NoError(t, err)
+	event = receiveEvents(t, events, 1)[0]
+	assert.Equal(t, onChainMsg.ID, event.ID)
+	assert.Equal(t, types.FillMsg, event.State)
+
+	// mark bad
+	assert.NoError(t, ms.MarkBadMessage(ctx, onChainMsg.ID))
+	event = receiveEvents(t, events, 1)[0]
+	assert.Equal(t, onChainMsg.ID, event.ID)
+	assert.Equal(t, types.FailedMsg, event.State)
+
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %v", event)
+	default:
+	}
+}
+
+func receiveEvents(t *testing.T, ch <-chan *sophonTypes.MessageEvent, count int) []*sophonTypes.MessageEvent {
+	events := make([]*sophonTypes.MessageEvent, 0, count)
+	timer := time.NewTimer(5 * time.Second)
+	defer timer.Stop()
+	for len(events) < count {
+		select {
+		case event := <-ch:
+			events = append(events, event)
+		case <-timer.C:
+			t.Fatalf("expect %d events, got %d", count, len(events))
+		}
+	}
+	return events
+}
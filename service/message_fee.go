@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/pkg/vm/gas"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// newMessageFee computes the gas spent by the applied message with the same rules as the vm
+func newMessageFee(id string, msg applyMessage) *sophonTypes.MessageFee {
+	baseFee := msg.baseFee
+	if baseFee.Nil() {
+		baseFee = big.Zero()
+	}
+	out := gas.ComputeGasOutputs(msg.receipt.GasUsed, msg.msg.GasLimit, baseFee, msg.msg.GasFeeCap, msg.msg.GasPremium, true)
+
+	return &sophonTypes.MessageFee{
+		MsgID:              id,
+		From:               msg.msg.From,
+		To:                 msg.msg.To,
+		Method:             msg.msg.Method,
+		Height:             int64(msg.height),
+		OnChainTime:        time.Unix(int64(msg.timestamp), 0),
+		GasUsed:            msg.receipt.GasUsed,
+		GasLimit:           msg.msg.GasLimit,
+		GasFeeCap:          msg.msg.GasFeeCap,
+		GasPremium:         msg.msg.GasPremium,
+		BaseFee:            baseFee,
+		BaseFeeBurn:        out.BaseFeeBurn,
+		OverEstimationBurn: out.OverEstimationBurn,
+		MinerTip:           out.MinerTip,
+		MinerPenalty:       out.MinerPenalty,
+		TotalCost:          big.Sum(out.BaseFeeBurn, out.OverEstimationBurn, out.MinerTip),
+	}
+}
+
+// FeeReport sums the fees of on chain messages matched params by group
+func (ms *MessageService) FeeReport(ctx context.Context, params *sophonTypes.FeeReportParams) (*sophonTypes.FeeReport, error) {
+	if len(params.GroupBy) == 0 {
+		params.GroupBy = sophonTypes.FeeReportByActor
+	}
+	if !params.GroupBy.Valid() {
+		return nil, fmt.Errorf("invalid group by %s, expect one of %s, %s and %s", params.GroupBy,
+			sophonTypes.FeeReportByActor, sophonTypes.FeeReportByMethod, sophonTypes.FeeReportByDay)
+	}
+	if !params.Since.IsZero() && !params.Until.IsZero() && !params.Since.Before(params.Until) {
+		return nil, fmt.Errorf("since %s must be before until %s", params.Since, params.Until)
+	}
+
+	fees, err := ms.repo.MessageFeeRepo().ListMessageFee(ctx, params.From, params.Since, params.Until)
+	if err != nil {
+		return nil, fmt.Errorf("list message fee failed: %w", err)
+	}
+
+	report := &sophonTypes.FeeReport{
+		GroupBy: params.GroupBy,
+		Since:   params.Since,
+		Until:   params.Until,
+		Items:   []*sophonTypes.FeeReportItem{},
+		Total:   sophonTypes.NewFeeReportItem("total"),
+	}
+	items := make(map[string]*sophonTypes.FeeReportItem)
+	for _, fee := range fees {
+		var key string
+		switch params.GroupBy {
+		case sophonTypes.FeeReportByActor:
+			key = fee.To.String()
+		case sophonTypes.FeeReportByMethod:
+			key = strconv.FormatUint(uint64(fee.Method), 10)
+		case sophonTypes.FeeReportByDay:
+			key = fee.OnChainTime.UTC().Format("2006-01-02")
+		}
+		item, ok := items[key]
+		if !ok {
+			item = sophonTypes.NewFeeReportItem(key)
+			items[key] = item
+			report.Items = append(report.Items, item)
+		}
+		item.Add(fee)
+		report.Total.Add(fee)
+	}
+	sort.Slice(report.Items, func(i, j int) bool {
+		if params.GroupBy == sophonTypes.FeeReportByMethod {
+			mi, _ := strconv.ParseUint(report.Items[i].Key, 10, 64)
+			mj, _ := strconv.ParseUint(report.Items[j].Key, 10, 64)
+			return mi < mj
+		}
+		return report.Items[i].Key < report.Items[j].Key
+	})
+
+	return report, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestNewMessageFee(t *testing.T) {
+	msg := &venusTypes.Message{
+		GasLimit:   1000,
+		GasFeeCap:  big.NewInt(200),
+		GasPremium: big.NewInt(10),
+	}
+	applyMsg := applyMessage{
+		msg:       msg,
+		height:    abi.ChainEpoch(10),
+		receipt:   &venusTypes.MessageReceipt{GasUsed: 800},
+		baseFee:   big.NewInt(100),
+		timestamp: 1000,
+	}
+
+	fee := newMessageFee("id", applyMsg)
+	assert.Equal(t, big.NewInt(100*800), fee.BaseFeeBurn)
+	assert.Equal(t, big.NewInt(10*1000), fee.MinerTip)
+	// 30 gas burned for over estimation: (1000-800)*(1000-800*1.1)/800
+	assert.Equal(t, big.NewInt(100*30), fee.OverEstimationBurn)
+	assert.Equal(t, big.Sum(fee.BaseFeeBurn, fee.OverEstimationBurn, fee.MinerTip), fee.TotalCost)
+	assert.True(t, fee.MinerPenalty.IsZero())
+	assert.Equal(t, time.Unix(1000, 0), fee.OnChainTime)
+
+	// fee cap is lower than base fee, the miner pays the penalty
+	applyMsg.baseFee = big.NewInt(300)
+	fee = newMessageFee("id", applyMsg)
+	assert.Equal(t, big.NewInt(200*800), fee.BaseFeeBurn)
+	assert.Equal(t, big.NewInt(100*(800+30)), fee.MinerPenalty)
+	assert.True(t, fee.MinerTip.IsZero())
+
+	// base fee is unknown
+	applyMsg.baseFee = big.Int{}
+	fee = newMessageFee("id", applyMsg)
+	assert.True(t, fee.BaseFeeBurn.IsZero())
+	assert.True(t, fee.BaseFee.IsZero())
+}
+
+func TestFeeReport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()[:2]
+	ms := msh.MessageService
+
+	assert.NoError(t, pushMessage(ctx, ms, genMessages(addrs, 4)))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs, ts)
+	assert.Len(t, selectResult.SelectMsg, 4)
+
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	applyMsgs := make([]applyMessage, 0, len(selectResult.SelectMsg))
+	for i, msg := range selectResult.SelectMsg {
+		applyMsgs = append(applyMsgs, applyMessage{
+			signedCID: *msg.SignedCid,
+			msg:       &msg.Message,
+			height:    abi.ChainEpoch(10),
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{ExitCode: 0, GasUsed: msg.GasLimit / 2},
+			baseFee:   big.NewInt(100),
+			timestamp: uint64(day.Add(time.Duration(i) * 24 * time.Hour).Unix()),
+		})
+	}
+	_, _, err = ms.updateMessageState(applyMsgs, nil)
+	assert.NoError(t, err)
+
+	total := big.Zero()
+	for i, msg := range selectResult.SelectMsg {
+		fee, err := ms.repo.MessageFeeRepo().GetMessageFee(ctx, msg.ID)
+		assert.NoError(t, err)
+		expect := newMessageFee(msg.ID, applyMsgs[i])
+		assert.Equal(t, expect.TotalCost, fee.TotalCost)
+		assert.Equal(t, msg.GasLimit/2, fee.GasUsed)
+		total = big.Add(total, fee.TotalCost)
+	}
+
+	t.Run("group by actor", func(t *testing.T) {
+		report, err := ms.FeeReport(ctx, &sophonTypes.FeeReportParams{GroupBy: sophonTypes.FeeReportByActor})
+		assert.NoError(t, err)
+		assert.Len(t, report.Items, 2)
+		assert.Equal(t, 4, report.Total.Count)
+		assert.Equal(t, total, report.Total.TotalCost)
+		for _, item := range report.Items {
+			assert.Equal(t, 2, item.Count)
+		}
+	})
+
+	t.Run("group by method", func(t *testing.T) {
+		report, err := ms.FeeReport(ctx, &sophonTypes.FeeReportParams{GroupBy: sophonTypes.FeeReportByMethod})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, report.Items)
+		assert.Equal(t, total, report.Total.TotalCost)
+	})
+
+	t.Run("group by day", func(t *testing.T) {
+		report, err := ms.FeeReport(ctx, &sophonTypes.FeeReportParams{
+			From:    []address.Address{addrs[0]},
+			Since:   day.Truncate(24 * time.Hour),
+			GroupBy: sophonTypes.FeeReportByDay,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, report.Items, 2)
+		for _, item := range report.Items {
+			assert.Equal(t, 1, item.Count)
+		}
+
+		report, err = ms.FeeReport(ctx, &sophonTypes.FeeReportParams{
+			Since:   day.Add(24 * time.Hour).Truncate(24 * time.Hour),
+			Until:   day.Add(3 * 24 * time.Hour).Truncate(24 * time.Hour),
+			GroupBy: sophonTypes.FeeReportByDay,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, report.Items, 2)
+		assert.Equal(t, "2024-01-02", report.Items[0].Key)
+		assert.Equal(t, "2024-01-03", report.Items[1].Key)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := ms.FeeReport(ctx, &sophonTypes.FeeReportParams{GroupBy: "miner"})
+		assert.Error(t, err)
+		_, err = ms.FeeReport(ctx, &sophonTypes.FeeReportParams{Since: day, Until: day})
+		assert.Error(t, err)
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		msg := selectResult.SelectMsg[0]
+		_, _, err := ms.updateMessageState(nil, map[cid.Cid]struct{}{*msg.UnsignedCid: {}})
+		assert.NoError(t, err)
+		_, err = ms.repo.MessageFeeRepo().GetMessageFee(ctx, msg.ID)
+		assert.ErrorIs(t, err, repo.ErrRecordNotFound)
+
+		report, err := ms.FeeReport(ctx, &sophonTypes.FeeReportParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Total.Count)
+	})
+}
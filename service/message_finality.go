@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	venustypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// finalizeBatchSize the max number of messages finalized in a transaction
+var finalizeBatchSize = 1000
+
+// finalizeMessage promotes the OnChainMsg messages which will not be reverted anymore to FinalizedMsg,
+// it is only called by the goroutine refreshing message state after processing a new head
+func (ms *MessageService) finalizeMessage(ctx context.Context, head *venustypes.TipSet) error {
+	cfg := ms.fsRepo.Config().MessageService.Finality
+	if !cfg.Enable {
+		return nil
+	}
+
+	height := ms.getFinalizedHeight(ctx, head, cfg)
+	if height <= ms.finalizedHeight {
+		return nil
+	}
+
+	// the messages are finalized in batches, there may be the whole history of messages to finalize after enabling.
+	// The events are only published when all messages fit in one batch, rather than for a backfill
+	backfill := false
+	total := 0
+	for {
+		var msgs []*types.Message
+		err := ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+			var err error
+			msgs, err = txRepo.MessageRepo().ListChainMessageBelowHeight(height, finalizeBatchSize)
+			if err != nil {
+				return err
+			}
+			if len(msgs) == 0 {
+				return nil
+			}
+			ids := make([]string, 0, len(msgs))
+			for _, msg := range msgs {
+				ids = append(ids, msg.ID)
+			}
+			return txRepo.MessageRepo().FinalizeMessage(ids)
+		})
+		if err != nil {
+			return fmt.Errorf("finalize message below height %d failed: %v", height, err)
+		}
+		total += len(msgs)
+		if len(msgs) == finalizeBatchSize {
+			backfill = true
+			continue
+		}
+
+		if !backfill {
+			events := make([]*sophonTypes.MessageEvent, 0, len(msgs))
+			for _, msg := range msgs {
+				msg.State = sophonTypes.FinalizedMsg
+				events = append(events, sophonTypes.NewMessageEvent(msg))
+			}
+			ms.eventHub.publish(events...)
+		}
+		break
+	}
+	ms.finalizedHeight = height
+	if total > 0 {
+		msgStateLog.Infof("finalize %d messages below height %d, backfill %v", total, height, backfill)
+	}
+
+	return nil
+}
+
+// getFinalizedHeight returns the height of the latest tipset which will not be reverted, it is the higher one of
+// the tipset which is `Epochs` lower than head and the tipset finalized by F3
+func (ms *MessageService) getFinalizedHeight(ctx context.Context, head *venustypes.TipSet, cfg config.FinalityConfig) abi.ChainEpoch {
+	epochs := cfg.Epochs
+	if epochs <= 0 {
+		epochs = config.DefaultFinalityEpochs
+	}
+	height := head.Height() - abi.ChainEpoch(epochs)
+
+	if cfg.EnableF3 {
+		cert, err := ms.nodeClient.F3GetLatestCertificate(ctx)
+		if err != nil {
+			msgStateLog.Warnf("get latest F3 certificate failed: %v", err)
+			return height
+		}
+		if cert != nil {
+			if ts := cert.ECChain.Head(); ts != nil && abi.ChainEpoch(ts.Epoch) > height {
+				// the tipset finalized by F3 can't be higher than the head
+				height = min(abi.ChainEpoch(ts.Epoch), head.Height())
+			}
+		}
+	}
+
+	return height
+}
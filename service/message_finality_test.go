@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestFinalizeMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	events, err := ms.SubscribeMessageEvents(ctx, &sophonTypes.MessageEventFilter{
+		States: []types.MessageState{sophonTypes.FinalizedMsg},
+	})
+	require.NoError(t, err)
+
+	msgs := genMessages(addrs[:1], 2)
+	require.NoError(t, pushMessage(ctx, ms, msgs))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	require.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	require.Len(t, selectResult.SelectMsg, 2)
+
+	// the first message is 10 epochs lower than head, the second one is 2 epochs lower
+	head, err := testhelper.GenTipset(100, 1, ts.Cids())
+	require.NoError(t, err)
+	heights := []abi.ChainEpoch{head.Height() - 10, head.Height() - 2}
+	var applyMsgs []applyMessage
+	for idx, msg := range selectResult.SelectMsg {
+		applyMsgs = append(applyMsgs, applyMessage{
+			signedCID: *msg.SignedCid,
+			msg:       &msg.Message,
+			height:    heights[idx],
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{},
+		})
+	}
+	_, _, err = ms.updateMessageState(applyMsgs, nil)
+	require.NoError(t, err)
+	finalized, unfinalized := selectResult.SelectMsg[0], selectResult.SelectMsg[1]
+
+	// disabled
+	cfg := ms.fsRepo.Config()
+	require.NoError(t, ms.finalizeMessage(ctx, head))
+	msg, err := ms.GetMessageByUid(ctx, finalized.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, msg.State)
+
+	cfg.MessageService.Finality.Enable = true
+	cfg.MessageService.Finality.Epochs = 5
+	require.NoError(t, ms.finalizeMessage(ctx, head))
+	assert.Equal(t, head.Height()-5, ms.finalizedHeight)
+
+	msg, err = ms.GetMessageByUid(ctx, finalized.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sophonTypes.FinalizedMsg, msg.State)
+	assert.Equal(t, int64(heights[0]), msg.Height)
+	msg, err = ms.GetMessageByUid(ctx, unfinalized.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, msg.State)
+
+	received := receiveEvents(t, events, 1)
+	assert.Equal(t, finalized.ID, received[0].ID)
+	assert.Equal(t, heights[0], received[0].Height)
+
+	// the finalized message is returned without waiting for confidence, the mock chain head is lower than it
+	msg, err = ms.WaitMessage(ctx, finalized.ID, 100)
+	require.NoError(t, err)
+	assert.Equal(t, sophonTypes.FinalizedMsg, msg.State)
+
+	list, err := ms.ListMessage(ctx, &types.MsgQueryParams{
+		State: []types.MessageState{sophonTypes.FinalizedMsg},
+		From:  []address.Address{addrs[0]},
+	})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, finalized.ID, list[0].ID)
+
+	// the finalized message is not reverted
+	var revert []*venusTypes.TipSet
+	for _, height := range heights {
+		revertTS, err := testhelper.GenTipset(height, 1, ts.Cids())
+		require.NoError(t, err)
+		revert = append(revert, revertTS)
+	}
+	revertMsgs, err := ms.processRevertHead(ctx, &headChan{revert: revert})
+	require.NoError(t, err)
+	require.Len(t, revertMsgs, 1)
+	assert.Contains(t, revertMsgs, *unfinalized.UnsignedCid)
+
+	// the messages more than a batch are finalized without events
+	defer func(size int) { finalizeBatchSize = size }(finalizeBatchSize)
+	finalizeBatchSize = 1
+	head, err = testhelper.GenTipset(200, 1, ts.Cids())
+	require.NoError(t, err)
+	msgs = genMessages(addrs[:1], 2)
+	require.NoError(t, pushMessage(ctx, ms, msgs))
+	selectResult = selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	require.Len(t, selectResult.SelectMsg, 2)
+	applyMsgs = applyMsgs[:0]
+	for _, msg := range selectResult.SelectMsg {
+		applyMsgs = append(applyMsgs, applyMessage{
+			signedCID: *msg.SignedCid,
+			msg:       &msg.Message,
+			height:    head.Height() - 10,
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{},
+		})
+	}
+	_, _, err = ms.updateMessageState(applyMsgs, nil)
+	require.NoError(t, err)
+	head, err = testhelper.GenTipset(300, 1, ts.Cids())
+	require.NoError(t, err)
+	require.NoError(t, ms.finalizeMessage(ctx, head))
+	for _, selected := range selectResult.SelectMsg {
+		msg, err = ms.GetMessageByUid(ctx, selected.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sophonTypes.FinalizedMsg, msg.State)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event of message %s", event.ID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
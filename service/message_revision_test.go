@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs-force-community/sophon-auth/core"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMessageRevisions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	msgs := genMessages(addrs[:1], 2)
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 2)
+
+	for _, msg := range selectResult.SelectMsg {
+		revisions, err := ms.GetMessageRevisions(ctx, msg.ID)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 1)
+		assert.Equal(t, sophonTypes.RevisionSelect, revisions[0].Reason)
+		assert.Equal(t, *msg.SignedCid, revisions[0].SignedCid)
+		assert.Equal(t, *msg.UnsignedCid, revisions[0].UnsignedCid)
+		assert.Equal(t, msg.GasPremium, revisions[0].GasPremium)
+		assert.Empty(t, revisions[0].Operator)
+	}
+
+	oldMsg := selectResult.SelectMsg[0]
+	conflictMsg := selectResult.SelectMsg[1]
+	_, err = ms.ReplaceMessage(core.CtxWithName(ctx, "alice"), &types.ReplacMessageParams{
+		ID:   oldMsg.ID,
+		Auto: true,
+	})
+	assert.NoError(t, err)
+
+	revisions, err := ms.GetMessageRevisions(ctx, oldMsg.ID)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, *oldMsg.SignedCid, revisions[0].SignedCid)
+	assert.Equal(t, sophonTypes.RevisionReplace, revisions[1].Reason)
+	assert.Equal(t, "alice", revisions[1].Operator)
+	assert.NotEqual(t, revisions[0].SignedCid, revisions[1].SignedCid)
+
+	replacedMsg, err := ms.GetMessageByUid(ctx, oldMsg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, revisions[1].SignedCid, *replacedMsg.SignedCid)
+
+	// the old version of oldMsg and a message sent out of messager land on chain
+	otherMsg := conflictMsg.Message
+	otherMsg.GasPremium = big.Add(otherMsg.GasPremium, big.NewInt(1))
+	otherSignedMsg := testhelper.NewShareSignedMessage()
+	otherSignedMsg.Message = otherMsg
+
+	applyMsgs := []applyMessage{
+		{
+			signedCID: *oldMsg.SignedCid,
+			msg:       &oldMsg.Message,
+			height:    abi.ChainEpoch(10),
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{ExitCode: 0, GasUsed: 100},
+		},
+		{
+			signedCID: otherSignedMsg.Cid(),
+			msg:       &otherMsg,
+			height:    abi.ChainEpoch(10),
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{ExitCode: 0, GasUsed: 100},
+		},
+	}
+	replaced, _, err := ms.updateMessageState(applyMsgs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, replaced, 1)
+	assert.Contains(t, replaced, conflictMsg.ID)
+
+	onChainMsg, err := ms.GetMessageByUid(ctx, oldMsg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.OnChainMsg, onChainMsg.State)
+	assert.Equal(t, *oldMsg.SignedCid, *onChainMsg.SignedCid)
+	assert.Equal(t, *oldMsg.UnsignedCid, *onChainMsg.UnsignedCid)
+	assert.Equal(t, oldMsg.GasPremium, onChainMsg.GasPremium)
+	assert.Equal(t, oldMsg.GasFeeCap, onChainMsg.GasFeeCap)
+	assert.Equal(t, oldMsg.Signature, onChainMsg.Signature)
+	assert.Equal(t, int64(10), onChainMsg.Height)
+
+	res, err := ms.GetMessageByUid(ctx, conflictMsg.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.NonceConflictMsg, res.State)
+}
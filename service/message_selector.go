@@ -35,11 +35,14 @@ import (
 	"github.com/ipfs-force-community/sophon-messager/metrics"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 	"github.com/ipfs-force-community/sophon-messager/publisher"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 	"github.com/ipfs-force-community/sophon-messager/utils"
 )
 
 const (
-	gasEstimate = "gas estimate: "
+	gasEstimate         = "gas estimate: "
+	insufficientBalance = "insufficient balance: "
+	preflightFailed     = "preflight: "
 )
 
 var msgSelectLog = logging.Logger("msg-select")
@@ -55,6 +58,9 @@ type MsgSelectMgr struct {
 
 	works       map[address.Address]*work
 	msgReceiver publisher.MessageReceiver
+	eventHub    *messageEventHub
+	leader      *leaderElector
+	shard       *addressSharder
 	lk          sync.Mutex
 }
 
@@ -66,7 +72,14 @@ func newMsgSelectMgr(ctx context.Context,
 	sps *SharedParamsService,
 	walletClient gatewayAPI.IWalletClient,
 	msgReceiver publisher.MessageReceiver,
+	eventHub *messageEventHub,
+	leader *leaderElector,
+	shard *addressSharder,
 ) (*MsgSelectMgr, error) {
+	if _, err := GetSelectionStrategy(cfg.SelectStrategy); err != nil {
+		return nil, err
+	}
+
 	ms := &MsgSelectMgr{
 		ctx:            ctx,
 		repo:           repo,
@@ -77,6 +90,9 @@ func newMsgSelectMgr(ctx context.Context,
 		walletClient:   walletClient,
 
 		msgReceiver: msgReceiver,
+		eventHub:    eventHub,
+		leader:      leader,
+		shard:       shard,
 		works:       make(map[address.Address]*work),
 	}
 
@@ -121,6 +137,41 @@ func (msgSelectMgr *MsgSelectMgr) SelectMessage(ctx context.Context, ts *venusTy
 	return nil
 }
 
+// SimulateSelect runs the selection of addr against the current head, nothing will be signed or saved
+func (msgSelectMgr *MsgSelectMgr) SimulateSelect(ctx context.Context, addr address.Address) (*sophonTypes.SelectSimulation, error) {
+	addrInfo, err := msgSelectMgr.addressService.GetAddress(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	sharedParams, err := msgSelectMgr.sps.GetSharedParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	activeAddrs, err := msgSelectMgr.addressService.ListActiveAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	selMsgNum, ok := addrSelectMsgNum(activeAddrs, sharedParams.SelMsgNum)[addr]
+	if !ok {
+		return nil, fmt.Errorf("address %s is not active", addr)
+	}
+
+	ts, err := msgSelectMgr.fullNode.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliedNonce, err := msgSelectMgr.getNonceInTipset(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	// use a new work to avoid blocking the running one
+	w := newWork(ctx, addr, msgSelectMgr.cfg, msgSelectMgr.fullNode, msgSelectMgr.repo, msgSelectMgr.addressService, msgSelectMgr.walletClient, msgSelectMgr.msgReceiver, msgSelectMgr.eventHub, msgSelectMgr.leader, msgSelectMgr.shard)
+	defer w.close()
+
+	return w.simulateSelect(ctx, appliedNonce, addrInfo, ts, selMsgNum, sharedParams)
+}
+
 func (msgSelectMgr *MsgSelectMgr) getNonceInTipset(ctx context.Context, ts *venusTypes.TipSet) (*utils.NonceMap, error) {
 	applied := utils.NewNonceMap()
 	selectMsg := func(m *venusTypes.Message) error {
@@ -155,10 +206,14 @@ func (msgSelectMgr *MsgSelectMgr) getNonceInTipset(ctx context.Context, ts *venu
 func (msgSelectMgr *MsgSelectMgr) tryUpdateWorks(addrInfos map[address.Address]*types.Address) error {
 	ws := make(map[address.Address]*work, len(addrInfos))
 	for _, addrInfo := range addrInfos {
+		// the addresses of other shards are selected by other instances
+		if !msgSelectMgr.shard.owns(addrInfo.Addr) {
+			continue
+		}
 		w, ok := msgSelectMgr.works[addrInfo.Addr]
 		if !ok {
 			msgSelectLog.Infof("add a work %v", addrInfo.Addr)
-			ws[addrInfo.Addr] = newWork(msgSelectMgr.ctx, addrInfo.Addr, msgSelectMgr.cfg, msgSelectMgr.fullNode, msgSelectMgr.repo, msgSelectMgr.addressService, msgSelectMgr.walletClient, msgSelectMgr.msgReceiver)
+			ws[addrInfo.Addr] = newWork(msgSelectMgr.ctx, addrInfo.Addr, msgSelectMgr.cfg, msgSelectMgr.fullNode, msgSelectMgr.repo, msgSelectMgr.addressService, msgSelectMgr.walletClient, msgSelectMgr.msgReceiver, msgSelectMgr.eventHub, msgSelectMgr.leader, msgSelectMgr.shard)
 		} else {
 			ws[addrInfo.Addr] = w
 			delete(msgSelectMgr.works, addrInfo.Addr)
@@ -213,6 +268,18 @@ func recordMetric(ctx context.Context, addr address.Address, selectResult *MsgSe
 	metrics.SelectedMsgNumOfLastRound.Set(ctx, int64(len(selectResult.SelectMsg)))
 	metrics.ToPushMsgNumOfLastRound.Set(ctx, int64(len(selectResult.ToPushMsg)))
 	metrics.ErrMsgNumOfLastRound.Set(ctx, int64(len(selectResult.ErrMsg)))
+
+	lowBalance, overBudget := 0, 0
+	for _, msg := range selectResult.ErrMsg {
+		if strings.HasPrefix(msg.err, insufficientBalance) {
+			lowBalance++
+		}
+		if strings.HasPrefix(msg.err, exceedSpendBudget) {
+			overBudget++
+		}
+	}
+	metrics.LowBalanceMsgNumOfLastRound.Set(ctx, int64(lowBalance))
+	metrics.OverBudgetMsgNumOfLastRound.Set(ctx, int64(overBudget))
 }
 
 var errSingMessage = errors.New("sign message failed")
@@ -222,6 +289,8 @@ type MsgSelectResult struct {
 	SelectMsg []*types.Message
 	ToPushMsg []*venusTypes.SignedMessage
 	ErrMsg    []msgErrInfo
+	// Spends the spends of selected messages, counted in the spend budget of address
+	Spends []*sophonTypes.AddressSpend
 }
 
 type msgErrInfo struct {
@@ -240,6 +309,9 @@ type work struct {
 	addressService *AddressService
 	walletClient   gatewayAPI.IWalletClient
 	msgReceiver    publisher.MessageReceiver
+	eventHub       *messageEventHub
+	leader         *leaderElector
+	shard          *addressSharder
 
 	start       time.Time
 	controlChan chan struct{}
@@ -257,6 +329,9 @@ func newWork(ctx context.Context,
 	addressService *AddressService,
 	walletClient gatewayAPI.IWalletClient,
 	msgReceiver publisher.MessageReceiver,
+	eventHub *messageEventHub,
+	leader *leaderElector,
+	shard *addressSharder,
 ) *work {
 	ctx, cancel := context.WithCancel(ctx)
 	cache, _ := lru.NewARC(100)
@@ -270,6 +345,9 @@ func newWork(ctx context.Context,
 		repo:           repo,
 		walletClient:   walletClient,
 		msgReceiver:    msgReceiver,
+		eventHub:       eventHub,
+		leader:         leader,
+		shard:          shard,
 		controlChan:    make(chan struct{}, 1),
 		actorCache:     cache,
 		log:            msgSelectLog.With("address", addr),
@@ -283,7 +361,7 @@ func (w *work) startSelectMessage(
 	maxAllowPendingMessage uint64,
 	sharedParams *types.SharedSpec,
 ) {
-	// first check w.ctx, avoid w.controlChan closed
+	// first check w.ctx, the work may be removed
 	select {
 	case <-w.ctx.Done():
 		w.log.Infof("context done: %s, skip select message", w.ctx.Err())
@@ -341,6 +419,28 @@ func (w *work) startSelectMessage(
 }
 
 func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap, addrInfo *types.Address, ts *venusTypes.TipSet, maxAllowPendingMessage uint64, sharedParams *types.SharedSpec) (*MsgSelectResult, error) {
+	return w.runSelect(ctx, appliedNonce, addrInfo, ts, maxAllowPendingMessage, sharedParams, nil)
+}
+
+// simulateSelect runs the same path as selectMessage, but stops before signing and saving
+func (w *work) simulateSelect(ctx context.Context, appliedNonce *utils.NonceMap, addrInfo *types.Address, ts *venusTypes.TipSet, maxAllowPendingMessage uint64, sharedParams *types.SharedSpec) (*sophonTypes.SelectSimulation, error) {
+	sim := newSelectSimulation(ts)
+	sim.res.Address = w.addr
+	if _, err := w.runSelect(ctx, appliedNonce, addrInfo, ts, maxAllowPendingMessage, sharedParams, sim); err != nil {
+		return nil, err
+	}
+	return sim.res, nil
+}
+
+// runSelect nothing will be written to database or wallet if sim is not nil
+func (w *work) runSelect(ctx context.Context,
+	appliedNonce *utils.NonceMap,
+	addrInfo *types.Address,
+	ts *venusTypes.TipSet,
+	maxAllowPendingMessage uint64,
+	sharedParams *types.SharedSpec,
+	sim *selectSimulation,
+) (*MsgSelectResult, error) {
 	// 没有绑定账号肯定无法签名
 	accounts, err := w.addressService.GetAccountsOfSigner(ctx, addrInfo.Addr)
 	if err != nil {
@@ -348,10 +448,11 @@ func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap,
 	}
 
 	// 判断是否需要推送消息
-	nonceInLatestTs, actorNonce, err := w.getNonce(ctx, ts, appliedNonce)
+	nonceInLatestTs, actor, err := w.getNonce(ctx, ts, appliedNonce)
 	if err != nil {
 		return nil, err
 	}
+	actorNonce := actor.Nonce
 	if nonceInLatestTs > addrInfo.Nonce {
 		w.log.Warnf("nonce in db %d is smaller than nonce on chain %d, update to latest", addrInfo.Nonce, nonceInLatestTs)
 		addrInfo.Nonce = nonceInLatestTs
@@ -363,18 +464,42 @@ func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap,
 				w.log.Warnf("max message nonce in db %d", maxMsgNonce)
 			}
 		}
-		err = w.repo.AddressRepo().SaveAddress(ctx, addrInfo)
-		if err != nil {
-			return nil, fmt.Errorf("update nonce failed: %v", err)
+		if !sim.dryRun() {
+			err = w.repo.AddressRepo().SaveAddress(ctx, addrInfo)
+			if err != nil {
+				return nil, fmt.Errorf("update nonce failed: %v", err)
+			}
+		}
+	}
+
+	if !sim.dryRun() && w.autoFillNonceGap(ctx) {
+		if _, err := w.fillNonceGaps(ctx, ts, nonceInLatestTs, actor, addrInfo, sharedParams, accounts); err != nil {
+			w.log.Errorf("fill nonce gap failed: %v", err)
 		}
 	}
 
 	toPushMessage := w.getFilledMessage(nonceInLatestTs)
 
+	// the signed messages not on chain yet will take their cost from the balance first
+	reserve := w.balanceReserve(ctx)
+	spendable := big.Sub(actor.Balance, reserve)
+	for _, msg := range toPushMessage {
+		spendable = big.Sub(spendable, maxMessageCost(&msg.Message))
+	}
+
 	// calc the message needed
 	nonceGap := addrInfo.Nonce - nonceInLatestTs
+	if sim.dryRun() {
+		sim.res.ActorNonce = actorNonce
+		sim.res.NonceInLatestTs = nonceInLatestTs
+		sim.res.AssignedNonce = addrInfo.Nonce
+		sim.res.NonceGap = nonceGap
+		sim.res.Balance = actor.Balance
+		sim.res.BalanceReserve = reserve
+	}
 	if nonceGap >= maxAllowPendingMessage {
 		w.log.Warnf("there are %d message not to be package, nonce gap: %d", len(toPushMessage), nonceGap)
+		sim.setReason(fmt.Sprintf("nonce gap %d reaches the max pending message %d", nonceGap, maxAllowPendingMessage))
 		return &MsgSelectResult{
 			ToPushMsg: toPushMessage,
 			Address:   addrInfo,
@@ -382,31 +507,86 @@ func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap,
 	}
 	wantCount := maxAllowPendingMessage - nonceGap
 
+	// the expired message should not take the place of others
+	expired, err := w.expireMessages(ts, sim.dryRun())
+	if err != nil {
+		w.log.Errorf("expire message failed: %v", err)
+	}
+	if !sim.dryRun() {
+		if err := w.batchMessages(ctx); err != nil {
+			w.log.Errorf("batch message failed: %v", err)
+		}
+	}
+
 	// get unfill message
+	strategy, err := w.getSelectionStrategy(ctx)
+	if err != nil {
+		return nil, err
+	}
 	selectCount := mathutil.MinUint64(wantCount, 100)
-	messages, err := w.repo.MessageRepo().ListUnChainMessageByAddress(addrInfo.Addr, int(selectCount))
+	// the expired messages are still unfill in dry run, list more to fill their places
+	listCount := int(selectCount)
+	if sim.dryRun() {
+		listCount += len(expired)
+	}
+	messages, err := strategy.SelectCandidates(ctx, w.repo.MessageRepo(), addrInfo.Addr, listCount)
 	if err != nil {
 		return nil, fmt.Errorf("list unfill message error: %v", err)
 	}
+	if sim.dryRun() {
+		sim.res.WantCount = wantCount
+		sim.res.Strategy = strategy.Name()
+		messages = excludeMessages(messages, expired)
+		if len(messages) > int(selectCount) {
+			messages = messages[:selectCount]
+		}
+		for _, msg := range expired {
+			sim.skip(msg.ID, "expired")
+		}
+	}
 
 	if len(messages) == 0 {
 		w.log.Debugf("have no unfill message")
+		sim.setReason("have no unfill message")
 		return &MsgSelectResult{
 			ToPushMsg: toPushMessage,
 			Address:   addrInfo,
 		}, nil
 	}
-	w.log.Infof("state actor nonce %d, latest nonce in ts %d, assigned nonce %d, nonce gap %d, want %d", actorNonce,
-		nonceInLatestTs, addrInfo.Nonce, nonceGap, wantCount)
+	w.log.Infof("state actor nonce %d, latest nonce in ts %d, assigned nonce %d, nonce gap %d, want %d, strategy %s", actorNonce,
+		nonceInLatestTs, addrInfo.Nonce, nonceGap, wantCount, strategy.Name())
 
 	var errMsg []msgErrInfo
 	count := uint64(0)
 	selectMsg := make([]*types.Message, 0, len(messages))
+	spends := make([]*sophonTypes.AddressSpend, 0, len(messages))
 
-	estimateResult, candidateMessages, err := w.estimateMessage(ctx, ts, messages, sharedParams, addrInfo)
+	estimateResult, candidateMessages, rejected, err := w.estimateMessage(ctx, ts, messages, sharedParams, addrInfo, sim)
 	if err != nil {
 		return nil, fmt.Errorf("estimate message failed: %v", err)
 	}
+	errMsg = append(errMsg, rejected...)
+
+	usage, err := w.loadSpendUsage(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	// holdBack leaves the messages from index unfill with err until the want count is reached
+	holdBack := func(index int, err string) {
+		heldBack := 0
+		for i := index; i < len(candidateMessages) && count+uint64(heldBack) < wantCount; i++ {
+			m := candidateMessages[i]
+			if len(estimateResult[i].Err) != 0 {
+				errMsg = append(errMsg, msgErrInfo{id: m.ID, err: gasEstimate + estimateResult[i].Err})
+				sim.fail(m.ID, gasEstimate+estimateResult[i].Err)
+				continue
+			}
+			errMsg = append(errMsg, msgErrInfo{id: m.ID, err: err})
+			sim.fail(m.ID, err)
+			heldBack++
+		}
+		w.log.Warnf("hold back %d messages: %s", heldBack, err)
+	}
 
 	// sign
 	for index, msg := range candidateMessages {
@@ -414,10 +594,17 @@ func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap,
 		if len(estimateResult[index].Err) != 0 {
 			errMsg = append(errMsg, msgErrInfo{id: msg.ID, err: gasEstimate + estimateResult[index].Err})
 			w.log.Errorf("estimate message %s fail %s", msg.ID, estimateResult[index].Err)
+			sim.fail(msg.ID, gasEstimate+estimateResult[index].Err)
 			continue
 		}
 		estimateMsg := estimateResult[index].Msg
+		sim.setEstimate(msg.ID, estimateMsg)
 		if count >= wantCount {
+			if sim.dryRun() {
+				for _, m := range candidateMessages[index:] {
+					sim.skip(m.ID, fmt.Sprintf("reach the want count %d", wantCount))
+				}
+			}
 			break
 		}
 
@@ -426,6 +613,27 @@ func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap,
 			err := fmt.Sprintf("%s gas limit %d over limit %d", gasEstimate, estimateMsg.GasLimit, constants.BlockGasLimit)
 			errMsg = append(errMsg, msgErrInfo{id: msg.ID, err: err})
 			w.log.Errorf(err)
+			sim.fail(msg.ID, err)
+			continue
+		}
+
+		// the following messages can not be selected before this one, hold back all of them
+		if cost := maxMessageCost(estimateMsg); spendable.LessThan(cost) {
+			holdBack(index, fmt.Sprintf("%sbalance %s minus reserve %s can not cover the cost %s, spendable %s", insufficientBalance,
+				venusTypes.FIL(actor.Balance), venusTypes.FIL(reserve), venusTypes.FIL(cost), venusTypes.FIL(spendable)))
+			break
+		}
+		if reason := usage.check(estimateMsg); len(reason) > 0 {
+			holdBack(index, reason)
+			break
+		}
+		spendable = big.Sub(spendable, maxMessageCost(estimateMsg))
+		usage.add(estimateMsg)
+
+		if sim.dryRun() {
+			sim.selected(msg.ID, addrInfo.Nonce)
+			addrInfo.Nonce++
+			count++
 			continue
 		}
 
@@ -467,6 +675,7 @@ func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap,
 		}
 
 		selectMsg = append(selectMsg, msg)
+		spends = append(spends, sophonTypes.NewAddressSpend(msg, ts.Height()))
 		addrInfo.Nonce++
 		count++
 	}
@@ -476,15 +685,17 @@ func (w *work) selectMessage(ctx context.Context, appliedNonce *utils.NonceMap,
 		ToPushMsg: toPushMessage,
 		Address:   addrInfo,
 		ErrMsg:    errMsg,
+		Spends:    spends,
 	}, nil
 }
 
-func (w *work) getNonce(ctx context.Context, ts *venusTypes.TipSet, appliedNonce *utils.NonceMap) (uint64, uint64, error) {
+// getNonce returns the nonce in the latest tipset and the actor of address in ts
+func (w *work) getNonce(ctx context.Context, ts *venusTypes.TipSet, appliedNonce *utils.NonceMap) (uint64, *venusTypes.Actor, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, w.cfg.DefaultTimeout)
 	defer cancel()
 	actorI, err := handleTimeout(timeoutCtx, w.fullNode.StateGetActor, []interface{}{w.addr, ts.Key()})
 	if err != nil {
-		return 0, 0, fmt.Errorf("get actor failed: %v", err)
+		return 0, nil, fmt.Errorf("get actor failed: %v", err)
 	}
 	actor := actorI.(*venusTypes.Actor)
 	nonceInLatestTs := actor.Nonce
@@ -494,7 +705,89 @@ func (w *work) getNonce(ctx context.Context, ts *venusTypes.TipSet, appliedNonce
 		nonceInLatestTs = nonceInTs
 	}
 
-	return nonceInLatestTs, actor.Nonce, nil
+	return nonceInLatestTs, actor, nil
+}
+
+// expireMessages moves the unfill messages which can not be packed before their deadline to ExpiredMsg state,
+// the message selected in ts will be packed in the next tipset at least, only returns them if dryRun is true
+func (w *work) expireMessages(ts *venusTypes.TipSet, dryRun bool) ([]*types.Message, error) {
+	msgs, err := w.repo.MessageRepo().ListExpiredUnFillMessage(w.addr, ts.Height(), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || dryRun {
+		return msgs, nil
+	}
+	if err := w.repo.MessageRepo().ExpireMessage(msgs); err != nil {
+		return nil, err
+	}
+	events := make([]*sophonTypes.MessageEvent, 0, len(msgs))
+	for _, msg := range msgs {
+		var expireEpoch abi.ChainEpoch
+		if msg.Meta != nil {
+			expireEpoch = msg.Meta.ExpireEpoch
+		}
+		w.log.Warnf("message %s expired, expire epoch %d, height %d", msg.ID, expireEpoch, ts.Height())
+		event := sophonTypes.NewMessageEvent(msg)
+		event.State = sophonTypes.ExpiredMsg
+		events = append(events, event)
+	}
+	w.eventHub.publish(events...)
+
+	return msgs, nil
+}
+
+func excludeMessages(msgs []*types.Message, excluded []*types.Message) []*types.Message {
+	if len(excluded) == 0 {
+		return msgs
+	}
+	ids := make(map[string]struct{}, len(excluded))
+	for _, msg := range excluded {
+		ids[msg.ID] = struct{}{}
+	}
+	res := make([]*types.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if _, ok := ids[msg.ID]; !ok {
+			res = append(res, msg)
+		}
+	}
+	return res
+}
+
+// balanceReserve returns the balance reserved by the address, zero if not configured
+func (w *work) balanceReserve(ctx context.Context) big.Int {
+	addrCfg, err := w.addressService.GetAddressConfig(ctx, w.addr)
+	if err != nil {
+		w.log.Warnf("get address config failed: %v", err)
+		return big.Zero()
+	}
+	if addrCfg.BalanceReserve.Nil() {
+		return big.Zero()
+	}
+	return addrCfg.BalanceReserve
+}
+
+// maxMessageCost the worst-case cost of message, the value and the gas fee when all gas limit is used at fee cap
+func maxMessageCost(msg *venusTypes.Message) big.Int {
+	cost := big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit))
+	if !msg.Value.Nil() {
+		cost = big.Add(cost, msg.Value)
+	}
+	return cost
+}
+
+// getSelectionStrategy returns the strategy set for the address, fallback to the global config
+func (w *work) getSelectionStrategy(ctx context.Context) (SelectionStrategy, error) {
+	name := w.cfg.SelectStrategy
+	addrCfg, err := w.repo.AddressConfigRepo().GetAddressConfig(ctx, w.addr)
+	if err != nil && !errors.Is(err, repo.ErrRecordNotFound) {
+		return nil, fmt.Errorf("get address config failed: %v", err)
+	}
+	if addrCfg != nil && len(addrCfg.SelectStrategy) != 0 {
+		name = addrCfg.SelectStrategy
+	}
+
+	return GetSelectionStrategy(name)
 }
 
 func (w *work) getMaxMessageNonceFromDB(addr address.Address) (uint64, error) {
@@ -511,6 +804,7 @@ func (w *work) getMaxMessageNonceFromDB(addr address.Address) (uint64, error) {
 		{
 			State: []types.MessageState{
 				types.OnChainMsg,
+				sophonTypes.FinalizedMsg,
 			},
 			From: []address.Address{
 				addr,
@@ -558,20 +852,32 @@ func (w *work) estimateMessage(ctx context.Context,
 	msgs []*types.Message,
 	sharedParams *types.SharedSpec,
 	addrInfo *types.Address,
-) ([]*venusTypes.EstimateResult, []*types.Message, error) {
+	sim *selectSimulation,
+) ([]*venusTypes.EstimateResult, []*types.Message, []msgErrInfo, error) {
 	candidateMessages := make([]*types.Message, 0, len(msgs))
 	estimateMessages := make([]*venusTypes.EstimateMessage, 0, len(msgs))
+	// the messages failed in the pre-flight are left unfill, they would consume a nonce and gas if landed on chain
+	var rejected []msgErrInfo
 
 	nv, err := w.fullNode.StateNetworkVersion(ctx, venusTypes.EmptyTSK)
 	if err != nil {
-		return nil, nil, fmt.Errorf("get network version failed: %v", err)
+		return nil, nil, nil, fmt.Errorf("get network version failed: %v", err)
+	}
+	preflightIDs, err := w.repo.ActorCfgRepo().ListPreflightActorCfgID(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list pre-flight actor config failed: %v", err)
+	}
+	preflightCfgs := make(map[venusTypes.UUID]struct{}, len(preflightIDs))
+	for _, id := range preflightIDs {
+		preflightCfgs[id] = struct{}{}
 	}
 	for _, msg := range msgs {
 		actorCfg, err := w.getActorCfg(ctx, msg, nv)
 		if err != nil {
-			return nil, nil, fmt.Errorf("get actor config failed: %v", err)
+			return nil, nil, nil, fmt.Errorf("get actor config failed: %v", err)
 		}
 		newMsgMeta := mergeMsgSpec(sharedParams, msg.Meta, addrInfo, actorCfg, msg)
+		sim.setGasSpec(msg.ID, newMsgMeta)
 
 		if msg.GasFeeCap.NilOrZero() && !newMsgMeta.GasFeeCap.NilOrZero() {
 			msg.GasFeeCap = newMsgMeta.GasFeeCap
@@ -580,9 +886,30 @@ func (w *work) estimateMessage(ctx context.Context,
 		baseFee := ts.At(0).ParentBaseFee
 		if !newMsgMeta.BaseFee.NilOrZero() && baseFee.GreaterThan(newMsgMeta.BaseFee) {
 			w.log.Infof("skip msg %v, base fee too height %v(local) < %v(chain), height %v", msg.ID, newMsgMeta.BaseFee, baseFee, ts.Height())
+			sim.skip(msg.ID, fmt.Sprintf("base fee too high %v(local) < %v(chain)", newMsgMeta.BaseFee, baseFee))
 			continue
 		}
 
+		// the pre-flight runs before estimating, so that the estimates of the following messages never assume that a
+		// rejected message was executed
+		preflight := false
+		if actorCfg != nil {
+			_, preflight = preflightCfgs[actorCfg.ID]
+		}
+		if preflight {
+			reason, err := w.preflight(ctx, &msg.Message, ts)
+			if err != nil {
+				w.log.Warnf("skip msg %v, pre-flight failed: %v", msg.ID, err)
+				sim.skip(msg.ID, fmt.Sprintf("pre-flight failed: %v", err))
+				continue
+			}
+			if len(reason) > 0 {
+				w.log.Warnf("reject message %s by pre-flight: %s", msg.ID, reason)
+				rejected = append(rejected, msgErrInfo{id: msg.ID, err: preflightFailed + reason})
+				sim.fail(msg.ID, preflightFailed+reason)
+				continue
+			}
+		}
 		candidateMessages = append(candidateMessages, msg)
 		estimateMessages = append(estimateMessages, &venusTypes.EstimateMessage{
 			Msg: &msg.Message,
@@ -602,8 +929,31 @@ func (w *work) estimateMessage(ctx context.Context,
 	defer estimateMsgCancel()
 
 	estimateResult, err := w.fullNode.GasBatchEstimateMessageGas(estimateMsgCtx, estimateMessages, addrInfo.Nonce, ts.Key())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return estimateResult, candidateMessages, rejected, nil
+}
 
-	return estimateResult, candidateMessages, err
+// preflight simulates msg on the state of ts by StateCall, returns the reason if it fails. The rpc error is returned
+// as err, the message should be tried again rather than rejected
+func (w *work) preflight(ctx context.Context, msg *venusTypes.Message, ts *venusTypes.TipSet) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, w.cfg.EstimateMessageTimeout)
+	defer cancel()
+
+	res, err := w.fullNode.StateCall(callCtx, msg, ts.Key())
+	if err != nil {
+		return "", fmt.Errorf("state call failed: %w", err)
+	}
+	if res.MsgRct != nil && !res.MsgRct.ExitCode.IsSuccess() {
+		reason := fmt.Sprintf("simulated exit code %d", res.MsgRct.ExitCode)
+		if len(res.Error) > 0 {
+			reason += ", " + res.Error
+		}
+		return reason, nil
+	}
+	return "", nil
 }
 
 func (w *work) signMessage(ctx context.Context, msg *types.Message, accounts []string) (*crypto.Signature, error) {
@@ -630,14 +980,37 @@ func (w *work) signMessage(ctx context.Context, msg *types.Message, accounts []s
 	return sigI.(*crypto.Signature), nil
 }
 
+// checkFence the address must be held by the instance when assigning nonces, it is checked by the shard of address
+// if sharding is enabled, otherwise by the lease of leader
+func (w *work) checkFence(ctx context.Context, txRepo repo.TxRepo) error {
+	if w.shard != nil {
+		return w.shard.checkFence(ctx, txRepo, w.addr)
+	}
+	return w.leader.checkFence(ctx, txRepo)
+}
+
 func (w *work) saveSelectedMessages(selectResult *MsgSelectResult) error {
 	startSaveDB := time.Now()
 	w.log.Infof("start save messages to database")
 	err := w.repo.Transaction(func(txRepo repo.TxRepo) error {
 		if len(selectResult.SelectMsg) > 0 {
+			// a stale leader or holder of address must not assign nonces after another instance took over
+			if err := w.checkFence(w.ctx, txRepo); err != nil {
+				return err
+			}
 			if err := txRepo.MessageRepo().BatchSaveMessage(selectResult.SelectMsg); err != nil {
 				return err
 			}
+			revisions := make([]*sophonTypes.MessageRevision, 0, len(selectResult.SelectMsg))
+			for _, msg := range selectResult.SelectMsg {
+				revisions = append(revisions, sophonTypes.NewMessageRevision(msg, sophonTypes.RevisionSelect, ""))
+			}
+			if err := txRepo.MessageRevisionRepo().SaveMessageRevisions(w.ctx, revisions); err != nil {
+				return err
+			}
+			if err := txRepo.AddressSpendRepo().SaveAddressSpends(w.ctx, selectResult.Spends); err != nil {
+				return err
+			}
 
 			addrInfo := selectResult.Address
 			row, err := txRepo.AddressRepo().UpdateNonce(addrInfo.Addr, addrInfo.Nonce)
@@ -656,6 +1029,9 @@ func (w *work) saveSelectedMessages(selectResult *MsgSelectResult) error {
 		return nil
 	})
 	w.log.Infof("end save messages to database, took %v, err %v", time.Since(startSaveDB), err)
+	if err == nil {
+		w.eventHub.publishMessages(selectResult.SelectMsg...)
+	}
 
 	return err
 }
@@ -709,9 +1085,10 @@ func (w *work) finish() {
 	<-w.controlChan
 }
 
+// close cancels the work, controlChan is not closed, the callers waiting for it may send to it concurrently,
+// they should wait for w.ctx as well
 func (w *work) close() {
 	w.cancel()
-	close(w.controlChan)
 }
 
 func CapGasFee(msg *venusTypes.Message, maxFee abi.TokenAmount) {
@@ -731,13 +1108,7 @@ func CapGasFee(msg *venusTypes.Message, maxFee abi.TokenAmount) {
 	msg.GasPremium = big.Min(msg.GasFeeCap, msg.GasPremium) // cap premium at FeeCap
 }
 
-type GasSpec struct {
-	GasOverEstimation float64
-	MaxFee            big.Int
-	GasOverPremium    float64
-	GasFeeCap         big.Int
-	BaseFee           big.Int
-}
+type GasSpec = sophonTypes.GasSpec
 
 func mergeMsgSpec(globalSpec *types.SharedSpec, sendSpec *types.SendSpec, addrInfo *types.Address, actorCfg *types.ActorCfg, msg *types.Message) *GasSpec {
 	newMsgMeta := &GasSpec{
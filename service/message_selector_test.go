@@ -3,6 +3,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"testing"
@@ -11,14 +12,19 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/filecoin-project/go-address"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/go-state-types/manifest"
 
 	"github.com/ipfs-force-community/sophon-messager/filestore"
 	"github.com/ipfs-force-community/sophon-messager/models"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 
 	"github.com/filecoin-project/venus/pkg/constants"
+	"github.com/filecoin-project/venus/venus-shared/actors"
 	"github.com/filecoin-project/venus/venus-shared/testutil"
 	shared "github.com/filecoin-project/venus/venus-shared/types"
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
@@ -142,7 +148,7 @@ func TestAddrSelectMsgNum(t *testing.T) {
 	fsRepo := filestore.NewMockFileStore(t.TempDir())
 	repo, err := models.SetDataBase(fsRepo)
 	assert.NoError(t, err)
-	assert.NoError(t, repo.AutoMigrate())
+	assert.NoError(t, repo.SchemaMigrator().Migrate(context.Background()))
 
 	sps, err := NewSharedParamsService(ctx, repo)
 	assert.NoError(t, err)
@@ -567,11 +573,350 @@ func TestGasLimitOverLimit(t *testing.T) {
 	}
 }
 
+func TestExpireMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+	msh.start()
+	defer msh.stop()
+
+	// zero expire epoch means no deadline, so wait for a tipset with non-zero height
+	var ts *shared.TipSet
+	assert.Eventually(t, func() bool {
+		var err error
+		ts, err = msh.fullNode.ChainHead(ctx)
+		return err == nil && ts.Height() > 0
+	}, time.Minute, 100*time.Millisecond)
+
+	msgs := genMessages(addrs[:1], 4)
+	// can not be packed in the next tipset
+	msgs[0].Meta = &types.SendSpec{ExpireEpoch: ts.Height()}
+	msgs[1].Meta = &types.SendSpec{ExpireEpoch: ts.Height() + 1}
+	assert.NoError(t, pushMessage(ctx, ms, msgs[:2]))
+	_, err := ms.PushMessageWithSpec(ctx, msgs[2].ID, &msgs[2].Message, &sophonTypes.SendSpec{
+		MessageOptions: sophonTypes.MessageOptions{ExpireAt: time.Now().Add(-time.Second)},
+	})
+	assert.NoError(t, err)
+	_, err = ms.PushMessageWithSpec(ctx, msgs[3].ID, &msgs[3].Message, &sophonTypes.SendSpec{
+		MessageOptions: sophonTypes.MessageOptions{ExpireAt: time.Now().Add(time.Hour)},
+	})
+	assert.NoError(t, err)
+
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 2)
+	selected := testhelper.SliceToMap(selectResult.SelectMsg)
+	for i, msg := range msgs {
+		res, err := ms.GetMessageByUid(ctx, msg.ID)
+		assert.NoError(t, err)
+		_, ok := selected[msg.ID]
+		if i%2 == 0 {
+			assert.False(t, ok)
+			assert.Equal(t, sophonTypes.ExpiredMsg, res.State)
+		} else {
+			assert.True(t, ok)
+			assert.Equal(t, types.FillMsg, res.State)
+		}
+	}
+
+	// WaitMessage returns when message expired
+	res, err := ms.WaitMessage(ctx, msgs[0].ID, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, sophonTypes.ExpiredMsg, res.State)
+}
+
+func TestSimulateSelect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+	msh.start()
+	defer msh.stop()
+
+	addr := addrs[0]
+	msgs := genMessages(addrs[:1], 5)
+	assert.NoError(t, pushMessage(ctx, ms, msgs[:4]))
+	_, err := ms.PushMessageWithSpec(ctx, msgs[4].ID, &msgs[4].Message, &sophonTypes.SendSpec{
+		MessageOptions: sophonTypes.MessageOptions{ExpireAt: time.Now().Add(-time.Second)},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ms.addressService.SetSelectMsgNum(ctx, addr, 3))
+
+	addrInfo, err := ms.addressService.GetAddress(ctx, addr)
+	assert.NoError(t, err)
+	sim, err := ms.SimulateSelect(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, addr, sim.Address)
+	assert.Equal(t, uint64(3), sim.WantCount)
+	assert.Equal(t, FIFOStrategy, sim.Strategy)
+	assert.Empty(t, sim.Reason)
+
+	selected := make(map[string]uint64)
+	for _, simMsg := range sim.Messages {
+		if simMsg.ID == msgs[4].ID {
+			assert.False(t, simMsg.Selected)
+			assert.Equal(t, "expired", simMsg.Skipped)
+			continue
+		}
+		if simMsg.Selected {
+			assert.NotNil(t, simMsg.GasSpec)
+			assert.NotZero(t, simMsg.GasLimit)
+			selected[simMsg.ID] = simMsg.Nonce
+		}
+	}
+	assert.Len(t, selected, 3)
+
+	// nothing changed
+	for _, msg := range msgs {
+		res, err := ms.GetMessageByUid(ctx, msg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.UnFillMsg, res.State)
+	}
+	addrInfo2, err := ms.addressService.GetAddress(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, addrInfo.Nonce, addrInfo2.Nonce)
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, len(selected))
+	for _, msg := range selectResult.SelectMsg {
+		assert.Equal(t, selected[msg.ID], msg.Nonce)
+	}
+}
+
+func TestSelectMessageWithBalance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	addr := addrs[0]
+	oneFIL := big.NewInt(1e18)
+	halfFIL := big.Div(oneFIL, big.NewInt(2))
+	msgs := genMessages(addrs[:1], 5)
+	for _, msg := range msgs {
+		msg.Value = oneFIL
+	}
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+
+	// only the first 3 messages can be covered
+	assert.NoError(t, msh.fullNode.SetActorBalance(addr, big.Add(big.Mul(oneFIL, big.NewInt(3)), halfFIL)))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 3)
+	assert.Len(t, selectResult.ErrMsg, 2)
+	heldBack := make(map[string]struct{})
+	for _, errMsg := range selectResult.ErrMsg {
+		heldBack[errMsg.id] = struct{}{}
+		res, err := ms.GetMessageByUid(ctx, errMsg.id)
+		assert.NoError(t, err)
+		assert.Equal(t, types.UnFillMsg, res.State)
+		assert.Contains(t, res.ErrorMsg, insufficientBalance)
+	}
+
+	assert.Error(t, ms.addressService.SetBalanceReserve(ctx, addr, big.NewInt(-1)))
+	assert.NoError(t, ms.addressService.SetBalanceReserve(ctx, addr, oneFIL))
+	addrCfg, err := ms.addressService.GetAddressConfig(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, oneFIL, addrCfg.BalanceReserve)
+
+	// the signed messages and the reserve take 4 FIL, one more message can be covered
+	balance := big.Add(big.Mul(oneFIL, big.NewInt(5)), halfFIL)
+	assert.NoError(t, msh.fullNode.SetActorBalance(addr, balance))
+	sim, err := ms.SimulateSelect(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, balance, sim.Balance)
+	assert.Equal(t, oneFIL, sim.BalanceReserve)
+	assert.Len(t, sim.Messages, 2)
+	var selectedID string
+	for _, simMsg := range sim.Messages {
+		assert.Contains(t, heldBack, simMsg.ID)
+		if simMsg.Selected {
+			selectedID = simMsg.ID
+		} else {
+			assert.Contains(t, simMsg.Err, insufficientBalance)
+		}
+	}
+
+	selectResult = selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 1)
+	assert.Equal(t, selectedID, selectResult.SelectMsg[0].ID)
+	assert.Empty(t, selectResult.SelectMsg[0].ErrorMsg)
+	assert.Len(t, selectResult.ErrMsg, 1)
+	res, err := ms.GetMessageByUid(ctx, selectedID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.FillMsg, res.State)
+	assert.Empty(t, res.ErrorMsg)
+}
+
+func TestSelectMessageWithSpendBudget(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	addr := addrs[0]
+	oneFIL := big.NewInt(1e18)
+	msgs := genMessages(addrs[:1], 5)
+	for _, msg := range msgs {
+		msg.Value = oneFIL
+	}
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+
+	assert.Error(t, ms.addressService.SetSpendBudget(ctx, addr, &sophonTypes.SpendBudget{MaxValue: big.NewInt(-1)}))
+	assert.Error(t, ms.addressService.SetSpendBudget(ctx, addr, &sophonTypes.SpendBudget{WindowEpochs: -1}))
+	// only the first 3 messages are within the budget
+	budget := &sophonTypes.SpendBudget{MaxValue: big.Mul(oneFIL, big.NewInt(3)), WindowEpochs: 2880}
+	assert.NoError(t, ms.addressService.SetSpendBudget(ctx, addr, budget))
+	addrCfg, err := ms.addressService.GetAddressConfig(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, *budget, addrCfg.SpendBudget)
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 3)
+	assert.Len(t, selectResult.ErrMsg, 2)
+	for _, errMsg := range selectResult.ErrMsg {
+		res, err := ms.GetMessageByUid(ctx, errMsg.id)
+		assert.NoError(t, err)
+		assert.Equal(t, types.UnFillMsg, res.State)
+		assert.Contains(t, res.ErrorMsg, exceedSpendBudget)
+	}
+	spends, err := ms.repo.AddressSpendRepo().ListAddressSpend(ctx, addr, time.Time{}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, spends, 3)
+
+	// the gas fee of the signed messages is also limited
+	budget = &sophonTypes.SpendBudget{MaxValue: big.Mul(oneFIL, big.NewInt(10)), MaxGasFee: big.NewInt(1)}
+	assert.NoError(t, ms.addressService.SetSpendBudget(ctx, addr, budget))
+	sim, err := ms.SimulateSelect(ctx, addr)
+	assert.NoError(t, err)
+	assert.Len(t, sim.Messages, 2)
+	for _, simMsg := range sim.Messages {
+		assert.False(t, simMsg.Selected)
+		assert.Contains(t, simMsg.Err, exceedSpendBudget)
+	}
+
+	// unlimited gas fee
+	budget.MaxGasFee = big.Zero()
+	assert.NoError(t, ms.addressService.SetSpendBudget(ctx, addr, budget))
+	selectResult = selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 2)
+	assert.Empty(t, selectResult.ErrMsg)
+	for _, msg := range selectResult.SelectMsg {
+		res, err := ms.GetMessageByUid(ctx, msg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.FillMsg, res.State)
+		assert.Empty(t, res.ErrorMsg)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	miner, err := address.NewIDAddress(70000)
+	assert.NoError(t, err)
+	assert.NoError(t, msh.fullNode.AddActors([]address.Address{miner}))
+	minerCode, ok := actors.GetActorCodeID(actorstypes.Version12, manifest.MinerKey)
+	assert.True(t, ok)
+	assert.NoError(t, msh.fullNode.SetActorCode(miner, minerCode))
+
+	actorCfg := &types.ActorCfg{
+		ID:           shared.NewUUID(),
+		ActorVersion: actorstypes.Version12,
+		MethodType:   types.MethodType{Code: minerCode, Method: 16},
+	}
+	assert.NoError(t, ms.SaveActorCfg(ctx, actorCfg))
+	assert.Error(t, ms.SetActorCfgPreflight(ctx, shared.NewUUID(), true))
+	assert.NoError(t, ms.SetActorCfgPreflight(ctx, actorCfg.ID, true))
+	ids, err := ms.ListPreflightActorCfg(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.UUID{actorCfg.ID}, ids)
+	// WithdrawBalance fails in the simulation, ChangeWorkerAddress is not checked though it fails too
+	msh.fullNode.SetCallExitCode(miner, 16, exitcode.ErrForbidden)
+	msh.fullNode.SetCallExitCode(miner, 3, exitcode.ErrForbidden)
+
+	msgs := genMessages(addrs[:1], 3)
+	for idx, msg := range msgs {
+		msg.To = miner
+		msg.Method = 16
+		if idx == 2 {
+			msg.Method = 3
+		}
+	}
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	sim, err := ms.SimulateSelect(ctx, addrs[0])
+	assert.NoError(t, err)
+	assert.Len(t, sim.Messages, 3)
+	for _, simMsg := range sim.Messages {
+		assert.Equal(t, simMsg.ID == msgs[2].ID, simMsg.Selected)
+	}
+
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 1)
+	assert.Equal(t, msgs[2].ID, selectResult.SelectMsg[0].ID)
+	// the nonce is not consumed by the rejected messages
+	assert.Equal(t, uint64(0), selectResult.SelectMsg[0].Nonce)
+	assert.Len(t, selectResult.ErrMsg, 2)
+	for _, errMsg := range selectResult.ErrMsg {
+		res, err := ms.GetMessageByUid(ctx, errMsg.id)
+		assert.NoError(t, err)
+		assert.Equal(t, types.UnFillMsg, res.State)
+		assert.Contains(t, res.ErrorMsg, preflightFailed)
+		assert.Contains(t, res.ErrorMsg, "exit code 18")
+	}
+
+	// selected once the pre-flight is disabled
+	assert.NoError(t, ms.SetActorCfgPreflight(ctx, actorCfg.ID, false))
+	selectResult = selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 2)
+	assert.Empty(t, selectResult.ErrMsg)
+
+	// the message is skipped in the round if the state call fails, it is neither rejected nor selected
+	assert.NoError(t, ms.SetActorCfgPreflight(ctx, actorCfg.ID, true))
+	msh.fullNode.SetCallExitCode(miner, 16, exitcode.Ok)
+	msh.fullNode.SetCallError(miner, 16, errors.New("mock rpc error"))
+	msgs = genMessages(addrs[:1], 1)
+	msgs[0].To = miner
+	msgs[0].Method = 16
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+	selectResult = selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Empty(t, selectResult.SelectMsg)
+	assert.Empty(t, selectResult.ErrMsg)
+	res, err := ms.GetMessageByUid(ctx, msgs[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.UnFillMsg, res.State)
+	assert.Empty(t, res.ErrorMsg)
+
+	msh.fullNode.SetCallError(miner, 16, nil)
+	selectResult = selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 1)
+}
+
 func pushMessage(ctx context.Context, ms *MessageService, msgs []*types.Message) error {
 	for _, msg := range msgs {
 		// avoid been modified
 		msgCopy := *msg
-		if err := ms.pushMessage(ctx, &msgCopy); err != nil {
+		if err := ms.pushMessage(ctx, &msgCopy, nil, nil); err != nil {
 			return err
 		}
 	}
@@ -700,7 +1045,7 @@ func selectMsgWithAddress(ctx context.Context,
 	addrSelMsgNum := addrSelectMsgNum(activeAddrs, sharedParams.SelMsgNum)
 	allSelectRes := &MsgSelectResult{}
 	for _, addr := range addrs {
-		work := newWork(ctx, addr, ms.msgSelectMgr.cfg, msh.fullNode, ms.repo, ms.addressService, ms.walletClient, ms.msgReceiver)
+		work := newWork(ctx, addr, ms.msgSelectMgr.cfg, msh.fullNode, ms.repo, ms.addressService, ms.walletClient, ms.msgReceiver, ms.eventHub, ms.leader, ms.shard)
 		appliedNonce, err := ms.msgSelectMgr.getNonceInTipset(ctx, ts)
 		assert.NoError(t, err)
 		addrInfo, err := ms.addressService.GetAddress(ctx, addr)
@@ -29,6 +29,7 @@ import (
 	"github.com/ipfs-force-community/sophon-messager/metrics"
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
 	"github.com/ipfs-force-community/sophon-messager/publisher"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 const (
@@ -39,6 +40,7 @@ const (
 type IMessageService interface {
 	PushMessage(ctx context.Context, msg *venusTypes.Message, meta *types.SendSpec) (string, error)
 	PushMessageWithId(ctx context.Context, id string, msg *venusTypes.Message, meta *types.SendSpec) (string, error)
+	PushMessageWithSpec(ctx context.Context, id string, msg *venusTypes.Message, spec *sophonTypes.SendSpec) (string, error)
 	HasMessageByUid(ctx context.Context, id string) (bool, error)
 	GetMessageByUid(ctx context.Context, id string) (*types.Message, error)
 	GetMessageByCid(ctx context.Context, cid cid.Cid) (*types.Message, error)
@@ -60,11 +62,47 @@ type IMessageService interface {
 	RecoverFailedMsg(ctx context.Context, addr address.Address) ([]string, error)
 	ClearUnFillMessage(ctx context.Context, addr address.Address) (int, error)
 	Send(ctx context.Context, params types.QuickSendParams) (string, error)
+	SendWithSpec(ctx context.Context, params sophonTypes.QuickSendParams) (string, error)
+	SetMessagePriority(ctx context.Context, id string, priority int) error
+	SimulateSelect(ctx context.Context, addr address.Address) (*sophonTypes.SelectSimulation, error)
+	NonceGapReport(ctx context.Context, addr address.Address) (*sophonTypes.NonceGapReport, error)
+	FillNonceGap(ctx context.Context, addr address.Address) ([]string, error)
+	SubscribeMessageEvents(ctx context.Context, filter *sophonTypes.MessageEventFilter) (<-chan *sophonTypes.MessageEvent, error)
+
+	SetFeeBumpPolicy(ctx context.Context, policy *sophonTypes.FeeBumpPolicy) error
+	ListFeeBumpPolicy(ctx context.Context) ([]*sophonTypes.FeeBumpPolicy, error)
+	DeleteFeeBumpPolicy(ctx context.Context, id string) error
+	ListFeeBumpRecord(ctx context.Context, id string) ([]*sophonTypes.FeeBumpRecord, error)
+	GetMessageRevisions(ctx context.Context, id string) ([]*sophonTypes.MessageRevision, error)
+	FeeReport(ctx context.Context, params *sophonTypes.FeeReportParams) (*sophonTypes.FeeReport, error)
+	SetSignerPolicy(ctx context.Context, policy *sophonTypes.SignerPolicy) (string, error)
+	ListSignerPolicy(ctx context.Context, signer address.Address) ([]*sophonTypes.SignerPolicy, error)
+	DeleteSignerPolicy(ctx context.Context, id string) error
+	CheckSignerPolicy(ctx context.Context, msg *venusTypes.Message) error
+	ProposeMsig(ctx context.Context, params *sophonTypes.MsigProposeParams) (string, error)
+	ApproveMsig(ctx context.Context, params *sophonTypes.MsigApproveParams) (string, error)
+	GetMsigProposal(ctx context.Context, id string) (*sophonTypes.MsigProposal, error)
+	ListMsigProposal(ctx context.Context, msig address.Address) ([]*sophonTypes.MsigProposal, error)
+	PushEthTransaction(ctx context.Context, params *sophonTypes.EthTxParams) (string, error)
+	GetMessageByEthHash(ctx context.Context, hash venusTypes.EthHash) (*types.Message, error)
+	SetContractABI(ctx context.Context, contractABI *sophonTypes.ContractABI) error
+	GetContractABI(ctx context.Context, addr address.Address) (*sophonTypes.ContractABI, error)
+	ListContractABI(ctx context.Context) ([]*sophonTypes.ContractABI, error)
+	DeleteContractABI(ctx context.Context, addr address.Address) error
+	DecodeMessages(ctx context.Context, msgs []*types.Message) ([]*sophonTypes.DecodedMessage, error)
+	SetExitCodeRule(ctx context.Context, rule *sophonTypes.ExitCodeRule) (string, error)
+	ListExitCodeRule(ctx context.Context) ([]*sophonTypes.ExitCodeRule, error)
+	DeleteExitCodeRule(ctx context.Context, id string) error
+	ListMessageRetries(ctx context.Context, id string) ([]*sophonTypes.MessageRetry, error)
 
 	SaveActorCfg(ctx context.Context, actorCfg *types.ActorCfg) error
 	UpdateActorCfg(ctx context.Context, id venusTypes.UUID, changeSpecParams *types.ChangeGasSpecParams) error
 	ListActorCfg(ctx context.Context) ([]*types.ActorCfg, error)
 	GetActorCfgByID(ctx context.Context, id venusTypes.UUID) (*types.ActorCfg, error)
+	SetActorCfgPreflight(ctx context.Context, id venusTypes.UUID, enable bool) error
+	ListPreflightActorCfg(ctx context.Context) ([]venusTypes.UUID, error)
+	GetLeader(ctx context.Context) (*sophonTypes.Lease, error)
+	ListAddressShard(ctx context.Context) ([]*sophonTypes.AddressShard, error)
 }
 
 type MessageService struct {
@@ -91,6 +129,15 @@ type MessageService struct {
 	blockDelay time.Duration
 
 	msgReceiver publisher.MessageReceiver
+	eventHub    *messageEventHub
+
+	// finalizedHeight the messages not after it have been finalized, only accessed when refreshing message state
+	finalizedHeight abi.ChainEpoch
+
+	// leader nil if leader election is disabled
+	leader *leaderElector
+	// shard nil if sharding is disabled
+	shard *addressSharder
 }
 
 type headChan struct {
@@ -112,7 +159,28 @@ func NewMessageService(ctx context.Context,
 	walletClient gatewayAPI.IWalletClient,
 	msgReceiver publisher.MessageReceiver,
 ) (*MessageService, error) {
-	msgSelectMgr, err := newMsgSelectMgr(ctx, repo, &fsRepo.Config().MessageService, nc, addressService, sps, walletClient, msgReceiver)
+	eventHub := newMessageEventHub()
+	instanceID := newInstanceID()
+	var leader *leaderElector
+	if cfg := fsRepo.Config().MessageService.LeaderElection; cfg.Enable {
+		if fsRepo.Config().DB.Type != "mysql" {
+			log.Warnf("leader election only works among the instances sharing the same mysql database")
+		}
+		leader = newLeaderElector(repo, cfg, instanceID)
+	}
+	var shard *addressSharder
+	if cfg := fsRepo.Config().MessageService; cfg.Sharding.Enable {
+		// the head changes and notifications are not split by address, they are left to the leader
+		if !cfg.LeaderElection.Enable {
+			return nil, fmt.Errorf("sharding requires leader election, enable leaderElection in config")
+		}
+		shard = newAddressSharder(repo, addressService, cfg.Sharding, instanceID)
+		if err := shard.rebalance(ctx); err != nil {
+			log.Errorf("rebalance addresses failed: %v", err)
+		}
+		go shard.run(ctx)
+	}
+	msgSelectMgr, err := newMsgSelectMgr(ctx, repo, &fsRepo.Config().MessageService, nc, addressService, sps, walletClient, msgReceiver, eventHub, leader, shard)
 	if err != nil {
 		return nil, err
 	}
@@ -130,10 +198,16 @@ func NewMessageService(ctx context.Context,
 		cleanUnFillMsgFunc: make(chan func() (int, error)),
 		cleanUnFillMsgRes:  make(chan cleanUnFillMsgResult),
 		msgReceiver:        msgReceiver,
+		eventHub:           eventHub,
+		leader:             leader,
+		shard:              shard,
 	}
 	ms.refreshMessageState(ctx)
-	if err := ms.tsCache.Load(ms.fsRepo.TipsetFile()); err != nil {
-		log.Infof("load tipset file failed: %v", err)
+	if err := importTipsetFile(ctx, ms.fsRepo.TipsetFile(), ms.repo.TipsetRepo()); err != nil {
+		log.Warnf("import tipset file failed: %v", err)
+	}
+	if err := ms.tsCache.Load(ctx, ms.repo.TipsetRepo()); err != nil {
+		log.Infof("load tipset failed: %v", err)
 	}
 
 	if fsRepo.Config().Metrics.Enabled {
@@ -146,6 +220,19 @@ func NewMessageService(ctx context.Context,
 	}
 	ms.blockDelay = time.Duration(networkParams.BlockDelaySecs) * time.Second
 
+	if ms.leader != nil {
+		ms.leader.onElected = ms.onElected
+		ms.leader.onDeposed = ms.onDeposed
+		if err := ms.leader.elect(ctx); err != nil {
+			log.Errorf("acquire lease failed: %v", err)
+		}
+		go ms.leader.run(ctx)
+	}
+
+	if cfg := fsRepo.Config(); cfg.FeeBump.Enable && !cfg.MessageService.SkipPushMessage {
+		go ms.feeBumpProc(ctx)
+	}
+
 	return ms, ms.verifyNetworkName()
 }
 
@@ -154,19 +241,22 @@ func (ms *MessageService) verifyNetworkName() error {
 	if err != nil {
 		return err
 	}
-	if len(ms.tsCache.NetworkName) != 0 {
-		if ms.tsCache.NetworkName != string(networkName) {
-			return fmt.Errorf("network name not match, expect %s, actual %s, please remove `%s`",
-				networkName, ms.tsCache.NetworkName, ms.fsRepo.TipsetFile())
+	if cachedName := ms.tsCache.getNetworkName(); len(cachedName) != 0 {
+		if cachedName != string(networkName) {
+			return fmt.Errorf("network name not match, expect %s, actual %s, please delete the records in table `tipsets`",
+				networkName, cachedName)
 		}
 		return nil
 	}
-	ms.tsCache.NetworkName = string(networkName)
+	ms.tsCache.setNetworkName(string(networkName))
 
-	return ms.tsCache.Save(ms.fsRepo.TipsetFile())
+	return nil
 }
 
-func (ms *MessageService) pushMessage(ctx context.Context, msg *types.Message) error {
+// pushMessage opts is optional, nil means use the default options
+// pushMessage saves msg as an unfill message, onSaved is called in the same transaction if it is not nil, so that the
+// records depending on msg are saved with it or not at all
+func (ms *MessageService) pushMessage(ctx context.Context, msg *types.Message, opts *sophonTypes.MessageOptions, onSaved func(txRepo repo.TxRepo) error) error {
 	if len(msg.ID) == 0 {
 		return errors.New("empty uid")
 	}
@@ -224,7 +314,22 @@ func (ms *MessageService) pushMessage(ctx context.Context, msg *types.Message) e
 
 	msg.Nonce = 0
 
-	return ms.repo.MessageRepo().CreateMessage(msg)
+	if onSaved == nil {
+		return createMessage(ms.repo, msg, opts)
+	}
+	return ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+		if err := createMessage(txRepo, msg, opts); err != nil {
+			return err
+		}
+		return onSaved(txRepo)
+	})
+}
+
+func createMessage(txRepo repo.TxRepo, msg *types.Message, opts *sophonTypes.MessageOptions) error {
+	if opts == nil {
+		return txRepo.MessageRepo().CreateMessage(msg)
+	}
+	return txRepo.MessageRepo().CreateMessageWithOptions(msg, opts)
 }
 
 func (ms *MessageService) PushMessage(ctx context.Context, msg *venusTypes.Message, meta *types.SendSpec) (string, error) {
@@ -232,6 +337,37 @@ func (ms *MessageService) PushMessage(ctx context.Context, msg *venusTypes.Messa
 }
 
 func (ms *MessageService) PushMessageWithId(ctx context.Context, id string, msg *venusTypes.Message, meta *types.SendSpec) (string, error) {
+	return ms.pushMessageWithOptions(ctx, id, msg, meta, nil, nil)
+}
+
+// PushMessageWithSpec generates a new id if id is empty
+func (ms *MessageService) PushMessageWithSpec(ctx context.Context, id string, msg *venusTypes.Message, spec *sophonTypes.SendSpec) (string, error) {
+	return ms.pushMessageWithSpec(ctx, id, msg, spec, nil)
+}
+
+// pushMessageWithSpec calls onSaved in the transaction which saves the message
+func (ms *MessageService) pushMessageWithSpec(ctx context.Context,
+	id string,
+	msg *venusTypes.Message,
+	spec *sophonTypes.SendSpec,
+	onSaved func(txRepo repo.TxRepo) error,
+) (string, error) {
+	if len(id) == 0 {
+		id = venusTypes.NewUUID().String()
+	}
+	if spec == nil {
+		return ms.pushMessageWithOptions(ctx, id, msg, nil, nil, onSaved)
+	}
+	return ms.pushMessageWithOptions(ctx, id, msg, &spec.SendSpec, &spec.MessageOptions, onSaved)
+}
+
+func (ms *MessageService) pushMessageWithOptions(ctx context.Context,
+	id string,
+	msg *venusTypes.Message,
+	meta *types.SendSpec,
+	opts *sophonTypes.MessageOptions,
+	onSaved func(txRepo repo.TxRepo) error,
+) (string, error) {
 	account, _ := core.CtxGetName(ctx)
 	if err := ms.pushMessage(ctx, &types.Message{
 		ID:         id,
@@ -239,7 +375,7 @@ func (ms *MessageService) PushMessageWithId(ctx context.Context, id string, msg
 		Meta:       meta,
 		WalletName: account,
 		State:      types.UnFillMsg,
-	}); err != nil {
+	}, opts, onSaved); err != nil {
 		log.Errorf("push message %s failed %v", id, err)
 		return id, err
 	}
@@ -285,7 +421,11 @@ func (ms *MessageService) WaitMessage(ctx context.Context, id string, confidence
 					return msg, nil
 				}
 				continue
+			case sophonTypes.FinalizedMsg:
+				return msg, nil
 			// Error
+			case sophonTypes.ExpiredMsg:
+				fallthrough
 			case types.FailedMsg:
 				return msg, nil
 			}
@@ -307,6 +447,9 @@ func (ms *MessageService) GetMessageByUid(ctx context.Context, id string) (*type
 	if err != nil {
 		return nil, err
 	}
+	if err := ms.resolveBatchedMessage(ctx, msg); err != nil {
+		return nil, err
+	}
 	if isChainMsg(msg.State) {
 		msg.Confidence = int64(ts.Height()) - msg.Height
 	}
@@ -314,7 +457,7 @@ func (ms *MessageService) GetMessageByUid(ctx context.Context, id string) (*type
 }
 
 func isChainMsg(msgState types.MessageState) bool {
-	return msgState == types.OnChainMsg || msgState == types.NonceConflictMsg
+	return sophonTypes.IsOnChain(msgState) || msgState == types.NonceConflictMsg
 }
 
 func (ms *MessageService) HasMessageByUid(_ context.Context, id string) (bool, error) {
@@ -468,13 +611,53 @@ func (ms *MessageService) UpdateMessageStateByCid(_ context.Context, cid string,
 }
 
 func (ms *MessageService) UpdateMessageStateByID(_ context.Context, id string, state types.MessageState) error {
-	return ms.repo.MessageRepo().UpdateMessageStateByID(id, state)
+	if err := ms.repo.MessageRepo().UpdateMessageStateByID(id, state); err != nil {
+		return err
+	}
+	ms.publishMessageEvent(id)
+
+	return nil
 }
 
+// UpdateMessageInfoByCid a message updated to OnChainMsg is processed the same as refreshing the message state
 func (ms *MessageService) UpdateMessageInfoByCid(unsignedCid string, receipt *venusTypes.MessageReceipt,
 	height abi.ChainEpoch, state types.MessageState, tsKey venusTypes.TipSetKey,
 ) (string, error) {
-	return unsignedCid, ms.repo.MessageRepo().UpdateMessageInfoByCid(unsignedCid, receipt, height, state, tsKey)
+	if state != types.OnChainMsg {
+		return unsignedCid, ms.repo.MessageRepo().UpdateMessageInfoByCid(unsignedCid, receipt, height, state, tsKey)
+	}
+
+	msgCid, err := cid.Decode(unsignedCid)
+	if err != nil {
+		return unsignedCid, err
+	}
+	msg, err := ms.repo.MessageRepo().GetMessageByCid(msgCid)
+	if err != nil {
+		return unsignedCid, err
+	}
+	if msg.SignedCid == nil {
+		return unsignedCid, fmt.Errorf("message %s is not signed", msg.ID)
+	}
+	return unsignedCid, ms.landMessage(applyMessage{
+		signedCID: *msg.SignedCid,
+		msg:       &msg.Message,
+		height:    height,
+		tsk:       tsKey,
+		receipt:   receipt,
+	})
+}
+
+// landMessage updates the filled message found on chain out of refreshing the message state, the fee, notifications,
+// exit code rules and events are handled the same as updateMessageState
+func (ms *MessageService) landMessage(msg applyMessage) error {
+	_, invalidMsgs, err := ms.updateMessageState([]applyMessage{msg}, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := invalidMsgs[msg.signedCID]; ok {
+		return fmt.Errorf("no filled message of %s with nonce %d", msg.msg.From, msg.msg.Nonce)
+	}
+	return nil
 }
 
 func (ms *MessageService) ProcessNewHead(ctx context.Context, apply []*venusTypes.TipSet) error {
@@ -483,12 +666,20 @@ func (ms *MessageService) ProcessNewHead(ctx context.Context, apply []*venusType
 		log.Infof("skip process new head")
 		return nil
 	}
-
 	if len(apply) == 0 {
 		log.Errorf("expect apply blocks, but got none")
 		return nil
 	}
 
+	if !ms.leader.isLeader() {
+		// every instance selects the messages of its own shard
+		if ms.shard != nil {
+			ms.triggerPush <- apply[len(apply)-1]
+		}
+		log.Infof("not the leader, skip process new head")
+		return nil
+	}
+
 	tsList := ms.tsCache.List()
 	sort.Slice(tsList, func(i, j int) bool {
 		return tsList[i].Height() > tsList[j].Height()
@@ -536,6 +727,10 @@ func (ms *MessageService) ProcessNewHead(ctx context.Context, apply []*venusType
 
 func (ms *MessageService) ReconnectCheck(ctx context.Context, head *venusTypes.TipSet) error {
 	log.Infof("reconnect to node")
+	if !ms.leader.isLeader() {
+		log.Infof("not the leader, skip reconnect check")
+		return nil
+	}
 
 	if len(ms.tsCache.Cache) == 0 {
 		count, err := ms.UpdateAllFilledMessage(ctx)
@@ -678,6 +873,10 @@ func (ms *MessageService) delaySelectMessage(ctx context.Context, ts *venusTypes
 		log.Info("skip push message")
 		return
 	}
+	if !ms.leader.isLeader() && ms.shard == nil {
+		log.Info("not the leader, skip push message")
+		return
+	}
 
 	start := time.Now()
 	log.Infof("start select message height: %d, ts: %s, wait task %d", ts.Height(), ts.String(), len(ms.triggerPush))
@@ -727,16 +926,37 @@ func (ms *MessageService) UpdateAllFilledMessage(ctx context.Context) (int, erro
 
 func (ms *MessageService) updateFilledMessage(ctx context.Context, msg *types.Message) error {
 	cid := msg.SignedCid
-	if cid != nil {
-		msgLookup, err := ms.nodeClient.StateSearchMsg(ctx, venusTypes.EmptyTSK, *cid, constants.LookbackNoLimit, true)
-		if err != nil || msgLookup == nil {
-			return fmt.Errorf("search message %s from node %v", cid.String(), err)
-		}
-		if _, err := ms.UpdateMessageInfoByCid(msg.UnsignedCid.String(), &msgLookup.Receipt, msgLookup.Height, types.OnChainMsg, msgLookup.TipSet); err != nil {
-			return err
+	if cid == nil || msg.State != types.FillMsg {
+		log.Debugf("skip updating message %s, state %s", msg.ID, sophonTypes.MessageStateString(msg.State))
+		return nil
+	}
+	msgLookup, err := ms.nodeClient.StateSearchMsg(ctx, venusTypes.EmptyTSK, *cid, constants.LookbackNoLimit, true)
+	if err != nil || msgLookup == nil {
+		return fmt.Errorf("search message %s from node %v", cid.String(), err)
+	}
+	chainMsg := &msg.Message
+	if !msgLookup.Message.Equals(*cid) {
+		// another message with the same nonce landed on chain
+		if chainMsg, err = ms.nodeClient.ChainGetMessage(ctx, msgLookup.Message); err != nil {
+			return fmt.Errorf("get message %s failed: %w", msgLookup.Message, err)
 		}
-		log.Infof("update message %v by node success, height: %d", msg.ID, msgLookup.Height)
 	}
+	ts, err := ms.nodeClient.ChainGetTipSet(ctx, msgLookup.TipSet)
+	if err != nil {
+		return fmt.Errorf("get tipset %s failed: %w", msgLookup.TipSet, err)
+	}
+	if err := ms.landMessage(applyMessage{
+		signedCID: msgLookup.Message,
+		msg:       chainMsg,
+		height:    msgLookup.Height,
+		tsk:       msgLookup.TipSet,
+		receipt:   &msgLookup.Receipt,
+		baseFee:   ts.At(0).ParentBaseFee,
+		timestamp: ts.MinTimestamp(),
+	}); err != nil {
+		return err
+	}
+	log.Infof("update message %v by node success, height: %d", msg.ID, msgLookup.Height)
 
 	return nil
 }
@@ -758,7 +978,7 @@ func (ms *MessageService) ReplaceMessage(ctx context.Context, params *types.Repl
 	if err != nil {
 		return cid.Undef, fmt.Errorf("found message %v", err)
 	}
-	if msg.State == types.OnChainMsg {
+	if sophonTypes.IsOnChain(msg.State) {
 		return cid.Undef, fmt.Errorf("message already on chain")
 	}
 	log := log.With("replace message", msg.ID)
@@ -770,60 +990,13 @@ func (ms *MessageService) ReplaceMessage(ctx context.Context, params *types.Repl
 			return cid.Undef, fmt.Errorf("failed to lookup the message pool config: %w", err)
 		}
 
-		minRBF := computeRBF(msg.GasPremium, cfg.ReplaceByFeeRatio)
-
 		mss := &venusTypes.MessageSendSpec{
 			MaxFee:         params.MaxFee,
 			GasOverPremium: params.GasOverPremium,
 		}
-
-		// msg.GasLimit = 0 // TODO: need to fix the way we estimate gas limits to account for the messages already being in the mempool
-		msg.GasFeeCap = abi.NewTokenAmount(0)
-		msg.GasPremium = abi.NewTokenAmount(0)
-		retm, err := ms.nodeClient.GasEstimateMessageGas(ctx, &msg.Message, mss, venusTypes.EmptyTSK)
-		if err != nil {
-			return cid.Undef, fmt.Errorf("failed to estimate gas values: %w", err)
+		if err := ms.repriceMessage(ctx, msg, cfg.ReplaceByFeeRatio, mss); err != nil {
+			return cid.Undef, err
 		}
-
-		msg.GasPremium = big.Max(retm.GasPremium, minRBF)
-		msg.GasFeeCap = big.Max(retm.GasFeeCap, msg.GasPremium)
-
-		if mss.MaxFee.NilOrZero() {
-			addrInfo, err := ms.addressService.GetAddress(ctx, msg.From)
-			if err != nil {
-				return cid.Undef, err
-			}
-			maxFee := addrInfo.MaxFee
-
-			if maxFee.NilOrZero() {
-				actor, err := ms.nodeClient.StateGetActor(ctx, msg.To, venusTypes.EmptyTSK)
-				if err != nil {
-					return cid.Undef, err
-				}
-				actorCfg, err := ms.repo.ActorCfgRepo().GetActorCfgByMethodType(ctx, &types.MethodType{
-					Code:   actor.Code,
-					Method: msg.Method,
-				})
-				if err == nil {
-					maxFee = actorCfg.MaxFee
-				} else {
-					if err != gorm.ErrRecordNotFound {
-						return cid.Undef, err
-					}
-				}
-			}
-
-			if maxFee.NilOrZero() {
-				sharedParams, err := ms.sps.GetSharedParams(ctx)
-				if err != nil {
-					return cid.Undef, err
-				}
-				maxFee = sharedParams.MaxFee
-			}
-			mss.MaxFee = maxFee
-		}
-
-		CapGasFee(&msg.Message, mss.MaxFee)
 	} else {
 		if params.GasLimit > 0 {
 			msg.GasLimit = params.GasLimit
@@ -851,7 +1024,19 @@ func (ms *MessageService) ReplaceMessage(ctx context.Context, params *types.Repl
 		return cid.Undef, err
 	}
 
-	if err := ms.repo.MessageRepo().UpdateMessageByState(msg, types.FillMsg); err != nil {
+	operator, _ := core.CtxGetName(ctx)
+	if err := ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+		if err := txRepo.MessageRepo().UpdateMessageByState(msg, types.FillMsg); err != nil {
+			return err
+		}
+		revision := sophonTypes.NewMessageRevision(msg, sophonTypes.RevisionReplace, operator)
+		if err := txRepo.MessageRevisionRepo().SaveMessageRevisions(ctx, []*sophonTypes.MessageRevision{revision}); err != nil {
+			return err
+		}
+		// the replacement is counted in the spend budget, but not limited by it, the nonce has been taken
+		spend := sophonTypes.NewAddressSpend(msg, abi.ChainEpoch(ms.tsCache.CurrHeight))
+		return txRepo.AddressSpendRepo().SaveAddressSpends(ctx, []*sophonTypes.AddressSpend{spend})
+	}); err != nil {
 		return cid.Undef, err
 	}
 	log.Infof("new message, gas fee cap: %v, gas premium: %v, gas limit: %d", msg.GasFeeCap, msg.GasPremium, msg.GasLimit)
@@ -859,8 +1044,146 @@ func (ms *MessageService) ReplaceMessage(ctx context.Context, params *types.Repl
 	return signedMsg.Cid(), ms.RepublishMessage(ctx, params.ID)
 }
 
-func (ms *MessageService) MarkBadMessage(_ context.Context, id string) error {
-	return ms.repo.MessageRepo().MarkBadMessage(id)
+// repriceMessage re-estimates the gas of msg, the new gas premium is at least replaceByFeeRatio percent of the old one,
+// the gas fee is capped by mss.MaxFee, or the max fee of address, actor config and shared params if it is empty
+func (ms *MessageService) repriceMessage(ctx context.Context,
+	msg *types.Message,
+	replaceByFeeRatio venusTypes.Percent,
+	mss *venusTypes.MessageSendSpec,
+) error {
+	minRBF := computeRBF(msg.GasPremium, replaceByFeeRatio)
+
+	// msg.GasLimit = 0 // TODO: need to fix the way we estimate gas limits to account for the messages already being in the mempool
+	msg.GasFeeCap = abi.NewTokenAmount(0)
+	msg.GasPremium = abi.NewTokenAmount(0)
+	retm, err := ms.nodeClient.GasEstimateMessageGas(ctx, &msg.Message, mss, venusTypes.EmptyTSK)
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas values: %w", err)
+	}
+
+	msg.GasPremium = big.Max(retm.GasPremium, minRBF)
+	msg.GasFeeCap = big.Max(retm.GasFeeCap, msg.GasPremium)
+
+	if mss.MaxFee.NilOrZero() {
+		addrInfo, err := ms.addressService.GetAddress(ctx, msg.From)
+		if err != nil {
+			return err
+		}
+		maxFee := addrInfo.MaxFee
+
+		if maxFee.NilOrZero() {
+			actor, err := ms.nodeClient.StateGetActor(ctx, msg.To, venusTypes.EmptyTSK)
+			if err != nil {
+				return err
+			}
+			actorCfg, err := ms.repo.ActorCfgRepo().GetActorCfgByMethodType(ctx, &types.MethodType{
+				Code:   actor.Code,
+				Method: msg.Method,
+			})
+			if err == nil {
+				maxFee = actorCfg.MaxFee
+			} else {
+				if err != gorm.ErrRecordNotFound {
+					return err
+				}
+			}
+		}
+
+		if maxFee.NilOrZero() {
+			sharedParams, err := ms.sps.GetSharedParams(ctx)
+			if err != nil {
+				return err
+			}
+			maxFee = sharedParams.MaxFee
+		}
+		mss.MaxFee = maxFee
+	}
+
+	CapGasFee(&msg.Message, mss.MaxFee)
+
+	return nil
+}
+
+func (ms *MessageService) GetMessageRevisions(ctx context.Context, id string) ([]*sophonTypes.MessageRevision, error) {
+	return ms.repo.MessageRevisionRepo().ListMessageRevisions(ctx, id)
+}
+
+func (ms *MessageService) MarkBadMessage(ctx context.Context, id string) error {
+	var msgs []*types.Message
+	if err := ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+		if err := txRepo.MessageRepo().MarkBadMessage(id); err != nil {
+			return err
+		}
+		msg, err := txRepo.MessageRepo().GetMessageByUid(id)
+		if err != nil {
+			return err
+		}
+		children, err := ms.failBatchedMessages(ctx, txRepo, msg)
+		if err != nil {
+			return err
+		}
+		msgs = append([]*types.Message{msg}, children...)
+		return ms.saveEventNotifications(ctx, txRepo, appendMessageEvents(nil, msgs)...)
+	}); err != nil {
+		return err
+	}
+	ms.eventHub.publishMessages(msgs...)
+
+	return nil
+}
+
+// SubscribeMessageEvents the returned channel will be closed when ctx is done or the instance is no longer the leader.
+// The events are only published by the leader which refreshes the message state, so the others reject subscriptions.
+func (ms *MessageService) SubscribeMessageEvents(ctx context.Context, filter *sophonTypes.MessageEventFilter) (<-chan *sophonTypes.MessageEvent, error) {
+	if !ms.leader.isLeader() {
+		if lease, err := ms.GetLeader(ctx); err == nil && !lease.IsExpired(time.Now()) {
+			return nil, fmt.Errorf("%w, subscribe to the leader %s", errNotLeader, lease.Holder)
+		}
+		return nil, fmt.Errorf("%w, subscribe to the leader", errNotLeader)
+	}
+	return ms.eventHub.subscribe(ctx, filter), nil
+}
+
+// publishMessageEvent publishes the state of message which is changed by user
+func (ms *MessageService) publishMessageEvent(id string) {
+	msg, err := ms.repo.MessageRepo().GetMessageByUid(id)
+	if err != nil {
+		log.Warnf("get message %s failed %v, skip publishing event", id, err)
+		return
+	}
+	ms.eventHub.publishMessages(msg)
+}
+
+// SetMessagePriority only the priority of unfill message can be changed
+func (ms *MessageService) SetMessagePriority(_ context.Context, id string, priority int) error {
+	state, err := ms.repo.MessageRepo().GetMessageState(id)
+	if err != nil {
+		return err
+	}
+	if state != types.UnFillMsg {
+		return fmt.Errorf("the priority of message in %s state cannot be changed", state)
+	}
+	if err := ms.repo.MessageRepo().UpdateMessagePriority(id, priority); err != nil {
+		if errors.Is(err, repo.ErrRecordNotFound) {
+			return fmt.Errorf("message %s is no longer unfill", id)
+		}
+		return err
+	}
+	log.Infof("set priority of message %s to %d", id, priority)
+
+	return nil
+}
+
+func (ms *MessageService) SimulateSelect(ctx context.Context, addr address.Address) (*sophonTypes.SelectSimulation, error) {
+	return ms.msgSelectMgr.SimulateSelect(ctx, addr)
+}
+
+func (ms *MessageService) NonceGapReport(ctx context.Context, addr address.Address) (*sophonTypes.NonceGapReport, error) {
+	return ms.msgSelectMgr.NonceGapReport(ctx, addr)
+}
+
+func (ms *MessageService) FillNonceGap(ctx context.Context, addr address.Address) ([]string, error) {
+	return ms.msgSelectMgr.FillNonceGap(ctx, addr)
 }
 
 func (ms *MessageService) RecoverFailedMsg(ctx context.Context, addr address.Address) ([]string, error) {
@@ -885,6 +1208,8 @@ func (ms *MessageService) RecoverFailedMsg(ctx context.Context, addr address.Add
 			if err = ms.repo.MessageRepo().UpdateMessageStateByID(msg.ID, types.FillMsg); err != nil {
 				return nil, err
 			}
+			msg.State = types.FillMsg
+			ms.eventHub.publishMessages(msg)
 			recoverIDs = append(recoverIDs, msg.ID)
 		}
 	}
@@ -953,7 +1278,11 @@ func (ms *MessageService) clearUnFillMessage(addr address.Address) (int, error)
 			if err := txRepo.MessageRepo().MarkBadMessage(msg.ID); err != nil {
 				return fmt.Errorf("mark bad message %s failed %v", msg.ID, err)
 			}
-			count++
+			children, err := ms.failBatchedMessages(context.TODO(), txRepo, msg)
+			if err != nil {
+				return err
+			}
+			count += 1 + len(children)
 		}
 		return nil
 	}); err != nil {
@@ -964,6 +1293,14 @@ func (ms *MessageService) clearUnFillMessage(addr address.Address) (int, error)
 }
 
 func (ms *MessageService) ClearUnFillMessage(ctx context.Context, addr address.Address) (int, error) {
+	// the unfill messages are cleared before selecting messages, which only happens on the instance holding the address
+	if ms.shard != nil {
+		if !ms.shard.owns(addr) {
+			return 0, errNotInShard
+		}
+	} else if !ms.leader.isLeader() {
+		return 0, errNotLeader
+	}
 	ms.cleanUnFillMsgFunc <- func() (int, error) {
 		return ms.clearUnFillMessage(addr)
 	}
@@ -996,6 +1333,20 @@ func (ms *MessageService) GetActorCfgByID(ctx context.Context, id venusTypes.UUI
 	return ms.repo.ActorCfgRepo().GetActorCfgByID(ctx, id)
 }
 
+func (ms *MessageService) SetActorCfgPreflight(ctx context.Context, id venusTypes.UUID, enable bool) error {
+	return ms.repo.ActorCfgRepo().UpdatePreflightById(ctx, id, enable)
+}
+
+func (ms *MessageService) ListPreflightActorCfg(ctx context.Context) ([]venusTypes.UUID, error) {
+	return ms.repo.ActorCfgRepo().ListPreflightActorCfgID(ctx)
+}
+
+// the thresholds of blocked message, used by metrics and notifier
+const (
+	msgBlockedThreeMinutes = 3 * time.Minute
+	msgBlockedFiveMinutes  = 5 * time.Minute
+)
+
 func (ms *MessageService) recordMetricsProc(ctx context.Context) {
 	tm := time.NewTicker(time.Second * 60)
 	defer tm.Stop()
@@ -1041,14 +1392,14 @@ func (ms *MessageService) recordMetricsProc(ctx context.Context) {
 					stats.Record(ctx, metrics.NumOfFillMsg.M(int64(len(msgs))))
 				}
 
-				msgs, err = ms.repo.MessageRepo().ListBlockedMessage(&repo.MsgQueryParams{From: []address.Address{addr.Addr}}, 3*time.Minute)
+				msgs, err = ms.repo.MessageRepo().ListBlockedMessage(&repo.MsgQueryParams{From: []address.Address{addr.Addr}}, msgBlockedThreeMinutes)
 				if err != nil {
 					log.Errorf("get blocked three minutes msg err: %s", err)
 				} else {
 					stats.Record(ctx, metrics.NumOfMsgBlockedThreeMinutes.M(int64(len(msgs))))
 				}
 
-				msgs, err = ms.repo.MessageRepo().ListBlockedMessage(&repo.MsgQueryParams{From: []address.Address{addr.Addr}}, 5*time.Minute)
+				msgs, err = ms.repo.MessageRepo().ListBlockedMessage(&repo.MsgQueryParams{From: []address.Address{addr.Addr}}, msgBlockedFiveMinutes)
 				if err != nil {
 					log.Errorf("get blocked five minutes msg err: %s", err)
 				} else {
@@ -43,19 +43,19 @@ func TestVerifyNetworkName(t *testing.T) {
 
 	msh := newMessageServiceHelper(ctx, t)
 	ms := msh.MessageService
-	assert.NoError(t, msh.MessageService.tsCache.Save(msh.fsRepo.TipsetFile()))
-
-	tipsetCache := &TipsetCache{
-		NetworkName: string(shared.NetworkNameMain),
-	}
-	assert.NoError(t, tipsetCache.Save(msh.fsRepo.TipsetFile()))
 
 	networkName, err := msh.fullNode.StateNetworkName(ctx)
 	assert.NoError(t, err)
-	ms.tsCache.NetworkName = string(shared.NetworkNameButterfly)
+	assert.Equal(t, string(networkName), ms.tsCache.NetworkName)
+
+	// the network name of the tipsets saved in db
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, ms.repo.TipsetRepo().SaveTipset(ctx, string(shared.NetworkNameButterfly), ts))
+	assert.NoError(t, ms.tsCache.Load(ctx, ms.repo.TipsetRepo()))
 	err = ms.verifyNetworkName()
-	expectErrStr := fmt.Sprintf("network name not match, expect %s, actual %s, please remove `%s`",
-		networkName, ms.tsCache.NetworkName, msh.fsRepo.TipsetFile())
+	expectErrStr := fmt.Sprintf("network name not match, expect %s, actual %s, please delete the records in table `tipsets`",
+		networkName, shared.NetworkNameButterfly)
 	assert.Equal(t, expectErrStr, err.Error())
 }
 
@@ -611,7 +611,7 @@ func newMessageServiceHelper(ctx context.Context, t *testing.T, opts ...opt) *me
 
 	repo, err := models.SetDataBase(fsRepo)
 	assert.NoError(t, err)
-	assert.NoError(t, repo.AutoMigrate())
+	assert.NoError(t, repo.SchemaMigrator().Migrate(context.Background()))
 
 	authClient := testhelper.NewMockAuthClient(t)
 	walletProxy := gateway.NewMockWalletProxy()
@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 
@@ -14,6 +16,7 @@ import (
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
 
 	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 var msgStateLog = logging.Logger("msg-state")
@@ -70,7 +73,11 @@ func (ms *MessageService) doRefreshMessageState(ctx context.Context, h *headChan
 	}
 
 	if err := ms.storeTipset(ctx, h.apply); err != nil {
-		msgStateLog.Errorf("store tipset to cache failed %v", err)
+		msgStateLog.Errorf("store tipset failed %v", err)
+	}
+
+	if err := ms.finalizeMessage(ctx, h.apply[0]); err != nil {
+		msgStateLog.Errorf("finalize message failed %v", err)
 	}
 
 	msgStateLog.Infof("process block %d, revert %d message, apply %d message, replaced %d message", ms.tsCache.CurrHeight, len(revertMsgs), len(applyMsgs)-len(invalidMsgs), len(replaceMsg))
@@ -81,12 +88,37 @@ func (ms *MessageService) doRefreshMessageState(ctx context.Context, h *headChan
 func (ms *MessageService) updateMessageState(applyMsgs []applyMessage, revertMsgs map[cid.Cid]struct{}) (map[string]*types.Message, map[cid.Cid]struct{}, error) {
 	replaceMsg := make(map[string]*types.Message)
 	invalidMsgs := make(map[cid.Cid]struct{})
-	return replaceMsg, invalidMsgs, ms.repo.Transaction(func(txRepo repo.TxRepo) error {
+	var events []*sophonTypes.MessageEvent
+	// the messages landed on chain with a non-zero exit code
+	var failedMsgs []*types.Message
+	codes := ms.resolveActorCodes(context.TODO(), applyMsgs)
+	err := ms.repo.Transaction(func(txRepo repo.TxRepo) error {
 		for cid := range revertMsgs {
 			if err := txRepo.MessageRepo().UpdateMessageInfoByCid(cid.String(), &venustypes.MessageReceipt{ExitCode: -1},
 				abi.ChainEpoch(0), types.FillMsg, venustypes.EmptyTSK); err != nil {
 				return err
 			}
+			msg, err := txRepo.MessageRepo().GetMessageByCid(cid)
+			if err != nil {
+				msgStateLog.Warnf("get reverted message %s failed %v", cid, err)
+				continue
+			}
+			if err := txRepo.MessageFeeRepo().DeleteMessageFee(context.TODO(), msg.ID); err != nil {
+				return fmt.Errorf("delete fee of message %s failed: %v", msg.ID, err)
+			}
+			children, err := ms.processAppliedMessage(context.TODO(), txRepo, msg, true)
+			if err != nil {
+				return err
+			}
+			events = append(events, sophonTypes.NewMessageEvent(msg))
+			events = appendMessageEvents(events, children)
+			retryMsg, err := cancelMessageRetry(context.TODO(), txRepo, msg)
+			if err != nil {
+				return err
+			}
+			if retryMsg != nil {
+				events = append(events, sophonTypes.NewMessageEvent(retryMsg))
+			}
 		}
 
 		for _, msg := range applyMsgs {
@@ -99,6 +131,34 @@ func (ms *MessageService) updateMessageState(applyMsgs []applyMessage, revertMsg
 				continue
 			}
 			if localMsg.SignedCid != nil && !(*localMsg.SignedCid).Equals(msg.signedCID) {
+				// an older signed version of the message landed on chain, restore it rather than treating it as replaced
+				rev, err := txRepo.MessageRevisionRepo().GetMessageRevisionBySignedCid(context.TODO(), msg.signedCID)
+				if err != nil && !errors.Is(err, repo.ErrRecordNotFound) {
+					return fmt.Errorf("get message revision by %s failed: %v", msg.signedCID, err)
+				}
+				if rev != nil && rev.MsgID == localMsg.ID {
+					msgStateLog.Infof("old revision of message %s landed on chain, local cid %s, on chain cid %s", localMsg.ID, localMsg.SignedCid, msg.signedCID)
+					restoreRevision(localMsg, rev)
+					localMsg.State = types.OnChainMsg
+					localMsg.Receipt = msg.receipt
+					localMsg.Height = int64(msg.height)
+					localMsg.TipSetKey = msg.tsk
+					if err = txRepo.MessageRepo().UpdateMessage(localMsg); err != nil {
+						return fmt.Errorf("update message receipt failed, cid:%s failed:%v", msg.signedCID, err)
+					}
+					if err = txRepo.MessageFeeRepo().SaveMessageFee(context.TODO(), newMessageFee(localMsg.ID, msg)); err != nil {
+						return fmt.Errorf("save fee of message %s failed: %v", localMsg.ID, err)
+					}
+					children, err := ms.processAppliedMessage(context.TODO(), txRepo, localMsg, false)
+					if err != nil {
+						return err
+					}
+					events = append(events, sophonTypes.NewMessageEvent(localMsg))
+					events = appendMessageEvents(events, children)
+					failedMsgs = appendFailedMessage(failedMsgs, localMsg)
+					continue
+				}
+
 				msgStateLog.Warnf("replace message old msg cid %s, new msg cid %s, id %s", localMsg.SignedCid, msg.signedCID, localMsg.ID)
 				// replace msg
 				localMsg.State = types.NonceConflictMsg
@@ -109,14 +169,77 @@ func (ms *MessageService) updateMessageState(applyMsgs []applyMessage, revertMsg
 					return fmt.Errorf("update message receipt failed, cid:%s failed:%v", msg.signedCID, err)
 				}
 				replaceMsg[localMsg.ID] = localMsg
+				// the nonce of batch is taken by another message, the messages collapsed into it are sent again
+				children, err := ms.releaseBatchedMessages(context.TODO(), txRepo, localMsg)
+				if err != nil {
+					return err
+				}
+				events = appendMessageEvents(events, children)
 			} else {
 				if err = txRepo.MessageRepo().UpdateMessageInfoByCid(msg.msg.Cid().String(), msg.receipt, msg.height, types.OnChainMsg, msg.tsk); err != nil {
 					return fmt.Errorf("update message receipt failed, cid:%s failed:%v", msg.msg.Cid(), err)
 				}
+				if err = txRepo.MessageFeeRepo().SaveMessageFee(context.TODO(), newMessageFee(localMsg.ID, msg)); err != nil {
+					return fmt.Errorf("save fee of message %s failed: %v", localMsg.ID, err)
+				}
+				localMsg.State = types.OnChainMsg
+				localMsg.Receipt = msg.receipt
+				localMsg.Height = int64(msg.height)
+				localMsg.TipSetKey = msg.tsk
+				children, err := ms.processAppliedMessage(context.TODO(), txRepo, localMsg, false)
+				if err != nil {
+					return err
+				}
+				events = appendMessageEvents(events, children)
+				failedMsgs = appendFailedMessage(failedMsgs, localMsg)
 			}
+			events = append(events, sophonTypes.NewMessageEvent(localMsg))
 		}
-		return nil
+		if err := ms.applyExitCodeRules(context.TODO(), txRepo, failedMsgs, codes); err != nil {
+			return err
+		}
+		return ms.saveEventNotifications(context.TODO(), txRepo, events...)
 	})
+	if err != nil {
+		return replaceMsg, invalidMsgs, err
+	}
+	ms.eventHub.publish(events...)
+
+	return replaceMsg, invalidMsgs, nil
+}
+
+// processAppliedMessage updates the records depending on msg after it lands on chain or is reverted,
+// returns the messages collapsed into msg whose state changed
+func (ms *MessageService) processAppliedMessage(ctx context.Context, txRepo repo.TxRepo, msg *types.Message, reverted bool) ([]*types.Message, error) {
+	if err := ms.updateMsigProposal(ctx, txRepo, msg, reverted); err != nil {
+		return nil, err
+	}
+	return ms.updateBatchedMessages(ctx, txRepo, msg, reverted)
+}
+
+func appendMessageEvents(events []*sophonTypes.MessageEvent, msgs []*types.Message) []*sophonTypes.MessageEvent {
+	for _, msg := range msgs {
+		events = append(events, sophonTypes.NewMessageEvent(msg))
+	}
+	return events
+}
+
+func appendFailedMessage(msgs []*types.Message, msg *types.Message) []*types.Message {
+	if msg.Receipt != nil && !msg.Receipt.ExitCode.IsSuccess() {
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// restoreRevision sets the gas params and signature of msg to the signed version in rev
+func restoreRevision(msg *types.Message, rev *sophonTypes.MessageRevision) {
+	unsignedCid, signedCid := rev.UnsignedCid, rev.SignedCid
+	msg.UnsignedCid = &unsignedCid
+	msg.SignedCid = &signedCid
+	msg.Signature = rev.Signature
+	msg.GasLimit = rev.GasLimit
+	msg.GasFeeCap = rev.GasFeeCap
+	msg.GasPremium = rev.GasPremium
 }
 
 func (ms *MessageService) storeTipset(ctx context.Context, apply []*venustypes.TipSet) error {
@@ -136,11 +259,11 @@ func (ms *MessageService) storeTipset(ctx context.Context, apply []*venustypes.T
 	}
 
 	ms.tsCache.CurrHeight = int64(processed[0].Height())
-	ms.tsCache.Add(processed...)
 
-	return ms.tsCache.Save(ms.fsRepo.TipsetFile())
+	return ms.tsCache.Save(ctx, ms.repo.TipsetRepo(), processed...)
 }
 
+// processRevertHead only reverts OnChainMsg messages, the finalized messages are never reverted
 func (ms *MessageService) processRevertHead(ctx context.Context, h *headChan) (map[cid.Cid]struct{}, error) {
 	revertMsgs := make(map[cid.Cid]struct{})
 
@@ -174,6 +297,9 @@ type applyMessage struct {
 	height    abi.ChainEpoch
 	tsk       venustypes.TipSetKey
 	receipt   *venustypes.MessageReceipt
+	// baseFee the parent base fee of the tipset including the message
+	baseFee   big.Int
+	timestamp uint64
 }
 
 func (ms *MessageService) processBlockParentMessages(ctx context.Context, apply []*venustypes.TipSet) ([]applyMessage, error) {
@@ -210,6 +336,8 @@ func (ms *MessageService) processBlockParentMessages(ctx context.Context, apply
 					receipt:   receipts[i],
 					msg:       msg,
 					signedCID: msgs[i].Cid,
+					baseFee:   pts.At(0).ParentBaseFee,
+					timestamp: pts.MinTimestamp(),
 				})
 				msgCIDs = append(msgCIDs, msg.Cid().String())
 			}
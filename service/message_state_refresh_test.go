@@ -259,3 +259,67 @@ func TestUpdateMessageState(t *testing.T) {
 		assert.Equal(t, msgLookup.Receipt, *res.Receipt)
 	}
 }
+
+func TestUpdateFilledMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	msgs := genMessages(addrs[:1], 2)
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 2)
+
+	events, err := ms.SubscribeMessageEvents(ctx, nil)
+	assert.NoError(t, err)
+
+	// the messages land on chain, but the state is not refreshed by head changes
+	smsgs := make([]*shared.SignedMessage, 0, len(selectResult.SelectMsg))
+	for _, msg := range selectResult.SelectMsg {
+		smsgs = append(smsgs, &shared.SignedMessage{Message: msg.Message, Signature: *msg.Signature})
+	}
+	_, err = msh.fullNode.MpoolBatchPushUntrusted(ctx, smsgs)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		for _, msg := range selectResult.SelectMsg {
+			msgLookup, err := msh.fullNode.StateSearchMsg(ctx, shared.EmptyTSK, *msg.SignedCid, constants.LookbackNoLimit, true)
+			if err != nil || msgLookup.TipSet.IsEmpty() {
+				return false
+			}
+		}
+		return true
+	}, 10*msh.blockDelay, msh.blockDelay/10)
+
+	count, err := ms.UpdateAllFilledMessage(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// the same as refreshing the message state, the fee is saved and the events are published
+	received := make(map[string]types.MessageState)
+	for _, event := range receiveEvents(t, events, 2) {
+		received[event.ID] = event.State
+	}
+	for _, msg := range selectResult.SelectMsg {
+		res, err := ms.GetMessageByUid(ctx, msg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.OnChainMsg, res.State)
+		assert.False(t, res.TipSetKey.IsEmpty())
+		assert.Equal(t, types.OnChainMsg, received[msg.ID])
+
+		fee, err := ms.repo.MessageFeeRepo().GetMessageFee(ctx, msg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, res.Height, fee.Height)
+	}
+
+	// already on chain
+	_, err = ms.UpdateFilledMessageByID(ctx, msgs[0].ID)
+	assert.NoError(t, err)
+	count, err = ms.UpdateAllFilledMessage(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
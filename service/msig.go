@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/big"
+	msig18 "github.com/filecoin-project/go-state-types/builtin/v18/multisig"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin/multisig"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// ProposeMsig wraps the inner message into a propose message of multisig and pushes it, returns the id of proposal
+func (ms *MessageService) ProposeMsig(ctx context.Context, params *sophonTypes.MsigProposeParams) (string, error) {
+	if params == nil {
+		return "", fmt.Errorf("params is nil")
+	}
+	from, err := ms.signerKey(ctx, params.From)
+	if err != nil {
+		return "", err
+	}
+	value := params.Value
+	if value.Nil() {
+		value = big.Zero()
+	}
+
+	builder, err := ms.msigMessageBuilder(ctx, from)
+	if err != nil {
+		return "", err
+	}
+	msg, err := builder.Propose(params.Msig, params.To, value, params.Method, params.Params)
+	if err != nil {
+		return "", fmt.Errorf("create propose message failed: %w", err)
+	}
+	msgID := venusTypes.NewUUID().String()
+	proposal := &sophonTypes.MsigProposal{
+		ID:           venusTypes.NewUUID().String(),
+		Msig:         params.Msig,
+		Proposer:     from,
+		To:           params.To,
+		Value:        value,
+		Method:       params.Method,
+		Params:       params.Params,
+		ProposeMsgID: msgID,
+		TxnID:        sophonTypes.NoTxnID,
+		State:        sophonTypes.MsigProposalPending,
+	}
+	// the proposal is saved with the propose message, so that the message is always tracked
+	if _, err := ms.pushMessageWithSpec(ctx, msgID, msg, params.Spec, func(txRepo repo.TxRepo) error {
+		if err := txRepo.MsigProposalRepo().SaveMsigProposal(ctx, proposal); err != nil {
+			return fmt.Errorf("save proposal of message %s failed: %w", msgID, err)
+		}
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	log.Infof("propose msig %s transaction by message %s, proposal %s", params.Msig, msgID, proposal.ID)
+
+	return proposal.ID, nil
+}
+
+// ApproveMsig pushes an approve message of the proposal from another signer, returns the id of message
+func (ms *MessageService) ApproveMsig(ctx context.Context, params *sophonTypes.MsigApproveParams) (string, error) {
+	if params == nil {
+		return "", fmt.Errorf("params is nil")
+	}
+	proposal, err := ms.repo.MsigProposalRepo().GetMsigProposal(ctx, params.ProposalID)
+	if err != nil {
+		return "", fmt.Errorf("get proposal %s failed: %w", params.ProposalID, err)
+	}
+	if proposal.State != sophonTypes.MsigProposalProposed {
+		return "", fmt.Errorf("proposal %s is %s, only the proposed one can be approved", proposal.ID, proposal.State)
+	}
+	from, err := ms.signerKey(ctx, params.From)
+	if err != nil {
+		return "", err
+	}
+	if from == proposal.Proposer {
+		return "", fmt.Errorf("proposal %s is proposed by %s", proposal.ID, from)
+	}
+	if containsAddress(proposal.Approvers, from) {
+		return "", fmt.Errorf("proposal %s has been approved by %s", proposal.ID, from)
+	}
+	pendingMsgID, err := ms.pendingApproveMsg(proposal, from)
+	if err != nil {
+		return "", err
+	}
+	if len(pendingMsgID) != 0 {
+		return "", fmt.Errorf("proposal %s is being approved by %s in message %s", proposal.ID, from, pendingMsgID)
+	}
+
+	builder, err := ms.msigMessageBuilder(ctx, from)
+	if err != nil {
+		return "", err
+	}
+	msg, err := builder.Approve(proposal.Msig, uint64(proposal.TxnID), nil)
+	if err != nil {
+		return "", fmt.Errorf("create approve message failed: %w", err)
+	}
+	// the approver is recorded after the approve message lands on chain successfully
+	msgID, err := ms.PushMessageWithSpec(ctx, "", msg, params.Spec)
+	if err != nil {
+		return "", err
+	}
+	log.Infof("approve msig %s transaction %d by message %s", proposal.Msig, proposal.TxnID, msgID)
+
+	return msgID, nil
+}
+
+// pendingApproveMsg returns the id of the approve message of proposal from the signer which is not on chain yet,
+// empty if there is none
+func (ms *MessageService) pendingApproveMsg(proposal *sophonTypes.MsigProposal, from address.Address) (string, error) {
+	msgs, err := ms.repo.MessageRepo().ListMessageByParams(&repo.MsgQueryParams{
+		From:  []address.Address{from},
+		State: []types.MessageState{types.UnFillMsg, types.FillMsg},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, msg := range msgs {
+		if msg.To != proposal.Msig || msg.Method != multisig.Methods.Approve {
+			continue
+		}
+		var params msig18.TxnIDParams
+		if err := params.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil {
+			continue
+		}
+		if int64(params.ID) == proposal.TxnID {
+			return msg.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func (ms *MessageService) GetMsigProposal(ctx context.Context, id string) (*sophonTypes.MsigProposal, error) {
+	return ms.repo.MsigProposalRepo().GetMsigProposal(ctx, id)
+}
+
+// ListMsigProposal lists the proposals of msig, all proposals if msig is address.Undef
+func (ms *MessageService) ListMsigProposal(ctx context.Context, msig address.Address) ([]*sophonTypes.MsigProposal, error) {
+	return ms.repo.MsigProposalRepo().ListMsigProposal(ctx, msig)
+}
+
+func (ms *MessageService) signerKey(ctx context.Context, addr address.Address) (address.Address, error) {
+	if addr.Protocol() != address.ID {
+		return addr, nil
+	}
+	key, err := ms.nodeClient.StateAccountKey(ctx, addr, venusTypes.EmptyTSK)
+	if err != nil {
+		return address.Undef, fmt.Errorf("getting key address: %w", err)
+	}
+	return key, nil
+}
+
+func (ms *MessageService) msigMessageBuilder(ctx context.Context, from address.Address) (multisig.MessageBuilder, error) {
+	nv, err := ms.nodeClient.StateNetworkVersion(ctx, venusTypes.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get network version failed: %w", err)
+	}
+	av, err := actorstypes.VersionForNetwork(nv)
+	if err != nil {
+		return nil, err
+	}
+	return multisig.Message(av, from), nil
+}
+
+// updateMsigProposal tracks the proposal when its propose or approve message lands on chain or is reverted,
+// the TxnID is parsed from the return of propose message
+func (ms *MessageService) updateMsigProposal(ctx context.Context, txRepo repo.TxRepo, msg *types.Message, reverted bool) error {
+	var proposal *sophonTypes.MsigProposal
+	var err error
+	switch msg.Method {
+	case multisig.Methods.Propose:
+		proposal, err = txRepo.MsigProposalRepo().GetMsigProposalByProposeMsg(ctx, msg.ID)
+		if err != nil {
+			break
+		}
+		updateProposalByPropose(proposal, msg, reverted)
+	case multisig.Methods.Approve:
+		var params msig18.TxnIDParams
+		if err := params.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil {
+			// not an approve message of multisig
+			return nil
+		}
+		proposal, err = txRepo.MsigProposalRepo().GetMsigProposalByTxnID(ctx, msg.To, int64(params.ID))
+		if err != nil {
+			break
+		}
+		updateProposalByApprove(proposal, msg, reverted)
+	default:
+		return nil
+	}
+	if err != nil {
+		if errors.Is(err, repo.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("get proposal of message %s failed: %w", msg.ID, err)
+	}
+
+	log.Infof("msig proposal %s is %s, txn id %d", proposal.ID, proposal.State, proposal.TxnID)
+	return txRepo.MsigProposalRepo().SaveMsigProposal(ctx, proposal)
+}
+
+func updateProposalByPropose(proposal *sophonTypes.MsigProposal, msg *types.Message, reverted bool) {
+	switch {
+	case reverted:
+		proposal.TxnID = sophonTypes.NoTxnID
+		proposal.State = sophonTypes.MsigProposalPending
+		proposal.ExitCode, proposal.Return = 0, nil
+	case msg.Receipt == nil || msg.Receipt.ExitCode != exitcode.Ok:
+		proposal.State = sophonTypes.MsigProposalFailed
+	default:
+		var ret multisig.ProposeReturn
+		if err := ret.UnmarshalCBOR(bytes.NewReader(msg.Receipt.Return)); err != nil {
+			log.Warnf("decode propose return of message %s failed: %v", msg.ID, err)
+			proposal.State = sophonTypes.MsigProposalFailed
+			return
+		}
+		proposal.TxnID = int64(ret.TxnID)
+		proposal.State = sophonTypes.MsigProposalProposed
+		// the transaction is applied immediately if the threshold of multisig is 1
+		if ret.Applied {
+			proposal.State = sophonTypes.MsigProposalApplied
+			proposal.ExitCode, proposal.Return = ret.Code, ret.Ret
+		}
+	}
+}
+
+func updateProposalByApprove(proposal *sophonTypes.MsigProposal, msg *types.Message, reverted bool) {
+	switch {
+	case reverted:
+		approvers := proposal.Approvers[:0]
+		for _, approver := range proposal.Approvers {
+			if approver != msg.From {
+				approvers = append(approvers, approver)
+			}
+		}
+		proposal.Approvers = approvers
+		if proposal.State == sophonTypes.MsigProposalApplied {
+			proposal.State = sophonTypes.MsigProposalProposed
+			proposal.ExitCode, proposal.Return = 0, nil
+		}
+	case msg.Receipt != nil && msg.Receipt.ExitCode == exitcode.Ok:
+		if !containsAddress(proposal.Approvers, msg.From) {
+			proposal.Approvers = append(proposal.Approvers, msg.From)
+		}
+		var ret multisig.ApproveReturn
+		if err := ret.UnmarshalCBOR(bytes.NewReader(msg.Receipt.Return)); err != nil {
+			log.Warnf("decode approve return of message %s failed: %v", msg.ID, err)
+			return
+		}
+		if ret.Applied {
+			proposal.State = sophonTypes.MsigProposalApplied
+			proposal.ExitCode, proposal.Return = ret.Code, ret.Ret
+		}
+	}
+}
+
+func containsAddress(addrs []address.Address, addr address.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin/multisig"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestMsigProposal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	msig, err := address.NewIDAddress(30001)
+	require.NoError(t, err)
+	require.NoError(t, msh.fullNode.AddActors([]address.Address{msig}))
+	proposer, approver := addrs[0], addrs[1]
+
+	encode := func(obj cbg.CBORMarshaler) []byte {
+		buf := new(bytes.Buffer)
+		require.NoError(t, obj.MarshalCBOR(buf))
+		return buf.Bytes()
+	}
+	// selects the message of addr and lands it on chain with ret
+	applyMessage := func(addr address.Address, ret []byte) *types.Message {
+		ts, err := msh.fullNode.ChainHead(ctx)
+		require.NoError(t, err)
+		selectResult := selectMsgWithAddress(ctx, t, msh, []address.Address{addr}, ts)
+		require.Len(t, selectResult.SelectMsg, 1)
+		msg := selectResult.SelectMsg[0]
+
+		_, _, err = ms.updateMessageState([]applyMessage{
+			{
+				signedCID: *msg.SignedCid,
+				msg:       &msg.Message,
+				height:    ts.Height(),
+				tsk:       ts.Key(),
+				receipt:   &venusTypes.MessageReceipt{ExitCode: exitcode.Ok, Return: ret, GasUsed: 100},
+			},
+		}, nil)
+		require.NoError(t, err)
+		return msg
+	}
+	revertMessage := func(msg *types.Message) {
+		_, _, err := ms.updateMessageState(nil, map[cid.Cid]struct{}{*msg.UnsignedCid: {}})
+		require.NoError(t, err)
+	}
+	getProposal := func(id string) *sophonTypes.MsigProposal {
+		proposal, err := ms.GetMsigProposal(ctx, id)
+		require.NoError(t, err)
+		return proposal
+	}
+
+	proposalID, err := ms.ProposeMsig(ctx, &sophonTypes.MsigProposeParams{
+		Msig:   msig,
+		From:   proposer,
+		To:     addrs[2],
+		Value:  big.NewInt(100),
+		Method: builtin.MethodSend,
+	})
+	require.NoError(t, err)
+	proposal := getProposal(proposalID)
+	assert.Equal(t, sophonTypes.MsigProposalPending, proposal.State)
+	assert.Equal(t, sophonTypes.NoTxnID, proposal.TxnID)
+	assert.Equal(t, proposer, proposal.Proposer)
+
+	proposeMsg, err := ms.GetMessageByUid(ctx, proposal.ProposeMsgID)
+	require.NoError(t, err)
+	assert.Equal(t, msig, proposeMsg.To)
+	assert.Equal(t, multisig.Methods.Propose, proposeMsg.Method)
+	assert.True(t, proposeMsg.Value.IsZero())
+	var proposeParams multisig.ProposeParams
+	require.NoError(t, proposeParams.UnmarshalCBOR(bytes.NewReader(proposeMsg.Params)))
+	assert.Equal(t, addrs[2], proposeParams.To)
+	assert.Equal(t, big.NewInt(100), proposeParams.Value)
+
+	// can not approve before the transaction id is known
+	_, err = ms.ApproveMsig(ctx, &sophonTypes.MsigApproveParams{ProposalID: proposalID, From: approver})
+	assert.Error(t, err)
+
+	proposeMsg = applyMessage(proposer, encode(&multisig.ProposeReturn{TxnID: 7}))
+	proposal = getProposal(proposalID)
+	assert.Equal(t, sophonTypes.MsigProposalProposed, proposal.State)
+	assert.Equal(t, int64(7), proposal.TxnID)
+
+	_, err = ms.ApproveMsig(ctx, &sophonTypes.MsigApproveParams{ProposalID: proposalID, From: proposer})
+	assert.Error(t, err)
+	approveMsgID, err := ms.ApproveMsig(ctx, &sophonTypes.MsigApproveParams{ProposalID: proposalID, From: approver})
+	require.NoError(t, err)
+	// the approve message is not on chain yet
+	_, err = ms.ApproveMsig(ctx, &sophonTypes.MsigApproveParams{ProposalID: proposalID, From: approver})
+	assert.Error(t, err)
+	assert.Empty(t, getProposal(proposalID).Approvers)
+
+	approveMsg := applyMessage(approver, encode(&multisig.ApproveReturn{Applied: true, Code: exitcode.Ok, Ret: []byte{}}))
+	assert.Equal(t, approveMsgID, approveMsg.ID)
+	proposal = getProposal(proposalID)
+	assert.Equal(t, sophonTypes.MsigProposalApplied, proposal.State)
+	assert.Equal(t, exitcode.Ok, proposal.ExitCode)
+	assert.Equal(t, []address.Address{approver}, proposal.Approvers)
+
+	proposals, err := ms.ListMsigProposal(ctx, msig)
+	require.NoError(t, err)
+	assert.Len(t, proposals, 1)
+	proposals, err = ms.ListMsigProposal(ctx, addrs[3])
+	require.NoError(t, err)
+	assert.Len(t, proposals, 0)
+
+	// the messages are reverted
+	revertMessage(approveMsg)
+	proposal = getProposal(proposalID)
+	assert.Equal(t, sophonTypes.MsigProposalProposed, proposal.State)
+	assert.Empty(t, proposal.Approvers)
+
+	// the approve message failed, the signer can approve again
+	_, err = ms.UpdateMessageInfoByCid(approveMsg.UnsignedCid.String(), &venusTypes.MessageReceipt{ExitCode: exitcode.ErrForbidden},
+		abi.ChainEpoch(10), types.OnChainMsg, venusTypes.EmptyTSK)
+	require.NoError(t, err)
+	assert.Empty(t, getProposal(proposalID).Approvers)
+	_, err = ms.ApproveMsig(ctx, &sophonTypes.MsigApproveParams{ProposalID: proposalID, From: approver})
+	require.NoError(t, err)
+
+	revertMessage(proposeMsg)
+	proposal = getProposal(proposalID)
+	assert.Equal(t, sophonTypes.MsigProposalPending, proposal.State)
+	assert.Equal(t, sophonTypes.NoTxnID, proposal.TxnID)
+
+	// the propose message failed
+	_, err = ms.UpdateMessageInfoByCid(proposeMsg.UnsignedCid.String(), &venusTypes.MessageReceipt{ExitCode: exitcode.ErrForbidden},
+		abi.ChainEpoch(10), types.OnChainMsg, venusTypes.EmptyTSK)
+	require.NoError(t, err)
+	assert.Equal(t, sophonTypes.MsigProposalFailed, getProposal(proposalID).State)
+}
@@ -22,7 +22,7 @@ func TestNodeService(t *testing.T) {
 
 	repo, err := models.SetDataBase(fsRepo)
 	assert.NoError(t, err)
-	assert.NoError(t, repo.AutoMigrate())
+	assert.NoError(t, repo.SchemaMigrator().Migrate(context.Background()))
 
 	nodeService := NewNodeService(repo.NodeRepo())
 
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs-force-community/sophon-auth/core"
+
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// maxFillNonceGap the max number of nonce gaps filled in one round, a huge gap is more likely caused by
+// updating nonce manually, it should be checked by user
+const maxFillNonceGap = 50
+
+// NonceGapReport reports the nonce gaps of addr against the current head
+func (msgSelectMgr *MsgSelectMgr) NonceGapReport(ctx context.Context, addr address.Address) (*sophonTypes.NonceGapReport, error) {
+	addrInfo, err := msgSelectMgr.addressService.GetAddress(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := msgSelectMgr.fullNode.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliedNonce, err := msgSelectMgr.getNonceInTipset(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	// use a new work to avoid blocking the running one, nothing will be changed
+	w := newWork(ctx, addr, msgSelectMgr.cfg, msgSelectMgr.fullNode, msgSelectMgr.repo, msgSelectMgr.addressService, msgSelectMgr.walletClient, msgSelectMgr.msgReceiver, msgSelectMgr.eventHub, msgSelectMgr.leader, msgSelectMgr.shard)
+	defer w.close()
+
+	nonceInLatestTs, actor, err := w.getNonce(ctx, ts, appliedNonce)
+	if err != nil {
+		return nil, err
+	}
+	gaps, err := w.findNonceGaps(nonceInLatestTs, addrInfo.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sophonTypes.NonceGapReport{
+		Address:         addr,
+		Height:          ts.Height(),
+		AddressNonce:    addrInfo.Nonce,
+		ActorNonce:      actor.Nonce,
+		NonceInLatestTs: nonceInLatestTs,
+		Gaps:            gaps,
+	}, nil
+}
+
+// FillNonceGap fills the nonce gaps of addr and pushes the messages, returns the id of messages used to fill gaps
+func (msgSelectMgr *MsgSelectMgr) FillNonceGap(ctx context.Context, addr address.Address) ([]string, error) {
+	sharedParams, err := msgSelectMgr.sps.GetSharedParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := msgSelectMgr.fullNode.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliedNonce, err := msgSelectMgr.getNonceInTipset(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !msgSelectMgr.shard.owns(addr) {
+		return nil, fmt.Errorf("%w: %s", errNotInShard, addr)
+	}
+	msgSelectMgr.lk.Lock()
+	w, ok := msgSelectMgr.works[addr]
+	msgSelectMgr.lk.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("address %s is not active", addr)
+	}
+	// wait for the running selection without holding the lock, avoid filling the same gap twice
+	select {
+	case w.controlChan <- struct{}{}:
+	case <-w.ctx.Done():
+		return nil, fmt.Errorf("address %s is not active", addr)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer w.finish()
+
+	// get address after the running selection finished, the nonce may be changed
+	addrInfo, err := msgSelectMgr.addressService.GetAddress(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := msgSelectMgr.addressService.GetAccountsOfSigner(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("get account failed: %v", err)
+	}
+	nonceInLatestTs, actor, err := w.getNonce(ctx, ts, appliedNonce)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := w.fillNonceGaps(ctx, ts, nonceInLatestTs, actor, addrInfo, sharedParams, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(msgs))
+	toPushMsg := make([]*venusTypes.SignedMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+		toPushMsg = append(toPushMsg, &venusTypes.SignedMessage{
+			Message:   msg.Message,
+			Signature: *msg.Signature,
+		})
+	}
+	if len(toPushMsg) > 0 {
+		select {
+		case msgSelectMgr.msgReceiver <- toPushMsg:
+		default:
+			w.log.Errorf("message receiver channel is full, skip %d messages", len(toPushMsg))
+		}
+	}
+
+	return ids, nil
+}
+
+// autoFillNonceGap returns whether the nonce gaps of address should be filled before selecting messages
+func (w *work) autoFillNonceGap(ctx context.Context) bool {
+	addrCfg, err := w.addressService.GetAddressConfig(ctx, w.addr)
+	if err != nil {
+		w.log.Warnf("get address config failed: %v", err)
+		return false
+	}
+	return addrCfg.AutoFillNonceGap
+}
+
+// findNonceGaps finds the nonces in [nonceInLatestTs, addrNonce) which have no FillMsg message
+func (w *work) findNonceGaps(nonceInLatestTs, addrNonce uint64) ([]*sophonTypes.NonceGap, error) {
+	gaps := make([]*sophonTypes.NonceGap, 0)
+	if addrNonce <= nonceInLatestTs {
+		return gaps, nil
+	}
+
+	filledMsgs, err := w.repo.MessageRepo().ListFilledMessageByAddress(w.addr)
+	if err != nil {
+		return nil, fmt.Errorf("list filled message failed: %v", err)
+	}
+	filled := make(map[uint64]struct{}, len(filledMsgs))
+	for _, msg := range filledMsgs {
+		filled[msg.Nonce] = struct{}{}
+	}
+	failedMsgs, err := w.repo.MessageRepo().GetSignedMessageFromFailedMsg(w.addr)
+	if err != nil {
+		return nil, fmt.Errorf("list failed message failed: %v", err)
+	}
+	failed := make(map[uint64]string, len(failedMsgs))
+	for _, msg := range failedMsgs {
+		failed[msg.Nonce] = msg.ID
+	}
+
+	for nonce := nonceInLatestTs; nonce < addrNonce; nonce++ {
+		if _, ok := filled[nonce]; ok {
+			continue
+		}
+		gaps = append(gaps, &sophonTypes.NonceGap{Nonce: nonce, FailedMsgID: failed[nonce]})
+	}
+
+	return gaps, nil
+}
+
+// fillNonceGaps re-signs the failed message or creates a zero value message sent to self for each nonce gap,
+// the messages are saved as FillMsg, but not pushed.
+// The gaps are filled only if the balance and the spend budget of address can cover all the messages, like selecting
+// messages.
+func (w *work) fillNonceGaps(ctx context.Context,
+	ts *venusTypes.TipSet,
+	nonceInLatestTs uint64,
+	actor *venusTypes.Actor,
+	addrInfo *types.Address,
+	sharedParams *types.SharedSpec,
+	accounts []string,
+) ([]*types.Message, error) {
+	gaps, err := w.findNonceGaps(nonceInLatestTs, addrInfo.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(gaps) == 0 {
+		return nil, nil
+	}
+	if len(gaps) > maxFillNonceGap {
+		return nil, fmt.Errorf("too many nonce gaps %d, nonce in latest ts %d, address nonce %d, check the address nonce",
+			len(gaps), nonceInLatestTs, addrInfo.Nonce)
+	}
+
+	// the signed messages not on chain yet will take their cost from the balance first
+	reserve := w.balanceReserve(ctx)
+	spendable := big.Sub(actor.Balance, reserve)
+	for _, msg := range w.getFilledMessage(nonceInLatestTs) {
+		spendable = big.Sub(spendable, maxMessageCost(&msg.Message))
+	}
+	usage, err := w.loadSpendUsage(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]*types.Message, 0, len(gaps))
+	for _, gap := range gaps {
+		msg, err := w.nonceGapMessage(gap)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.estimateGapMessage(ctx, ts, msg, sharedParams, addrInfo); err != nil {
+			return nil, fmt.Errorf("estimate message %s with nonce %d failed: %v", msg.ID, gap.Nonce, err)
+		}
+		if cost := maxMessageCost(&msg.Message); spendable.LessThan(cost) {
+			return nil, fmt.Errorf("message %s with nonce %d: %sbalance %s minus reserve %s can not cover the cost %s, spendable %s",
+				msg.ID, gap.Nonce, insufficientBalance, venusTypes.FIL(actor.Balance), venusTypes.FIL(reserve), venusTypes.FIL(cost),
+				venusTypes.FIL(spendable))
+		}
+		if reason := usage.check(&msg.Message); len(reason) > 0 {
+			return nil, fmt.Errorf("message %s with nonce %d: %s", msg.ID, gap.Nonce, reason)
+		}
+		spendable = big.Sub(spendable, maxMessageCost(&msg.Message))
+		usage.add(&msg.Message)
+
+		unsignedCid := msg.Message.Cid()
+		msg.UnsignedCid = &unsignedCid
+		sig, err := w.signMessage(ctx, msg, accounts)
+		if err != nil {
+			return nil, err
+		}
+		msg.Signature = sig
+		msg.State = types.FillMsg
+		signedMsg := venusTypes.SignedMessage{
+			Message:   msg.Message,
+			Signature: *msg.Signature,
+		}
+		signedCid := signedMsg.Cid()
+		msg.SignedCid = &signedCid
+		msg.ErrorMsg = ""
+
+		msgs = append(msgs, msg)
+	}
+
+	operator, _ := core.CtxGetName(ctx)
+	if err := w.repo.Transaction(func(txRepo repo.TxRepo) error {
+		if err := w.checkFence(ctx, txRepo); err != nil {
+			return err
+		}
+		revisions := make([]*sophonTypes.MessageRevision, 0, len(msgs))
+		for _, msg := range msgs {
+			revisions = append(revisions, sophonTypes.NewMessageRevision(msg, sophonTypes.RevisionFillNonceGap, operator))
+		}
+		if err := txRepo.MessageRevisionRepo().SaveMessageRevisions(ctx, revisions); err != nil {
+			return err
+		}
+		spends := make([]*sophonTypes.AddressSpend, 0, len(msgs))
+		for _, msg := range msgs {
+			spends = append(spends, sophonTypes.NewAddressSpend(msg, ts.Height()))
+		}
+		if err := txRepo.AddressSpendRepo().SaveAddressSpends(ctx, spends); err != nil {
+			return err
+		}
+		for idx, msg := range msgs {
+			if len(gaps[idx].FailedMsgID) != 0 {
+				if err := txRepo.MessageRepo().UpdateMessageByState(msg, types.FailedMsg); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := txRepo.MessageRepo().CreateMessage(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("save messages failed: %v", err)
+	}
+	w.eventHub.publishMessages(msgs...)
+	for idx, msg := range msgs {
+		w.log.Infof("fill nonce gap %d with message %s, failed message %s", gaps[idx].Nonce, msg.ID, gaps[idx].FailedMsgID)
+	}
+
+	return msgs, nil
+}
+
+func (w *work) nonceGapMessage(gap *sophonTypes.NonceGap) (*types.Message, error) {
+	if len(gap.FailedMsgID) != 0 {
+		msg, err := w.repo.MessageRepo().GetMessageByUid(gap.FailedMsgID)
+		if err != nil {
+			return nil, err
+		}
+		// re-estimate gas, the failed message may be caused by too low fee
+		msg.GasFeeCap = big.Zero()
+		msg.GasPremium = big.Zero()
+		msg.GasLimit = 0
+		if msg.Meta == nil {
+			msg.Meta = &types.SendSpec{}
+		}
+		return msg, nil
+	}
+
+	// the delegated address only can sign InvokeContract and CreateExternal, calling itself is a zero value transfer
+	method := builtin.MethodSend
+	if w.addr.Protocol() == address.Delegated {
+		method = builtin.MethodsEVM.InvokeContract
+	}
+	now := time.Now()
+	return &types.Message{
+		ID: venusTypes.NewUUID().String(),
+		Message: venusTypes.Message{
+			From:       w.addr,
+			To:         w.addr,
+			Nonce:      gap.Nonce,
+			Value:      big.Zero(),
+			GasFeeCap:  big.Zero(),
+			GasPremium: big.Zero(),
+			Method:     method,
+		},
+		Meta:      &types.SendSpec{},
+		State:     types.UnFillMsg,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func (w *work) estimateGapMessage(ctx context.Context,
+	ts *venusTypes.TipSet,
+	msg *types.Message,
+	sharedParams *types.SharedSpec,
+	addrInfo *types.Address,
+) error {
+	nv, err := w.fullNode.StateNetworkVersion(ctx, venusTypes.EmptyTSK)
+	if err != nil {
+		return fmt.Errorf("get network version failed: %v", err)
+	}
+	actorCfg, err := w.getActorCfg(ctx, msg, nv)
+	if err != nil {
+		return fmt.Errorf("get actor config failed: %v", err)
+	}
+	gasSpec := mergeMsgSpec(sharedParams, msg.Meta, addrInfo, actorCfg, msg)
+	if !gasSpec.GasFeeCap.NilOrZero() {
+		msg.GasFeeCap = gasSpec.GasFeeCap
+	}
+
+	estimateMsgCtx, estimateMsgCancel := context.WithTimeout(ctx, w.cfg.EstimateMessageTimeout)
+	defer estimateMsgCancel()
+	estimateMsg, err := w.fullNode.GasEstimateMessageGas(estimateMsgCtx, &msg.Message, &venusTypes.MessageSendSpec{
+		MaxFee:            gasSpec.MaxFee,
+		GasOverEstimation: gasSpec.GasOverEstimation,
+		GasOverPremium:    gasSpec.GasOverPremium,
+	}, ts.Key())
+	if err != nil {
+		return err
+	}
+	msg.GasLimit = estimateMsg.GasLimit
+	msg.GasFeeCap = estimateMsg.GasFeeCap
+	msg.GasPremium = estimateMsg.GasPremium
+
+	return nil
+}
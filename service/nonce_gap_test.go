@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/stretchr/testify/assert"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestNonceGap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	// make nonce gaps: the message with nonce 1 is marked failed, nonce 3 and 4 are skipped
+	makeGaps := func(addr address.Address) *types.Message {
+		msgs := genMessages([]address.Address{addr}, 3)
+		assert.NoError(t, pushMessage(ctx, ms, msgs))
+		ts, err := msh.fullNode.ChainHead(ctx)
+		assert.NoError(t, err)
+		selectResult := selectMsgWithAddress(ctx, t, msh, []address.Address{addr}, ts)
+		assert.Len(t, selectResult.SelectMsg, 3)
+
+		failedMsg := selectResult.SelectMsg[1]
+		assert.Equal(t, uint64(1), failedMsg.Nonce)
+		assert.NoError(t, ms.MarkBadMessage(ctx, failedMsg.ID))
+		assert.NoError(t, ms.addressService.UpdateNonce(ctx, addr, 5))
+
+		return failedMsg
+	}
+
+	checkFilled := func(addr address.Address, failedMsg *types.Message) {
+		report, err := ms.NonceGapReport(ctx, addr)
+		assert.NoError(t, err)
+		assert.Empty(t, report.Gaps)
+
+		msg, err := ms.GetMessageByUid(ctx, failedMsg.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, types.FillMsg, msg.State)
+		assert.Equal(t, failedMsg.Nonce, msg.Nonce)
+
+		filled, err := ms.repo.MessageRepo().ListFilledMessageByAddress(addr)
+		assert.NoError(t, err)
+		assert.Len(t, filled, 5)
+		for _, msg := range filled {
+			if msg.Nonce < 3 {
+				continue
+			}
+			assert.Equal(t, addr, msg.To)
+			assert.Equal(t, big.Zero(), msg.Value)
+			assert.NotNil(t, msg.Signature)
+		}
+	}
+
+	t.Run("report and fill", func(t *testing.T) {
+		addr := addrs[0]
+		failedMsg := makeGaps(addr)
+
+		report, err := ms.NonceGapReport(ctx, addr)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(5), report.AddressNonce)
+		assert.Equal(t, uint64(0), report.NonceInLatestTs)
+		assert.Len(t, report.Gaps, 3)
+		assert.Equal(t, uint64(1), report.Gaps[0].Nonce)
+		assert.Equal(t, failedMsg.ID, report.Gaps[0].FailedMsgID)
+		assert.Equal(t, uint64(3), report.Gaps[1].Nonce)
+		assert.Empty(t, report.Gaps[1].FailedMsgID)
+		assert.Equal(t, uint64(4), report.Gaps[2].Nonce)
+
+		activeAddrs, err := ms.addressService.ListActiveAddress(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, ms.msgSelectMgr.tryUpdateWorks(addressMap(activeAddrs)))
+
+		ids, err := ms.FillNonceGap(ctx, addr)
+		assert.NoError(t, err)
+		assert.Len(t, ids, 3)
+		assert.Contains(t, ids, failedMsg.ID)
+		checkFilled(addr, failedMsg)
+
+		// nothing to fill
+		ids, err = ms.FillNonceGap(ctx, addr)
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("auto fill", func(t *testing.T) {
+		addr := addrs[1]
+		failedMsg := makeGaps(addr)
+
+		// not enabled
+		ts, err := msh.fullNode.ChainHead(ctx)
+		assert.NoError(t, err)
+		selectMsgWithAddress(ctx, t, msh, []address.Address{addr}, ts)
+		report, err := ms.NonceGapReport(ctx, addr)
+		assert.NoError(t, err)
+		assert.Len(t, report.Gaps, 3)
+
+		assert.NoError(t, ms.addressService.SetAutoFillNonceGap(ctx, addr, true))
+		selectMsgWithAddress(ctx, t, msh, []address.Address{addr}, ts)
+		checkFilled(addr, failedMsg)
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		addr := addrs[2]
+		makeGaps(addr)
+
+		activeAddrs, err := ms.addressService.ListActiveAddress(ctx)
+		assert.NoError(t, err)
+		assert.NoError(t, ms.msgSelectMgr.tryUpdateWorks(addressMap(activeAddrs)))
+
+		// the balance can not cover the gas fee of gap messages
+		assert.NoError(t, msh.fullNode.SetActorBalance(addr, big.Zero()))
+		_, err = ms.FillNonceGap(ctx, addr)
+		assert.ErrorContains(t, err, insufficientBalance)
+		report, err := ms.NonceGapReport(ctx, addr)
+		assert.NoError(t, err)
+		assert.Len(t, report.Gaps, 3)
+	})
+}
+
+func TestNonceGapMessage(t *testing.T) {
+	addr, err := address.NewDelegatedAddress(builtin.EthereumAddressManagerActorID, make([]byte, 20))
+	assert.NoError(t, err)
+	w := &work{addr: addr}
+	msg, err := w.nonceGapMessage(&sophonTypes.NonceGap{Nonce: 1})
+	assert.NoError(t, err)
+	// the delegated address can not sign MethodSend
+	assert.Equal(t, builtin.MethodsEVM.InvokeContract, msg.Method)
+	assert.Equal(t, addr, msg.To)
+	assert.Equal(t, uint64(1), msg.Nonce)
+}
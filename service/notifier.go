@@ -0,0 +1,334 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	logging "github.com/ipfs/go-log/v2"
+	"go.uber.org/fx"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var notifierLog = logging.Logger("notifier")
+
+const (
+	SignatureHeader = "X-Messager-Signature"
+
+	checkBlockedInterval = time.Minute
+	deliverInterval      = time.Second * 5
+	deliverBatchSize     = 100
+	webhookTimeout       = time.Second * 10
+)
+
+// msgBlockedThresholds a notification is sent when a message is blocked longer than each of them
+var msgBlockedThresholds = []time.Duration{msgBlockedThreeMinutes, msgBlockedFiveMinutes}
+
+// Notifier posts the notifications of message outcomes to webhooks, notifications are saved to the outbox
+// table in the transaction which changes the state of message, so they will be delivered after restart
+type Notifier struct {
+	cfg        config.NotifierConfig
+	repo       repo.Repo
+	msgService *MessageService
+	client     *http.Client
+
+	// wake up delivering after new notifications are saved
+	notify chan struct{}
+}
+
+func newNotifier(cfg config.NotifierConfig, repo repo.Repo, msgService *MessageService) *Notifier {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = config.DefaultNotifyMaxAttempts
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = config.DefaultNotifyRetryInterval
+	}
+	if cfg.MaxRetryInterval < cfg.RetryInterval {
+		cfg.MaxRetryInterval = cfg.RetryInterval
+	}
+
+	return &Notifier{
+		cfg:        cfg,
+		repo:       repo,
+		msgService: msgService,
+		client:     &http.Client{Timeout: webhookTimeout},
+		notify:     make(chan struct{}, 1),
+	}
+}
+
+func StartNotifier(lc fx.Lifecycle, msgService *MessageService) *Notifier {
+	n := newNotifier(msgService.fsRepo.Config().Notifier, msgService.repo, msgService)
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if len(n.cfg.Webhooks) == 0 {
+				notifierLog.Info("no webhook configured, notifier is disabled")
+				return nil
+			}
+			n.start(ctx)
+			return nil
+		},
+	})
+	return n
+}
+
+func (n *Notifier) start(ctx context.Context) {
+	go n.checkBlockedLoop(ctx)
+	go n.deliverLoop(ctx)
+}
+
+// saveEventNotifications saves the notifications of events to the outbox, it must be called in the transaction which
+// changes the state of messages, so that a notification is neither lost by a crash nor saved for a rolled back change
+func (ms *MessageService) saveEventNotifications(ctx context.Context, txRepo repo.TxRepo, events ...*sophonTypes.MessageEvent) error {
+	var notifications []*sophonTypes.Notification
+	for _, event := range events {
+		if notification := notificationFromEvent(event); notification != nil {
+			notifications = append(notifications, notification)
+		}
+	}
+	return saveNotifications(ctx, txRepo, ms.fsRepo.Config().Notifier.Webhooks, notifications...)
+}
+
+// notificationFromEvent returns nil if the event should not be notified
+func notificationFromEvent(event *sophonTypes.MessageEvent) *sophonTypes.Notification {
+	notification := &sophonTypes.Notification{
+		MsgID:     event.ID,
+		From:      event.From,
+		Nonce:     event.Nonce,
+		SignedCid: event.SignedCid,
+		Height:    event.Height,
+		CreatedAt: time.Now(),
+	}
+	if event.Receipt != nil {
+		notification.ExitCode = event.Receipt.ExitCode
+	}
+
+	switch event.State {
+	case types.OnChainMsg:
+		if event.Receipt == nil || event.Receipt.ExitCode.IsSuccess() {
+			return nil
+		}
+		notification.Kind = sophonTypes.NotifyExecFailed
+		notification.ID = fmt.Sprintf("%s/%s/%d", event.ID, notification.Kind, event.Height)
+	case types.NonceConflictMsg:
+		notification.Kind = sophonTypes.NotifyReplaced
+		notification.ID = fmt.Sprintf("%s/%s/%d", event.ID, notification.Kind, event.Height)
+	case types.FailedMsg:
+		notification.Kind = sophonTypes.NotifyMarkedBad
+		notification.ID = fmt.Sprintf("%s/%s/%d", event.ID, notification.Kind, event.CreatedAt.UnixNano())
+	default:
+		return nil
+	}
+
+	return notification
+}
+
+func (n *Notifier) checkBlockedLoop(ctx context.Context) {
+	tm := time.NewTicker(checkBlockedInterval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			notifierLog.Warnf("stop check blocked message: %v", ctx.Err())
+			return
+		case <-tm.C:
+			// only the leader checks and delivers, so that a notification is not sent by several instances
+			if !n.msgService.leader.isLeader() {
+				continue
+			}
+			if err := n.checkBlocked(ctx); err != nil {
+				notifierLog.Errorf("check blocked message failed: %v", err)
+			}
+		}
+	}
+}
+
+// checkBlocked a message is only notified once for each threshold
+func (n *Notifier) checkBlocked(ctx context.Context) error {
+	now := time.Now()
+	var notifications []*sophonTypes.Notification
+	for _, threshold := range msgBlockedThresholds {
+		msgs, err := n.repo.MessageRepo().ListBlockedMessage(&repo.MsgQueryParams{}, threshold)
+		if err != nil {
+			return fmt.Errorf("list message blocked %v failed: %w", threshold, err)
+		}
+		for _, msg := range msgs {
+			notifications = append(notifications, &sophonTypes.Notification{
+				ID:         fmt.Sprintf("%s/%s/%v", msg.ID, sophonTypes.NotifyBlocked, threshold),
+				Kind:       sophonTypes.NotifyBlocked,
+				MsgID:      msg.ID,
+				From:       msg.From,
+				Nonce:      msg.Nonce,
+				SignedCid:  msg.SignedCid,
+				BlockedFor: threshold,
+				CreatedAt:  now,
+			})
+		}
+	}
+
+	return n.enqueue(ctx, notifications...)
+}
+
+// enqueue saves notifications to the outbox, one record for each webhook
+func (n *Notifier) enqueue(ctx context.Context, notifications ...*sophonTypes.Notification) error {
+	if err := saveNotifications(ctx, n.repo, n.cfg.Webhooks, notifications...); err != nil {
+		return err
+	}
+
+	select {
+	case n.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// saveNotifications saves the notifications to the outbox for each webhook, they are delivered by the notifier
+func saveNotifications(ctx context.Context, r repo.TxRepo, webhooks []config.WebhookConfig, notifications ...*sophonTypes.Notification) error {
+	if len(notifications) == 0 || len(webhooks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	outbox := make([]*sophonTypes.OutboxNotification, 0, len(notifications)*len(webhooks))
+	for _, notification := range notifications {
+		payload, err := json.Marshal(notification)
+		if err != nil {
+			return err
+		}
+		for _, webhook := range webhooks {
+			outbox = append(outbox, &sophonTypes.OutboxNotification{
+				ID:             venusTypes.NewUUID().String(),
+				NotificationID: notification.ID,
+				URL:            webhook.URL,
+				Payload:        payload,
+				State:          sophonTypes.OutboxPending,
+				NextAttemptAt:  now,
+			})
+		}
+	}
+	return r.NotificationRepo().SaveNotifications(ctx, outbox)
+}
+
+func (n *Notifier) deliverLoop(ctx context.Context) {
+	tm := time.NewTicker(deliverInterval)
+	defer tm.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			notifierLog.Warnf("stop deliver notification: %v", ctx.Err())
+			return
+		case <-tm.C:
+		case <-n.notify:
+		}
+		if !n.msgService.leader.isLeader() {
+			continue
+		}
+		if err := n.deliver(ctx); err != nil {
+			notifierLog.Errorf("deliver notification failed: %v", err)
+		}
+	}
+}
+
+// deliver sends the pending notifications which reach the next attempt time
+func (n *Notifier) deliver(ctx context.Context) error {
+	for {
+		pending, err := n.repo.NotificationRepo().ListPendingNotifications(ctx, time.Now(), deliverBatchSize)
+		if err != nil {
+			return fmt.Errorf("list pending notification failed: %w", err)
+		}
+
+		for _, notification := range pending {
+			notification.Attempts++
+			if err := n.send(ctx, notification); err != nil {
+				notification.LastError = err.Error()
+				if notification.Attempts >= n.cfg.MaxAttempts {
+					notification.State = sophonTypes.OutboxFailed
+					notifierLog.Errorf("give up notification %s to %s after %d attempts: %v", notification.NotificationID,
+						notification.URL, notification.Attempts, err)
+				} else {
+					notification.NextAttemptAt = time.Now().Add(n.retryInterval(notification.Attempts))
+					notifierLog.Warnf("send notification %s to %s failed, will retry at %v: %v", notification.NotificationID,
+						notification.URL, notification.NextAttemptAt, err)
+				}
+			} else {
+				notification.State = sophonTypes.OutboxSent
+				notification.LastError = ""
+			}
+
+			if err := n.repo.NotificationRepo().UpdateNotificationDelivery(ctx, notification); err != nil {
+				return fmt.Errorf("update notification %s failed: %w", notification.ID, err)
+			}
+		}
+
+		if len(pending) < deliverBatchSize {
+			return nil
+		}
+	}
+}
+
+// retryInterval the interval is doubled after each failed attempt
+func (n *Notifier) retryInterval(attempts int) time.Duration {
+	interval := n.cfg.RetryInterval
+	for i := 1; i < attempts && interval < n.cfg.MaxRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > n.cfg.MaxRetryInterval {
+		interval = n.cfg.MaxRetryInterval
+	}
+	return interval
+}
+
+func (n *Notifier) send(ctx context.Context, notification *sophonTypes.OutboxNotification) error {
+	var webhook *config.WebhookConfig
+	for idx := range n.cfg.Webhooks {
+		if n.cfg.Webhooks[idx].URL == notification.URL {
+			webhook = &n.cfg.Webhooks[idx]
+			break
+		}
+	}
+	if webhook == nil {
+		return fmt.Errorf("webhook %s is not configured", notification.URL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(notification.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(webhook.Secret) != 0 {
+		req.Header.Set(SignatureHeader, SignPayload(webhook.Secret, notification.Payload))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignPayload returns the hex encoded HMAC-SHA256 of payload, receivers use it to verify notifications
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload) // nolint:errcheck
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+type webhookStandIn struct {
+	lk            sync.Mutex
+	failures      int
+	notifications []*sophonTypes.Notification
+	signatures    []string
+	payloads      [][]byte
+}
+
+func (s *webhookStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	payload, _ := io.ReadAll(r.Body)
+	var notification sophonTypes.Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.notifications = append(s.notifications, &notification)
+	s.signatures = append(s.signatures, r.Header.Get(SignatureHeader))
+	s.payloads = append(s.payloads, payload)
+}
+
+func (s *webhookStandIn) received() map[sophonTypes.NotificationKind][]*sophonTypes.Notification {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	res := make(map[sophonTypes.NotificationKind][]*sophonTypes.Notification)
+	for _, n := range s.notifications {
+		res[n.Kind] = append(res[n.Kind], n)
+	}
+	return res
+}
+
+func TestNotifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	standIn := &webhookStandIn{failures: 2}
+	server := httptest.NewServer(standIn)
+	defer server.Close()
+
+	cfg := config.NotifierConfig{
+		Webhooks:         []config.WebhookConfig{{URL: server.URL, Secret: "secret"}},
+		MaxAttempts:      5,
+		RetryInterval:    time.Millisecond,
+		MaxRetryInterval: 10 * time.Millisecond,
+	}
+	notifier := newNotifier(cfg, ms.repo, ms)
+	// the notifications of state changes are saved with the webhooks in config
+	fsCfg := msh.fsRepo.Config()
+	fsCfg.Notifier = cfg
+	assert.NoError(t, msh.fsRepo.ReplaceConfig(fsCfg))
+
+	msgs := genMessages(addrs[:1], 3)
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, 3)
+
+	// on chain with non-zero exit code and success
+	var applyMsgs []applyMessage
+	for idx, msg := range selectResult.SelectMsg[:2] {
+		applyMsgs = append(applyMsgs, applyMessage{
+			signedCID: *msg.SignedCid,
+			msg:       &msg.Message,
+			height:    abi.ChainEpoch(10),
+			tsk:       ts.Key(),
+			receipt:   &venusTypes.MessageReceipt{ExitCode: exitcode.ExitCode(idx * 16), GasUsed: 100},
+		})
+	}
+	_, _, err = ms.updateMessageState(applyMsgs, nil)
+	assert.NoError(t, err)
+
+	// marked bad
+	badMsg := selectResult.SelectMsg[2]
+	assert.NoError(t, ms.MarkBadMessage(ctx, badMsg.ID))
+
+	// blocked
+	blockedMsg := genMessages(addrs[:1], 1)[0]
+	assert.NoError(t, pushMessage(ctx, ms, []*types.Message{blockedMsg}))
+	assert.NoError(t, ms.repo.GetDb().Table("messages").Where("id = ?", blockedMsg.ID).
+		Update("created_at", time.Now().Add(-4*time.Minute)).Error)
+	assert.NoError(t, notifier.checkBlocked(ctx))
+	// only notified once
+	assert.NoError(t, notifier.checkBlocked(ctx))
+
+	assert.Eventually(t, func() bool {
+		pending, err := ms.repo.NotificationRepo().ListPendingNotifications(ctx, time.Now().Add(time.Hour), 100)
+		assert.NoError(t, err)
+		return len(pending) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the first notification fails twice
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, notifier.deliver(ctx))
+		pending, err := ms.repo.NotificationRepo().ListPendingNotifications(ctx, time.Now().Add(time.Hour), 100)
+		assert.NoError(t, err)
+		return len(pending) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	received := standIn.received()
+	assert.Len(t, received, 3)
+	assert.Len(t, received[sophonTypes.NotifyExecFailed], 1)
+	assert.Equal(t, selectResult.SelectMsg[1].ID, received[sophonTypes.NotifyExecFailed][0].MsgID)
+	assert.Equal(t, exitcode.ExitCode(16), received[sophonTypes.NotifyExecFailed][0].ExitCode)
+	assert.Equal(t, abi.ChainEpoch(10), received[sophonTypes.NotifyExecFailed][0].Height)
+	assert.Len(t, received[sophonTypes.NotifyMarkedBad], 1)
+	assert.Equal(t, badMsg.ID, received[sophonTypes.NotifyMarkedBad][0].MsgID)
+	assert.Len(t, received[sophonTypes.NotifyBlocked], 1)
+	assert.Equal(t, blockedMsg.ID, received[sophonTypes.NotifyBlocked][0].MsgID)
+	assert.Equal(t, msgBlockedThreeMinutes, received[sophonTypes.NotifyBlocked][0].BlockedFor)
+
+	standIn.lk.Lock()
+	for idx, payload := range standIn.payloads {
+		assert.Equal(t, SignPayload("secret", payload), standIn.signatures[idx])
+	}
+	standIn.lk.Unlock()
+
+	t.Run("give up", func(t *testing.T) {
+		cfg := cfg
+		cfg.Webhooks = []config.WebhookConfig{{URL: server.URL + "/not-exist"}}
+		notifier := newNotifier(cfg, ms.repo, ms)
+		assert.NoError(t, notifier.enqueue(ctx, &sophonTypes.Notification{ID: "give-up", Kind: sophonTypes.NotifyBlocked}))
+
+		standIn.lk.Lock()
+		standIn.failures = cfg.MaxAttempts
+		standIn.lk.Unlock()
+
+		for i := 0; i < cfg.MaxAttempts; i++ {
+			time.Sleep(cfg.MaxRetryInterval)
+			assert.NoError(t, notifier.deliver(ctx))
+		}
+		pending, err := ms.repo.NotificationRepo().ListPendingNotifications(ctx, time.Now().Add(time.Hour), 100)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+		assert.Len(t, standIn.received()[sophonTypes.NotifyBlocked], 1)
+	})
+}
+
+func TestNotificationFromEvent(t *testing.T) {
+	addr, _ := address.NewIDAddress(1)
+	event := &sophonTypes.MessageEvent{ID: "id", From: addr, State: types.OnChainMsg, Height: 10,
+		Receipt: &venusTypes.MessageReceipt{ExitCode: exitcode.Ok}}
+	assert.Nil(t, notificationFromEvent(event))
+
+	event.Receipt.ExitCode = exitcode.ErrInsufficientFunds
+	n := notificationFromEvent(event)
+	assert.Equal(t, sophonTypes.NotifyExecFailed, n.Kind)
+	assert.Equal(t, "id/exec_failed/10", n.ID)
+	assert.Equal(t, exitcode.ErrInsufficientFunds, n.ExitCode)
+
+	event.State = types.NonceConflictMsg
+	assert.Equal(t, sophonTypes.NotifyReplaced, notificationFromEvent(event).Kind)
+
+	event.State = types.FailedMsg
+	assert.Equal(t, sophonTypes.NotifyMarkedBad, notificationFromEvent(event).Kind)
+
+	event.State = types.FillMsg
+	assert.Nil(t, notificationFromEvent(event))
+
+	notifier := newNotifier(config.NotifierConfig{RetryInterval: time.Second, MaxRetryInterval: 5 * time.Second}, nil, nil)
+	assert.Equal(t, config.DefaultNotifyMaxAttempts, notifier.cfg.MaxAttempts)
+	assert.Equal(t, time.Second, notifier.retryInterval(1))
+	assert.Equal(t, 4*time.Second, notifier.retryInterval(3))
+	assert.Equal(t, 5*time.Second, notifier.retryInterval(4))
+}
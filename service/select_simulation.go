@@ -0,0 +1,86 @@
+package service
+
+import (
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+// selectSimulation collects the details of a dry run selection, all methods are no-op on nil receiver,
+// so the normal selection passes nil
+type selectSimulation struct {
+	res  *sophonTypes.SelectSimulation
+	msgs map[string]*sophonTypes.SimulatedMessage
+}
+
+func newSelectSimulation(ts *venusTypes.TipSet) *selectSimulation {
+	return &selectSimulation{
+		res: &sophonTypes.SelectSimulation{
+			Height:  ts.Height(),
+			BaseFee: ts.At(0).ParentBaseFee,
+		},
+		msgs: make(map[string]*sophonTypes.SimulatedMessage),
+	}
+}
+
+func (s *selectSimulation) dryRun() bool {
+	return s != nil
+}
+
+// message returns the simulated message of id, keeps the order of messages the same as they first appear
+func (s *selectSimulation) message(id string) *sophonTypes.SimulatedMessage {
+	msg, ok := s.msgs[id]
+	if !ok {
+		msg = &sophonTypes.SimulatedMessage{ID: id}
+		s.msgs[id] = msg
+		s.res.Messages = append(s.res.Messages, msg)
+	}
+	return msg
+}
+
+func (s *selectSimulation) setReason(reason string) {
+	if s == nil {
+		return
+	}
+	s.res.Reason = reason
+}
+
+func (s *selectSimulation) skip(id string, reason string) {
+	if s == nil {
+		return
+	}
+	s.message(id).Skipped = reason
+}
+
+func (s *selectSimulation) fail(id string, err string) {
+	if s == nil {
+		return
+	}
+	s.message(id).Err = err
+}
+
+func (s *selectSimulation) setGasSpec(id string, spec *GasSpec) {
+	if s == nil {
+		return
+	}
+	s.message(id).GasSpec = spec
+}
+
+func (s *selectSimulation) setEstimate(id string, msg *venusTypes.Message) {
+	if s == nil || msg == nil {
+		return
+	}
+	simMsg := s.message(id)
+	simMsg.GasLimit = msg.GasLimit
+	simMsg.GasFeeCap = msg.GasFeeCap
+	simMsg.GasPremium = msg.GasPremium
+}
+
+func (s *selectSimulation) selected(id string, nonce uint64) {
+	if s == nil {
+		return
+	}
+	simMsg := s.message(id)
+	simMsg.Selected = true
+	simMsg.Nonce = nonce
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/config"
+	"github.com/ipfs-force-community/sophon-messager/models/repo"
+)
+
+const (
+	FIFOStrategy     = config.DefaultSelectStrategy
+	FeeStrategy      = "fee"
+	DeadlineStrategy = "deadline"
+)
+
+// candidatePoolSize the max number of unfill message loaded by the strategies which need to sort messages
+const candidatePoolSize = 1000
+
+// SelectionStrategy decides which unfill messages of an address are selected and the order of assigning nonce
+type SelectionStrategy interface {
+	Name() string
+	// SelectCandidates returns at most count unfill messages of addr, the order of the result is the order of assigning nonce
+	SelectCandidates(ctx context.Context, msgRepo repo.MessageRepo, addr address.Address, count int) ([]*types.Message, error)
+}
+
+var selectionStrategies = map[string]SelectionStrategy{
+	FIFOStrategy:     &fifoStrategy{},
+	FeeStrategy:      newSortStrategy(FeeStrategy, feeFirst),
+	DeadlineStrategy: newSortStrategy(DeadlineStrategy, deadlineFirst),
+}
+
+// GetSelectionStrategy returns the strategy with name, empty name means the default strategy
+func GetSelectionStrategy(name string) (SelectionStrategy, error) {
+	if len(name) == 0 {
+		name = config.DefaultSelectStrategy
+	}
+	s, ok := selectionStrategies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown select strategy %s, supported: %v", name, SelectionStrategyNames())
+	}
+	return s, nil
+}
+
+// SelectionStrategyNames returns the name of all supported strategies
+func SelectionStrategyNames() []string {
+	names := make([]string, 0, len(selectionStrategies))
+	for name := range selectionStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fifoStrategy keeps the order of ListUnChainMessageByAddress, the messages of the same priority are selected in the
+// order they were pushed
+type fifoStrategy struct{}
+
+func (s *fifoStrategy) Name() string {
+	return FIFOStrategy
+}
+
+func (s *fifoStrategy) SelectCandidates(_ context.Context, msgRepo repo.MessageRepo, addr address.Address, count int) ([]*types.Message, error) {
+	return msgRepo.ListUnChainMessageByAddress(addr, count)
+}
+
+// sortStrategy loads up to candidatePoolSize unfill messages and stable sorts them with less,
+// the priority of message always takes precedence over less
+type sortStrategy struct {
+	name string
+	less func(a, b *types.Message) bool
+}
+
+func newSortStrategy(name string, less func(a, b *types.Message) bool) *sortStrategy {
+	return &sortStrategy{name: name, less: less}
+}
+
+func (s *sortStrategy) Name() string {
+	return s.name
+}
+
+func (s *sortStrategy) SelectCandidates(_ context.Context, msgRepo repo.MessageRepo, addr address.Address, count int) ([]*types.Message, error) {
+	msgs, err := msgRepo.ListUnChainMessageByAddress(addr, candidatePoolSize)
+	if err != nil {
+		return nil, err
+	}
+	priorities, err := msgRepo.ListUnChainMessagePriority(addr)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if pi, pj := priorities[msgs[i].ID], priorities[msgs[j].ID]; pi != pj {
+			return pi > pj
+		}
+		return s.less(msgs[i], msgs[j])
+	})
+	if count > 0 && len(msgs) > count {
+		msgs = msgs[:count]
+	}
+
+	return msgs, nil
+}
+
+func msgMaxFee(msg *types.Message) big.Int {
+	if msg.Meta == nil || msg.Meta.MaxFee.Nil() {
+		return big.Zero()
+	}
+	return msg.Meta.MaxFee
+}
+
+func msgFeeCap(msg *types.Message) big.Int {
+	if msg.GasFeeCap.Nil() {
+		return big.Zero()
+	}
+	return msg.GasFeeCap
+}
+
+// feeFirst the message willing to pay more fee goes first
+func feeFirst(a, b *types.Message) bool {
+	aMaxFee, bMaxFee := msgMaxFee(a), msgMaxFee(b)
+	if !aMaxFee.Equals(bMaxFee) {
+		return aMaxFee.GreaterThan(bMaxFee)
+	}
+	return msgFeeCap(a).GreaterThan(msgFeeCap(b))
+}
+
+// deadlineFirst the message expires earlier goes first, message without ExpireEpoch goes last
+func deadlineFirst(a, b *types.Message) bool {
+	var aExpire, bExpire int64
+	if a.Meta != nil {
+		aExpire = int64(a.Meta.ExpireEpoch)
+	}
+	if b.Meta != nil {
+		bExpire = int64(b.Meta.ExpireEpoch)
+	}
+	if aExpire == 0 || bExpire == 0 {
+		return aExpire != 0
+	}
+	return aExpire < bExpire
+}
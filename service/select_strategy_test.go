@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/venus/venus-shared/testutil"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+
+	"github.com/ipfs-force-community/sophon-messager/filestore"
+	"github.com/ipfs-force-community/sophon-messager/models"
+	"github.com/ipfs-force-community/sophon-messager/testhelper"
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestGetSelectionStrategy(t *testing.T) {
+	for _, name := range []string{FIFOStrategy, FeeStrategy, DeadlineStrategy} {
+		s, err := GetSelectionStrategy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, s.Name())
+	}
+
+	s, err := GetSelectionStrategy("")
+	assert.NoError(t, err)
+	assert.Equal(t, FIFOStrategy, s.Name())
+
+	_, err = GetSelectionStrategy("not-exist")
+	assert.Error(t, err)
+
+	assert.True(t, sort.StringsAreSorted(SelectionStrategyNames()))
+	assert.Len(t, SelectionStrategyNames(), len(selectionStrategies))
+}
+
+func TestSelectionStrategy(t *testing.T) {
+	ctx := context.Background()
+	fsRepo := filestore.NewMockFileStore(t.TempDir())
+	repo, err := models.SetDataBase(fsRepo)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SchemaMigrator().Migrate(context.Background()))
+
+	addr := testutil.AddressProvider()(t)
+	// max fee and expire epoch of each message
+	specs := []types.SendSpec{
+		{MaxFee: big.NewInt(100), ExpireEpoch: 0},
+		{MaxFee: big.NewInt(300), ExpireEpoch: 2000},
+		{MaxFee: big.NewInt(200), ExpireEpoch: 1000},
+		{ExpireEpoch: 3000},
+	}
+	msgs := testhelper.NewMessages(len(specs))
+	now := time.Now()
+	for i, msg := range msgs {
+		msg.From = addr
+		msg.Meta = &specs[i]
+		msg.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		assert.NoError(t, repo.MessageRepo().CreateMessage(msg))
+	}
+
+	ids := func(msgs []*types.Message) []string {
+		res := make([]string, 0, len(msgs))
+		for _, msg := range msgs {
+			res = append(res, msg.ID)
+		}
+		return res
+	}
+
+	fifo, err := GetSelectionStrategy(FIFOStrategy)
+	assert.NoError(t, err)
+	fifoRes, err := fifo.SelectCandidates(ctx, repo.MessageRepo(), addr, len(msgs))
+	assert.NoError(t, err)
+	// the messages are selected in the order they were pushed
+	assert.Equal(t, ids(msgs), ids(fifoRes))
+
+	fee, err := GetSelectionStrategy(FeeStrategy)
+	assert.NoError(t, err)
+	feeRes, err := fee.SelectCandidates(ctx, repo.MessageRepo(), addr, len(msgs))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[1].ID, msgs[2].ID, msgs[0].ID, msgs[3].ID}, ids(feeRes))
+
+	// only return the top n messages
+	feeRes, err = fee.SelectCandidates(ctx, repo.MessageRepo(), addr, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[1].ID, msgs[2].ID}, ids(feeRes))
+
+	deadline, err := GetSelectionStrategy(DeadlineStrategy)
+	assert.NoError(t, err)
+	deadlineRes, err := deadline.SelectCandidates(ctx, repo.MessageRepo(), addr, len(msgs))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[2].ID, msgs[1].ID, msgs[3].ID, msgs[0].ID}, ids(deadlineRes))
+
+	// priority takes precedence over the strategy
+	assert.NoError(t, repo.MessageRepo().UpdateMessagePriority(msgs[3].ID, 1))
+	feeRes, err = fee.SelectCandidates(ctx, repo.MessageRepo(), addr, len(msgs))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[3].ID, msgs[1].ID, msgs[2].ID, msgs[0].ID}, ids(feeRes))
+	fifoRes, err = fifo.SelectCandidates(ctx, repo.MessageRepo(), addr, len(msgs))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{msgs[3].ID, msgs[0].ID, msgs[1].ID, msgs[2].ID}, ids(fifoRes))
+}
+
+func TestSelectMessageWithPriority(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+	msh.start()
+	defer msh.stop()
+
+	msgs := genMessages(addrs[:1], 4)
+	assert.NoError(t, pushMessage(ctx, ms, msgs[:2]))
+	// pushed latest, but has the highest priority
+	_, err := ms.PushMessageWithSpec(ctx, msgs[2].ID, &msgs[2].Message, &sophonTypes.SendSpec{MessageOptions: sophonTypes.MessageOptions{Priority: 2}})
+	assert.NoError(t, err)
+	id, err := ms.PushMessageWithSpec(ctx, "", &msgs[3].Message, nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, id)
+	msgs[3].ID = id
+
+	assert.NoError(t, ms.SetMessagePriority(ctx, msgs[0].ID, 1))
+	assert.Error(t, ms.SetMessagePriority(ctx, "not-exist", 1))
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, len(msgs))
+	expect := []string{msgs[2].ID, msgs[0].ID, msgs[1].ID, msgs[3].ID}
+	for i, msg := range selectResult.SelectMsg {
+		assert.Equal(t, expect[i], msg.ID)
+	}
+
+	// the priority of filled message cannot be changed
+	assert.Error(t, ms.SetMessagePriority(ctx, msgs[0].ID, 3))
+}
+
+func TestSelectMessageWithStrategy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+	msh.start()
+	defer msh.stop()
+
+	addr := addrs[0]
+	// address not exist
+	assert.Error(t, ms.addressService.SetSelectStrategy(ctx, addr, DeadlineStrategy))
+
+	msgs := genMessages(addrs[:1], 5)
+	for i, msg := range msgs {
+		msg.Meta = &types.SendSpec{ExpireEpoch: abi.ChainEpoch(10000 - i)}
+	}
+	assert.NoError(t, pushMessage(ctx, ms, msgs))
+
+	addrCfg, err := ms.addressService.GetAddressConfig(ctx, addr)
+	assert.NoError(t, err)
+	assert.Empty(t, addrCfg.SelectStrategy)
+	assert.Error(t, ms.addressService.SetSelectStrategy(ctx, addr, "not-exist"))
+	assert.NoError(t, ms.addressService.SetSelectStrategy(ctx, addr, DeadlineStrategy))
+	addrCfg, err = ms.addressService.GetAddressConfig(ctx, addr)
+	assert.NoError(t, err)
+	assert.Equal(t, DeadlineStrategy, addrCfg.SelectStrategy)
+
+	ts, err := msh.fullNode.ChainHead(ctx)
+	assert.NoError(t, err)
+	selectResult := selectMsgWithAddress(ctx, t, msh, addrs[:1], ts)
+	assert.Len(t, selectResult.SelectMsg, len(msgs))
+	// the message expires earlier has a smaller nonce
+	for i, msg := range selectResult.SelectMsg {
+		assert.Equal(t, msgs[len(msgs)-1-i].ID, msg.ID)
+	}
+}
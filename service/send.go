@@ -17,9 +17,20 @@ import (
 	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
 	types "github.com/filecoin-project/venus/venus-shared/types/messager"
 	msgparser "github.com/filecoin-project/venus/venus-shared/utils/msg_parser"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
 )
 
 func (ms *MessageService) Send(ctx context.Context, params types.QuickSendParams) (string, error) {
+	return ms.send(ctx, params, "", nil)
+}
+
+func (ms *MessageService) SendWithSpec(ctx context.Context, params sophonTypes.QuickSendParams) (string, error) {
+	return ms.send(ctx, params.QuickSendParams, params.ABIMethod, &params.MessageOptions)
+}
+
+// send encodes the params by the abi of contract if abiMethod is not empty, the params are the json args of the method
+func (ms *MessageService) send(ctx context.Context, params types.QuickSendParams, abiMethod string, opts *sophonTypes.MessageOptions) (string, error) {
 	var decParams []byte
 	var err error
 
@@ -27,13 +38,21 @@ func (ms *MessageService) Send(ctx context.Context, params types.QuickSendParams
 		return "", fmt.Errorf("do not use it to send funds")
 	}
 
-	switch params.ParamsType {
-	case types.QuickSendParamsCodecJSON:
+	switch {
+	case len(abiMethod) > 0:
+		if params.ParamsType != types.QuickSendParamsCodecJSON {
+			return "", fmt.Errorf("the args of abi method must be json")
+		}
+		decParams, err = ms.encodeContractCall(ctx, params.To, params.Method, abiMethod, params.Params)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode contract call: %w", err)
+		}
+	case params.ParamsType == types.QuickSendParamsCodecJSON:
 		decParams, err = ms.decodeTypedParamsFromJSON(ctx, params.To, params.Method, params.Params)
 		if err != nil {
 			return "", fmt.Errorf("failed to decode json params: %w", err)
 		}
-	case types.QuickSendParamsCodecHex:
+	case params.ParamsType == types.QuickSendParamsCodecHex:
 		decParams, err = hex.DecodeString(params.Params)
 		if err != nil {
 			return "", fmt.Errorf("failed to decode hex params: %w", err)
@@ -72,7 +91,7 @@ func (ms *MessageService) Send(ctx context.Context, params types.QuickSendParams
 		msg.Message.GasLimit = 0
 	}
 
-	err = ms.pushMessage(ctx, msg)
+	err = ms.pushMessage(ctx, msg, opts, nil)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/venus/venus-shared/actors"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+var ErrRejectedBySignerPolicy = errors.New("rejected by signer policy")
+
+// SetSignerPolicy creates a policy if its id is empty, otherwise updates the policy with the id
+func (ms *MessageService) SetSignerPolicy(ctx context.Context, policy *sophonTypes.SignerPolicy) (string, error) {
+	if policy == nil {
+		return "", fmt.Errorf("policy is nil")
+	}
+	if policy.Signer == address.Undef {
+		return "", fmt.Errorf("signer must be set")
+	}
+	if policy.OwnedMinerOnly && len(policy.ActorType) != 0 && policy.ActorType != manifest.MinerKey {
+		return "", fmt.Errorf("actor type %s conflicts with owned miner only", policy.ActorType)
+	}
+	if len(policy.ID) == 0 {
+		policy.ID = venusTypes.NewUUID().String()
+	} else if _, err := ms.repo.SignerPolicyRepo().GetSignerPolicy(ctx, policy.ID); err != nil {
+		return "", fmt.Errorf("get policy %s failed: %w", policy.ID, err)
+	}
+
+	if err := ms.repo.SignerPolicyRepo().SaveSignerPolicy(ctx, policy); err != nil {
+		return "", err
+	}
+	return policy.ID, nil
+}
+
+// ListSignerPolicy lists the policies of signer, all policies if signer is address.Undef
+func (ms *MessageService) ListSignerPolicy(ctx context.Context, signer address.Address) ([]*sophonTypes.SignerPolicy, error) {
+	return ms.repo.SignerPolicyRepo().ListSignerPolicy(ctx, signer)
+}
+
+func (ms *MessageService) DeleteSignerPolicy(ctx context.Context, id string) error {
+	return ms.repo.SignerPolicyRepo().DeleteSignerPolicy(ctx, id)
+}
+
+// CheckSignerPolicy returns ErrRejectedBySignerPolicy if the signer of msg has policies and none of them matches msg
+func (ms *MessageService) CheckSignerPolicy(ctx context.Context, msg *venusTypes.Message) error {
+	from := msg.From
+	if from.Protocol() == address.ID {
+		var err error
+		if from, err = ms.nodeClient.StateAccountKey(ctx, from, venusTypes.EmptyTSK); err != nil {
+			return fmt.Errorf("getting key address: %w", err)
+		}
+	}
+	policies, err := ms.repo.SignerPolicyRepo().ListSignerPolicy(ctx, from)
+	if err != nil {
+		return fmt.Errorf("list signer policy failed: %w", err)
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	target := &policyTarget{ms: ms, signer: from, msg: msg}
+	reasons := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		reason, err := target.match(ctx, policy)
+		if err != nil {
+			return err
+		}
+		if len(reason) == 0 {
+			return nil
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s", policy.ID, reason))
+	}
+
+	return fmt.Errorf("%w: message from %s to %s method %d value %s, %s", ErrRejectedBySignerPolicy, from, msg.To,
+		msg.Method, venusTypes.FIL(msg.Value), strings.Join(reasons, "; "))
+}
+
+// policyTarget loads the state of the recipient lazily, it is shared by the policies of the signer
+type policyTarget struct {
+	ms     *MessageService
+	signer address.Address
+	msg    *venusTypes.Message
+
+	toKey     *address.Address
+	actorType *string
+	owners    map[address.Address]struct{}
+}
+
+// match returns the reason if msg does not match policy, empty if it matches
+func (t *policyTarget) match(ctx context.Context, policy *sophonTypes.SignerPolicy) (string, error) {
+	if !policy.AllowValue && !t.msg.Value.NilOrZero() {
+		return "value transfer is not allowed", nil
+	}
+	if len(policy.Methods) > 0 && !containsMethod(policy.Methods, t.msg.Method) {
+		return fmt.Sprintf("method %d is not allowed", t.msg.Method), nil
+	}
+	if len(policy.To) > 0 {
+		allowed := false
+		toKey := t.recipientKey(ctx)
+		for _, to := range policy.To {
+			if to == t.msg.To || to == toKey {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("recipient %s is not allowed", t.msg.To), nil
+		}
+	}
+	if len(policy.ActorType) > 0 || policy.OwnedMinerOnly {
+		actorType, err := t.recipientType(ctx)
+		if err != nil {
+			return "", err
+		}
+		if len(policy.ActorType) > 0 && actorType != policy.ActorType {
+			return fmt.Sprintf("actor type %s is not allowed", actorType), nil
+		}
+		if policy.OwnedMinerOnly {
+			if actorType != manifest.MinerKey {
+				return fmt.Sprintf("recipient %s is not a miner", t.msg.To), nil
+			}
+			owners, err := t.minerOwners(ctx)
+			if err != nil {
+				return "", err
+			}
+			if _, ok := owners[t.signer]; !ok {
+				return fmt.Sprintf("miner %s is not owned by %s", t.msg.To, t.signer), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// recipientKey the key address of recipient if it is an ID address of account
+func (t *policyTarget) recipientKey(ctx context.Context) address.Address {
+	if t.toKey == nil {
+		toKey := t.msg.To
+		if t.msg.To.Protocol() == address.ID {
+			if key, err := t.ms.nodeClient.StateAccountKey(ctx, t.msg.To, venusTypes.EmptyTSK); err == nil {
+				toKey = key
+			}
+		}
+		t.toKey = &toKey
+	}
+	return *t.toKey
+}
+
+// recipientType the canonical name of recipient actor, eg. account, storageminer, empty if the actor not exists
+func (t *policyTarget) recipientType(ctx context.Context) (string, error) {
+	if t.actorType == nil {
+		actorType := ""
+		actor, err := t.ms.nodeClient.StateGetActor(ctx, t.msg.To, venusTypes.EmptyTSK)
+		if err != nil {
+			if !strings.Contains(err.Error(), venusTypes.ErrActorNotFound.Error()) {
+				return "", fmt.Errorf("get actor %s failed: %w", t.msg.To, err)
+			}
+		} else {
+			actorType = actors.CanonicalName(builtin.ActorNameByCode(actor.Code))
+		}
+		t.actorType = &actorType
+	}
+	return *t.actorType, nil
+}
+
+// minerOwners the key addresses of owner, worker and control addresses of the recipient miner
+func (t *policyTarget) minerOwners(ctx context.Context) (map[address.Address]struct{}, error) {
+	if t.owners == nil {
+		info, err := t.ms.nodeClient.StateMinerInfo(ctx, t.msg.To, venusTypes.EmptyTSK)
+		if err != nil {
+			return nil, fmt.Errorf("get miner info %s failed: %w", t.msg.To, err)
+		}
+		t.owners = make(map[address.Address]struct{})
+		for _, addr := range append([]address.Address{info.Owner, info.Worker}, info.ControlAddresses...) {
+			// the owner may be a multisig which has no key address
+			if key, err := t.ms.nodeClient.StateAccountKey(ctx, addr, venusTypes.EmptyTSK); err == nil {
+				t.owners[key] = struct{}{}
+			}
+		}
+	}
+	return t.owners, nil
+}
+
+func containsMethod(methods []abi.MethodNum, method abi.MethodNum) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/venus/venus-shared/actors"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+func TestCheckSignerPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msh := newMessageServiceHelper(ctx, t, skipPushMessage())
+	addrs := msh.genAddresses()
+	ms := msh.MessageService
+
+	signer := addrs[0]
+	other := addrs[1]
+	ownedMiner, err := address.NewIDAddress(20001)
+	require.NoError(t, err)
+	otherMiner, err := address.NewIDAddress(20002)
+	require.NoError(t, err)
+	minerCode, ok := actors.GetActorCodeID(actorstypes.Version12, manifest.MinerKey)
+	require.True(t, ok)
+	require.NoError(t, msh.fullNode.AddActors([]address.Address{ownedMiner, otherMiner}))
+	require.NoError(t, msh.fullNode.SetActorCode(ownedMiner, minerCode))
+	require.NoError(t, msh.fullNode.SetActorCode(otherMiner, minerCode))
+	msh.fullNode.SetMinerInfo(ownedMiner, venusTypes.MinerInfo{Owner: other, Worker: signer})
+	msh.fullNode.SetMinerInfo(otherMiner, venusTypes.MinerInfo{Owner: other, Worker: other})
+
+	newMsg := func(from, to address.Address, method abi.MethodNum, value int64) *venusTypes.Message {
+		return &venusTypes.Message{From: from, To: to, Method: method, Value: big.NewInt(value)}
+	}
+
+	// signer without policy is not restricted
+	assert.NoError(t, ms.CheckSignerPolicy(ctx, newMsg(signer, other, builtin.MethodSend, 100)))
+
+	_, err = ms.SetSignerPolicy(ctx, &sophonTypes.SignerPolicy{})
+	assert.Error(t, err)
+	_, err = ms.SetSignerPolicy(ctx, &sophonTypes.SignerPolicy{Signer: signer, ActorType: "account", OwnedMinerOnly: true})
+	assert.Error(t, err)
+	_, err = ms.SetSignerPolicy(ctx, &sophonTypes.SignerPolicy{ID: "not-exist", Signer: signer})
+	assert.Error(t, err)
+
+	minerPolicyID, err := ms.SetSignerPolicy(ctx, &sophonTypes.SignerPolicy{
+		Signer:         signer,
+		Methods:        []abi.MethodNum{builtin.MethodsMiner.SubmitWindowedPoSt, builtin.MethodsMiner.PreCommitSectorBatch2},
+		OwnedMinerOnly: true,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, minerPolicyID)
+
+	t.Run("owned miner", func(t *testing.T) {
+		assert.NoError(t, ms.CheckSignerPolicy(ctx, newMsg(signer, ownedMiner, builtin.MethodsMiner.SubmitWindowedPoSt, 0)))
+
+		err := ms.CheckSignerPolicy(ctx, newMsg(signer, otherMiner, builtin.MethodsMiner.SubmitWindowedPoSt, 0))
+		assert.True(t, errors.Is(err, ErrRejectedBySignerPolicy))
+		err = ms.CheckSignerPolicy(ctx, newMsg(signer, ownedMiner, builtin.MethodsMiner.WithdrawBalance, 0))
+		assert.True(t, errors.Is(err, ErrRejectedBySignerPolicy))
+		err = ms.CheckSignerPolicy(ctx, newMsg(signer, ownedMiner, builtin.MethodsMiner.SubmitWindowedPoSt, 1))
+		assert.True(t, errors.Is(err, ErrRejectedBySignerPolicy))
+		err = ms.CheckSignerPolicy(ctx, newMsg(signer, other, builtin.MethodsMiner.SubmitWindowedPoSt, 0))
+		assert.True(t, errors.Is(err, ErrRejectedBySignerPolicy))
+
+		// other signers are not affected
+		assert.NoError(t, ms.CheckSignerPolicy(ctx, newMsg(other, otherMiner, builtin.MethodsMiner.WithdrawBalance, 0)))
+	})
+
+	t.Run("value transfer to allowlist", func(t *testing.T) {
+		err := ms.CheckSignerPolicy(ctx, newMsg(signer, other, builtin.MethodSend, 100))
+		assert.True(t, errors.Is(err, ErrRejectedBySignerPolicy))
+
+		_, err = ms.SetSignerPolicy(ctx, &sophonTypes.SignerPolicy{
+			Signer:     signer,
+			To:         []address.Address{other},
+			Methods:    []abi.MethodNum{builtin.MethodSend},
+			AllowValue: true,
+		})
+		require.NoError(t, err)
+		assert.NoError(t, ms.CheckSignerPolicy(ctx, newMsg(signer, other, builtin.MethodSend, 100)))
+		err = ms.CheckSignerPolicy(ctx, newMsg(signer, addrs[2], builtin.MethodSend, 100))
+		assert.True(t, errors.Is(err, ErrRejectedBySignerPolicy))
+		// the previous policy still matches
+		assert.NoError(t, ms.CheckSignerPolicy(ctx, newMsg(signer, ownedMiner, builtin.MethodsMiner.SubmitWindowedPoSt, 0)))
+	})
+
+	t.Run("actor type", func(t *testing.T) {
+		policies, err := ms.ListSignerPolicy(ctx, signer)
+		require.NoError(t, err)
+		assert.Len(t, policies, 2)
+
+		// replace the miner policy, allow any method of any miner
+		_, err = ms.SetSignerPolicy(ctx, &sophonTypes.SignerPolicy{
+			ID:        minerPolicyID,
+			Signer:    signer,
+			ActorType: manifest.MinerKey,
+		})
+		require.NoError(t, err)
+		policies, err = ms.ListSignerPolicy(ctx, address.Undef)
+		require.NoError(t, err)
+		assert.Len(t, policies, 2)
+
+		assert.NoError(t, ms.CheckSignerPolicy(ctx, newMsg(signer, otherMiner, builtin.MethodsMiner.WithdrawBalance, 0)))
+		err = ms.CheckSignerPolicy(ctx, newMsg(signer, addrs[2], builtin.MethodsMiner.WithdrawBalance, 0))
+		assert.True(t, errors.Is(err, ErrRejectedBySignerPolicy))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		policies, err := ms.ListSignerPolicy(ctx, signer)
+		require.NoError(t, err)
+		for _, policy := range policies {
+			assert.NoError(t, ms.DeleteSignerPolicy(ctx, policy.ID))
+		}
+		assert.Error(t, ms.DeleteSignerPolicy(ctx, minerPolicyID))
+		assert.NoError(t, ms.CheckSignerPolicy(ctx, newMsg(signer, addrs[2], builtin.MethodSend, 100)))
+	})
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+
+	sophonTypes "github.com/ipfs-force-community/sophon-messager/types"
+)
+
+const exceedSpendBudget = "exceed spend budget: "
+
+// spendUsage tracks the spend of an address in the current budget window
+type spendUsage struct {
+	budget sophonTypes.SpendBudget
+	window string
+
+	value  big.Int
+	gasFee big.Int
+}
+
+// loadSpendUsage sums the spends of address in the budget window ending at ts, returns nil if the budget is not enabled
+func (w *work) loadSpendUsage(ctx context.Context, ts *venusTypes.TipSet) (*spendUsage, error) {
+	addrCfg, err := w.addressService.GetAddressConfig(ctx, w.addr)
+	if err != nil {
+		return nil, fmt.Errorf("get address config failed: %v", err)
+	}
+	budget := addrCfg.SpendBudget
+	if !budget.Enabled() {
+		return nil, nil
+	}
+
+	usage := &spendUsage{budget: budget, value: big.Zero(), gasFee: big.Zero()}
+	var since time.Time
+	var sinceHeight abi.ChainEpoch
+	if budget.WindowEpochs > 0 {
+		sinceHeight = ts.Height() - budget.WindowEpochs + 1
+		usage.window = fmt.Sprintf("%d epochs", budget.WindowEpochs)
+	} else {
+		window := budget.Window
+		if window <= 0 {
+			window = sophonTypes.DefaultSpendBudgetWindow
+		}
+		since = time.Now().Add(-window)
+		usage.window = window.String()
+	}
+
+	spends, err := w.repo.AddressSpendRepo().ListAddressSpend(ctx, w.addr, since, sinceHeight)
+	if err != nil {
+		return nil, fmt.Errorf("list address spend failed: %v", err)
+	}
+	for _, spend := range spends {
+		usage.value = big.Add(usage.value, spend.Value)
+		usage.gasFee = big.Add(usage.gasFee, spend.GasFee)
+	}
+
+	return usage, nil
+}
+
+// check returns the reason if msg can not be signed within the budget, empty if it can
+func (u *spendUsage) check(msg *venusTypes.Message) string {
+	if u == nil {
+		return ""
+	}
+	if !u.budget.MaxValue.NilOrZero() && !msg.Value.Nil() {
+		if value := big.Add(u.value, msg.Value); value.GreaterThan(u.budget.MaxValue) {
+			return fmt.Sprintf("%svalue %s plus spent %s exceeds the max value %s in %s", exceedSpendBudget,
+				venusTypes.FIL(msg.Value), venusTypes.FIL(u.value), venusTypes.FIL(u.budget.MaxValue), u.window)
+		}
+	}
+	if !u.budget.MaxGasFee.NilOrZero() {
+		fee := big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit))
+		if gasFee := big.Add(u.gasFee, fee); gasFee.GreaterThan(u.budget.MaxGasFee) {
+			return fmt.Sprintf("%sgas fee %s plus spent %s exceeds the max gas fee %s in %s", exceedSpendBudget,
+				venusTypes.FIL(fee), venusTypes.FIL(u.gasFee), venusTypes.FIL(u.budget.MaxGasFee), u.window)
+		}
+	}
+	return ""
+}
+
+func (u *spendUsage) add(msg *venusTypes.Message) {
+	if u == nil {
+		return
+	}
+	if !msg.Value.Nil() {
+		u.value = big.Add(u.value, msg.Value)
+	}
+	u.gasFee = big.Add(u.gasFee, big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit)))
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
 	v1 "github.com/filecoin-project/venus/venus-shared/api/chain/v1"
 	"go.uber.org/atomic"
 
@@ -38,7 +39,8 @@ var (
 	DefGasOverPremium    = 4.0
 	DefMaxFee            = big.Mul(big.NewInt(DefGasUsed*10), DefGasFeeCap)
 
-	defBalance = abi.NewTokenAmount(1000)
+	// DefBalance large enough to cover the cost of messages in tests
+	DefBalance = big.Mul(big.NewInt(10000), big.NewInt(1e18))
 
 	// MinPackedPremium If the gas premium is lower than this value, the message will not be packaged
 	MinPackedPremium = abi.NewTokenAmount(500)
@@ -51,7 +53,8 @@ type MockFullNode struct {
 
 	miner address.Address
 
-	actors map[address.Address]*types.Actor
+	actors     map[address.Address]*types.Actor
+	minerInfos map[address.Address]types.MinerInfo
 
 	ts        map[types.TipSetKey]*types.TipSet
 	heightKey map[abi.ChainEpoch]types.TipSetKey
@@ -61,6 +64,10 @@ type MockFullNode struct {
 	blockInfos  map[cid.Cid]*blockInfo
 	chainMsgs   map[cid.Cid]*types.SignedMessage
 	msgReceipts map[cid.Cid]*types.MessageReceipt
+	// callExitCodes the exit codes returned by StateCall, keyed by the recipient and method
+	callExitCodes map[callKey]exitcode.ExitCode
+	// callErrors the errors returned by StateCall, keyed by the recipient and method
+	callErrors map[callKey]error
 
 	pendingMsgs []*types.SignedMessage
 
@@ -73,6 +80,11 @@ type MockFullNode struct {
 	mockV1.MockFullNode
 }
 
+type callKey struct {
+	to     address.Address
+	method abi.MethodNum
+}
+
 type blockInfo struct {
 	bh   *types.BlockHeader
 	msgs []cid.Cid
@@ -88,11 +100,14 @@ func NewMockFullNode(ctx context.Context, blockDelay time.Duration) (*MockFullNo
 		blockDelay:         blockDelay,
 		miner:              miner,
 		actors:             make(map[address.Address]*types.Actor),
+		minerInfos:         make(map[address.Address]types.MinerInfo),
 		ts:                 make(map[types.TipSetKey]*types.TipSet),
 		heightKey:          make(map[abi.ChainEpoch]types.TipSetKey),
 		blockInfos:         make(map[cid.Cid]*blockInfo),
 		chainMsgs:          make(map[cid.Cid]*types.SignedMessage),
 		msgReceipts:        make(map[cid.Cid]*types.MessageReceipt),
+		callExitCodes:      make(map[callKey]exitcode.ExitCode),
+		callErrors:         make(map[callKey]error),
 		eventBus:           EventBus.New(),
 		revertSignReceiver: make(chan *RevertSignal, 5),
 	}
@@ -138,12 +153,77 @@ func (f *MockFullNode) AddActors(addrs []address.Address) error {
 		}
 		_, ok := f.actors[addr]
 		if !ok {
-			f.actors[addr] = &types.Actor{Nonce: 0, Balance: defBalance}
+			f.actors[addr] = &types.Actor{Nonce: 0, Balance: DefBalance}
+		}
+	}
+	return nil
+}
+
+func (f *MockFullNode) SetActorBalance(addr address.Address, balance abi.TokenAmount) error {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if addr.Protocol() == address.ID {
+		var err error
+		addr, err = ResolveIDAddr(addr)
+		if err != nil {
+			return err
 		}
 	}
+	actor, ok := f.actors[addr]
+	if !ok {
+		return fmt.Errorf("not found actor %v", addr)
+	}
+	actor.Balance = balance
 	return nil
 }
 
+func (f *MockFullNode) SetActorCode(addr address.Address, code cid.Cid) error {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if addr.Protocol() == address.ID {
+		var err error
+		addr, err = ResolveIDAddr(addr)
+		if err != nil {
+			return err
+		}
+	}
+	actor, ok := f.actors[addr]
+	if !ok {
+		return fmt.Errorf("not found actor %v", addr)
+	}
+	actor.Code = code
+	return nil
+}
+
+func (f *MockFullNode) SetMinerInfo(addr address.Address, info types.MinerInfo) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	f.minerInfos[addr] = info
+}
+
+// SetCallExitCode sets the exit code returned by StateCall for the messages sent to addr with method
+func (f *MockFullNode) SetCallExitCode(addr address.Address, method abi.MethodNum, code exitcode.ExitCode) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	f.callExitCodes[callKey{to: addr, method: method}] = code
+}
+
+// SetCallError sets the error returned by StateCall for the messages sent to addr with method, nil to clear it
+func (f *MockFullNode) SetCallError(addr address.Address, method abi.MethodNum, err error) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if err == nil {
+		delete(f.callErrors, callKey{to: addr, method: method})
+		return
+	}
+	f.callErrors[callKey{to: addr, method: method}] = err
+}
+
 type RevertSignal struct {
 	ExpectRevertCount int
 	RevertedTS        chan []*types.TipSet
@@ -364,6 +444,14 @@ func (f *MockFullNode) StateAccountKey(_ context.Context, addr address.Address,
 	return ResolveIDAddr(addr)
 }
 
+// StateLookupID only knows the ID addresses, which are returned directly
+func (f *MockFullNode) StateLookupID(_ context.Context, addr address.Address, _ types.TipSetKey) (address.Address, error) {
+	if addr.Protocol() != address.ID {
+		return address.Undef, fmt.Errorf("not found actor id of %v", addr)
+	}
+	return addr, nil
+}
+
 func (f *MockFullNode) StateNetworkName(_ context.Context) (types.NetworkName, error) {
 	return types.NetworkNameMain, nil
 }
@@ -505,6 +593,36 @@ func (f *MockFullNode) StateGetActor(_ context.Context, addr address.Address, _
 	return &actorCp, nil
 }
 
+func (f *MockFullNode) StateMinerInfo(_ context.Context, addr address.Address, _ types.TipSetKey) (types.MinerInfo, error) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	info, ok := f.minerInfos[addr]
+	if !ok {
+		return types.MinerInfo{}, fmt.Errorf("not found miner %v", addr)
+	}
+	return info, nil
+}
+
+func (f *MockFullNode) StateCall(_ context.Context, msg *types.Message, _ types.TipSetKey) (*types.InvocResult, error) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if err, ok := f.callErrors[callKey{to: msg.To, method: msg.Method}]; ok {
+		return nil, err
+	}
+	code := f.callExitCodes[callKey{to: msg.To, method: msg.Method}]
+	res := &types.InvocResult{
+		MsgCid: msg.Cid(),
+		Msg:    msg,
+		MsgRct: &types.MessageReceipt{ExitCode: code, GasUsed: DefGasUsed},
+	}
+	if !code.IsSuccess() {
+		res.Error = fmt.Sprintf("mock call failed with exit code %d", code)
+	}
+	return res, nil
+}
+
 func (f *MockFullNode) GasBatchEstimateMessageGas(ctx context.Context, estimateMessages []*types.EstimateMessage, fromNonce uint64, tsk types.TipSetKey) ([]*types.EstimateResult, error) {
 	var err error
 	res := make([]*types.EstimateResult, 0, len(estimateMessages))
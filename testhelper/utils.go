@@ -52,6 +52,8 @@ func AddressProtocolToSignType(protocol address.Protocol) crypto.SigType {
 		return crypto.SigTypeSecp256k1
 	case address.BLS:
 		return crypto.SigTypeBLS
+	case address.Delegated:
+		return crypto.SigTypeDelegated
 	default:
 		return crypto.SigTypeUnknown
 	}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/venus/venus-shared/types/messager"
+)
+
+// DefaultSpendBudgetWindow the window of budget if neither Window nor WindowEpochs is set
+const DefaultSpendBudgetWindow = 24 * time.Hour
+
+// AddressConfig the settings of an address which are not included in messager.Address
+type AddressConfig struct {
+	Addr address.Address
+	// SelectStrategy the name of strategy used to select message, empty means use the global config
+	SelectStrategy string
+	// AutoFillNonceGap fill the nonce gaps of address automatically before selecting messages
+	AutoFillNonceGap bool
+	// BalanceReserve the balance kept by address, messages are held back if their worst-case cost eats into it
+	BalanceReserve big.Int
+	SpendBudget    SpendBudget
+	// BatchSend collapses the small sends of address into one message of the aggregator before selecting messages
+	BatchSend bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SpendBudget limits the value and the gas fee of messages signed by an address in a rolling window,
+// the messages exceeding the budget are left unfill
+type SpendBudget struct {
+	// MaxValue the max value transferred in the window, zero means unlimited
+	MaxValue big.Int
+	// MaxGasFee the max of GasLimit*GasFeeCap in the window, zero means unlimited
+	MaxGasFee big.Int
+	// Window the rolling window of budget, DefaultSpendBudgetWindow if zero
+	Window time.Duration
+	// WindowEpochs the rolling window in epochs, takes precedence over Window if not zero
+	WindowEpochs abi.ChainEpoch
+}
+
+func (b *SpendBudget) Enabled() bool {
+	return !b.MaxValue.NilOrZero() || !b.MaxGasFee.NilOrZero()
+}
+
+// AddressSpend the worst-case spend of a signed message, it is recorded when the message is signed first,
+// only the gas fee is updated when the message is replaced
+type AddressSpend struct {
+	MsgID string
+	From  address.Address
+	Value big.Int
+	// GasFee GasLimit*GasFeeCap
+	GasFee big.Int
+	// Height the height of tipset when the message is signed
+	Height abi.ChainEpoch
+
+	CreatedAt time.Time
+}
+
+func NewAddressSpend(msg *messager.Message, height abi.ChainEpoch) *AddressSpend {
+	value := msg.Value
+	if value.Nil() {
+		value = big.Zero()
+	}
+	return &AddressSpend{
+		MsgID:  msg.ID,
+		From:   msg.From,
+		Value:  value,
+		GasFee: big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit)),
+		Height: height,
+	}
+}
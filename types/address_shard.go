@@ -0,0 +1,20 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// AddressShard assigns an address to one of the messager instances sharing the same database, only the holder
+// selects the messages of the address
+type AddressShard struct {
+	Addr address.Address
+	// Holder the instance holding the address, empty after it is released
+	Holder string
+	// Token the fencing token, increased each time the address is claimed by another holder
+	Token uint64
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// ContractABI the solidity abi of an EVM actor, used to encode the calldata of `InvokeContract`
+// and decode its return data
+type ContractABI struct {
+	// Address the ID address of actor
+	Address address.Address
+	// ABI the json abi generated by solc
+	ABI         string
+	Description string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	actorsTypes "github.com/filecoin-project/venus/venus-shared/actors/types"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+)
+
+// EthTxParams the EIP-1559 fields of an eth transaction sent from a delegated address
+type EthTxParams struct {
+	From address.Address
+	// To the receiver of transaction, nil to create a contract
+	To    *venusTypes.EthAddress
+	Value big.Int
+	Input []byte
+
+	// GasLimit, MaxFeePerGas and MaxPriorityFeePerGas are estimated by messager if they are zero
+	GasLimit             int64
+	MaxFeePerGas         big.Int
+	MaxPriorityFeePerGas big.Int
+
+	// Spec the send spec of message, optional
+	Spec *SendSpec
+}
+
+// Message builds the filecoin message carrying the transaction, a contract is invoked by `InvokeContract`
+// and created by the `CreateExternal` of EAM actor
+func (p *EthTxParams) Message() (*venusTypes.Message, error) {
+	if p.From.Protocol() != address.Delegated {
+		return nil, fmt.Errorf("sender %s is not a delegated address", p.From)
+	}
+	if _, err := venusTypes.EthAddressFromFilecoinAddress(p.From); err != nil {
+		return nil, fmt.Errorf("sender %s is not an eth account: %w", p.From, err)
+	}
+	if p.GasLimit < 0 {
+		return nil, fmt.Errorf("invalid gas limit %d", p.GasLimit)
+	}
+
+	tx := venusTypes.Eth1559TxArgs{
+		ChainID:              actorsTypes.Eip155ChainID,
+		To:                   p.To,
+		Value:                zeroIfNil(p.Value),
+		MaxFeePerGas:         zeroIfNil(p.MaxFeePerGas),
+		MaxPriorityFeePerGas: zeroIfNil(p.MaxPriorityFeePerGas),
+		GasLimit:             int(p.GasLimit),
+		Input:                p.Input,
+	}
+	return tx.ToUnsignedFilecoinMessage(p.From)
+}
+
+// EthTxHash computes the hash of eth transaction of a message signed by a delegated address
+func EthTxHash(msg *types.Message) (venusTypes.EthHash, error) {
+	if msg.Signature == nil || msg.Signature.Type != crypto.SigTypeDelegated {
+		return venusTypes.EmptyEthHash, errors.New("message is not signed by a delegated address")
+	}
+	tx, err := venusTypes.EthTransactionFromSignedFilecoinMessage(&venusTypes.SignedMessage{
+		Message:   msg.Message,
+		Signature: *msg.Signature,
+	})
+	if err != nil {
+		return venusTypes.EmptyEthHash, err
+	}
+	return tx.TxHash()
+}
+
+func zeroIfNil(v big.Int) big.Int {
+	if v.Nil() {
+		return big.Zero()
+	}
+	return v
+}
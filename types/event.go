@@ -0,0 +1,71 @@
+package types
+
+import (
+	"slices"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+)
+
+// MessageEvent is published when a message moves to a new state
+type MessageEvent struct {
+	ID        string
+	From      address.Address
+	Nonce     uint64
+	SignedCid *cid.Cid
+	State     messager.MessageState
+
+	// Height, TipSetKey and Receipt are only set when the message is on chain or replaced
+	Height    abi.ChainEpoch
+	TipSetKey venusTypes.TipSetKey
+	Receipt   *venusTypes.MessageReceipt
+
+	CreatedAt time.Time
+}
+
+// NewMessageEvent creates an event with the current state of msg
+func NewMessageEvent(msg *messager.Message) *MessageEvent {
+	event := &MessageEvent{
+		ID:        msg.ID,
+		From:      msg.From,
+		Nonce:     msg.Nonce,
+		SignedCid: msg.SignedCid,
+		State:     msg.State,
+		CreatedAt: time.Now(),
+	}
+	if IsOnChain(msg.State) || msg.State == messager.NonceConflictMsg {
+		event.Height = abi.ChainEpoch(msg.Height)
+		event.TipSetKey = msg.TipSetKey
+		event.Receipt = msg.Receipt
+	}
+
+	return event
+}
+
+// MessageEventFilter an event is matched only if it matches all the non-empty fields
+type MessageEventFilter struct {
+	From   []address.Address
+	IDs    []string
+	States []messager.MessageState
+}
+
+func (f *MessageEventFilter) Match(event *MessageEvent) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.From) > 0 && !slices.Contains(f.From, event.From) {
+		return false
+	}
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, event.ID) {
+		return false
+	}
+	if len(f.States) > 0 && !slices.Contains(f.States, event.State) {
+		return false
+	}
+
+	return true
+}
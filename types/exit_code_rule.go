@@ -0,0 +1,69 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+)
+
+// ExitCodeAction what to do with a message landed on chain with the exit code of a rule
+type ExitCodeAction string
+
+const (
+	// ExitCodeRetry pushes a new message with the same content, GasOverEstimation of the new message is
+	// multiplied by GasMultiplier of the rule
+	ExitCodeRetry ExitCodeAction = "retry"
+	// ExitCodeAlert posts an alert notification to the webhooks, the message is not retried
+	ExitCodeAlert ExitCodeAction = "alert"
+	// ExitCodeMarkBad records the exit code and rule in the error message of the message, the message keeps its
+	// on chain state
+	ExitCodeMarkBad ExitCodeAction = "mark_bad"
+)
+
+func ParseExitCodeAction(s string) (ExitCodeAction, error) {
+	switch action := ExitCodeAction(s); action {
+	case ExitCodeRetry, ExitCodeAlert, ExitCodeMarkBad:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown exit code action %s", s)
+	}
+}
+
+// ExitCodeRule the action of the failed messages with ExitCode, sent to the actors with Code and the Methods.
+// When several rules match a message, the rule with Code takes precedence over the rule without Code,
+// then the rule with Methods takes precedence over the rule without Methods
+type ExitCodeRule struct {
+	ID       string
+	ExitCode exitcode.ExitCode
+	// Code the code of recipient actor, cid.Undef matches any actor
+	Code cid.Cid
+	// Methods any method if empty
+	Methods []abi.MethodNum
+
+	Action ExitCodeAction
+	// GasMultiplier only for retry, the GasOverEstimation of the retry message is multiplied by it
+	GasMultiplier float64
+	// MaxRetries only for retry, the max number of retries following the first message
+	MaxRetries int
+
+	Description string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MessageRetry links a failed message to the message retrying it
+type MessageRetry struct {
+	// MsgID the id of failed message
+	MsgID string
+	// RetryMsgID the id of new message
+	RetryMsgID string
+	RuleID     string
+	// Attempt the sequence number of retries from the first message, starting from 1
+	Attempt int
+
+	CreatedAt time.Time
+}
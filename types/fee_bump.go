@@ -0,0 +1,61 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/ipfs/go-cid"
+)
+
+// FeeBumpPolicy limits the automatic fee bumping of blocked messages. An address policy applies to the messages
+// sent from Addr, an actor policy applies to the messages sent to the actor with Code and Method,
+// the address policy takes precedence over the actor policy
+type FeeBumpPolicy struct {
+	ID string
+	// Addr address.Undef for an actor policy
+	Addr address.Address
+	// Code cid.Undef for an address policy
+	Code   cid.Cid
+	Method abi.MethodNum
+
+	// MaxBumps the max number of automatic replacements of one message, zero disables bumping
+	MaxBumps int
+	// BumpRatio the new gas premium is at least BumpRatio percent of the old one,
+	// the replace ratio of mpool is used if it is lower
+	BumpRatio venusTypes.Percent
+	// MaxFeeCap the ceiling of gas fee cap, zero means only limited by the max fee
+	MaxFeeCap big.Int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (p *FeeBumpPolicy) IsAddressPolicy() bool {
+	return p.Addr != address.Undef
+}
+
+// FeeBumpRecord the audit record of an automatic replacement
+type FeeBumpRecord struct {
+	ID    string
+	MsgID string
+	From  address.Address
+	Nonce uint64
+	// Bump the sequence number of replacements of the message, starting from 1
+	Bump     int
+	PolicyID string
+
+	OldSignedCid  cid.Cid
+	OldGasLimit   int64
+	OldGasFeeCap  big.Int
+	OldGasPremium big.Int
+
+	NewSignedCid  cid.Cid
+	NewGasLimit   int64
+	NewGasFeeCap  big.Int
+	NewGasPremium big.Int
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+// Lease is held by the leader of the messager instances sharing the same database, only the holder of the lease
+// selects messages and processes the head changes
+type Lease struct {
+	Name   string
+	Holder string
+	// Token the fencing token, increased each time the lease is taken over by another holder
+	Token    uint64
+	ExpireAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsExpired returns true if the lease is not renewed before now
+func (l *Lease) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpireAt)
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/venus/venus-shared/types/messager"
+)
+
+// DefaultPriority the priority of the message pushed without priority
+const DefaultPriority = 0
+
+// ExpiredMsg the state of unfill message which is not selected before its deadline,
+// the value keeps away from the states defined in messager.MessageState
+const ExpiredMsg messager.MessageState = 7
+
+// BatchedMsg the state of unfill message which is collapsed into a batch message, it follows the state of batch
+// message when queried by id and turns to OnChainMsg with its own receipt after the batch message lands on chain
+const BatchedMsg messager.MessageState = 8
+
+// FinalizedMsg the state of on chain message which will not be reverted, messages are promoted to it from OnChainMsg
+// only when finality is enabled
+const FinalizedMsg messager.MessageState = 9
+
+// MessageStateString extends messager.MessageState.String with the states only used by sophon-messager
+func MessageStateString(state messager.MessageState) string {
+	switch state {
+	case ExpiredMsg:
+		return "ExpiredMsg"
+	case BatchedMsg:
+		return "BatchedMsg"
+	case FinalizedMsg:
+		return "FinalizedMsg"
+	}
+	return state.String()
+}
+
+// IsOnChain returns true if state is OnChainMsg or FinalizedMsg
+func IsOnChain(state messager.MessageState) bool {
+	return state == messager.OnChainMsg || state == FinalizedMsg
+}
+
+// MessageOptions the options of message which are not included in messager.SendSpec
+type MessageOptions struct {
+	// Priority the unfill message with higher priority will be selected first
+	Priority int
+	// ExpireAt the message will be expired if it is not selected before ExpireAt, zero means no deadline
+	ExpireAt time.Time
+}
+
+// SendSpec extends messager.SendSpec with MessageOptions
+type SendSpec struct {
+	messager.SendSpec
+	MessageOptions
+}
+
+// QuickSendParams extends messager.QuickSendParams with MessageOptions
+type QuickSendParams struct {
+	messager.QuickSendParams
+	MessageOptions
+	// ABIMethod the method of contract abi called by `InvokeContract`, eg. transfer(address,uint256),
+	// the params are its args in a json array if it is set
+	ABIMethod string
+}
@@ -0,0 +1,13 @@
+package types
+
+import "time"
+
+// MessageBatchItem maps a message collapsed into a batch to the batch message,
+// Index is the position of its call in the batch, which is also the position of its result in the batch receipt
+type MessageBatchItem struct {
+	MsgID    string
+	ParentID string
+	Index    int
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"encoding/json"
+
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+)
+
+// DecodedMessage the message with its params and return decoded by the method of recipient actor
+type DecodedMessage struct {
+	Message *types.Message
+	// Params the json of decoded params, empty if the method has no params
+	Params json.RawMessage
+	// Return the json of decoded return, empty if the message is not executed successfully or returns nothing
+	Return json.RawMessage
+	// DecodeError why the params or return can not be decoded, the raw bytes are still in Message
+	DecodeError string
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+// MessageFee the gas actually spent by an on chain message, computed from the receipt and
+// the parent base fee of the tipset including the message
+type MessageFee struct {
+	MsgID  string
+	From   address.Address
+	To     address.Address
+	Method abi.MethodNum
+	// Height the height of the tipset including the message
+	Height int64
+	// OnChainTime the timestamp of the tipset including the message
+	OnChainTime time.Time
+
+	GasUsed    int64
+	GasLimit   int64
+	GasFeeCap  big.Int
+	GasPremium big.Int
+	BaseFee    big.Int
+
+	BaseFeeBurn        big.Int
+	OverEstimationBurn big.Int
+	MinerTip           big.Int
+	// MinerPenalty paid by the miner when the fee cap is lower than the base fee, not included in TotalCost
+	MinerPenalty big.Int
+	// TotalCost the amount paid by the sender, BaseFeeBurn + OverEstimationBurn + MinerTip
+	TotalCost big.Int
+
+	CreatedAt time.Time
+}
+
+type FeeReportGroupBy string
+
+const (
+	// FeeReportByActor groups fees by the actor messages sent to
+	FeeReportByActor FeeReportGroupBy = "actor"
+	// FeeReportByMethod groups fees by the method of messages
+	FeeReportByMethod FeeReportGroupBy = "method"
+	// FeeReportByDay groups fees by the UTC day messages landed on chain
+	FeeReportByDay FeeReportGroupBy = "day"
+)
+
+func (g FeeReportGroupBy) Valid() bool {
+	switch g {
+	case FeeReportByActor, FeeReportByMethod, FeeReportByDay:
+		return true
+	}
+	return false
+}
+
+type FeeReportParams struct {
+	// From the senders of messages, all addresses if empty
+	From []address.Address
+	// Since and Until limit the on chain time of messages to [Since, Until), zero means unlimited
+	Since   time.Time
+	Until   time.Time
+	GroupBy FeeReportGroupBy
+}
+
+type FeeReportItem struct {
+	// Key the actor address, the method number or the day in format 2006-01-02
+	Key   string
+	Count int
+
+	GasUsed            int64
+	BaseFeeBurn        big.Int
+	OverEstimationBurn big.Int
+	MinerTip           big.Int
+	MinerPenalty       big.Int
+	TotalCost          big.Int
+}
+
+func NewFeeReportItem(key string) *FeeReportItem {
+	return &FeeReportItem{
+		Key:                key,
+		BaseFeeBurn:        big.Zero(),
+		OverEstimationBurn: big.Zero(),
+		MinerTip:           big.Zero(),
+		MinerPenalty:       big.Zero(),
+		TotalCost:          big.Zero(),
+	}
+}
+
+// Add accumulates fee into the item
+func (i *FeeReportItem) Add(fee *MessageFee) {
+	i.Count++
+	i.GasUsed += fee.GasUsed
+	i.BaseFeeBurn = big.Add(i.BaseFeeBurn, fee.BaseFeeBurn)
+	i.OverEstimationBurn = big.Add(i.OverEstimationBurn, fee.OverEstimationBurn)
+	i.MinerTip = big.Add(i.MinerTip, fee.MinerTip)
+	i.MinerPenalty = big.Add(i.MinerPenalty, fee.MinerPenalty)
+	i.TotalCost = big.Add(i.TotalCost, fee.TotalCost)
+}
+
+type FeeReport struct {
+	GroupBy FeeReportGroupBy
+	Since   time.Time
+	Until   time.Time
+	// Items sorted by key, method numbers are sorted numerically
+	Items []*FeeReportItem
+	Total *FeeReportItem
+}
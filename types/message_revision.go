@@ -0,0 +1,76 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	types "github.com/filecoin-project/venus/venus-shared/types/messager"
+	"github.com/ipfs/go-cid"
+)
+
+// RevisionReason why a new signed version of message was created
+type RevisionReason string
+
+const (
+	// RevisionSelect the message was signed when it was selected
+	RevisionSelect RevisionReason = "select"
+	// RevisionReplace the message was replaced by `ReplaceMessage`
+	RevisionReplace RevisionReason = "replace"
+	// RevisionFeeBump the message was replaced by the fee bump loop
+	RevisionFeeBump RevisionReason = "fee_bump"
+	// RevisionFillNonceGap the failed message was re-signed to fill a nonce gap
+	RevisionFillNonceGap RevisionReason = "fill_nonce_gap"
+)
+
+// MessageRevision a signed version of message, any of them may land on chain
+type MessageRevision struct {
+	ID    string
+	MsgID string
+	Nonce uint64
+
+	UnsignedCid cid.Cid
+	SignedCid   cid.Cid
+	Signature   *crypto.Signature
+	// EthHash the hash of eth transaction if the message is signed by a delegated address, otherwise empty
+	EthHash venusTypes.EthHash
+
+	GasLimit   int64
+	GasFeeCap  big.Int
+	GasPremium big.Int
+
+	Reason RevisionReason
+	// Operator the user who replaced the message, empty if the revision was created by messager itself
+	Operator string
+
+	CreatedAt time.Time
+}
+
+// NewMessageRevision records the current signed version of msg
+func NewMessageRevision(msg *types.Message, reason RevisionReason, operator string) *MessageRevision {
+	rev := &MessageRevision{
+		ID:          venusTypes.NewUUID().String(),
+		MsgID:       msg.ID,
+		Nonce:       msg.Nonce,
+		UnsignedCid: cid.Undef,
+		SignedCid:   cid.Undef,
+		Signature:   msg.Signature,
+		GasLimit:    msg.GasLimit,
+		GasFeeCap:   msg.GasFeeCap,
+		GasPremium:  msg.GasPremium,
+		Reason:      reason,
+		Operator:    operator,
+	}
+	if msg.UnsignedCid != nil {
+		rev.UnsignedCid = *msg.UnsignedCid
+	}
+	if msg.SignedCid != nil {
+		rev.SignedCid = *msg.SignedCid
+	}
+	// a delegated signature not from an eth transaction leaves the hash empty
+	if hash, err := EthTxHash(msg); err == nil {
+		rev.EthHash = hash
+	}
+	return rev
+}
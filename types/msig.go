@@ -0,0 +1,77 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/exitcode"
+)
+
+// MsigProposalState the state of a multisig transaction proposed through messager
+type MsigProposalState string
+
+const (
+	// MsigProposalPending the propose message is not on chain yet
+	MsigProposalPending MsigProposalState = "pending"
+	// MsigProposalProposed the transaction is created in multisig and waiting for approvals
+	MsigProposalProposed MsigProposalState = "proposed"
+	// MsigProposalApplied the transaction has been approved by enough signers and executed
+	MsigProposalApplied MsigProposalState = "applied"
+	// MsigProposalFailed the propose message failed on chain
+	MsigProposalFailed MsigProposalState = "failed"
+)
+
+// NoTxnID the TxnID of proposal before its propose message lands on chain
+const NoTxnID int64 = -1
+
+// MsigProposal a multisig transaction proposed through messager, the inner message is wrapped into
+// the propose message, other signers managed by messager approve it by the TxnID
+type MsigProposal struct {
+	ID       string
+	Msig     address.Address
+	Proposer address.Address
+
+	// the inner message executed by multisig
+	To     address.Address
+	Value  big.Int
+	Method abi.MethodNum
+	Params []byte
+
+	ProposeMsgID string
+	// TxnID the id of transaction in multisig, parsed from the return of propose message
+	TxnID int64
+	// Approvers the signers whose approve messages pushed through messager landed on chain successfully
+	Approvers []address.Address
+	State     MsigProposalState
+	// ExitCode and Return the result of inner message once the transaction is applied
+	ExitCode exitcode.ExitCode
+	Return   []byte
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MsigProposeParams the params to propose a multisig transaction from a signer of the multisig
+type MsigProposeParams struct {
+	Msig address.Address
+	From address.Address
+
+	To     address.Address
+	Value  big.Int
+	Method abi.MethodNum
+	Params []byte
+
+	// Spec the send spec of propose message, optional
+	Spec *SendSpec
+}
+
+// MsigApproveParams the params to approve a proposal from another signer of the multisig
+type MsigApproveParams struct {
+	ProposalID string
+	From       address.Address
+
+	// Spec the send spec of approve message, optional
+	Spec *SendSpec
+}
@@ -0,0 +1,28 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// NonceGap a nonce which is assigned by messager but has no FillMsg message, the messages with bigger nonce
+// will never be packed until the gap is filled
+type NonceGap struct {
+	Nonce uint64
+	// FailedMsgID the signed message with the nonce which is marked failed, it will be re-signed to fill the gap,
+	// empty means a zero value message sent to self will be created
+	FailedMsgID string
+}
+
+// NonceGapReport the nonce gaps between the nonce on chain and the nonce assigned by messager
+type NonceGapReport struct {
+	Address address.Address
+	Height  abi.ChainEpoch
+
+	// AddressNonce the next nonce will be assigned by messager
+	AddressNonce    uint64
+	ActorNonce      uint64
+	NonceInLatestTs uint64
+
+	Gaps []*NonceGap
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/ipfs/go-cid"
+)
+
+type NotificationKind string
+
+const (
+	// NotifyExecFailed the message landed on chain with a non-zero exit code
+	NotifyExecFailed NotificationKind = "exec_failed"
+	// NotifyReplaced another message with the same nonce landed on chain
+	NotifyReplaced  NotificationKind = "replaced"
+	NotifyMarkedBad NotificationKind = "marked_bad"
+	// NotifyBlocked the message is not on chain after BlockedFor since it was created
+	NotifyBlocked NotificationKind = "blocked"
+	// NotifyAlert the message landed on chain with the exit code of an exit code rule with the alert action
+	NotifyAlert NotificationKind = "alert"
+)
+
+// Notification is the payload posted to webhooks
+type Notification struct {
+	// ID the unique key of notification, the same notification is only sent once
+	ID   string
+	Kind NotificationKind
+
+	MsgID     string
+	From      address.Address
+	Nonce     uint64
+	SignedCid *cid.Cid
+
+	// Height and ExitCode are only set for exec_failed, replaced and alert
+	Height   abi.ChainEpoch
+	ExitCode exitcode.ExitCode
+	// BlockedFor only set for blocked
+	BlockedFor time.Duration
+	// RuleID the exit code rule, only set for alert
+	RuleID string `json:",omitempty"`
+
+	CreatedAt time.Time
+}
+
+type OutboxState int
+
+const (
+	OutboxPending OutboxState = iota
+	OutboxSent
+	// OutboxFailed the notification is given up after too many failed attempts
+	OutboxFailed
+)
+
+func (s OutboxState) String() string {
+	switch s {
+	case OutboxPending:
+		return "pending"
+	case OutboxSent:
+		return "sent"
+	case OutboxFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// OutboxNotification a notification waiting to be delivered to a webhook url
+type OutboxNotification struct {
+	ID             string
+	NotificationID string
+	URL            string
+	// Payload the json encoded Notification
+	Payload []byte
+
+	State         OutboxState
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
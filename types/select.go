@@ -0,0 +1,60 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+// GasSpec the gas params of message merged from message, address, actor config and shared params
+type GasSpec struct {
+	GasOverEstimation float64
+	MaxFee            big.Int
+	GasOverPremium    float64
+	GasFeeCap         big.Int
+	BaseFee           big.Int
+}
+
+// SelectSimulation the result of running message selection of an address against the current head,
+// without signing and saving messages
+type SelectSimulation struct {
+	Address  address.Address
+	Height   abi.ChainEpoch
+	BaseFee  abi.TokenAmount
+	Strategy string
+
+	ActorNonce      uint64
+	NonceInLatestTs uint64
+	// AssignedNonce the nonce will be assigned to the first selected message
+	AssignedNonce uint64
+	NonceGap      uint64
+	WantCount     uint64
+	// Balance the balance of actor, messages are held back when their cost exceeds Balance minus BalanceReserve
+	Balance        abi.TokenAmount
+	BalanceReserve abi.TokenAmount
+	// Reason why no message will be selected, empty if the selection reaches the candidates
+	Reason string
+
+	Messages []*SimulatedMessage
+}
+
+// SimulatedMessage the selection result of a candidate message
+type SimulatedMessage struct {
+	ID string
+	// Selected means the message will be signed and pushed
+	Selected bool
+	// Nonce the nonce will be assigned to the selected message
+	Nonce uint64
+
+	// GasSpec nil if the message is skipped before merging gas params
+	GasSpec *GasSpec
+
+	GasLimit   int64
+	GasFeeCap  abi.TokenAmount
+	GasPremium abi.TokenAmount
+
+	// Skipped is the reason why the message is not selected in this round but stays unfill
+	Skipped string
+	// Err is the error will be recorded to the message
+	Err string
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// SignerPolicy an allow rule of the messages pushed from Signer. The messages from a signer without any policy
+// are not restricted, otherwise a message is accepted only if it matches one of the policies of its signer
+type SignerPolicy struct {
+	ID     string
+	Signer address.Address
+
+	// To the recipients allowed, any recipient if empty
+	To []address.Address
+	// ActorType the type of recipient actor allowed, eg. storageminer, multisig, any type if empty
+	ActorType string
+	// Methods the methods allowed, any method if empty
+	Methods []abi.MethodNum
+	// OwnedMinerOnly only allows the miner actors whose owner, worker or control address is the signer
+	OwnedMinerOnly bool
+	// AllowValue allows the message to transfer value, only zero value messages match the policy if false
+	AllowValue bool
+
+	Description string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
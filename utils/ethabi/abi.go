@@ -0,0 +1,198 @@
+// Package ethabi encodes and decodes the calls of solidity contracts by the json abi
+package ethabi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// SelectorSize the size of method selector at the beginning of calldata
+const SelectorSize = 4
+
+// Argument an input or output of method in the json abi
+type Argument struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Method a function of contract
+type Method struct {
+	Name    string
+	Inputs  []Argument
+	Outputs []Argument
+	// Sig the canonical signature, eg. transfer(address,uint256)
+	Sig string
+	// Selector the first 4 bytes of keccak256 of Sig
+	Selector []byte
+
+	inputTypes  []*Type
+	outputTypes []*Type
+}
+
+// ABI the functions of contract, other entries like events and errors are ignored
+type ABI struct {
+	Methods []*Method
+	// Unsupported the names of functions skipped for using the types not supported, eg. tuple
+	Unsupported []string
+}
+
+type abiEntry struct {
+	Type    string     `json:"type"`
+	Name    string     `json:"name"`
+	Inputs  []Argument `json:"inputs"`
+	Outputs []Argument `json:"outputs"`
+}
+
+// Parse parses the json abi generated by solc, the functions using unsupported types are skipped rather than
+// failing the whole abi
+func Parse(data []byte) (*ABI, error) {
+	var entries []abiEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid json abi: %w", err)
+	}
+
+	abi := &ABI{}
+	for _, entry := range entries {
+		// the type of function can be omitted
+		if entry.Type != "function" && entry.Type != "" {
+			continue
+		}
+		method, err := newMethod(entry.Name, entry.Inputs, entry.Outputs)
+		if errors.Is(err, ErrUnsupportedType) {
+			abi.Unsupported = append(abi.Unsupported, entry.Name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", entry.Name, err)
+		}
+		abi.Methods = append(abi.Methods, method)
+	}
+	return abi, nil
+}
+
+func newMethod(name string, inputs, outputs []Argument) (*Method, error) {
+	method := &Method{
+		Name:    name,
+		Inputs:  inputs,
+		Outputs: outputs,
+	}
+	var err error
+	if method.inputTypes, err = parseArguments(inputs); err != nil {
+		return nil, err
+	}
+	if method.outputTypes, err = parseArguments(outputs); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(method.inputTypes))
+	for _, t := range method.inputTypes {
+		names = append(names, t.String())
+	}
+	method.Sig = fmt.Sprintf("%s(%s)", name, strings.Join(names, ","))
+	hasher := sha3.NewLegacyKeccak256()
+	_, _ = hasher.Write([]byte(method.Sig))
+	method.Selector = hasher.Sum(nil)[:SelectorSize]
+	return method, nil
+}
+
+func parseArguments(args []Argument) ([]*Type, error) {
+	types := make([]*Type, 0, len(args))
+	for _, arg := range args {
+		t, err := ParseType(arg.Type)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// Method finds the method by its signature, or by its name if there is only one method with the name
+func (a *ABI) Method(sig string) (*Method, error) {
+	sig = strings.ReplaceAll(sig, " ", "")
+	var found []*Method
+	for _, method := range a.Methods {
+		if method.Sig == sig {
+			return method, nil
+		}
+		if method.Name == sig {
+			found = append(found, method)
+		}
+	}
+	switch len(found) {
+	case 0:
+		for _, name := range a.Unsupported {
+			if strings.HasPrefix(sig, name+"(") || sig == name {
+				return nil, fmt.Errorf("method %s uses the types not supported", sig)
+			}
+		}
+		return nil, fmt.Errorf("method %s not found in abi", sig)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("method %s is overloaded, use the signature", sig)
+	}
+}
+
+// MethodBySelector finds the method called by calldata
+func (a *ABI) MethodBySelector(calldata []byte) (*Method, error) {
+	if len(calldata) < SelectorSize {
+		return nil, fmt.Errorf("calldata too short")
+	}
+	for _, method := range a.Methods {
+		if bytes.Equal(method.Selector, calldata[:SelectorSize]) {
+			return method, nil
+		}
+	}
+	return nil, fmt.Errorf("method of selector %x not found in abi", calldata[:SelectorSize])
+}
+
+// EncodeCallJSON encodes the calldata from the arguments in a json array
+func (m *Method) EncodeCallJSON(args string) ([]byte, error) {
+	var raws []json.RawMessage
+	if len(strings.TrimSpace(args)) > 0 {
+		if err := json.Unmarshal([]byte(args), &raws); err != nil {
+			return nil, fmt.Errorf("arguments should be a json array: %w", err)
+		}
+	}
+	if len(raws) != len(m.inputTypes) {
+		return nil, fmt.Errorf("%s expects %d arguments, got %d", m.Sig, len(m.inputTypes), len(raws))
+	}
+
+	values := make([]interface{}, 0, len(raws))
+	for i, raw := range raws {
+		v, err := valueFromJSON(m.inputTypes[i], raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		values = append(values, v)
+	}
+	return m.EncodeCall(values...)
+}
+
+// EncodeCall encodes the calldata, see codec.go for the go values of solidity types
+func (m *Method) EncodeCall(values ...interface{}) ([]byte, error) {
+	data, err := encodeTuple(m.inputTypes, values)
+	if err != nil {
+		return nil, err
+	}
+	return append(bytes.Clone(m.Selector), data...), nil
+}
+
+// DecodeCall decodes the arguments from calldata
+func (m *Method) DecodeCall(calldata []byte) ([]interface{}, error) {
+	if len(calldata) < SelectorSize || !bytes.Equal(calldata[:SelectorSize], m.Selector) {
+		return nil, fmt.Errorf("calldata does not call %s", m.Sig)
+	}
+	return decodeTuple(m.inputTypes, calldata[SelectorSize:])
+}
+
+// DecodeReturn decodes the outputs from the return data
+func (m *Method) DecodeReturn(data []byte) ([]interface{}, error) {
+	return decodeTuple(m.outputTypes, data)
+}
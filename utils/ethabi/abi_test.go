@@ -0,0 +1,151 @@
+package ethabi
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"baz","inputs":[{"name":"x","type":"uint32"},{"name":"y","type":"bool"}],"outputs":[]},
+	{"type":"function","name":"sam","inputs":[{"name":"a","type":"bytes"},{"name":"b","type":"bool"},{"name":"c","type":"uint256[]"}],"outputs":[{"name":"","type":"string"},{"name":"","type":"int8"}]},
+	{"type":"function","name":"set","inputs":[{"name":"v","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"set","inputs":[{"name":"v","type":"bytes3[2]"}],"outputs":[]},
+	{"type":"function","name":"submit","inputs":[{"name":"o","type":"tuple","components":[{"name":"a","type":"uint256"}]}],"outputs":[]},
+	{"type":"event","name":"Transfer","inputs":[{"name":"from","type":"address","indexed":true}]}
+]`
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	require.NoError(t, err)
+	return b
+}
+
+func TestEncodeCall(t *testing.T) {
+	abi, err := Parse([]byte(testABI))
+	require.NoError(t, err)
+	require.Len(t, abi.Methods, 5)
+
+	transfer, err := abi.Method("transfer")
+	require.NoError(t, err)
+	assert.Equal(t, "transfer(address,uint256)", transfer.Sig)
+	assert.Equal(t, mustHex(t, "a9059cbb"), transfer.Selector)
+
+	// the examples in the solidity abi specification
+	baz, err := abi.Method("baz(uint32, bool)")
+	require.NoError(t, err)
+	data, err := baz.EncodeCallJSON(`[69, true]`)
+	require.NoError(t, err)
+	assert.Equal(t, mustHex(t, `cdcd77c0
+		0000000000000000000000000000000000000000000000000000000000000045
+		0000000000000000000000000000000000000000000000000000000000000001`), data)
+
+	sam, err := abi.Method("sam")
+	require.NoError(t, err)
+	data, err = sam.EncodeCallJSON(`["0x64617665", true, [1, "2", "0x3"]]`)
+	require.NoError(t, err)
+	assert.Equal(t, mustHex(t, `a5643bf2
+		0000000000000000000000000000000000000000000000000000000000000060
+		0000000000000000000000000000000000000000000000000000000000000001
+		00000000000000000000000000000000000000000000000000000000000000a0
+		0000000000000000000000000000000000000000000000000000000000000004
+		6461766500000000000000000000000000000000000000000000000000000000
+		0000000000000000000000000000000000000000000000000000000000000003
+		0000000000000000000000000000000000000000000000000000000000000001
+		0000000000000000000000000000000000000000000000000000000000000002
+		0000000000000000000000000000000000000000000000000000000000000003`), data)
+
+	args, err := sam.DecodeCall(data)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		venusTypes.EthBytes("dave"),
+		true,
+		[]interface{}{big.NewInt(1), big.NewInt(2), big.NewInt(3)},
+	}, args)
+
+	_, err = abi.Method("set")
+	assert.Error(t, err)
+	set, err := abi.Method("set(bytes3[2])")
+	require.NoError(t, err)
+	data, err = set.EncodeCallJSON(`[["0x616263", "0x646566"]]`)
+	require.NoError(t, err)
+	method, err := abi.MethodBySelector(data)
+	require.NoError(t, err)
+	assert.Equal(t, set, method)
+	args, err = set.DecodeCall(data)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{[]interface{}{venusTypes.EthBytes("abc"), venusTypes.EthBytes("def")}}, args)
+
+	// a filecoin address is accepted for the address argument
+	to, err := venusTypes.ParseEthAddress("0xd4c5fb16488aa48081296299d54b0c648c9333da")
+	require.NoError(t, err)
+	toAddr, err := to.ToFilecoinAddress()
+	require.NoError(t, err)
+	data, err = transfer.EncodeCallJSON(`["` + toAddr.String() + `", "1000"]`)
+	require.NoError(t, err)
+	args, err = transfer.DecodeCall(data)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{to, big.NewInt(1000)}, args)
+
+	for _, invalid := range []string{`[69]`, `[-1, true]`, `[4294967296, true]`, `["x", true]`, `{}`} {
+		_, err = baz.EncodeCallJSON(invalid)
+		assert.Error(t, err, invalid)
+	}
+	_, err = baz.DecodeCall(data)
+	assert.Error(t, err)
+}
+
+func TestDecodeReturn(t *testing.T) {
+	abi, err := Parse([]byte(testABI))
+	require.NoError(t, err)
+	sam, err := abi.Method("sam")
+	require.NoError(t, err)
+
+	ret, err := encodeTuple(sam.outputTypes, []interface{}{"hello", big.NewInt(-2)})
+	require.NoError(t, err)
+	values, err := sam.DecodeReturn(ret)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"hello", big.NewInt(-2)}, values)
+
+	// the string is cut off
+	_, err = sam.DecodeReturn(ret[:3*wordSize+3])
+	assert.Error(t, err)
+	_, err = sam.DecodeReturn(ret[:wordSize])
+	assert.Error(t, err)
+}
+
+func TestParseType(t *testing.T) {
+	for _, s := range []string{"uint", "int8", "bytes32", "address[]", "uint256[3][]", "string[2]"} {
+		_, err := ParseType(s)
+		assert.NoError(t, err, s)
+	}
+	for _, s := range []string{"uint7", "int512", "bytes33", "bytes0", "[]", "uint[0]", "tuple"} {
+		_, err := ParseType(s)
+		assert.Error(t, err, s)
+	}
+	typ, err := ParseType("uint")
+	require.NoError(t, err)
+	assert.Equal(t, "uint256", typ.String())
+	_, err = ParseType("tuple[]")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestParseUnsupported(t *testing.T) {
+	abi, err := Parse([]byte(testABI))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"submit"}, abi.Unsupported)
+	_, err = abi.Method("submit")
+	assert.ErrorContains(t, err, "not supported")
+	_, err = abi.Method("submit((uint256))")
+	assert.ErrorContains(t, err, "not supported")
+
+	// the invalid type still fails the abi
+	_, err = Parse([]byte(`[{"type":"function","name":"f","inputs":[{"name":"a","type":"uint7"}],"outputs":[]}]`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,333 @@
+package ethabi
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	venusTypes "github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// The go values of solidity types:
+//   address         venusTypes.EthAddress
+//   bool            bool
+//   uintN, intN     *big.Int
+//   bytesN, bytes   venusTypes.EthBytes
+//   string          string
+//   T[], T[n]       []interface{}
+
+// encodeTuple encodes values as a tuple of types, the head holds static values and offsets of dynamic values
+func encodeTuple(types []*Type, values []interface{}) ([]byte, error) {
+	if len(types) != len(values) {
+		return nil, fmt.Errorf("expect %d values, got %d", len(types), len(values))
+	}
+	headSize := 0
+	for _, t := range types {
+		headSize += t.headSize()
+	}
+
+	var head, tail []byte
+	for i, t := range types {
+		enc, err := encodeValue(t, values[i])
+		if err != nil {
+			return nil, err
+		}
+		if t.dynamic() {
+			head = append(head, uintWord(uint64(headSize+len(tail)))...)
+			tail = append(tail, enc...)
+			continue
+		}
+		head = append(head, enc...)
+	}
+	return append(head, tail...), nil
+}
+
+func encodeValue(t *Type, v interface{}) ([]byte, error) {
+	switch t.Kind {
+	case AddressKind:
+		addr, ok := v.(venusTypes.EthAddress)
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		return leftPad(addr[:]), nil
+	case BoolKind:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		if b {
+			return uintWord(1), nil
+		}
+		return uintWord(0), nil
+	case UintKind, IntKind:
+		i, ok := v.(*big.Int)
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		if err := checkIntRange(t, i); err != nil {
+			return nil, err
+		}
+		// two's complement of negative value
+		if i.Sign() < 0 {
+			i = new(big.Int).Add(i, new(big.Int).Lsh(big.NewInt(1), wordSize*8))
+		}
+		return leftPad(i.Bytes()), nil
+	case FixedBytesKind:
+		b, ok := v.(venusTypes.EthBytes)
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		if len(b) != t.Size {
+			return nil, fmt.Errorf("expect %d bytes for %s, got %d", t.Size, t, len(b))
+		}
+		return rightPad(b), nil
+	case BytesKind:
+		b, ok := v.(venusTypes.EthBytes)
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		return append(uintWord(uint64(len(b))), rightPad(b)...), nil
+	case StringKind:
+		s, ok := v.(string)
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		return append(uintWord(uint64(len(s))), rightPad([]byte(s))...), nil
+	case SliceKind:
+		elems, ok := v.([]interface{})
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		enc, err := encodeTuple(repeat(t.Elem, len(elems)), elems)
+		if err != nil {
+			return nil, err
+		}
+		return append(uintWord(uint64(len(elems))), enc...), nil
+	case ArrayKind:
+		elems, ok := v.([]interface{})
+		if !ok {
+			return nil, typeMismatch(t, v)
+		}
+		if len(elems) != t.Size {
+			return nil, fmt.Errorf("expect %d elements for %s, got %d", t.Size, t, len(elems))
+		}
+		return encodeTuple(repeat(t.Elem, len(elems)), elems)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// decodeTuple decodes the tuple of types from data
+func decodeTuple(types []*Type, data []byte) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(types))
+	offset := 0
+	for _, t := range types {
+		if offset+t.headSize() > len(data) {
+			return nil, fmt.Errorf("data too short to decode %s", t)
+		}
+		var v interface{}
+		var err error
+		if t.dynamic() {
+			var ptr int
+			if ptr, err = readLength(data[offset:]); err != nil {
+				return nil, err
+			}
+			if ptr > len(data) {
+				return nil, fmt.Errorf("offset %d of %s out of range", ptr, t)
+			}
+			v, err = decodeValue(t, data[ptr:])
+		} else {
+			v, err = decodeValue(t, data[offset:])
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		offset += t.headSize()
+	}
+	return values, nil
+}
+
+func decodeValue(t *Type, data []byte) (interface{}, error) {
+	if len(data) < wordSize && t.Kind != ArrayKind {
+		return nil, fmt.Errorf("data too short to decode %s", t)
+	}
+	switch t.Kind {
+	case AddressKind:
+		var addr venusTypes.EthAddress
+		copy(addr[:], data[wordSize-len(addr):wordSize])
+		return addr, nil
+	case BoolKind:
+		return new(big.Int).SetBytes(data[:wordSize]).Sign() != 0, nil
+	case UintKind:
+		return new(big.Int).SetBytes(data[:wordSize]), nil
+	case IntKind:
+		i := new(big.Int).SetBytes(data[:wordSize])
+		if data[0]&0x80 != 0 {
+			i.Sub(i, new(big.Int).Lsh(big.NewInt(1), wordSize*8))
+		}
+		return i, nil
+	case FixedBytesKind:
+		return venusTypes.EthBytes(bytes.Clone(data[:t.Size])), nil
+	case BytesKind, StringKind:
+		n, err := readLength(data)
+		if err != nil {
+			return nil, err
+		}
+		if wordSize+n > len(data) {
+			return nil, fmt.Errorf("length %d of %s out of range", n, t)
+		}
+		b := bytes.Clone(data[wordSize : wordSize+n])
+		if t.Kind == StringKind {
+			return string(b), nil
+		}
+		return venusTypes.EthBytes(b), nil
+	case SliceKind:
+		n, err := readLength(data)
+		if err != nil {
+			return nil, err
+		}
+		// every element takes at least a word
+		if n > (len(data)-wordSize)/wordSize {
+			return nil, fmt.Errorf("length %d of %s out of range", n, t)
+		}
+		return decodeTuple(repeat(t.Elem, n), data[wordSize:])
+	case ArrayKind:
+		return decodeTuple(repeat(t.Elem, t.Size), data)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// valueFromJSON converts the json value to the go value of t, numbers can be json numbers or decimal / hex strings,
+// bytes are hex strings and addresses can be eth addresses or filecoin addresses
+func valueFromJSON(t *Type, raw json.RawMessage) (interface{}, error) {
+	switch t.Kind {
+	case AddressKind:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("expect string for %s: %w", t, err)
+		}
+		if strings.HasPrefix(s, "0x") {
+			return venusTypes.ParseEthAddress(s)
+		}
+		addr, err := address.NewFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		return venusTypes.EthAddressFromFilecoinAddress(addr)
+	case BoolKind:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, fmt.Errorf("expect bool for %s: %w", t, err)
+		}
+		return b, nil
+	case UintKind, IntKind:
+		s := string(raw)
+		if strings.HasPrefix(s, `"`) {
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil, err
+			}
+		}
+		i, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid number %s for %s", s, t)
+		}
+		if err := checkIntRange(t, i); err != nil {
+			return nil, err
+		}
+		return i, nil
+	case FixedBytesKind, BytesKind:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("expect hex string for %s: %w", t, err)
+		}
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex %s for %s: %w", s, t, err)
+		}
+		if t.Kind == FixedBytesKind && len(b) != t.Size {
+			return nil, fmt.Errorf("expect %d bytes for %s, got %d", t.Size, t, len(b))
+		}
+		return venusTypes.EthBytes(b), nil
+	case StringKind:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("expect string for %s: %w", t, err)
+		}
+		return s, nil
+	case SliceKind, ArrayKind:
+		var raws []json.RawMessage
+		if err := json.Unmarshal(raw, &raws); err != nil {
+			return nil, fmt.Errorf("expect array for %s: %w", t, err)
+		}
+		if t.Kind == ArrayKind && len(raws) != t.Size {
+			return nil, fmt.Errorf("expect %d elements for %s, got %d", t.Size, t, len(raws))
+		}
+		elems := make([]interface{}, 0, len(raws))
+		for _, r := range raws {
+			elem, err := valueFromJSON(t.Elem, r)
+			if err != nil {
+				return nil, err
+			}
+			elems = append(elems, elem)
+		}
+		return elems, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func checkIntRange(t *Type, i *big.Int) error {
+	bits := t.Size
+	if t.Kind == IntKind {
+		bits--
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	lower := big.NewInt(0)
+	if t.Kind == IntKind {
+		lower = new(big.Int).Neg(limit)
+	}
+	if i.Cmp(lower) < 0 || i.Cmp(limit) >= 0 {
+		return fmt.Errorf("%s out of range of %s", i, t)
+	}
+	return nil
+}
+
+func typeMismatch(t *Type, v interface{}) error {
+	return fmt.Errorf("can not encode %T as %s", v, t)
+}
+
+func uintWord(v uint64) []byte {
+	return leftPad(new(big.Int).SetUint64(v).Bytes())
+}
+
+// readLength reads the word at the beginning of data as a length or an offset
+func readLength(data []byte) (int, error) {
+	if len(data) < wordSize {
+		return 0, fmt.Errorf("data too short to read length")
+	}
+	n := new(big.Int).SetBytes(data[:wordSize])
+	if !n.IsInt64() || n.Int64() > math.MaxInt32 {
+		return 0, fmt.Errorf("length %s out of range", n)
+	}
+	return int(n.Int64()), nil
+}
+
+func leftPad(b []byte) []byte {
+	word := make([]byte, wordSize)
+	copy(word[wordSize-len(b):], b)
+	return word
+}
+
+func rightPad(b []byte) []byte {
+	size := (len(b) + wordSize - 1) / wordSize * wordSize
+	padded := make([]byte, size)
+	copy(padded, b)
+	return padded
+}
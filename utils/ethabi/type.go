@@ -0,0 +1,132 @@
+package ethabi
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedType the type is valid in solidity but not supported by the codec, eg. tuple
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// wordSize the size of a slot in the solidity abi encoding
+const wordSize = 32
+
+// Kind the kind of solidity type
+type Kind int
+
+const (
+	AddressKind Kind = iota
+	BoolKind
+	UintKind
+	IntKind
+	// FixedBytesKind bytes1 to bytes32
+	FixedBytesKind
+	BytesKind
+	StringKind
+	// SliceKind the dynamic array T[]
+	SliceKind
+	// ArrayKind the fixed array T[n]
+	ArrayKind
+)
+
+// Type a solidity type supported by the codec, tuples are not supported
+type Type struct {
+	Kind Kind
+	// Size the bits of uint and int, the length of fixed bytes and fixed array
+	Size int
+	// Elem the element type of slice and array
+	Elem *Type
+
+	raw string
+}
+
+// ParseType parses a canonical type name, eg. uint256, address[], bytes32[2]
+func ParseType(s string) (*Type, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "]") {
+		i := strings.LastIndex(s, "[")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid type %s", s)
+		}
+		elem, err := ParseType(s[:i])
+		if err != nil {
+			return nil, err
+		}
+		if i+1 == len(s)-1 {
+			return &Type{Kind: SliceKind, Elem: elem, raw: s}, nil
+		}
+		size, err := strconv.Atoi(s[i+1 : len(s)-1])
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid array length of %s", s)
+		}
+		return &Type{Kind: ArrayKind, Size: size, Elem: elem, raw: s}, nil
+	}
+
+	switch {
+	case s == "address":
+		return &Type{Kind: AddressKind, raw: s}, nil
+	case s == "bool":
+		return &Type{Kind: BoolKind, raw: s}, nil
+	case s == "string":
+		return &Type{Kind: StringKind, raw: s}, nil
+	case s == "bytes":
+		return &Type{Kind: BytesKind, raw: s}, nil
+	case s == "uint" || s == "int":
+		// aliases of uint256 and int256
+		return ParseType(s + "256")
+	case strings.HasPrefix(s, "uint"):
+		return parseIntType(UintKind, s, "uint")
+	case strings.HasPrefix(s, "int"):
+		return parseIntType(IntKind, s, "int")
+	case strings.HasPrefix(s, "bytes"):
+		size, err := strconv.Atoi(s[len("bytes"):])
+		if err != nil || size <= 0 || size > wordSize {
+			return nil, fmt.Errorf("invalid type %s", s)
+		}
+		return &Type{Kind: FixedBytesKind, Size: size, raw: s}, nil
+	default:
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedType, s)
+	}
+}
+
+func parseIntType(kind Kind, s, prefix string) (*Type, error) {
+	size, err := strconv.Atoi(s[len(prefix):])
+	if err != nil || size <= 0 || size > 256 || size%8 != 0 {
+		return nil, fmt.Errorf("invalid type %s", s)
+	}
+	return &Type{Kind: kind, Size: size, raw: s}, nil
+}
+
+func (t *Type) String() string {
+	return t.raw
+}
+
+// dynamic the value of dynamic type is encoded in the tail and referenced by an offset in the head
+func (t *Type) dynamic() bool {
+	switch t.Kind {
+	case BytesKind, StringKind, SliceKind:
+		return true
+	case ArrayKind:
+		return t.Elem.dynamic()
+	default:
+		return false
+	}
+}
+
+// headSize the size taken in the head of tuple
+func (t *Type) headSize() int {
+	if t.Kind == ArrayKind && !t.dynamic() {
+		return t.Size * t.Elem.headSize()
+	}
+	return wordSize
+}
+
+func repeat(t *Type, n int) []*Type {
+	types := make([]*Type, n)
+	for i := range types {
+		types[i] = t
+	}
+	return types
+}